@@ -4,6 +4,11 @@ import (
 	"bannersrv/internal/app/config"
 	"bannersrv/internal/banner"
 	bp "bannersrv/internal/banner/repository/postgres"
+	bu "bannersrv/internal/banner/usecase"
+	"bannersrv/internal/caches"
+	cm "bannersrv/internal/caches/manager"
+	cr "bannersrv/internal/caches/repository/redis"
+	"bannersrv/pkg/logger"
 	"context"
 	"flag"
 	"fmt"
@@ -16,6 +21,7 @@ import (
 	"github.com/go-co-op/gocron/v2"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
@@ -66,14 +72,39 @@ func main() { // nolint: revive // this a small executable file and big length o
 
 	l.Println("INIT: success check connection to postgresql")
 
+	// Redis
+	opt, err := redis.ParseURL(cfg.Redis.URL)
+	if err != nil {
+		l.Fatalf("INIT: - redis.New: %s", err)
+	}
+
+	rds := redis.NewClient(opt)
+	defer func() {
+		_ = rds.Close() // nolint: errcheck // нет смысла логировать ошибку закрытия при выключении сервиса
+	}()
+
+	// Без Redis прогрев кэша завершается ошибкой, пока Redis не станет доступен, остальные задачи не затрагиваются
+	if err = rds.Ping(context.Background()).Err(); err != nil {
+		l.Printf("WARN: can't check connection to redis with error %s", err)
+	} else {
+		l.Println("INIT: success check connection to redis")
+	}
+
 	// Repository
 	bannerRepository := bp.NewBannerRepository(pg)
 
+	// Use-cases
+	cacheLogger := logger.New(logger.Params{AppName: "cron-service", Level: logger.WarnLevel}, os.Stderr)
+	cacheManager := cm.NewCacheManager(cr.NewCashRedis(rds), cfg.Cache.TTL, nil, cacheLogger)
+	popularityManager := cm.NewPopularityManager(cr.NewPopularityRedis(rds, cfg.Cache.Popularity),
+		cfg.Cache.Popularity, cacheLogger)
+	bannerUsecase := bu.NewBannerUsecase(bannerRepository, cacheManager, popularityManager, nil, &cfg.Coalescing)
+
 	if _, err = cronScheduler.NewJob(
 		gocron.DurationJob(time.Duration(period)*time.Millisecond),
 		gocron.NewTask(
 			func(rep banner.Repository, l *log.Logger) {
-				if err := rep.CleanDeletedBanner(); err != nil {
+				if err := rep.CleanDeletedBanner(context.Background()); err != nil {
 					l.Printf("ERROR: %s", errors.Wrap(err, "in cron job of cleaning deleted banner"))
 				}
 				l.Println("INFO: deleted banner was cleaned by cron job")
@@ -85,6 +116,31 @@ func main() { // nolint: revive // this a small executable file and big length o
 		l.Fatalf("INIT: setup gocron task: %s", err)
 	}
 
+	// Кэш в памяти сервиса недоступен cron сервису, поэтому прогревается только кэш в Redis
+	if cfg.Cache.Backend != caches.RedisBackend {
+		l.Println("INIT: cache warming is disabled for cache backend " + string(cfg.Cache.Backend))
+	} else if _, err = cronScheduler.NewJob(
+		gocron.DurationJob(time.Duration(cfg.Cache.Warm.Period)*time.Millisecond),
+		gocron.NewTask(
+			func(usecase banner.Usecase, limit uint64, l *log.Logger) {
+				result, err := usecase.WarmCache(context.Background(), &limit)
+				if err != nil {
+					l.Printf("ERROR: %s", errors.Wrap(err, "in cron job of warming cache"))
+
+					return
+				}
+				l.Printf("INFO: cache was warmed by cron job with %d banners, %d were already cached, %d were skipped",
+					result.Warmed, result.Cached, result.Skipped)
+			},
+			bannerUsecase,
+			cfg.Cache.Warm.Limit,
+			l,
+		),
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+	); err != nil {
+		l.Fatalf("INIT: setup gocron task: %s", err)
+	}
+
 	// Waiting signal
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
@@ -18,32 +18,26 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/banner": {
+        "/api_key": {
             "get": {
                 "security": [
                     {
                         "AdminToken": []
                     }
                 ],
-                "description": "Возвращает список баннеров на основе фильтра по фиче и/или тегу.",
+                "description": "Возвращает выданные ключи доступа от новых к старым, включая отозванные, без самих ключей.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "banner"
+                    "api_key"
                 ],
-                "summary": "Получение всех баннеров c фильтрацией по фиче и/или тегу",
+                "summary": "Получение ключей доступа.",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Идентификатор тэга группы пользователей",
-                        "name": "tag_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Идентификатор фичи",
-                        "name": "feature_id",
+                        "type": "string",
+                        "description": "Владелец ключа",
+                        "name": "owner",
                         "in": "query"
                     },
                     {
@@ -61,11 +55,11 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список баннеров успешно отфильтрован",
+                        "description": "Список ключей",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/response.Banner"
+                                "$ref": "#/definitions/response.APIKey"
                             }
                         }
                     },
@@ -86,6 +80,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/tools.Error"
                         }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
                     }
                 }
             },
@@ -95,7 +95,7 @@ const docTemplate = `{
                         "AdminToken": []
                     }
                 ],
-                "description": "Добавляет баннер включая его содержания, id фичи, список id тэгов и состояние.",
+                "description": "|",
                 "consumes": [
                     "application/json"
                 ],
@@ -103,25 +103,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "banner"
+                    "api_key"
                 ],
-                "summary": "Создание нового баннера.",
+                "summary": "Выдача ключа доступа.",
                 "parameters": [
                     {
-                        "description": "Информация о добавляемом пользователе",
+                        "description": "Параметры ключа",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/request.CreateBanner"
+                            "$ref": "#/definitions/request.CreateAPIKey"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Баннер успешно добавлен в систему",
+                        "description": "Ключ успешно выдан",
                         "schema": {
-                            "$ref": "#/definitions/response.BannerID"
+                            "$ref": "#/definitions/response.IssuedAPIKey"
                         }
                     },
                     "400": {
@@ -136,45 +136,48 @@ const docTemplate = `{
                     "403": {
                         "description": "Пользователь не имеет доступа"
                     },
-                    "409": {
-                        "description": "Баннер с указанной парой id фичи и ia тэга уже существует"
-                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "$ref": "#/definitions/tools.Error"
                         }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
                     }
                 }
             }
         },
-        "/banner/{id}": {
-            "delete": {
+        "/api_key/{id}/revoke": {
+            "post": {
                 "security": [
                     {
                         "AdminToken": []
                     }
                 ],
-                "description": "Удаляет информацию о банере по его id.",
+                "description": "|",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "banner"
+                    "api_key"
                 ],
-                "summary": "Удаление банера.",
+                "summary": "Отзыв ключа доступа.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Идентификатор баннера",
+                        "description": "Идентификатор ключа",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Баннер успешно удалён"
+                    "200": {
+                        "description": "Ключ успешно отозван"
                     },
                     "400": {
                         "description": "Некорректные данные",
@@ -189,54 +192,62 @@ const docTemplate = `{
                         "description": "Пользователь не имеет доступа"
                     },
                     "404": {
-                        "description": "Баннер с данным id не найден"
+                        "description": "Ключ с данным id не найден"
+                    },
+                    "409": {
+                        "description": "Ключ уже отозван"
                     },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "$ref": "#/definitions/tools.Error"
                         }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/api_key/{id}/rotate": {
+            "post": {
                 "security": [
                     {
                         "AdminToken": []
                     }
                 ],
-                "description": "Обновляет информацию о баннере по его id.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "|",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "banner"
+                    "api_key"
                 ],
-                "summary": "Обновление баннера.",
+                "summary": "Ротация ключа доступа.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Идентификатор баннера",
+                        "description": "Идентификатор ключа",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Информация об обновлении",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.UpdateBanner"
-                        }
+                        "type": "integer",
+                        "description": "Время действия заменяемого ключа в секундах",
+                        "name": "grace_period",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Баннер успешно обновлён"
+                    "201": {
+                        "description": "Ключ успешно заменён",
+                        "schema": {
+                            "$ref": "#/definitions/response.IssuedAPIKey"
+                        }
                     },
                     "400": {
                         "description": "Некорректные данные",
@@ -251,52 +262,90 @@ const docTemplate = `{
                         "description": "Пользователь не имеет доступа"
                     },
                     "404": {
-                        "description": "Баннер с данным id не найден"
+                        "description": "Ключ с данным id не найден"
                     },
                     "409": {
-                        "description": "Баннер с указанной парой id фичи и ia тэга уже существует"
+                        "description": "Ключ отозван или истёк"
                     },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "$ref": "#/definitions/tools.Error"
                         }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
                     }
                 }
             }
         },
-        "/filter_banner": {
-            "delete": {
+        "/audit": {
+            "get": {
                 "security": [
                     {
                         "AdminToken": []
                     }
                 ],
-                "description": "Удаляет баннеры на основе фильтра по фиче или тегу. Обязателен один из query параметров.",
+                "description": "|",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "banner"
+                    "audit"
                 ],
-                "summary": "Удаление всех баннеров c фильтрацией по фиче или тегу",
+                "summary": "Получение журнала изменений баннеров.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Идентификатор тэга группы пользователей",
-                        "name": "tag_id",
+                        "description": "Идентификатор баннера",
+                        "name": "banner_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Админ, выполнивший действие",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Начало периода",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Конец периода",
+                        "name": "to",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Идентификатор фичи",
-                        "name": "feature_id",
+                        "description": "Лимит",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Оффсет",
+                        "name": "offset",
                         "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Баннеры успешно удалены"
+                    "200": {
+                        "description": "Список записей журнала",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.AuditEntry"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Некорректные данные",
@@ -310,63 +359,26 @@ const docTemplate = `{
                     "403": {
                         "description": "Пользователь не имеет доступа"
                     },
-                    "404": {
-                        "description": "Баннер с указанными тэгом и фичёй не найден"
-                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "$ref": "#/definitions/tools.Error"
                         }
-                    }
-                }
-            }
-        },
-        "/token/admin": {
-            "get": {
-                "description": "Возвращает токен с правами админа.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Получение токена админа.",
-                "responses": {
-                    "200": {
-                        "description": "Токен успешно создан",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/token/user": {
-            "get": {
-                "description": "Возвращает токен с правами пользователя.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Получение токена пользователя.",
-                "responses": {
-                    "200": {
-                        "description": "Токен успешно создан",
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/tools.Error"
                         }
                     }
                 }
             }
         },
-        "/user_banner": {
+        "/banner": {
             "get": {
                 "security": [
                     {
-                        "UserToken": []
+                        "AdminToken": []
                     }
                 ],
                 "description": "|",
@@ -376,40 +388,133 @@ const docTemplate = `{
                 "tags": [
                     "banner"
                 ],
-                "summary": "Получение баннера для пользователя.",
+                "summary": "Получение всех баннеров c фильтрацией по фиче и/или тегу",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Идентификатор тэга группы пользователей",
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Идентификаторы тэгов группы пользователей",
                         "name": "tag_id",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Идентификатор фичи",
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Идентификаторы фич",
                         "name": "feature_id",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Версия баннера",
-                        "name": "version",
+                        "enum": [
+                            "scheduled",
+                            "live",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "description": "Состояние окна показа",
+                        "name": "state",
                         "in": "query"
                     },
                     {
                         "type": "boolean",
-                        "description": "Получать актуальную информацию",
-                        "name": "use_last_revision",
+                        "description": "Активность баннера",
+                        "name": "is_active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало диапазона времени создания в формате RFC 3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец диапазона времени создания (не включая) в формате RFC 3339",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало диапазона времени изменения в формате RFC 3339",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец диапазона времени изменения (не включая) в формате RFC 3339",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "created_at",
+                            "updated_at"
+                        ],
+                        "type": "string",
+                        "description": "Поле сортировки, по умолчанию id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Направление сортировки, по умолчанию asc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор из заголовка X-Next-Cursor предыдущей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Вернуть число всех баннеров фильтра",
+                        "name": "with_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Оффсет",
+                        "name": "offset",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JSON-отображение баннера",
+                        "description": "Список баннеров успешно отфильтрован",
                         "schema": {
-                            "type": "object"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.Banner"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Курсор следующей страницы"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Число всех баннеров фильтра"
+                            }
                         }
                     },
                     "400": {
@@ -424,126 +529,3223 @@ const docTemplate = `{
                     "403": {
                         "description": "Пользователь не имеет доступа"
                     },
-                    "404": {
-                        "description": "Баннер с указанными тэгом и фичёй не найден"
-                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "$ref": "#/definitions/tools.Error"
                         }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "request.CreateBanner": {
-            "type": "object",
-            "properties": {
-                "content": {
-                    "description": "Содержимое баннера",
-                    "type": "object"
-                },
-                "feature_id": {
-                    "description": "Идентификатор фичи",
-                    "type": "integer",
-                    "format": "uint64"
-                },
-                "is_active": {
-                    "description": "Флаг активности баннера",
-                    "type": "boolean"
-                },
-                "tag_ids": {
-                    "description": "Идентификаторы тегов",
-                    "type": "array",
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Добавляет баннер включая его содержания, id фичи, список id тэгов и состояние.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Создание нового баннера.",
+                "parameters": [
+                    {
+                        "description": "Информация о добавляемом пользователе",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.CreateBanner"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Баннер успешно добавлен в систему",
+                        "schema": {
+                            "$ref": "#/definitions/response.BannerID"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "409": {
+                        "description": "Баннер с указанной парой id фичи и ia тэга уже существует"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/banner/batch": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "|",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Пакетное изменение баннеров.",
+                "parameters": [
+                    {
+                        "description": "Операции над баннерами",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.BatchBanners"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Итоги операций",
+                        "schema": {
+                            "$ref": "#/definitions/response.BatchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "422": {
+                        "description": "Операции не применены из-за ошибок",
+                        "schema": {
+                            "$ref": "#/definitions/response.BatchResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/banner/export": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "|",
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Выгрузка баннеров.",
+                "responses": {
+                    "200": {
+                        "description": "Баннеры, по одному в строке",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.Banner"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/banner/import": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "|",
+                "consumes": [
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Импорт баннеров.",
+                "parameters": [
+                    {
+                        "description": "Баннеры, по одному в строке",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.ImportBanner"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "create",
+                            "upsert",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "description": "Режим импорта, по умолчанию create",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только проверить импорт",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Итог импорта",
+                        "schema": {
+                            "$ref": "#/definitions/response.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "422": {
+                        "description": "Отчёт с ошибками импорта по строкам",
+                        "schema": {
+                            "$ref": "#/definitions/response.ImportResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/banner/search": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "|",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Поиск баннеров по содержимому.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Предикат JSONPath над содержимым",
+                        "name": "jsonpath",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Полнотекстовый запрос по строковым значениям",
+                        "name": "text",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "current",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Искомые версии, по умолчанию current",
+                        "name": "versions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Идентификаторы фич",
+                        "name": "feature_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Оффсет",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подходящие версии баннеров",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.ContentMatch"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/banner/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Удаляет информацию о банере по его id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Удаление банера.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор баннера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Баннер успешно удалён"
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Баннер с данным id не найден"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "|",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Обновление баннера.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор баннера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Информация об обновлении",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.UpdateBanner"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Баннер обновлён, при изменении содержимого создан черновик",
+                        "schema": {
+                            "$ref": "#/definitions/response.DraftID"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Баннер с данным id не найден"
+                    },
+                    "409": {
+                        "description": "Баннер с указанной парой id фичи и ia тэга уже существует"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/banner/{id}/drafts": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает черновики содержимого баннера от новых к старым с фильтрацией по состоянию.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "draft"
+                ],
+                "summary": "Получение черновиков баннера.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор баннера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "rejected",
+                            "published"
+                        ],
+                        "type": "string",
+                        "description": "Состояние черновика",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Оффсет",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список черновиков баннера",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.Draft"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Баннер с данным id не найден"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/banner/{id}/experiment": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "|",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "experiment"
+                ],
+                "summary": "Создание эксперимента для баннера.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор баннера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Варианты эксперимента",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.CreateExperiment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Эксперимент успешно создан",
+                        "schema": {
+                            "$ref": "#/definitions/response.ExperimentID"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Баннер с данным id не найден"
+                    },
+                    "409": {
+                        "description": "У баннера уже есть незавершённый эксперимент"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/banner/{id}/rollback": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "|",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Откат баннера к предыдущей версии.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор баннера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Версия для отката",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.RollbackBanner"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Баннер успешно откачен",
+                        "schema": {
+                            "$ref": "#/definitions/response.CurrentVersion"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Баннер или версия не найдены"
+                    },
+                    "409": {
+                        "description": "Версия уже является текущей или перенесена в архив"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/banner/{id}/versions": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает версии баннера от новых к старым, включая перенесённые в архив.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Получение истории версий баннера.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор баннера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Оффсет",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список версий баннера",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.Version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Баннер с данным id не найден"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/banner/{id}/versions/{version}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает версию баннера по её номеру, в том числе перенесённую в архив.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Получение версии баннера.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор баннера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Версия баннера",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Версия баннера",
+                        "schema": {
+                            "$ref": "#/definitions/response.Version"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Версия баннера не найдена"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/cache": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "|",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cache"
+                ],
+                "summary": "Сброс кэша баннеров.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор тэга группы пользователей",
+                        "name": "tag_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фичи",
+                        "name": "feature_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Кэш успешно сброшен",
+                        "schema": {
+                            "$ref": "#/definitions/response.PurgeResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/cache/entry": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "|",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cache"
+                ],
+                "summary": "Получение записи кэша баннера.",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Идентификаторы тэгов групп пользователя",
+                        "name": "tag_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фичи",
+                        "name": "feature_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Версия баннера",
+                        "name": "version",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Запись кэша баннера",
+                        "schema": {
+                            "$ref": "#/definitions/response.CacheEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Баннера нет в кэше"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/cache/warm": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "|",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cache"
+                ],
+                "summary": "Прогрев кэша баннеров.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Число самых популярных пар",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Итог прогрева",
+                        "schema": {
+                            "$ref": "#/definitions/response.WarmResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/draft/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает черновик содержимого баннера вместе с его состоянием.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "draft"
+                ],
+                "summary": "Предпросмотр черновика.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор черновика",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Черновик успешно получен",
+                        "schema": {
+                            "$ref": "#/definitions/response.Draft"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Черновик с данным id не найден"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/draft/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Одобряет черновик на рассмотрении. Автор черновика не может одобрить его сам.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "draft"
+                ],
+                "summary": "Одобрение черновика.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор черновика",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Черновик успешно одобрен"
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа или является автором черновика"
+                    },
+                    "404": {
+                        "description": "Черновик с данным id не найден"
+                    },
+                    "409": {
+                        "description": "Черновик уже рассмотрен"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/draft/{id}/publish": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "|",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "draft"
+                ],
+                "summary": "Публикация черновика.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор черновика",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Черновик успешно опубликован",
+                        "schema": {
+                            "$ref": "#/definitions/response.CurrentVersion"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Черновик с данным id или его баннер не найден"
+                    },
+                    "409": {
+                        "description": "Черновик не одобрен"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/draft/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Отклоняет черновик на рассмотрении, отклонённый черновик не может быть опубликован.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "draft"
+                ],
+                "summary": "Отклонение черновика.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор черновика",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Черновик успешно отклонён"
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Черновик с данным id не найден"
+                    },
+                    "409": {
+                        "description": "Черновик уже рассмотрен"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/experiment/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает состояние эксперимента и его варианты.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "experiment"
+                ],
+                "summary": "Получение эксперимента.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор эксперимента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Эксперимент успешно получен",
+                        "schema": {
+                            "$ref": "#/definitions/response.Experiment"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Эксперимент с данным id не найден"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/experiment/{id}/conclude": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Завершает эксперимент и делает содержимое победившего варианта новой версией баннера.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "experiment"
+                ],
+                "summary": "Завершение эксперимента.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор эксперимента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Победивший вариант",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.ConcludeExperiment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Эксперимент успешно завершён",
+                        "schema": {
+                            "$ref": "#/definitions/response.ConcludedExperiment"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Эксперимент или вариант не найден"
+                    },
+                    "409": {
+                        "description": "Эксперимент уже завершён"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/experiment/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Приостанавливает эксперимент, пока он приостановлен пользователи получают основную версию баннера.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "experiment"
+                ],
+                "summary": "Приостановка эксперимента.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор эксперимента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Эксперимент успешно приостановлен"
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Эксперимент с данным id не найден"
+                    },
+                    "409": {
+                        "description": "Эксперимент не запущен"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/experiment/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возобновляет приостановленный эксперимент.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "experiment"
+                ],
+                "summary": "Возобновление эксперимента.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор эксперимента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Эксперимент успешно возобновлён"
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Эксперимент с данным id не найден"
+                    },
+                    "409": {
+                        "description": "Эксперимент не приостановлен"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/feature/{feature_id}/cache_ttl": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает время жизни в кэше, заданное для баннеров фичи.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cache"
+                ],
+                "summary": "Получение времени жизни баннеров фичи в кэше.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фичи",
+                        "name": "feature_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Время жизни баннеров фичи в кэше",
+                        "schema": {
+                            "$ref": "#/definitions/response.FeatureCacheTTL"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Для фичи используется время жизни по умолчанию"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "|",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cache"
+                ],
+                "summary": "Изменение времени жизни баннеров фичи в кэше.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фичи",
+                        "name": "feature_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Время жизни в кэше",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetCacheTTL"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Время жизни успешно изменено"
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает баннерам фичи время жизни в кэше по умолчанию и сбрасывает их кэш.",
+                "tags": [
+                    "cache"
+                ],
+                "summary": "Сброс времени жизни баннеров фичи в кэше.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фичи",
+                        "name": "feature_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Время жизни успешно сброшено"
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Для фичи уже используется время жизни по умолчанию"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/filter_banner": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Удаляет баннеры на основе фильтра по фиче или тегу. Обязателен один из query параметров.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Удаление всех баннеров c фильтрацией по фиче или тегу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор тэга группы пользователей",
+                        "name": "tag_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фичи",
+                        "name": "feature_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Баннеры успешно удалены"
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Баннер с указанными тэгом и фичёй не найден"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/settings/retention": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Возвращает число хранимых версий баннеров, для которых не задано собственное значение.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Получение глобального числа хранимых версий.",
+                "responses": {
+                    "200": {
+                        "description": "Глобальное число хранимых версий",
+                        "schema": {
+                            "$ref": "#/definitions/response.Retention"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "|",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Изменение глобального числа хранимых версий.",
+                "parameters": [
+                    {
+                        "description": "Число хранимых версий",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetRetention"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Число хранимых версий успешно изменено"
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/admin": {
+            "get": {
+                "description": "|",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Получение токена админа.",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Идентификаторы доступных фич",
+                        "name": "feature_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Доступ только на чтение",
+                        "name": "read_only",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Токен успешно создан",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/user": {
+            "get": {
+                "description": "Возвращает подписанный JWT токен с ролью пользователя. Доступен только в режиме отладки.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Получение токена пользователя.",
+                "responses": {
+                    "200": {
+                        "description": "Токен успешно создан",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/user_banner": {
+            "get": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "|",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Получение баннера для пользователя.",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Идентификаторы тэгов групп пользователя",
+                        "name": "tag_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фичи",
+                        "name": "feature_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Версия баннера",
+                        "name": "version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор пользователя для выбора варианта эксперимента",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Получать актуальную информацию",
+                        "name": "use_last_revision",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JSON-отображение баннера",
+                        "schema": {
+                            "type": "object"
+                        },
+                        "headers": {
+                            "X-Cache-Stale": {
+                                "type": "boolean",
+                                "description": "Баннер отдан из кэша после истечения времени свежести"
+                            },
+                            "X-Experiment-Id": {
+                                "type": "integer",
+                                "description": "Идентификатор эксперимента, если был выдан его вариант"
+                            },
+                            "X-Experiment-Variant": {
+                                "type": "integer",
+                                "description": "Идентификатор выданного варианта эксперимента"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "404": {
+                        "description": "Баннер с указанными тэгом и фичёй не найден"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/user_banners": {
+            "post": {
+                "security": [
+                    {
+                        "UserToken": []
+                    }
+                ],
+                "description": "|",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "banner"
+                ],
+                "summary": "Получение баннеров нескольких фич для пользователя.",
+                "parameters": [
+                    {
+                        "description": "Тэг и фичи баннеров",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.GetUserBanners"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Баннеры по идентификаторам фич",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/response.UserBanner"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован"
+                    },
+                    "403": {
+                        "description": "Пользователь не имеет доступа"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Превышено время обработки запроса",
+                        "schema": {
+                            "$ref": "#/definitions/tools.Error"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "request.BatchBanners": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "description": "Режим применения операций, по умолчанию atomic",
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ]
+                },
+                "operations": {
+                    "description": "Операции над баннерами, выполняемые по порядку",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/request.BatchOperation"
+                    }
+                }
+            }
+        },
+        "request.BatchOperation": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Действие над баннером",
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ]
+                },
+                "banner": {
+                    "description": "Поля создаваемого баннера, как в POST /banner, или изменяемые поля, как в PATCH /banner/{id}",
+                    "type": "object"
+                },
+                "banner_id": {
+                    "description": "Идентификатор изменяемого или удаляемого баннера",
+                    "type": "integer",
+                    "format": "uint64"
+                }
+            }
+        },
+        "request.ConcludeExperiment": {
+            "type": "object",
+            "properties": {
+                "winner_variant_id": {
+                    "description": "Идентификатор победившего варианта",
+                    "type": "integer",
+                    "format": "uint64"
+                }
+            }
+        },
+        "request.CreateAPIKey": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "Время окончания действия ключа, при отсутствии ключ бессрочный",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "feature_ids": {
+                    "description": "Доступные админу фичи, при отсутствии доступны все фичи",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "owner": {
+                    "description": "Владелец ключа, сохраняется в журнал изменений как автор действий",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Роль владельца ключа",
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "admin_read_only",
+                        "user"
+                    ]
+                }
+            }
+        },
+        "request.CreateBanner": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "Содержимое баннера",
+                    "type": "object"
+                },
+                "end_at": {
+                    "description": "Конец окна показа баннера",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "feature_id": {
+                    "description": "Идентификатор фичи",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "is_active": {
+                    "description": "Флаг активности баннера",
+                    "type": "boolean"
+                },
+                "priority": {
+                    "description": "Приоритет баннера при выборе среди баннеров нескольких тэгов пользователя, больше -- важнее",
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "start_at": {
+                    "description": "Начало окна показа баннера",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "tag_ids": {
+                    "description": "Идентификаторы тегов",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "request.CreateExperiment": {
+            "type": "object",
+            "properties": {
+                "variants": {
+                    "description": "Варианты содержимого баннера",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/request.Variant"
+                    }
+                }
+            }
+        },
+        "request.GetUserBanners": {
+            "type": "object",
+            "properties": {
+                "feature_ids": {
+                    "description": "Идентификаторы фич",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "tag_id": {
+                    "description": "Идентификатор тэга группы пользователей",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "use_last_revision": {
+                    "description": "Получать актуальную информацию",
+                    "type": "boolean"
+                },
+                "user_id": {
+                    "description": "Идентификатор пользователя для выбора вариантов экспериментов",
+                    "type": "string"
+                }
+            }
+        },
+        "request.ImportBanner": {
+            "type": "object",
+            "properties": {
+                "banner_id": {
+                    "description": "Идентификатор баннера, учитывается только при импорте с заменой",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "cache_ttl": {
+                    "description": "Время жизни баннера в кэше в миллисекундах",
+                    "type": "integer",
+                    "format": "uint32"
+                },
+                "end_at": {
+                    "description": "Конец окна показа баннера",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "feature_id": {
+                    "description": "Идентификатор фичи",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "is_active": {
+                    "description": "Флаг активности баннера",
+                    "type": "boolean"
+                },
+                "priority": {
+                    "description": "Приоритет баннера при выборе среди баннеров нескольких тэгов пользователя",
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "retention": {
+                    "description": "Число хранимых версий баннера",
+                    "type": "integer",
+                    "format": "uint32"
+                },
+                "start_at": {
+                    "description": "Начало окна показа баннера",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "tag_ids": {
+                    "description": "Идентификаторы тэгов",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "versions": {
+                    "description": "Версии баннера, последняя из них становится текущей",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/request.ImportVersion"
+                    }
+                }
+            }
+        },
+        "request.ImportVersion": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "Содержимое баннера",
+                    "type": "object"
+                },
+                "created_at": {
+                    "description": "Дата создания версии, без неё версия создаётся с текущей датой",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "version": {
+                    "description": "Версия содержимого баннера",
+                    "type": "integer",
+                    "format": "uint32"
+                }
+            }
+        },
+        "request.RollbackBanner": {
+            "type": "object",
+            "properties": {
+                "version": {
+                    "description": "Версия баннера, содержимое которой станет текущим",
+                    "type": "integer",
+                    "format": "uint32"
+                }
+            }
+        },
+        "request.SetCacheTTL": {
+            "type": "object",
+            "properties": {
+                "cache_ttl": {
+                    "description": "Время жизни баннеров фичи в кэше в миллисекундах",
+                    "type": "integer",
+                    "format": "uint32"
+                }
+            }
+        },
+        "request.SetRetention": {
+            "type": "object",
+            "properties": {
+                "retention": {
+                    "description": "Число хранимых версий баннеров, для которых не задано собственное значение",
+                    "type": "integer",
+                    "format": "uint32"
+                }
+            }
+        },
+        "request.UpdateBanner": {
+            "type": "object",
+            "properties": {
+                "cache_ttl": {
+                    "description": "Время жизни баннера в кэше в миллисекундах, 0 -- использовать значение фичи",
+                    "type": "integer",
+                    "format": "uint32"
+                },
+                "content": {
+                    "description": "Содержимое баннера",
+                    "type": "object"
+                },
+                "end_at": {
+                    "description": "Конец окна показа баннера, null -- снять ограничение",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "feature_id": {
+                    "description": "Идентификатор фичи",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "is_active": {
+                    "description": "Флаг активности баннера",
+                    "type": "boolean"
+                },
+                "priority": {
+                    "description": "Приоритет баннера при выборе среди баннеров нескольких тэгов пользователя, больше -- важнее",
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "retention": {
+                    "description": "Число хранимых версий баннера, 0 -- использовать глобальное значение",
+                    "type": "integer",
+                    "format": "uint32"
+                },
+                "start_at": {
+                    "description": "Начало окна показа баннера, null -- снять ограничение",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "tag_ids": {
+                    "description": "Идентификаторы тегов",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "request.Variant": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "Содержимое баннера в варианте",
+                    "type": "object"
+                },
+                "weight": {
+                    "description": "Вес варианта, доля пользователей варианта равна его весу, делённому на сумму весов всех вариантов",
+                    "type": "integer",
+                    "format": "uint32"
+                }
+            }
+        },
+        "response.APIKey": {
+            "type": "object",
+            "properties": {
+                "api_key_id": {
+                    "description": "Идентификатор ключа",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "created_at": {
+                    "description": "Дата выдачи ключа",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "expires_at": {
+                    "description": "Время окончания действия ключа",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "feature_ids": {
+                    "description": "Доступные админу фичи, при отсутствии доступны все фичи",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "owner": {
+                    "description": "Владелец ключа",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "description": "Время отзыва ключа",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "role": {
+                    "description": "Роль владельца ключа",
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "admin_read_only",
+                        "user"
+                    ]
+                },
+                "rotated_from": {
+                    "description": "Идентификатор ключа, на замену которому выдан данный",
+                    "type": "integer",
+                    "format": "uint64"
+                }
+            }
+        },
+        "response.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Действие над баннером",
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete",
+                        "rollback",
+                        "publish",
+                        "conclude",
+                        "draft",
+                        "approve",
+                        "reject"
+                    ]
+                },
+                "actor": {
+                    "description": "Админ, выполнивший действие",
+                    "type": "string"
+                },
+                "after": {
+                    "description": "Состояние баннера после изменения",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/response.BannerSnapshot"
+                        }
+                    ]
+                },
+                "banner_id": {
+                    "description": "Идентификатор баннера",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "before": {
+                    "description": "Состояние баннера до изменения",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/response.BannerSnapshot"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "description": "Дата действия",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "description": "Идентификатор записи журнала",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "request_id": {
+                    "description": "Идентификатор запроса, в котором было выполнено действие",
+                    "type": "string"
+                }
+            }
+        },
+        "response.Banner": {
+            "type": "object",
+            "properties": {
+                "banner_id": {
+                    "description": "Идентификатор баннера",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "cache_ttl": {
+                    "description": "Время жизни баннера в кэше в миллисекундах, если оно отличается от времени жизни фичи",
+                    "type": "integer",
+                    "format": "uint32"
+                },
+                "created_at": {
+                    "description": "Дата создания баннера",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "end_at": {
+                    "description": "Конец окна показа баннера",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "feature_id": {
+                    "description": "Идентификатор фичи",
+                    "type": "integer"
+                },
+                "is_active": {
+                    "description": "Флаг активности баннера",
+                    "type": "boolean",
+                    "format": "uint64"
+                },
+                "priority": {
+                    "description": "Приоритет баннера при выборе среди баннеров нескольких тэгов пользователя",
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "retention": {
+                    "description": "Число хранимых версий баннера, если оно отличается от глобального",
+                    "type": "integer",
+                    "format": "uint32"
+                },
+                "start_at": {
+                    "description": "Начало окна показа баннера",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "tag_ids": {
+                    "description": "Идентификаторы тэгов",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "updated_at": {
+                    "description": "Дата обновления баннера",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "versions": {
+                    "description": "Хранимые версии баннера, без перенесённых в архив",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.Content"
+                    }
+                }
+            }
+        },
+        "response.BannerID": {
+            "type": "object",
+            "properties": {
+                "banner_id": {
+                    "description": "Идентификатор созданного баннера",
+                    "type": "integer",
+                    "format": "uint64"
+                }
+            }
+        },
+        "response.BannerSnapshot": {
+            "type": "object",
+            "properties": {
+                "feature_id": {
+                    "description": "Идентификатор фичи",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "is_active": {
+                    "description": "Флаг активности баннера",
+                    "type": "boolean"
+                },
+                "tag_ids": {
+                    "description": "Идентификаторы тэгов",
+                    "type": "array",
+                    "items": {
+                        "type": "integer",
+                        "format": "uint64"
+                    }
+                },
+                "version": {
+                    "description": "Текущая версия содержимого баннера",
+                    "type": "integer",
+                    "format": "uint32"
+                }
+            }
+        },
+        "response.BatchOperationResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Действие над баннером",
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ]
+                },
+                "banner_id": {
+                    "description": "Идентификатор созданного, изменённого или удалённого баннера",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "conflict_tag_ids": {
+                    "description": "Тэги, по которым баннер конфликтует с другим баннером той же фичи",
+                    "type": "array",
                     "items": {
                         "type": "integer"
                     }
+                },
+                "draft_id": {
+                    "description": "Идентификатор черновика с новым содержимым изменённого баннера",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "error": {
+                    "description": "Ошибка операции",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Статус операции, такой же, как у отдельного запроса с этим действием",
+                    "type": "integer"
+                }
+            }
+        },
+        "response.BatchResult": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "description": "Флаг применения успешных операций",
+                    "type": "boolean"
+                },
+                "mode": {
+                    "description": "Режим применения операций",
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ]
+                },
+                "results": {
+                    "description": "Итоги операций в порядке операций запроса",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.BatchOperationResult"
+                    }
+                }
+            }
+        },
+        "response.CacheEntry": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "Содержимое баннера",
+                    "type": "object"
+                },
+                "fresh_until": {
+                    "description": "Время, до которого баннер считается свежим",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "key": {
+                    "description": "Ключ записи в кэше",
+                    "type": "string"
+                },
+                "stale": {
+                    "description": "Флаг устаревшего баннера, который отдаётся с обновлением в фоне",
+                    "type": "boolean"
+                },
+                "ttl": {
+                    "description": "Оставшееся время жизни записи в миллисекундах",
+                    "type": "integer",
+                    "format": "int64"
                 }
             }
         },
-        "request.UpdateBanner": {
+        "response.ConcludedExperiment": {
+            "type": "object",
+            "properties": {
+                "version": {
+                    "description": "Версия баннера, созданная из победившего варианта",
+                    "type": "integer",
+                    "format": "uint32"
+                }
+            }
+        },
+        "response.Content": {
             "type": "object",
             "properties": {
                 "content": {
                     "description": "Содержимое баннера",
                     "type": "object"
                 },
-                "feature_id": {
-                    "description": "Идентификатор фичи",
+                "created_at": {
+                    "description": "Дата создания версии",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "version": {
+                    "description": "Версия содержимого баннера",
+                    "type": "integer",
+                    "format": "uint32"
+                }
+            }
+        },
+        "response.ContentMatch": {
+            "type": "object",
+            "properties": {
+                "banner_id": {
+                    "description": "Идентификатор баннера",
                     "type": "integer",
                     "format": "uint64"
                 },
-                "is_active": {
-                    "description": "Флаг активности баннера",
+                "created_at": {
+                    "description": "Дата создания версии",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "current": {
+                    "description": "Флаг текущей версии баннера",
                     "type": "boolean"
                 },
-                "tag_ids": {
-                    "description": "Идентификаторы тегов",
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "version": {
+                    "description": "Версия, содержимое которой подошло под поиск",
+                    "type": "integer",
+                    "format": "uint32"
                 }
             }
         },
-        "response.Banner": {
+        "response.CurrentVersion": {
+            "type": "object",
+            "properties": {
+                "version": {
+                    "description": "Текущая версия баннера после отката",
+                    "type": "integer",
+                    "format": "uint32"
+                }
+            }
+        },
+        "response.Draft": {
             "type": "object",
             "properties": {
+                "author": {
+                    "description": "Автор черновика",
+                    "type": "string"
+                },
                 "banner_id": {
                     "description": "Идентификатор баннера",
                     "type": "integer",
                     "format": "uint64"
                 },
+                "content": {
+                    "description": "Содержимое баннера в черновике",
+                    "type": "object"
+                },
                 "created_at": {
-                    "description": "Дата создания баннера",
+                    "description": "Дата создания черновика",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "draft_id": {
+                    "description": "Идентификатор черновика",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "published_version": {
+                    "description": "Версия баннера, созданная при публикации черновика",
+                    "type": "integer",
+                    "format": "uint32"
+                },
+                "reviewer": {
+                    "description": "Админ, рассмотревший черновик",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Состояние черновика",
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "approved",
+                        "rejected",
+                        "published"
+                    ]
+                },
+                "updated_at": {
+                    "description": "Дата обновления черновика",
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "response.DraftID": {
+            "type": "object",
+            "properties": {
+                "draft_id": {
+                    "description": "Идентификатор созданного черновика содержимого",
+                    "type": "integer",
+                    "format": "uint64"
+                }
+            }
+        },
+        "response.Experiment": {
+            "type": "object",
+            "properties": {
+                "banner_id": {
+                    "description": "Идентификатор баннера",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "concluded_at": {
+                    "description": "Дата завершения эксперимента",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_at": {
+                    "description": "Дата создания эксперимента",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "experiment_id": {
+                    "description": "Идентификатор эксперимента",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "status": {
+                    "description": "Состояние эксперимента",
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "paused",
+                        "concluded"
+                    ]
+                },
+                "updated_at": {
+                    "description": "Дата обновления эксперимента",
                     "type": "string",
                     "format": "date-time"
                 },
+                "variants": {
+                    "description": "Варианты содержимого баннера",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.Variant"
+                    }
+                },
+                "winner_variant_id": {
+                    "description": "Идентификатор победившего варианта",
+                    "type": "integer",
+                    "format": "uint64"
+                }
+            }
+        },
+        "response.ExperimentID": {
+            "type": "object",
+            "properties": {
+                "experiment_id": {
+                    "description": "Идентификатор созданного эксперимента",
+                    "type": "integer",
+                    "format": "uint64"
+                }
+            }
+        },
+        "response.FeatureCacheTTL": {
+            "type": "object",
+            "properties": {
+                "cache_ttl": {
+                    "description": "Время жизни баннеров фичи в кэше в миллисекундах",
+                    "type": "integer",
+                    "format": "uint32"
+                },
                 "feature_id": {
                     "description": "Идентификатор фичи",
-                    "type": "integer"
+                    "type": "integer",
+                    "format": "uint64"
                 },
-                "is_active": {
-                    "description": "Флаг активности баннера",
-                    "type": "boolean",
+                "updated_at": {
+                    "description": "Дата изменения времени жизни",
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "response.ImportLineError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Ошибка импорта строки",
+                    "type": "string"
+                },
+                "line": {
+                    "description": "Номер строки импорта, начиная с 1",
+                    "type": "integer",
+                    "format": "uint64"
+                }
+            }
+        },
+        "response.ImportResult": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "description": "Флаг применения изменений, изменения не применяются при ошибке хотя бы в одной строке",
+                    "type": "boolean"
+                },
+                "created": {
+                    "description": "Число созданных баннеров",
+                    "type": "integer",
                     "format": "uint64"
                 },
-                "tag_ids": {
-                    "description": "Идентификаторы тэгов",
+                "deleted": {
+                    "description": "Число удалённых перед импортом баннеров",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "dry_run": {
+                    "description": "Флаг проверочного импорта без применения изменений",
+                    "type": "boolean"
+                },
+                "errors": {
+                    "description": "Ошибки импорта по строкам",
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "$ref": "#/definitions/response.ImportLineError"
                     }
                 },
-                "updated_at": {
-                    "description": "Дата обновления баннера",
+                "mode": {
+                    "description": "Режим импорта",
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "upsert",
+                        "replace"
+                    ]
+                },
+                "updated": {
+                    "description": "Число заменённых баннеров",
+                    "type": "integer",
+                    "format": "uint64"
+                }
+            }
+        },
+        "response.IssuedAPIKey": {
+            "type": "object",
+            "properties": {
+                "api_key_id": {
+                    "description": "Идентификатор ключа",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "created_at": {
+                    "description": "Дата выдачи ключа",
                     "type": "string",
                     "format": "date-time"
                 },
-                "versions": {
-                    "description": "Последние три версии баннера",
+                "expires_at": {
+                    "description": "Время окончания действия ключа",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "feature_ids": {
+                    "description": "Доступные админу фичи, при отсутствии доступны все фичи",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/response.Content"
+                        "type": "integer"
                     }
+                },
+                "key": {
+                    "description": "Ключ доступа, возвращается только при выдаче",
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Владелец ключа",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "description": "Время отзыва ключа",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "role": {
+                    "description": "Роль владельца ключа",
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "admin_read_only",
+                        "user"
+                    ]
+                },
+                "rotated_from": {
+                    "description": "Идентификатор ключа, на замену которому выдан данный",
+                    "type": "integer",
+                    "format": "uint64"
                 }
             }
         },
-        "response.BannerID": {
+        "response.PurgeResult": {
             "type": "object",
             "properties": {
-                "banner_id": {
-                    "description": "Идентификатор созданного баннера",
+                "purged": {
+                    "description": "Число пар фича-тэг, кэш которых был сброшен",
                     "type": "integer",
                     "format": "uint64"
                 }
             }
         },
-        "response.Content": {
+        "response.Retention": {
+            "type": "object",
+            "properties": {
+                "retention": {
+                    "description": "Число хранимых версий баннеров, для которых не задано собственное значение",
+                    "type": "integer",
+                    "format": "uint32"
+                }
+            }
+        },
+        "response.UserBanner": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "Содержимое баннера",
+                    "type": "object"
+                },
+                "experiment_id": {
+                    "description": "Идентификатор эксперимента, если был выдан его вариант",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "stale": {
+                    "description": "Флаг баннера, отданного из кэша после истечения времени свежести",
+                    "type": "boolean"
+                },
+                "status": {
+                    "description": "Статус получения баннера: 200 -- баннер найден, 404 -- баннер с тэгом и фичёй не найден",
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "description": "Идентификатор выданного варианта эксперимента",
+                    "type": "integer",
+                    "format": "uint64"
+                }
+            }
+        },
+        "response.Variant": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "Содержимое баннера в варианте",
+                    "type": "object"
+                },
+                "variant_id": {
+                    "description": "Идентификатор варианта",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "weight": {
+                    "description": "Вес варианта",
+                    "type": "integer",
+                    "format": "uint32"
+                }
+            }
+        },
+        "response.Version": {
             "type": "object",
             "properties": {
+                "archived": {
+                    "description": "Флаг переноса версии в архив",
+                    "type": "boolean"
+                },
+                "archived_at": {
+                    "description": "Дата переноса версии в архив",
+                    "type": "string",
+                    "format": "date-time"
+                },
                 "content": {
                     "description": "Содержимое баннера",
                     "type": "object"
@@ -560,6 +3762,26 @@ const docTemplate = `{
                 }
             }
         },
+        "response.WarmResult": {
+            "type": "object",
+            "properties": {
+                "cached": {
+                    "description": "Число пар фича-тэг, баннеры которых уже были в кэше",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "skipped": {
+                    "description": "Число пар фича-тэг без активного баннера или с идущим экспериментом",
+                    "type": "integer",
+                    "format": "uint64"
+                },
+                "warmed": {
+                    "description": "Число пар фича-тэг, баннеры которых сохранены в кэш",
+                    "type": "integer",
+                    "format": "uint64"
+                }
+            }
+        },
         "tools.Error": {
             "type": "object",
             "properties": {
@@ -4,9 +4,23 @@ import (
 	"bannersrv/external/auth"
 	"bannersrv/internal/app/delivery/http/middleware"
 	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	FeatureIDParam = "feature_id"
+	ReadOnlyParam  = "read_only"
+)
+
+var (
+	ErrorFeatureIDIncorrectType = errors.New("feature id have incorrect type")
+	ErrorReadOnlyIncorrectType  = errors.New("read only have incorrect type")
 )
 
 type AuthHandlers struct {
@@ -20,27 +34,93 @@ func NewAuthHandlers(usecase auth.Usecase) *AuthHandlers {
 // GetAdminToken
 //
 //	@Summary		Получение токена админа.
-//	@Description	Возвращает токен с правами админа.
+//	@Description	|
+//					Возвращает подписанный JWT токен с ролью админа. Доступен только в режиме отладки.
+//					Параметры feature_id ограничивают доступные админу фичи, а read_only запрещает изменения.
 //	@Tags			auth
+//	@Param			feature_id	query	[]integer	false	"Идентификаторы доступных фич"	collectionFormat(multi)
+//	@Param			read_only	query	boolean		false	"Доступ только на чтение"
 //	@Produce		json
 //	@Success		200 {string} string	"Токен успешно создан"
+//	@Failure		400	{object}	tools.Error	"Некорректные данные"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
 //	@Router			/token/admin [get]
 func (ah *AuthHandlers) GetAdminToken(c *gin.Context) {
 	l := middleware.GetLogger(c)
 
-	tools.SendStatus(c, http.StatusOK, ah.usecase.GetAdminToken(), l)
+	scope, err := parseScope(c)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	tok, err := ah.usecase.GetAdminToken(scope)
+	if err != nil {
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrap(err, "can't issue admin token"))
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, tok, l)
 }
 
 // GetUserToken
 //
 //	@Summary		Получение токена пользователя.
-//	@Description	Возвращает токен с правами пользователя.
+//	@Description	Возвращает подписанный JWT токен с ролью пользователя. Доступен только в режиме отладки.
 //	@Tags			auth
 //	@Produce		json
 //	@Success		200 {string} string	"Токен успешно создан"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
 //	@Router			/token/user [get]
 func (ah *AuthHandlers) GetUserToken(c *gin.Context) {
 	l := middleware.GetLogger(c)
 
-	tools.SendStatus(c, http.StatusOK, ah.usecase.GetUserToken(), l)
+	tok, err := ah.usecase.GetUserToken()
+	if err != nil {
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrap(err, "can't issue user token"))
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, tok, l)
+}
+
+// parseScope возвращает права выдаваемого админского токена или nil, если параметры прав не переданы
+func parseScope(c *gin.Context) (*access.Scope, error) {
+	rawFeatures, hasFeatures := c.GetQueryArray(FeatureIDParam)
+	rawReadOnly, hasReadOnly := c.GetQuery(ReadOnlyParam)
+
+	if !hasFeatures && !hasReadOnly {
+		return nil, nil
+	}
+
+	scope := &access.Scope{}
+
+	if hasReadOnly {
+		readOnly, err := strconv.ParseBool(rawReadOnly)
+		if err != nil {
+			return nil, ErrorReadOnlyIncorrectType
+		}
+
+		scope.ReadOnly = readOnly
+	}
+
+	if hasFeatures {
+		scope.FeatureIDs = make([]types.ID, 0, len(rawFeatures))
+
+		for _, rawFeature := range rawFeatures {
+			featureID, err := strconv.ParseUint(rawFeature, 10, 32)
+			if err != nil {
+				return nil, ErrorFeatureIDIncorrectType
+			}
+
+			scope.FeatureIDs = append(scope.FeatureIDs, types.ID(featureID))
+		}
+	}
+
+	return scope, nil
 }
@@ -1,8 +1,11 @@
 package auth
 
+import "bannersrv/internal/pkg/access"
+
 type Token string
 
 type Usecase interface {
-	GetUserToken() Token
-	GetAdminToken() Token
+	GetUserToken() (Token, error)
+	// GetAdminToken выдаёт админский токен с правами scope, nil соответствует полным правам
+	GetAdminToken(scope *access.Scope) (Token, error)
 }
@@ -2,42 +2,109 @@ package usecase
 
 import (
 	"bannersrv/external/auth"
-	"strings"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/internal/token"
+	"time"
 
+	tu "bannersrv/internal/token/usecase"
+
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
-const (
-	adminPrefix = "admin"
-	userPrefix  = "user"
-)
+// AuthUsecase эмулирует сервис авторизации, выдавая подписанные токены с ролью админа или пользователя.
+// Владельцем каждого выданного токена становится новый uuid.
+type AuthUsecase struct {
+	method        jwt.SigningMethod
+	key           any
+	keyID         string
+	issuer        string
+	audience      string
+	roleClaim     string
+	adminRole     string
+	readOnlyRole  string
+	userRole      string
+	featuresClaim string
+	ttl           time.Duration
+}
 
-type AuthUsecase struct{}
+func NewAuthUsecase(cfg *token.Config) (*AuthUsecase, error) {
+	method, err := tu.SigningMethod(cfg.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := tu.LoadSigningKey(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't load token signing key")
+	}
 
-func NewAuthUsecase() *AuthUsecase {
-	return &AuthUsecase{}
+	return &AuthUsecase{
+		method:        method,
+		key:           key,
+		keyID:         cfg.KeyID,
+		issuer:        cfg.Issuer,
+		audience:      cfg.Audience,
+		roleClaim:     cfg.RoleClaim,
+		adminRole:     cfg.AdminRole,
+		readOnlyRole:  cfg.ReadOnlyRole,
+		userRole:      cfg.UserRole,
+		featuresClaim: cfg.FeaturesClaim,
+		ttl:           time.Duration(cfg.TTL) * time.Millisecond,
+	}, nil
 }
 
-func (*AuthUsecase) IsAdminToken(token auth.Token) (bool, error) {
-	if strings.HasPrefix(string(token), adminPrefix) {
-		return true, nil
+func (au *AuthUsecase) GetUserToken() (auth.Token, error) {
+	return au.issue(au.userRole, nil)
+}
+
+func (au *AuthUsecase) GetAdminToken(scope *access.Scope) (auth.Token, error) {
+	if scope == nil {
+		return au.issue(au.adminRole, nil)
 	}
 
-	return false, nil
+	role := au.adminRole
+	if scope.ReadOnly {
+		role = au.readOnlyRole
+	}
+
+	return au.issue(role, scope.FeatureIDs)
 }
 
-func (*AuthUsecase) IsUserToken(token auth.Token) (bool, error) {
-	if strings.HasPrefix(string(token), userPrefix) {
-		return true, nil
+// issue подписывает токен с ролью role, featureIDs равный nil не ограничивает доступные фичи
+func (au *AuthUsecase) issue(role string, featureIDs []types.ID) (auth.Token, error) {
+	now := time.Now()
+
+	claims := jwt.MapClaims{
+		"sub":        uuid.New().String(),
+		"iat":        now.Unix(),
+		"exp":        now.Add(au.ttl).Unix(),
+		au.roleClaim: role,
 	}
 
-	return false, nil
-}
+	if featureIDs != nil {
+		claims[au.featuresClaim] = featureIDs
+	}
 
-func (*AuthUsecase) GetUserToken() auth.Token {
-	return auth.Token(userPrefix + "-" + uuid.New().String())
-}
+	if au.issuer != "" {
+		claims["iss"] = au.issuer
+	}
+
+	if au.audience != "" {
+		claims["aud"] = au.audience
+	}
+
+	tok := jwt.NewWithClaims(au.method, claims)
+	if au.keyID != "" {
+		tok.Header["kid"] = au.keyID
+	}
+
+	signed, err := tok.SignedString(au.key)
+	if err != nil {
+		return "", errors.Wrapf(err, "can't sign %s token", role)
+	}
 
-func (*AuthUsecase) GetAdminToken() auth.Token {
-	return auth.Token(adminPrefix + "-" + uuid.New().String())
+	return auth.Token(signed), nil
 }
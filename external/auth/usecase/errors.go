@@ -1,5 +0,0 @@
-package usecase
-
-import "github.com/pkg/errors"
-
-var ErrorInvalidToken = errors.New("token is invalid")
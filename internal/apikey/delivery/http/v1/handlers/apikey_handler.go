@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"bannersrv/internal/apikey"
+	"bannersrv/internal/apikey/delivery/http/v1/models/request"
+	"bannersrv/internal/apikey/delivery/http/v1/models/response"
+	"bannersrv/internal/apikey/models"
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/logger"
+	"bannersrv/pkg/slices"
+	"net/http"
+	"strconv"
+	"time"
+
+	ar "bannersrv/internal/apikey/repository"
+	au "bannersrv/internal/apikey/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const APIKeyIDField = "id"
+
+const (
+	OwnerParam       = "owner"
+	LimitParam       = "limit"
+	OffsetParam      = "offset"
+	GracePeriodParam = "grace_period"
+)
+
+type APIKeyHandlers struct {
+	usecase apikey.Usecase
+}
+
+func NewAPIKeyHandlers(usecase apikey.Usecase) *APIKeyHandlers {
+	return &APIKeyHandlers{usecase: usecase}
+}
+
+// CreateAPIKey
+//
+//	@Summary		Выдача ключа доступа.
+//	@Description	|
+//					Выдаёт ключ доступа с ролью админа или пользователя. Ключ возвращается только в ответе
+//					на этот запрос, сервис хранит лишь его хэш. Доступно только админу с полным доступом.
+//	@Tags			api_key
+//	@Accept			json
+//	@Param			request	body	request.CreateAPIKey	true	"Параметры ключа"
+//	@Produce		json
+//	@Success		201	{object}	response.IssuedAPIKey	"Ключ успешно выдан"
+//	@Failure		400	{object}	tools.Error				"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/api_key [post]
+//
+//	@Security		AdminToken
+func (ah *APIKeyHandlers) CreateAPIKey(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение значения тела запроса
+	var createAPIKey request.CreateAPIKey
+	if code, err := tools.ParseRequestBody(c.Request.Body, &createAPIKey, request.ValidateCreateAPIKey, l); err != nil {
+		tools.SendError(c, err, code, l)
+
+		return
+	}
+
+	issued, err := ah.usecase.IssueAPIKey(c.Request.Context(), createAPIKey.ToModel())
+	if err != nil {
+		if errors.Is(err, au.ErrorUserKeyFeatures) || errors.Is(err, au.ErrorExpiresInPast) {
+			tools.SendError(c, err, http.StatusBadRequest, l)
+
+			return
+		}
+
+		sendAPIKeyError(c, err, l, "can't issue api key")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusCreated, response.FromModelIssuedAPIKey(issued), l)
+}
+
+// GetAPIKeys
+//
+//	@Summary		Получение ключей доступа.
+//	@Description	Возвращает выданные ключи доступа от новых к старым, включая отозванные, без самих ключей.
+//	@Tags			api_key
+//	@Param			owner	query	string	false	"Владелец ключа"
+//	@Param			limit	query	integer	false	"Лимит"
+//	@Param			offset	query	integer	false	"Оффсет"
+//	@Produce		json
+//	@Success		200	{array}		response.APIKey	"Список ключей"
+//	@Failure		400	{object}	tools.Error		"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/api_key [get]
+//
+//	@Security		AdminToken
+func (ah *APIKeyHandlers) GetAPIKeys(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	var owner *string
+	if rawOwner, ok := c.GetQuery(OwnerParam); ok {
+		owner = &rawOwner
+	}
+
+	limit, err := tools.ParseQueryParamToUint64(c, LimitParam, nil, ErrorLimitIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	offset, err := tools.ParseQueryParamToUint64(c, OffsetParam, nil, ErrorOffsetIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	keys, err := ah.usecase.GetAPIKeys(c.Request.Context(), owner, offset, limit)
+	if err != nil {
+		sendAPIKeyError(c, err, l, "can't get api keys")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, slices.Map(keys, func(key *models.APIKey) response.APIKey {
+		return *response.FromModelAPIKey(key)
+	}), l)
+}
+
+// RotateAPIKey
+//
+//	@Summary		Ротация ключа доступа.
+//	@Description	|
+//					Выдаёт новый ключ с ролью, владельцем, фичами и сроком действия заменяемого ключа.
+//					Заменяемый ключ действует ещё grace_period секунд, по умолчанию он отзывается сразу.
+//	@Tags			api_key
+//	@Param			id				path	integer	true	"Идентификатор ключа"
+//	@Param			grace_period	query	integer	false	"Время действия заменяемого ключа в секундах"
+//	@Produce		json
+//	@Success		201	{object}	response.IssuedAPIKey	"Ключ успешно заменён"
+//	@Failure		400	{object}	tools.Error				"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Ключ с данным id не найден"
+//	@Failure		409	"Ключ отозван или истёк"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/api_key/{id}/rotate [post]
+//
+//	@Security		AdminToken
+func (ah *APIKeyHandlers) RotateAPIKey(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(APIKeyIDField), 10, 32)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get api key id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	gracePeriod, err := tools.ParseQueryParamToUint32(c, GracePeriodParam, nil, ErrorGracePeriodIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	var grace time.Duration
+	if gracePeriod != nil {
+		grace = time.Duration(*gracePeriod) * time.Second
+	}
+
+	rotated, err := ah.usecase.RotateAPIKey(c.Request.Context(), types.ID(id), grace)
+	if err != nil {
+		sendAPIKeyError(c, err, l, "can't rotate api key")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusCreated, response.FromModelIssuedAPIKey(rotated), l)
+}
+
+// RevokeAPIKey
+//
+//	@Summary		Отзыв ключа доступа.
+//	@Description	|
+//					Отзывает ключ доступа. Проверки ключей кэшируются каждым экземпляром сервиса,
+//					поэтому отозванный ключ перестаёт приниматься не позднее чем через api_key_cache_ttl.
+//	@Tags			api_key
+//	@Param			id	path	integer	true	"Идентификатор ключа"
+//	@Produce		json
+//	@Success		200	"Ключ успешно отозван"
+//	@Failure		400	{object}	tools.Error	"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Ключ с данным id не найден"
+//	@Failure		409	"Ключ уже отозван"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/api_key/{id}/revoke [post]
+//
+//	@Security		AdminToken
+func (ah *APIKeyHandlers) RevokeAPIKey(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(APIKeyIDField), 10, 32)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get api key id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	if err := ah.usecase.RevokeAPIKey(c.Request.Context(), types.ID(id)); err != nil {
+		sendAPIKeyError(c, err, l, "can't revoke api key")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, nil, l)
+}
+
+func sendAPIKeyError(c *gin.Context, err error, l logger.Interface, message string) {
+	switch {
+	case errors.Is(err, ar.ErrorAPIKeyNotFound):
+		tools.SendErrorStatus(c, err, http.StatusNotFound, l)
+	case errors.Is(err, ar.ErrorAPIKeyInactive):
+		tools.SendErrorStatus(c, err, http.StatusConflict, l)
+	default:
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrap(err, message))
+	}
+}
@@ -0,0 +1,9 @@
+package handlers
+
+import "github.com/pkg/errors"
+
+var (
+	ErrorLimitIncorrectType       = errors.New("limit have incorrect type")
+	ErrorOffsetIncorrectType      = errors.New("offset have incorrect type")
+	ErrorGracePeriodIncorrectType = errors.New("grace period have incorrect type")
+)
@@ -0,0 +1,44 @@
+package request
+
+import (
+	"bannersrv/internal/apikey/entity"
+	"bannersrv/internal/apikey/models"
+	"bannersrv/internal/pkg/evjson"
+	"bannersrv/internal/pkg/types"
+	"time"
+
+	"github.com/miladibra10/vjson"
+)
+
+type CreateAPIKey struct {
+	// Роль владельца ключа
+	Role string `json:"role" enums:"admin,admin_read_only,user"`
+	// Владелец ключа, сохраняется в журнал изменений как автор действий
+	Owner string `json:"owner"`
+	// Доступные админу фичи, при отсутствии доступны все фичи
+	FeatureIDs []types.ID `json:"feature_ids,omitempty"`
+	// Время окончания действия ключа, при отсутствии ключ бессрочный
+	ExpiresAt *time.Time `json:"expires_at,omitempty" swaggertype:"string" format:"date-time"`
+}
+
+func ValidateCreateAPIKey(data []byte) error {
+	schema := evjson.NewSchema(
+		vjson.String("role").Choices(
+			string(entity.RoleAdmin), string(entity.RoleAdminReadOnly), string(entity.RoleUser),
+		).Required(),
+		vjson.String("owner").MinLength(1).Required(),
+		vjson.Array("feature_ids", vjson.Integer("id").Positive()),
+		vjson.String("expires_at"),
+	)
+
+	return schema.ValidateBytes(data)
+}
+
+func (ck *CreateAPIKey) ToModel() *models.APIKeyParams {
+	return &models.APIKeyParams{
+		Role:       entity.Role(ck.Role),
+		Owner:      ck.Owner,
+		FeatureIDs: ck.FeatureIDs,
+		ExpiresAt:  ck.ExpiresAt,
+	}
+}
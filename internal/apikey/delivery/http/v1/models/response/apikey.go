@@ -0,0 +1,52 @@
+package response
+
+import (
+	"bannersrv/internal/apikey/models"
+	"bannersrv/internal/pkg/types"
+	"time"
+)
+
+type APIKey struct {
+	// Идентификатор ключа
+	ID types.ID `json:"api_key_id" swaggertype:"integer" format:"uint64"`
+	// Роль владельца ключа
+	Role string `json:"role" enums:"admin,admin_read_only,user"`
+	// Владелец ключа
+	Owner string `json:"owner"`
+	// Доступные админу фичи, при отсутствии доступны все фичи
+	FeatureIDs []types.ID `json:"feature_ids,omitempty"`
+	// Дата выдачи ключа
+	CreatedAt time.Time `json:"created_at" swaggertype:"string" format:"date-time"`
+	// Время окончания действия ключа
+	ExpiresAt *time.Time `json:"expires_at,omitempty" swaggertype:"string" format:"date-time"`
+	// Время отзыва ключа
+	RevokedAt *time.Time `json:"revoked_at,omitempty" swaggertype:"string" format:"date-time"`
+	// Идентификатор ключа, на замену которому выдан данный
+	RotatedFrom *types.ID `json:"rotated_from,omitempty" swaggertype:"integer" format:"uint64"`
+}
+
+type IssuedAPIKey struct {
+	APIKey
+	// Ключ доступа, возвращается только при выдаче
+	Key string `json:"key"`
+}
+
+func FromModelAPIKey(key *models.APIKey) *APIKey {
+	return &APIKey{
+		ID:          key.ID,
+		Role:        string(key.Role),
+		Owner:       key.Owner,
+		FeatureIDs:  key.FeatureIDs,
+		CreatedAt:   key.CreatedAt,
+		ExpiresAt:   key.ExpiresAt,
+		RevokedAt:   key.RevokedAt,
+		RotatedFrom: key.RotatedFrom,
+	}
+}
+
+func FromModelIssuedAPIKey(key *models.IssuedAPIKey) *IssuedAPIKey {
+	return &IssuedAPIKey{
+		APIKey: *FromModelAPIKey(&key.APIKey),
+		Key:    key.Key,
+	}
+}
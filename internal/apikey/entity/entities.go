@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"bannersrv/internal/pkg/types"
+	"time"
+)
+
+// Role права, которые получает владелец ключа
+type Role string
+
+const (
+	RoleAdmin         Role = "admin"
+	RoleAdminReadOnly Role = "admin_read_only"
+	RoleUser          Role = "user"
+)
+
+type APIKey struct {
+	ID          types.ID
+	Role        Role
+	Owner       string
+	FeatureIDs  []types.ID
+	CreatedAt   time.Time
+	ExpiresAt   *time.Time
+	RevokedAt   *time.Time
+	RotatedFrom *types.ID
+}
+
+// IsActive возвращает true, если ключ не отозван и не истёк к моменту now
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -0,0 +1,38 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Prefix отличает ключи доступа от JWT токенов
+const Prefix = "bk_"
+
+const keySize = 32
+
+// GenerateKey создаёт новый случайный ключ доступа
+func GenerateKey() (string, error) {
+	raw := make([]byte, keySize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.Wrap(err, "can't generate api key")
+	}
+
+	return Prefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// HashKey возвращает хэш ключа, под которым он хранится в базе.
+// Ключи имеют достаточную энтропию, поэтому медленное хэширование не требуется.
+func HashKey(key string) []byte {
+	hash := sha256.Sum256([]byte(key))
+
+	return hash[:]
+}
+
+// IsKey возвращает true, если токен имеет формат ключа доступа
+func IsKey(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
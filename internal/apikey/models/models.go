@@ -0,0 +1,54 @@
+package models
+
+import (
+	"bannersrv/internal/apikey/entity"
+	"bannersrv/internal/pkg/types"
+	"time"
+)
+
+// APIKeyParams параметры выдаваемого ключа, FeatureIDs равный nil не ограничивает доступные фичи
+type APIKeyParams struct {
+	Role       entity.Role
+	Owner      string
+	FeatureIDs []types.ID
+	ExpiresAt  *time.Time
+}
+
+type APIKey struct {
+	ID          types.ID
+	Role        entity.Role
+	Owner       string
+	FeatureIDs  []types.ID
+	CreatedAt   time.Time
+	ExpiresAt   *time.Time
+	RevokedAt   *time.Time
+	RotatedFrom *types.ID
+}
+
+// IssuedAPIKey только что выданный ключ, сам ключ возвращается единственный раз
+type IssuedAPIKey struct {
+	APIKey
+	Key string
+}
+
+func FromAPIKeyEntity(key *entity.APIKey) *APIKey {
+	return &APIKey{
+		ID:          key.ID,
+		Role:        key.Role,
+		Owner:       key.Owner,
+		FeatureIDs:  key.FeatureIDs,
+		CreatedAt:   key.CreatedAt,
+		ExpiresAt:   key.ExpiresAt,
+		RevokedAt:   key.RevokedAt,
+		RotatedFrom: key.RotatedFrom,
+	}
+}
+
+func (p *APIKeyParams) ToAPIKeyEntity() *entity.APIKey {
+	return &entity.APIKey{
+		Role:       p.Role,
+		Owner:      p.Owner,
+		FeatureIDs: p.FeatureIDs,
+		ExpiresAt:  p.ExpiresAt,
+	}
+}
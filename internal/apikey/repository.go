@@ -0,0 +1,16 @@
+package apikey
+
+import (
+	"bannersrv/internal/apikey/entity"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"time"
+)
+
+type Repository interface {
+	CreateAPIKey(ctx context.Context, key *entity.APIKey, hash []byte) (*entity.APIKey, error)
+	GetAPIKeys(ctx context.Context, owner *string, offset, limit uint64) ([]entity.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash []byte) (*entity.APIKey, error)
+	RotateAPIKey(ctx context.Context, id types.ID, hash []byte, oldExpiresAt *time.Time) (*entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id types.ID) error
+}
@@ -0,0 +1,8 @@
+package repository
+
+import "github.com/pkg/errors"
+
+var (
+	ErrorAPIKeyNotFound = errors.New("api key not found")
+	ErrorAPIKeyInactive = errors.New("api key is revoked or expired")
+)
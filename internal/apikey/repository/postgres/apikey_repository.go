@@ -0,0 +1,228 @@
+package postgres
+
+import (
+	"bannersrv/internal/apikey/entity"
+	"bannersrv/internal/apikey/repository"
+	"bannersrv/internal/pkg/pg"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+const (
+	createAPIKeyQuery = `
+		INSERT INTO api_keys (key_hash, role, owner, feature_ids, expires_at, rotated_from)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at
+	`
+
+	getAPIKeysQuery = `
+		SELECT id, role, owner, feature_ids, created_at, expires_at, revoked_at, rotated_from
+			FROM api_keys
+			WHERE (CASE WHEN $1::text IS NOT NULL THEN owner = $1 ELSE true END)
+			ORDER BY id DESC
+			LIMIT $2 OFFSET $3
+	`
+
+	getAPIKeyByHashQuery = `
+		SELECT id, role, owner, feature_ids, created_at, expires_at, revoked_at, rotated_from
+			FROM api_keys WHERE key_hash = $1
+	`
+
+	lockAPIKeyQuery = `
+		SELECT role, owner, feature_ids, expires_at,
+			   revoked_at IS NULL and (expires_at IS NULL or expires_at > now())
+			FROM api_keys WHERE id = $1 FOR UPDATE
+	`
+
+	revokeRotatedAPIKeyQuery = `
+		UPDATE api_keys SET revoked_at = now() WHERE id = $1
+	`
+
+	expireRotatedAPIKeyQuery = `
+		UPDATE api_keys SET expires_at = LEAST(expires_at, $2) WHERE id = $1
+	`
+
+	revokeAPIKeyQuery = `
+		UPDATE api_keys SET revoked_at = now() WHERE id = $1 and revoked_at IS NULL RETURNING id
+	`
+
+	checkAPIKeyExistsQuery = `
+		SELECT id FROM api_keys WHERE id = $1
+	`
+)
+
+type APIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+func (ar *APIKeyRepository) CreateAPIKey(ctx context.Context, key *entity.APIKey,
+	hash []byte,
+) (*entity.APIKey, error) {
+	created := *key
+
+	if err := ar.db.QueryRow(ctx, createAPIKeyQuery,
+		hash, key.Role, key.Owner, key.FeatureIDs, key.ExpiresAt, key.RotatedFrom,
+	).Scan(&created.ID, &created.CreatedAt); err != nil {
+		return nil, errors.Wrapf(err, "can't create api key with role %s for %s", key.Role, key.Owner)
+	}
+
+	return &created, nil
+}
+
+func (ar *APIKeyRepository) GetAPIKeys(ctx context.Context, owner *string,
+	offset, limit uint64,
+) ([]entity.APIKey, error) {
+	keys := make([]entity.APIKey, 0)
+
+	sqlOwner := &pgtype.Text{}
+	if owner != nil {
+		sqlOwner = &pgtype.Text{Valid: true, String: *owner}
+	}
+
+	rows, err := ar.db.Query(ctx, getAPIKeysQuery, sqlOwner, limit, offset)
+	//nolint: staticcheck
+	defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't execute get api keys query with limit %d and offset %d", limit, offset)
+	}
+
+	for rows.Next() {
+		var key entity.APIKey
+
+		if err := scanAPIKey(rows, &key); err != nil {
+			return nil, errors.Wrap(err, "can't scan get api keys query result")
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "can't end scan get api keys query result")
+	}
+
+	return keys, nil
+}
+
+func (ar *APIKeyRepository) GetAPIKeyByHash(ctx context.Context, hash []byte) (*entity.APIKey, error) {
+	var key entity.APIKey
+
+	if err := scanAPIKey(ar.db.QueryRow(ctx, getAPIKeyByHashQuery, hash), &key); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrorAPIKeyNotFound
+		}
+
+		return nil, errors.Wrap(err, "can't get api key by hash")
+	}
+
+	return &key, nil
+}
+
+// RotateAPIKey выдаёт ключ на замену ключу id с теми же правами и сроком действия.
+// Если oldExpiresAt равен nil, то старый ключ сразу отзывается, иначе продолжает действовать до oldExpiresAt.
+func (ar *APIKeyRepository) RotateAPIKey(ctx context.Context, id types.ID, hash []byte,
+	oldExpiresAt *time.Time,
+) (*entity.APIKey, error) {
+	rotated := &entity.APIKey{RotatedFrom: &id}
+
+	if err := pg.WithTransaction(ctx, ar.db,
+		func(tx pgx.Tx) error {
+			var featureIDs pgtype.Array[types.ID]
+
+			var active bool
+
+			if err := tx.QueryRow(ctx, lockAPIKeyQuery, id).
+				Scan(&rotated.Role, &rotated.Owner, &featureIDs, &rotated.ExpiresAt, &active); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return repository.ErrorAPIKeyNotFound
+				}
+
+				return errors.Wrap(err, "can't lock api key")
+			}
+
+			if !active {
+				return repository.ErrorAPIKeyInactive
+			}
+
+			if featureIDs.Valid {
+				rotated.FeatureIDs = featureIDs.Elements
+			}
+
+			if oldExpiresAt == nil {
+				if _, err := tx.Exec(ctx, revokeRotatedAPIKeyQuery, id); err != nil {
+					return errors.Wrap(err, "can't revoke rotated api key")
+				}
+			} else if _, err := tx.Exec(ctx, expireRotatedAPIKeyQuery, id, oldExpiresAt); err != nil {
+				return errors.Wrap(err, "can't shorten expiration of rotated api key")
+			}
+
+			if err := tx.QueryRow(ctx, createAPIKeyQuery,
+				hash, rotated.Role, rotated.Owner, rotated.FeatureIDs, rotated.ExpiresAt, id,
+			).Scan(&rotated.ID, &rotated.CreatedAt); err != nil {
+				return errors.Wrap(err, "can't create new api key")
+			}
+
+			return nil
+		},
+	); err != nil {
+		return nil, errors.Wrapf(err, "when rotating api key with id %d", id)
+	}
+
+	return rotated, nil
+}
+
+func (ar *APIKeyRepository) RevokeAPIKey(ctx context.Context, id types.ID) error {
+	var revokedID types.ID
+	if err := ar.db.QueryRow(ctx, revokeAPIKeyQuery, id).Scan(&revokedID); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return errors.Wrapf(err, "can't revoke api key with id %d", id)
+		}
+
+		if err := ar.db.QueryRow(ctx, checkAPIKeyExistsQuery, id).Scan(&revokedID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errors.Wrapf(repository.ErrorAPIKeyNotFound, "with id %d", id)
+			}
+
+			return errors.Wrapf(err, "can't check api key with id %d on existence", id)
+		}
+
+		return errors.Wrapf(repository.ErrorAPIKeyInactive, "with id %d", id)
+	}
+
+	return nil
+}
+
+func scanAPIKey(row pgx.Row, key *entity.APIKey) error {
+	var featureIDs pgtype.Array[types.ID]
+
+	if err := row.Scan(
+		&key.ID,
+		&key.Role,
+		&key.Owner,
+		&featureIDs,
+		&key.CreatedAt,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.RotatedFrom,
+	); err != nil {
+		return err
+	}
+
+	if featureIDs.Valid {
+		key.FeatureIDs = featureIDs.Elements
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package apikey
+
+import (
+	"bannersrv/internal/apikey/models"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"time"
+)
+
+type Usecase interface {
+	IssueAPIKey(ctx context.Context, params *models.APIKeyParams) (*models.IssuedAPIKey, error)
+	GetAPIKeys(ctx context.Context, owner *string, offset, limit *uint64) ([]models.APIKey, error)
+	RotateAPIKey(ctx context.Context, id types.ID, gracePeriod time.Duration) (*models.IssuedAPIKey, error)
+	RevokeAPIKey(ctx context.Context, id types.ID) error
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"bannersrv/internal/apikey"
+	"bannersrv/internal/apikey/entity"
+	"bannersrv/internal/apikey/models"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/slices"
+	"context"
+	"time"
+)
+
+const (
+	defaultOffset = 0
+	defaultLimit  = 100
+)
+
+// APIKeyUsecase управляет ключами доступа. Выдавать и отзывать ключи может только админ
+// с полным доступом, иначе через ключ можно было бы получить права шире собственных.
+type APIKeyUsecase struct {
+	rep apikey.Repository
+}
+
+func NewAPIKeyUsecase(rep apikey.Repository) *APIKeyUsecase {
+	return &APIKeyUsecase{
+		rep: rep,
+	}
+}
+
+func (au *APIKeyUsecase) IssueAPIKey(ctx context.Context, params *models.APIKeyParams) (*models.IssuedAPIKey, error) {
+	if err := access.FromContext(ctx).CheckFull(); err != nil {
+		return nil, err
+	}
+
+	if params.Role == entity.RoleUser && params.FeatureIDs != nil {
+		return nil, ErrorUserKeyFeatures
+	}
+
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(time.Now()) {
+		return nil, ErrorExpiresInPast
+	}
+
+	key, err := apikey.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := au.rep.CreateAPIKey(ctx, params.ToAPIKeyEntity(), apikey.HashKey(key))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.IssuedAPIKey{APIKey: *models.FromAPIKeyEntity(created), Key: key}, nil
+}
+
+func (au *APIKeyUsecase) GetAPIKeys(ctx context.Context, owner *string,
+	offset, limit *uint64,
+) ([]models.APIKey, error) {
+	if err := access.FromContext(ctx).CheckFull(); err != nil {
+		return nil, err
+	}
+
+	var entityOffset uint64 = defaultOffset
+
+	var entityLimit uint64 = defaultLimit
+
+	if offset != nil {
+		entityOffset = *offset
+	}
+
+	if limit != nil {
+		entityLimit = *limit
+	}
+
+	keys, err := au.rep.GetAPIKeys(ctx, owner, entityOffset, entityLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Map(keys, func(k *entity.APIKey) models.APIKey {
+		return *models.FromAPIKeyEntity(k)
+	}), nil
+}
+
+// RotateAPIKey выдаёт новый ключ с правами ключа id. Старый ключ продолжает действовать
+// ещё gracePeriod, чтобы клиенты успели перейти на новый, при нулевом gracePeriod он отзывается сразу.
+func (au *APIKeyUsecase) RotateAPIKey(ctx context.Context, id types.ID,
+	gracePeriod time.Duration,
+) (*models.IssuedAPIKey, error) {
+	if err := access.FromContext(ctx).CheckFull(); err != nil {
+		return nil, err
+	}
+
+	var oldExpiresAt *time.Time
+
+	if gracePeriod > 0 {
+		expiresAt := time.Now().Add(gracePeriod)
+		oldExpiresAt = &expiresAt
+	}
+
+	key, err := apikey.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := au.rep.RotateAPIKey(ctx, id, apikey.HashKey(key), oldExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.IssuedAPIKey{APIKey: *models.FromAPIKeyEntity(rotated), Key: key}, nil
+}
+
+func (au *APIKeyUsecase) RevokeAPIKey(ctx context.Context, id types.ID) error {
+	if err := access.FromContext(ctx).CheckFull(); err != nil {
+		return err
+	}
+
+	return au.rep.RevokeAPIKey(ctx, id)
+}
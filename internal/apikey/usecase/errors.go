@@ -0,0 +1,8 @@
+package usecase
+
+import "github.com/pkg/errors"
+
+var (
+	ErrorUserKeyFeatures = errors.New("features can be limited only for admin keys")
+	ErrorExpiresInPast   = errors.New("expiration time of key must be in the future")
+)
@@ -0,0 +1,177 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"net/http"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+// isMarkedDeleted проверяет, что баннер помечен на отложенное удаление
+func (as *ApiSuite) isMarkedDeleted(bannerID types.ID) (bool, error) {
+	var deleted bool
+	err := as.pgConnection.
+		QueryRow(context.Background(),
+			"SELECT bool_and(deleted) FROM features_tags_banner WHERE banner_id = $1", bannerID).
+		Scan(&deleted)
+
+	return deleted, err
+}
+
+func (as *ApiSuite) TestScopedAdmin(t provider.T) {
+	t.Title("Тестирование ограничения прав админа по фичам")
+	t.NewStep("Инициализация тестовых данных")
+
+	ownBannerID, err := as.bannerRepository.CreateBanner(context.Background(), 90, []types.ID{1, 2},
+		`{"title": "own"}`, true, nil, 0)
+	t.Require().NoError(err)
+
+	foreignBannerID, err := as.bannerRepository.CreateBanner(context.Background(), 91, []types.ID{1, 2},
+		`{"title": "foreign"}`, true, nil, 0)
+	t.Require().NoError(err)
+
+	scopedToken := as.scopedAdminToken(t, &access.Scope{FeatureIDs: []types.ID{90, 92}})
+
+	t.Run("Админ видит только баннеры своих фич", func(t provider.T) {
+		resp := apitest.New().
+			Handler(as.router).
+			Get("/api/v1/banner").
+			Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, scopedToken).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var bnrs []response.Banner
+		resp.JSON(&bnrs)
+
+		t.Require().Len(bnrs, 1)
+		t.Require().Equal(ownBannerID, bnrs[0].ID)
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/banner").
+			Query(bh.FeatureIDParam, "91").
+			Header(middleware.TokenHeaderField, scopedToken).
+			Expect(t).
+			Body(`[]`).
+			Status(http.StatusOK).
+			End()
+	})
+
+	t.Run("Попытка создания баннера в чужой фиче", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/banner").
+			Body(`{"tag_ids": [3], "feature_id": 91, "content": {"title": "foreign"}, "is_active": true}`).
+			Header(middleware.TokenHeaderField, scopedToken).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+
+	t.Run("Попытка переноса баннера в чужую фичу", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Patchf("/api/v1/banner/%d", ownBannerID).
+			Body(`{"feature_id": 91}`).
+			Header(middleware.TokenHeaderField, scopedToken).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Patchf("/api/v1/banner/%d", ownBannerID).
+			Body(`{"feature_id": 92}`).
+			Header(middleware.TokenHeaderField, scopedToken).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+	})
+
+	t.Run("Попытка изменения и удаления баннера чужой фичи", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Patchf("/api/v1/banner/%d", foreignBannerID).
+			Body(`{"is_active": false}`).
+			Header(middleware.TokenHeaderField, scopedToken).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Deletef("/api/v1/banner/%d", foreignBannerID).
+			Header(middleware.TokenHeaderField, scopedToken).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+
+	t.Run("Удаление по тегу затрагивает только баннеры своих фич", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Delete("/api/v1/filter_banner").
+			Query(bh.FeatureIDParam, "91").
+			Header(middleware.TokenHeaderField, scopedToken).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Delete("/api/v1/filter_banner").
+			Query(bh.TagIDParam, "2").
+			Header(middleware.TokenHeaderField, scopedToken).
+			Expect(t).
+			Status(http.StatusNoContent).
+			End()
+
+		deleted, err := as.isMarkedDeleted(ownBannerID)
+		t.Require().NoError(err)
+		t.Require().True(deleted)
+
+		deleted, err = as.isMarkedDeleted(foreignBannerID)
+		t.Require().NoError(err)
+		t.Require().False(deleted)
+	})
+
+	t.Run("Админ с доступом только на чтение", func(t provider.T) {
+		readOnlyToken := as.scopedAdminToken(t, &access.Scope{ReadOnly: true})
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/banner").
+			Header(middleware.TokenHeaderField, readOnlyToken).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Patchf("/api/v1/banner/%d", foreignBannerID).
+			Body(`{"is_active": false}`).
+			Header(middleware.TokenHeaderField, readOnlyToken).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/banner").
+			Body(`{"tag_ids": [3], "feature_id": 93, "content": {"title": "read only"}, "is_active": true}`).
+			Header(middleware.TokenHeaderField, readOnlyToken).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+}
@@ -0,0 +1,263 @@
+//go:build integration
+
+package api_test
+
+import (
+	kh "bannersrv/internal/apikey/delivery/http/v1/handlers"
+	kr "bannersrv/internal/apikey/delivery/http/v1/models/response"
+	"bannersrv/internal/app/delivery/http/middleware"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+// issueAPIKey выдаёт ключ доступа через API от имени админа с полным доступом
+func (as *ApiSuite) issueAPIKey(t provider.T, body string) kr.IssuedAPIKey {
+	resp := apitest.New().
+		Handler(as.router).
+		Post("/api/v1/api_key").
+		Body(body).
+		Header(middleware.TokenHeaderField, as.adminToken(t)).
+		Expect(t).
+		Status(http.StatusCreated).
+		End()
+
+	var issued kr.IssuedAPIKey
+	resp.JSON(&issued)
+
+	return issued
+}
+
+func (as *ApiSuite) TestAPIKeys(t provider.T) {
+	t.Title("Тестирование выдачи, ротации и отзыва ключей доступа")
+	t.NewStep("Инициализация тестовых данных")
+
+	_, err := as.bannerRepository.CreateBanner(context.Background(), 95, []types.ID{1},
+		`{"title": "api key"}`, true, nil, 0)
+	t.Require().NoError(err)
+
+	t.Run("Ключ пользователя даёт доступ к баннеру пользователя", func(t provider.T) {
+		issued := as.issueAPIKey(t, `{"role": "user", "owner": "mobile-app"}`)
+		t.Require().NotEmpty(issued.Key)
+		t.Require().Equal("user", issued.Role)
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/user_banner").
+			Query(bh.FeatureIDParam, "95").
+			Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, issued.Key).
+			Expect(t).
+			Body(`{"title": "api key"}`).
+			Status(http.StatusOK).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/banner").
+			Header(middleware.TokenHeaderField, issued.Key).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+
+	t.Run("Ключ админа с доступом только на чтение", func(t provider.T) {
+		issued := as.issueAPIKey(t, `{"role": "admin_read_only", "owner": "dashboard", "feature_ids": [95]}`)
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/banner").
+			Query(bh.FeatureIDParam, "95").
+			Header(middleware.TokenHeaderField, issued.Key).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/banner").
+			Body(`{"tag_ids": [2], "feature_id": 95, "content": {"title": "new"}, "is_active": true}`).
+			Header(middleware.TokenHeaderField, issued.Key).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+
+	t.Run("Некорректные параметры ключа", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/api_key").
+			Body(`{"role": "root", "owner": "someone"}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/api_key").
+			Body(`{"role": "user", "owner": "someone", "feature_ids": [1]}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/api_key").
+			Body(`{"role": "user", "owner": "someone", "expires_at": "2000-01-01T00:00:00Z"}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+
+	t.Run("Управлять ключами может только админ с полным доступом", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/api_key").
+			Body(`{"role": "admin", "owner": "escalation"}`).
+			Header(middleware.TokenHeaderField, as.scopedAdminToken(t, &access.Scope{FeatureIDs: []types.ID{95}})).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/api_key").
+			Header(middleware.TokenHeaderField, as.scopedAdminToken(t, &access.Scope{ReadOnly: true})).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/api_key").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+
+	t.Run("Ротация ключа", func(t provider.T) {
+		issued := as.issueAPIKey(t, `{"role": "user", "owner": "rotated-app"}`)
+
+		resp := apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/api_key/%d/rotate", issued.ID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusCreated).
+			End()
+
+		var rotated kr.IssuedAPIKey
+		resp.JSON(&rotated)
+
+		t.Require().NotEqual(issued.Key, rotated.Key)
+		t.Require().Equal("rotated-app", rotated.Owner)
+		t.Require().NotNil(rotated.RotatedFrom)
+		t.Require().Equal(issued.ID, *rotated.RotatedFrom)
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/user_banner").
+			Query(bh.FeatureIDParam, "95").
+			Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, rotated.Key).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/api_key/%d/rotate", issued.ID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusConflict).
+			End()
+
+		resp = apitest.New().
+			Handler(as.router).
+			Get("/api/v1/api_key").
+			Query(kh.OwnerParam, "rotated-app").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var keys []kr.APIKey
+		resp.JSON(&keys)
+
+		t.Require().Len(keys, 2)
+		t.Require().Equal(rotated.ID, keys[0].ID)
+		t.Require().NotNil(keys[1].RevokedAt)
+	})
+
+	t.Run("Отозванный ключ перестаёт приниматься после истечения кэша", func(t provider.T) {
+		issued := as.issueAPIKey(t, `{"role": "user", "owner": "revoked-app"}`)
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/user_banner").
+			Query(bh.FeatureIDParam, "95").
+			Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, issued.Key).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/api_key/%d/revoke", issued.ID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/api_key/%d/revoke", issued.ID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusConflict).
+			End()
+
+		time.Sleep(time.Duration(tokenConfig.APIKeyCacheTTL) * time.Millisecond)
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/user_banner").
+			Query(bh.FeatureIDParam, "95").
+			Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, issued.Key).
+			Expect(t).
+			Status(http.StatusUnauthorized).
+			End()
+	})
+
+	t.Run("Неизвестный ключ и отзыв несуществующего ключа", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/user_banner").
+			Query(bh.FeatureIDParam, "95").
+			Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, "bk_unknown").
+			Expect(t).
+			Status(http.StatusUnauthorized).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/api_key/100000/revoke").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusNotFound).
+			End()
+	})
+}
@@ -6,16 +6,26 @@ import (
 	"bannersrv/external/auth"
 	ah "bannersrv/external/auth/delivery/http/v1/handlers"
 	au "bannersrv/external/auth/usecase"
+	kh "bannersrv/internal/apikey/delivery/http/v1/handlers"
+	kp "bannersrv/internal/apikey/repository/postgres"
+	ku "bannersrv/internal/apikey/usecase"
 	"bannersrv/internal/app"
 	"bannersrv/internal/app/config"
+	"bannersrv/internal/app/delivery/http/middleware"
 	v1 "bannersrv/internal/app/delivery/http/v1"
 	"bannersrv/internal/banner"
 	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
 	bp "bannersrv/internal/banner/repository/postgres"
 	bu "bannersrv/internal/banner/usecase"
+	"bannersrv/internal/caches"
 	cm "bannersrv/internal/caches/manager"
 	cr "bannersrv/internal/caches/repository/redis"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/lock"
+	"bannersrv/internal/pkg/ratelimit"
 	"bannersrv/internal/pkg/types"
+	"bannersrv/internal/token"
+	tu "bannersrv/internal/token/usecase"
 	"bannersrv/pkg/logger"
 	"context"
 	"fmt"
@@ -42,6 +52,51 @@ type ApiSuite struct {
 	rdsClient        *redis.Client
 	bannerRepository banner.Repository
 	authService      auth.Usecase
+	tokenService     token.Service
+	rateLimiter      middleware.RateLimiter
+	// stopPopularity останавливает учёт запросов и дожидается сохранения оставшихся
+	stopPopularity func()
+}
+
+// tokenConfig настройки токенов для тестов, выдаваемые и проверяемые токены подписываются одним секретом
+var tokenConfig = token.Config{
+	Algorithm:     "HS256",
+	Secret:        "api-test-secret",
+	Issuer:        "bannersrv",
+	Audience:      "bannersrv",
+	RoleClaim:     "role",
+	AdminRole:     "admin",
+	ReadOnlyRole:  "admin_read_only",
+	UserRole:      "user",
+	FeaturesClaim: "features",
+	TTL:           60000,
+
+	APIKeyCacheTTL:      100,
+	APIKeyCacheSize:     100,
+	APIKeyMissCacheSize: 100,
+	APIKeyLookupTimeout: 1000,
+}
+
+// coalescingConfig объединение запросов баннера пользователем в тестах включено и между репликами
+var coalescingConfig = banner.CoalescingConfig{
+	Timeout: 1000,
+	Lock:    true,
+	LockTTL: 500,
+}
+
+// ttlConfig время жизни баннеров в кэше в тестах совпадает с настройками по умолчанию,
+// но без случайного сокращения, чтобы время жизни записей было предсказуемым
+var ttlConfig = caches.TTLConfig{
+	Soft: 300000,
+	Hard: 3600000,
+}
+
+// popularityConfig запросы пользователей в тестах сохраняются почти сразу
+var popularityConfig = caches.PopularityConfig{
+	Window:      3600000,
+	Bucket:      60000,
+	FlushPeriod: 50,
+	MaxPairs:    100,
 }
 
 func (as *ApiSuite) BeforeEach(t provider.T) {
@@ -88,30 +143,58 @@ func (as *ApiSuite) BeforeEach(t provider.T) {
 	// Repository
 	as.bannerRepository = bp.NewBannerRepository(as.pgConnection)
 	cacheRepository := cr.NewCashRedis(as.rdsClient)
+	apiKeyRepository := kp.NewAPIKeyRepository(as.pgConnection)
+	as.rateLimiter = ratelimit.NewRedisLimiter(as.rdsClient)
 
 	t.NewStep("Инициализация юзкейсов")
 	// Use-cases
-	bannerUsecase := bu.NewBannerUsecase(as.bannerRepository)
-	cacheManager := cm.NewCacheManager(cacheRepository)
-	authService := au.NewAuthUsecase()
-	as.authService = authService
+	cacheManager := cm.NewCacheManager(cacheRepository, ttlConfig, nil, l)
+	popularityManager := cm.NewPopularityManager(cr.NewPopularityRedis(as.rdsClient, popularityConfig),
+		popularityConfig, l)
+	bannerUsecase := bu.NewBannerUsecase(as.bannerRepository, cacheManager, popularityManager,
+		lock.NewRedisLocker(as.rdsClient), &coalescingConfig)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		popularityManager.Run(ctx)
+	}()
+
+	as.stopPopularity = func() {
+		cancel()
+		<-stopped
+	}
+	as.authService, err = au.NewAuthUsecase(&tokenConfig)
+	t.Require().NoError(err)
+
+	jwtService, err := tu.NewJWTUsecase(&tokenConfig)
+	t.Require().NoError(err)
+
+	as.tokenService = tu.NewCompositeUsecase(jwtService, tu.NewAPIKeyUsecase(apiKeyRepository, &tokenConfig))
 
 	t.NewStep("Инициализация обработчиков запросов")
 	// Handlers
-	bannerHandlers := bh.NewBannerHandlers(bannerUsecase, cacheManager)
+	bannerHandlers := bh.NewBannerHandlers(bannerUsecase)
 	authHandlers := ah.NewAuthHandlers(as.authService)
+	apiKeyHandlers := kh.NewAPIKeyHandlers(ku.NewAPIKeyUsecase(apiKeyRepository))
 
 	t.NewStep("Инициализация роутера")
 	// routes
-	as.router, err = v1.NewRouter("/api", app.PrepareRoutes(bannerHandlers, cacheManager, authService, authHandlers),
-		config.Release, l, nil)
+	as.router, err = v1.NewRouter("/api", app.PrepareRoutes(bannerHandlers, apiKeyHandlers, cacheManager,
+		popularityManager, bannerUsecase, as.tokenService, authHandlers,
+		middleware.NewRateLimits(as.rateLimiter, config.RateLimit{}, nil), nil, 0),
+		config.Release, 0, nil, l, nil)
 	if err != nil {
 		t.Fatalf("init router error: %s", err)
 	}
 }
 
 func (as *ApiSuite) AfterEach(t provider.T) {
-	_, err := as.pgConnection.Exec(context.Background(), `TRUNCATE banner CASCADE`)
+	as.stopPopularity()
+
+	_, err := as.pgConnection.Exec(context.Background(), `TRUNCATE banner, api_keys, feature_settings CASCADE`)
 	t.Require().NoError(err)
 
 	t.Require().NoError(as.rdsClient.FlushAll(context.Background()).Err())
@@ -119,6 +202,24 @@ func (as *ApiSuite) AfterEach(t provider.T) {
 	as.pgConnection.Close()
 }
 
+func (as *ApiSuite) adminToken(t provider.T) string {
+	return as.scopedAdminToken(t, nil)
+}
+
+func (as *ApiSuite) scopedAdminToken(t provider.T, scope *access.Scope) string {
+	tok, err := as.authService.GetAdminToken(scope)
+	t.Require().NoError(err)
+
+	return string(tok)
+}
+
+func (as *ApiSuite) userToken(t provider.T) string {
+	tok, err := as.authService.GetUserToken()
+	t.Require().NoError(err)
+
+	return string(tok)
+}
+
 func (as *ApiSuite) checkDeleted(bannerID types.ID) error {
 	id := 0
 	return as.pgConnection.
@@ -0,0 +1,174 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/external/auth"
+	"bannersrv/internal/app/delivery/http/middleware"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+func (as *ApiSuite) TestAudit(t provider.T) {
+	t.Title("Тестирование апи метода журнала изменений: GET /audit")
+	t.NewStep("Инициализация тестовых данных")
+
+	from := time.Now().Add(-time.Minute).Format(time.RFC3339)
+
+	bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 84, []types.ID{1},
+		`{"title": "audit"}`, true, nil, 0)
+	t.Require().NoError(err)
+
+	token := as.adminToken(t)
+	claims, err := as.tokenService.Parse(auth.Token(token))
+	t.Require().NoError(err)
+
+	actor := claims.Subject
+
+	apitest.New().
+		Handler(as.router).
+		Patchf("/api/v1/banner/%d", bannerID).
+		Body(`{"is_active": false, "tag_ids": [2, 3]}`).
+		Header(middleware.TokenHeaderField, token).
+		Expect(t).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New().
+		Handler(as.router).
+		Deletef("/api/v1/banner/%d", bannerID).
+		Header(middleware.TokenHeaderField, token).
+		Expect(t).
+		Status(http.StatusNoContent).
+		End()
+
+	t.Run("Успешное получение журнала изменений баннера", func(t provider.T) {
+		resp := apitest.New().
+			Handler(as.router).
+			Get("/api/v1/audit").
+			Query(bh.AuditBannerIDParam, strconv.FormatUint(uint64(bannerID), 10)).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var entries []response.AuditEntry
+		resp.JSON(&entries)
+
+		t.Require().Len(entries, 3)
+		t.Require().Equal("delete", entries[0].Action)
+		t.Require().Equal("update", entries[1].Action)
+		t.Require().Equal("create", entries[2].Action)
+
+		t.Require().Nil(entries[2].Before)
+		t.Require().NotNil(entries[2].After)
+		t.Require().True(entries[2].After.IsActive)
+
+		update := entries[1]
+		t.Require().NotNil(update.Actor)
+		t.Require().Equal(actor, *update.Actor)
+		t.Require().NotNil(update.RequestID)
+		t.Require().True(update.Before.IsActive)
+		t.Require().False(update.After.IsActive)
+		t.Require().ElementsMatch([]types.ID{2, 3}, update.After.TagIDs)
+
+		t.Require().NotNil(entries[0].Before)
+		t.Require().Nil(entries[0].After)
+	})
+
+	t.Run("Успешное получение журнала изменений по админу и периоду", func(t provider.T) {
+		resp := apitest.New().
+			Handler(as.router).
+			Get("/api/v1/audit").
+			Query(bh.AuditActorParam, actor).
+			Query(bh.AuditFromParam, from).
+			Query(bh.AuditToParam, time.Now().Add(time.Minute).Format(time.RFC3339)).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var entries []response.AuditEntry
+		resp.JSON(&entries)
+
+		t.Require().Len(entries, 2)
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/audit").
+			Query(bh.AuditActorParam, actor).
+			Query(bh.AuditToParam, from).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Body(`[]`).
+			Status(http.StatusOK).
+			End()
+	})
+
+	t.Run("Получение журнала изменений админом с доступом к части фич", func(t provider.T) {
+		t.NewStep("Тестирование админа без доступа к фиче баннера")
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/audit").
+			Query(bh.AuditBannerIDParam, strconv.FormatUint(uint64(bannerID), 10)).
+			Header(middleware.TokenHeaderField, as.scopedAdminToken(t, &access.Scope{FeatureIDs: []types.ID{131}})).
+			Expect(t).
+			Body(`[]`).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Тестирование админа с доступом к фиче баннера")
+		resp := apitest.New().
+			Handler(as.router).
+			Get("/api/v1/audit").
+			Query(bh.AuditBannerIDParam, strconv.FormatUint(uint64(bannerID), 10)).
+			Header(middleware.TokenHeaderField, as.scopedAdminToken(t, &access.Scope{FeatureIDs: []types.ID{84}})).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var entries []response.AuditEntry
+		resp.JSON(&entries)
+
+		t.Require().Len(entries, 3)
+	})
+
+	t.Run("Попытка получения журнала изменений с некорректными параметрами", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/audit").
+			Query(bh.AuditFromParam, "yesterday").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/audit").
+			Query(bh.AuditBannerIDParam, "mir").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+
+	t.Run("Попытка получения журнала изменений пользователем с неверными правами", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/audit").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+}
@@ -0,0 +1,243 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+func (as *ApiSuite) TestBannerVersions(t provider.T) {
+	t.Title("Тестирование апи методов истории версий: GET /banner/{id}/versions, /banner/{id}/versions/{version}")
+	t.NewStep("Инициализация тестовых данных")
+
+	bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 80, []types.ID{1},
+		`{"title": "version_1"}`, true, nil, 0)
+	t.Require().NoError(err)
+
+	// Создаётся пять версий, при глобальном ограничении в три версии две первые переносятся в архив
+	for version := 2; version <= 5; version++ {
+		_, err := as.bannerRepository.UpdateBanner(context.Background(), &entity.BannerUpdate{
+			ID:        bannerID,
+			Content:   types.NewObject[types.Content](types.Content(fmt.Sprintf(`{"title": "version_%d"}`, version))),
+			TagIDs:    types.NewNullObject[[]types.ID](),
+			FeatureID: (*types.NullableID)(types.NewNullObject[types.ID]()),
+			IsActive:  types.NewNullObject[bool](),
+		})
+		t.Require().NoError(err)
+	}
+
+	t.Run("Успешное получение истории версий", func(t provider.T) {
+		resp := apitest.New().
+			Handler(as.router).
+			Getf("/api/v1/banner/%d/versions", bannerID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var versions []response.Version
+		resp.JSON(&versions)
+
+		t.Require().Len(versions, 5)
+		t.Require().EqualValues(5, versions[0].Version)
+		t.Require().False(versions[0].Archived)
+		t.Require().EqualValues(1, versions[4].Version)
+		t.Require().True(versions[4].Archived)
+	})
+
+	t.Run("Успешное получение истории версий с пагинацией", func(t provider.T) {
+		resp := apitest.New().
+			Handler(as.router).
+			Getf("/api/v1/banner/%d/versions", bannerID).
+			Query(bh.LimitParam, "2").Query(bh.OffsetParam, "1").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var versions []response.Version
+		resp.JSON(&versions)
+
+		t.Require().Len(versions, 2)
+		t.Require().EqualValues(4, versions[0].Version)
+		t.Require().EqualValues(3, versions[1].Version)
+	})
+
+	t.Run("Успешное получение архивной версии", func(t provider.T) {
+		resp := apitest.New().
+			Handler(as.router).
+			Getf("/api/v1/banner/%d/versions/1", bannerID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var version response.Version
+		resp.JSON(&version)
+
+		t.Require().True(version.Archived)
+		t.Require().JSONEq(`{"title": "version_1"}`, string(version.Content))
+	})
+
+	t.Run("Изменение числа хранимых версий баннера", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Patchf("/api/v1/banner/%d", bannerID).
+			Body(`{"retention": 1}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		resp := apitest.New().
+			Handler(as.router).
+			Getf("/api/v1/banner/%d/versions/4", bannerID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var version response.Version
+		resp.JSON(&version)
+
+		t.Require().True(version.Archived)
+	})
+
+	t.Run("Изменение глобального числа хранимых версий", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+		globalBannerID, err := as.bannerRepository.CreateBanner(context.Background(), 134, []types.ID{1},
+			`{"title": "version_1"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		for version := 2; version <= 3; version++ {
+			_, err := as.bannerRepository.UpdateBanner(context.Background(), &entity.BannerUpdate{
+				ID:        globalBannerID,
+				Content:   types.NewObject[types.Content](types.Content(fmt.Sprintf(`{"title": "version_%d"}`, version))),
+				TagIDs:    types.NewNullObject[[]types.ID](),
+				FeatureID: (*types.NullableID)(types.NewNullObject[types.ID]()),
+				IsActive:  types.NewNullObject[bool](),
+			})
+			t.Require().NoError(err)
+		}
+
+		defer apitest.New().
+			Handler(as.router).
+			Put("/api/v1/settings/retention").
+			Body(`{"retention": 3}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Put("/api/v1/settings/retention").
+			Body(`{"retention": 2}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/settings/retention").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Body(`{"retention": 2}`).
+			Status(http.StatusOK).
+			End()
+
+		resp := apitest.New().
+			Handler(as.router).
+			Getf("/api/v1/banner/%d/versions/1", globalBannerID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var version response.Version
+		resp.JSON(&version)
+
+		t.Require().True(version.Archived)
+
+		t.NewStep("Попытка изменения админом с доступом к части фич и некорректным значением")
+		apitest.New().
+			Handler(as.router).
+			Put("/api/v1/settings/retention").
+			Body(`{"retention": 1}`).
+			Header(middleware.TokenHeaderField, as.scopedAdminToken(t, &access.Scope{FeatureIDs: []types.ID{134}})).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Put("/api/v1/settings/retention").
+			Body(`{"retention": 0}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+
+	t.Run("Попытка получения несуществующей версии", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Getf("/api/v1/banner/%d/versions/10", bannerID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusNotFound).
+			End()
+	})
+
+	t.Run("Попытка получения истории версий несуществующего баннера", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/banner/100000/versions").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusNotFound).
+			End()
+	})
+
+	t.Run("Попытка получения истории версий с некорректными параметрами", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/banner/mir/versions").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Getf("/api/v1/banner/%d/versions", bannerID).
+			Query(bh.LimitParam, "mir").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+
+	t.Run("Попытка получения истории версий пользователем с неверными правами", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Getf("/api/v1/banner/%d/versions", bannerID).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+}
@@ -0,0 +1,164 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+func (as *ApiSuite) TestBatchBanners(t provider.T) {
+	t.Title("Тестирование апи метода BatchBanners: POST /banner/batch")
+	const path = "/api/v1/banner/batch"
+
+	t.Run("Успешное пакетное изменение баннеров", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		updatedID, err := as.bannerRepository.CreateBanner(context.Background(), 50, []types.ID{1},
+			`{"title": "updated"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		deletedID, err := as.bannerRepository.CreateBanner(context.Background(), 51, []types.ID{1},
+			`{"title": "deleted"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		t.NewStep("Тестирование")
+		resp := apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body(fmt.Sprintf(`{"operations": [
+				{"action": "create", "banner": {"feature_id": 52, "tag_ids": [1, 2], "content": {}, "is_active": true}},
+				{"action": "update", "banner_id": %d, "banner": {"is_active": false}},
+				{"action": "delete", "banner_id": %d}
+			]}`, updatedID, deletedID)).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+		var result response.BatchResult
+
+		resp.JSON(&result)
+		t.Require().Equal("atomic", result.Mode)
+		t.Require().True(result.Applied)
+		t.Require().Len(result.Results, 3)
+		t.Require().Equal(http.StatusCreated, result.Results[0].Status)
+		t.Require().NotNil(result.Results[0].BannerID)
+		t.Require().Equal(http.StatusOK, result.Results[1].Status)
+		t.Require().Equal(http.StatusNoContent, result.Results[2].Status)
+
+		as.requestUserBanner(t, "52", "2", http.StatusOK)
+		as.requestUserBanner(t, "50", "1", http.StatusNotFound)
+		as.requestUserBanner(t, "51", "1", http.StatusNotFound)
+	})
+
+	t.Run("Откат всех операций при конфликте", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		_, err := as.bannerRepository.CreateBanner(context.Background(), 53, []types.ID{3},
+			`{"title": "existing"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		t.NewStep("Тестирование")
+		resp := apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body(`{"operations": [
+				{"action": "create", "banner": {"feature_id": 54, "tag_ids": [1], "content": {}, "is_active": true}},
+				{"action": "create", "banner": {"feature_id": 53, "tag_ids": [1, 3], "content": {}, "is_active": true}}
+			]}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusUnprocessableEntity).
+			End()
+
+		t.NewStep("Проверка результатов")
+		var result response.BatchResult
+
+		resp.JSON(&result)
+		t.Require().False(result.Applied)
+		t.Require().Equal(http.StatusFailedDependency, result.Results[0].Status)
+		t.Require().Nil(result.Results[0].BannerID)
+		t.Require().Equal(http.StatusConflict, result.Results[1].Status)
+		t.Require().Equal([]types.ID{3}, result.Results[1].ConflictTagIDs)
+
+		as.requestUserBanner(t, "54", "1", http.StatusNotFound)
+	})
+
+	t.Run("Применение успешных операций в режиме best_effort", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		_, err := as.bannerRepository.CreateBanner(context.Background(), 55, []types.ID{3},
+			`{"title": "existing"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		t.NewStep("Тестирование")
+		resp := apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body(`{"mode": "best_effort", "operations": [
+				{"action": "create", "banner": {"feature_id": 56, "tag_ids": [1], "content": {}, "is_active": true}},
+				{"action": "create", "banner": {"feature_id": 55, "tag_ids": [3], "content": {}, "is_active": true}},
+				{"action": "delete", "banner_id": 1000000}
+			]}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+		var result response.BatchResult
+
+		resp.JSON(&result)
+		t.Require().True(result.Applied)
+		t.Require().Equal(http.StatusCreated, result.Results[0].Status)
+		t.Require().Equal(http.StatusConflict, result.Results[1].Status)
+		t.Require().Equal([]types.ID{3}, result.Results[1].ConflictTagIDs)
+		t.Require().Equal(http.StatusNotFound, result.Results[2].Status)
+
+		as.requestUserBanner(t, "56", "1", http.StatusOK)
+	})
+
+	t.Run("Операция над фичей, недоступной админу", func(t provider.T) {
+		t.NewStep("Тестирование")
+		resp := apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body(`{"operations": [
+				{"action": "create", "banner": {"feature_id": 57, "tag_ids": [1], "content": {}, "is_active": true}},
+				{"action": "create", "banner": {"feature_id": 58, "tag_ids": [1], "content": {}, "is_active": true}}
+			]}`).
+			Header(middleware.TokenHeaderField, as.scopedAdminToken(t, &access.Scope{FeatureIDs: []types.ID{57}})).
+			Expect(t).
+			Status(http.StatusUnprocessableEntity).
+			End()
+
+		t.NewStep("Проверка результатов")
+		var result response.BatchResult
+
+		resp.JSON(&result)
+		t.Require().Equal(http.StatusFailedDependency, result.Results[0].Status)
+		t.Require().Equal(http.StatusForbidden, result.Results[1].Status)
+	})
+
+	t.Run("Попытка изменения без идентификатора баннера", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body(`{"operations": [{"action": "update", "banner": {"is_active": false}}]}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+}
@@ -0,0 +1,156 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"net/http"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+func (as *ApiSuite) TestInspectCache(t provider.T) {
+	t.Title("Тестирование апи метода InspectCache: GET /cache/entry")
+	const path = "/api/v1/cache/entry"
+
+	t.Run("Получение записи кэша баннера", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		_, err := as.bannerRepository.CreateBanner(context.Background(), 89, []types.ID{1, 2},
+			`{"title": "cached"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		as.requestUserBanner(t, "89", "1", http.StatusOK)
+
+		t.NewStep("Тестирование")
+		resp := apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.FeatureIDParam, "89").
+			Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+		var entry response.CacheEntry
+
+		resp.JSON(&entry)
+		t.Require().Equal("89-1", entry.Key)
+		t.Require().JSONEq(`{"title": "cached"}`, string(entry.Content))
+		t.Require().False(entry.Stale)
+		t.Require().Greater(entry.TTL, int64(0))
+
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.FeatureIDParam, "89").
+			Query(bh.TagIDParam, "2").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusNotFound).
+			End()
+	})
+
+	t.Run("Попытка получения записи без тэга", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.FeatureIDParam, "89").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+
+	t.Run("Попытка получения записи админом без доступа к фиче", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.FeatureIDParam, "89").
+			Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, as.scopedAdminToken(t, &access.Scope{FeatureIDs: []types.ID{78}})).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+}
+
+func (as *ApiSuite) TestPurgeCache(t provider.T) {
+	t.Title("Тестирование апи метода PurgeCache: DELETE /cache")
+	const path = "/api/v1/cache"
+
+	t.Run("Сброс кэша по фиче и всего кэша", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		_, err := as.bannerRepository.CreateBanner(context.Background(), 89, []types.ID{1, 2},
+			`{"title": "purged"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		_, err = as.bannerRepository.CreateBanner(context.Background(), 78, []types.ID{1},
+			`{"title": "kept"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		for _, pair := range [][2]string{{"89", "1"}, {"89", "2"}, {"78", "1"}} {
+			as.requestUserBanner(t, pair[0], pair[1], http.StatusOK)
+		}
+
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Delete(path).
+			Query(bh.FeatureIDParam, "89").
+			Header(middleware.TokenHeaderField, as.scopedAdminToken(t, &access.Scope{FeatureIDs: []types.ID{89}})).
+			Expect(t).
+			Body(`{"purged": 2}`).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+		t.Require().Zero(as.rdsClient.Exists(context.Background(), "89-1", "89-2").Val())
+		t.Require().EqualValues(1, as.rdsClient.Exists(context.Background(), "78-1").Val())
+
+		apitest.New().
+			Handler(as.router).
+			Delete(path).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Body(`{"purged": 1}`).
+			Status(http.StatusOK).
+			End()
+
+		t.Require().Zero(as.rdsClient.Exists(context.Background(), "78-1").Val())
+	})
+
+	t.Run("Попытка сброса всего кэша админом с ограниченным доступом", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Delete(path).
+			Header(middleware.TokenHeaderField, as.scopedAdminToken(t, &access.Scope{FeatureIDs: []types.ID{89}})).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+
+	t.Run("Попытка сброса кэша с некорректной фичей", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Delete(path).
+			Query(bh.FeatureIDParam, "feature").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+}
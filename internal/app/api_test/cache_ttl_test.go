@@ -0,0 +1,145 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+// freshFor возвращает, сколько ещё баннер по ключу key будет считаться в кэше свежим
+func (as *ApiSuite) freshFor(t provider.T, key string) time.Duration {
+	raw, err := as.rdsClient.Get(context.Background(), key).Result()
+	t.Require().NoError(err)
+
+	var entry struct {
+		FreshUntil int64 `json:"fresh_until"`
+	}
+
+	t.Require().NoError(json.Unmarshal([]byte(raw), &entry))
+
+	return time.Until(time.UnixMilli(entry.FreshUntil))
+}
+
+func (as *ApiSuite) TestFeatureCacheTTL(t provider.T) {
+	t.Title("Тестирование апи методов времени жизни баннеров фичи в кэше: /feature/{feature_id}/cache_ttl")
+	const path = "/api/v1/feature/86/cache_ttl"
+
+	t.Run("Время жизни баннера важнее времени жизни фичи", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 86, []types.ID{1, 2},
+			`{"title": "banner"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		as.requestUserBanner(t, "86", "1", http.StatusOK)
+		t.Require().Greater(as.freshFor(t, "86-1"), time.Minute)
+
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Put(path).
+			Body(`{"cache_ttl": 30000}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		resp := apitest.New().
+			Handler(as.router).
+			Get(path).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var ttl response.FeatureCacheTTL
+
+		resp.JSON(&ttl)
+		t.Require().EqualValues(86, ttl.FeatureID)
+		t.Require().EqualValues(30000, ttl.CacheTTL)
+
+		// Изменение времени жизни сразу сбрасывает кэш баннеров фичи
+		t.Require().Zero(as.rdsClient.Exists(context.Background(), "86-1").Val())
+
+		as.requestUserBanner(t, "86", "1", http.StatusOK)
+		t.Require().LessOrEqual(as.freshFor(t, "86-1"), 30*time.Second)
+
+		apitest.New().
+			Handler(as.router).
+			Patchf("/api/v1/banner/%d", bannerID).
+			Body(`{"cache_ttl": 1000}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		as.requestUserBanner(t, "86", "2", http.StatusOK)
+		t.Require().LessOrEqual(as.freshFor(t, "86-2"), time.Second)
+
+		t.NewStep("Проверка результатов")
+		apitest.New().
+			Handler(as.router).
+			Delete(path).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusNoContent).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusNotFound).
+			End()
+
+		// Собственное время жизни баннера сохраняется после сброса времени жизни фичи
+		as.requestUserBanner(t, "86", "1", http.StatusOK)
+		t.Require().LessOrEqual(as.freshFor(t, "86-1"), time.Second)
+	})
+
+	t.Run("Попытка сбросить не заданное время жизни", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Delete("/api/v1/feature/87/cache_ttl").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusNotFound).
+			End()
+	})
+
+	t.Run("Попытка задать некорректное время жизни", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Put(path).
+			Body(`{"cache_ttl": 0}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+
+	t.Run("Попытка задать время жизни админом без доступа к фиче", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Put(path).
+			Body(`{"cache_ttl": 30000}`).
+			Header(middleware.TokenHeaderField, as.scopedAdminToken(t, &access.Scope{FeatureIDs: []types.ID{87}})).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+}
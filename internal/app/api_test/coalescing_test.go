@@ -0,0 +1,169 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/banner"
+	"bannersrv/internal/banner/entity"
+	bu "bannersrv/internal/banner/usecase"
+	cm "bannersrv/internal/caches/manager"
+	cr "bannersrv/internal/caches/repository/redis"
+	"bannersrv/internal/pkg/lock"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/logger"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/pkg/errors"
+)
+
+var errorCountingFailure = errors.New("counting repository failure")
+
+// countingRepository считает запросы баннера пользователем и замедляет их, чтобы запросы пересекались
+type countingRepository struct {
+	banner.Repository
+	calls atomic.Int64
+	fail  bool
+}
+
+func (rep *countingRepository) GetBanner(ctx context.Context, featureID types.ID, tagIDs []types.ID,
+	version types.NullableObject[uint32],
+) (*entity.UserBanner, error) {
+	rep.calls.Add(1)
+	time.Sleep(100 * time.Millisecond)
+
+	if rep.fail {
+		return nil, errorCountingFailure
+	}
+
+	return rep.Repository.GetBanner(ctx, featureID, tagIDs, version)
+}
+
+// busyLocker считает попытки захвата блокировки, которая всегда занята другой репликой
+type busyLocker struct {
+	tries atomic.Int64
+}
+
+func (bl *busyLocker) TryLock(context.Context, string, time.Duration) (string, bool, error) {
+	bl.tries.Add(1)
+
+	return "", false, nil
+}
+
+func (*busyLocker) Unlock(context.Context, string, string) error {
+	return nil
+}
+
+// getUserBannerConcurrently запрашивает баннер одновременно count раз и возвращает результаты запросов
+func getUserBannerConcurrently(usecase banner.Usecase, featureID, tagID types.ID, count int) ([]string, []error) {
+	contents := make([]string, count)
+	errs := make([]error, count)
+
+	var wg sync.WaitGroup
+
+	for i := range count {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			bnr, err := usecase.GetUserBanner(context.Background(), featureID, []types.ID{tagID}, nil, nil)
+			if err == nil {
+				contents[i] = string(bnr.Content)
+			}
+
+			errs[i] = err
+		}()
+	}
+
+	wg.Wait()
+
+	return contents, errs
+}
+
+func (as *ApiSuite) TestCoalescing(t provider.T) {
+	t.Title("Тестирование объединения одинаковых запросов баннера пользователем")
+	t.NewStep("Инициализация тестовых данных")
+
+	_, err := as.bannerRepository.CreateBanner(context.Background(), 99, []types.ID{1},
+		`{"title": "coalesced"}`, true, nil, 0)
+	t.Require().NoError(err)
+
+	cacheManager := cm.NewCacheManager(cr.NewCashRedis(as.rdsClient), ttlConfig, nil, &logger.EmptyLogger{})
+
+	t.Run("Одновременные запросы выполняют один запрос к базе", func(t provider.T) {
+		rep := &countingRepository{Repository: as.bannerRepository}
+		usecase := bu.NewBannerUsecase(rep, cacheManager, nil, lock.NewRedisLocker(as.rdsClient), &coalescingConfig)
+
+		contents, errs := getUserBannerConcurrently(usecase, 99, 1, 20)
+
+		for i := range contents {
+			t.Require().NoError(errs[i])
+			t.Require().Equal(`{"title": "coalesced"}`, contents[i])
+		}
+
+		t.Require().EqualValues(1, rep.calls.Load())
+	})
+
+	t.Run("Некэшируемый баннер с экспериментом запрашивается без блокировки", func(t provider.T) {
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 136, []types.ID{1},
+			`{"title": "experiment"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		_, _, err = as.bannerRepository.CreateExperiment(context.Background(), bannerID, []entity.Variant{
+			{Weight: 50, Content: `{"title": "a"}`},
+			{Weight: 50, Content: `{"title": "b"}`},
+		})
+		t.Require().NoError(err)
+
+		rep := &countingRepository{Repository: as.bannerRepository}
+		locker := &busyLocker{}
+		usecase := bu.NewBannerUsecase(rep, cacheManager, nil, locker, &coalescingConfig)
+
+		_, err = usecase.GetUserBanner(context.Background(), 136, []types.ID{1}, nil, nil)
+		t.Require().NoError(err)
+
+		tries := locker.tries.Load()
+		t.Require().Greater(tries, int64(0))
+
+		start := time.Now()
+		_, err = usecase.GetUserBanner(context.Background(), 136, []types.ID{1}, nil, nil)
+		t.Require().NoError(err)
+
+		t.Require().Less(time.Since(start), time.Duration(coalescingConfig.LockTTL)*time.Millisecond)
+		t.Require().Equal(tries, locker.tries.Load())
+		t.Require().EqualValues(2, rep.calls.Load())
+	})
+
+	t.Run("Устаревший баннер обновляет только реплика, захватившая блокировку", func(t provider.T) {
+		rep := &countingRepository{Repository: as.bannerRepository}
+		locker := &busyLocker{}
+		usecase := bu.NewBannerUsecase(rep, cacheManager, nil, locker, &coalescingConfig)
+
+		t.Require().NoError(usecase.RefreshUserBanner(context.Background(), 99, []types.ID{1}, nil))
+		t.Require().EqualValues(1, locker.tries.Load())
+		t.Require().Zero(rep.calls.Load())
+
+		rep = &countingRepository{Repository: as.bannerRepository}
+		usecase = bu.NewBannerUsecase(rep, cacheManager, nil, lock.NewRedisLocker(as.rdsClient), &coalescingConfig)
+
+		t.Require().NoError(usecase.RefreshUserBanner(context.Background(), 99, []types.ID{1}, nil))
+		t.Require().EqualValues(1, rep.calls.Load())
+	})
+
+	t.Run("Ошибка общего запроса возвращается всем ожидающим без повторов", func(t provider.T) {
+		rep := &countingRepository{Repository: as.bannerRepository, fail: true}
+		usecase := bu.NewBannerUsecase(rep, cacheManager, nil, nil, &coalescingConfig)
+
+		_, errs := getUserBannerConcurrently(usecase, 99, 2, 20)
+
+		for _, err := range errs {
+			t.Require().ErrorIs(err, errorCountingFailure)
+		}
+
+		t.Require().EqualValues(1, rep.calls.Load())
+	})
+}
@@ -5,6 +5,7 @@ package api_test
 import (
 	"bannersrv/internal/app/delivery/http/middleware"
 	"bannersrv/internal/pkg/types"
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -25,15 +26,15 @@ func (as *ApiSuite) TestDeleteBanner(t provider.T) {
 			TagIDs:    []types.ID{2, 4, 3},
 			IsActive:  true,
 		}
-		bannerID, err := as.bannerRepository.CreateBanner(bnr.FeatureID, bnr.TagIDs,
-			types.Content(bnr.Content), bnr.IsActive)
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), bnr.FeatureID, bnr.TagIDs,
+			types.Content(bnr.Content), bnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
 		apitest.New().
 			Handler(as.router).
 			Deletef("%s/%d", path, bannerID).
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusNoContent).
 			End()
@@ -48,7 +49,7 @@ func (as *ApiSuite) TestDeleteBanner(t provider.T) {
 		apitest.New().
 			Handler(as.router).
 			Deletef("%s/100", path).
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusNotFound).
 			End()
@@ -59,7 +60,7 @@ func (as *ApiSuite) TestDeleteBanner(t provider.T) {
 		apitest.New().
 			Handler(as.router).
 			Deletef("%s/more", path).
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
@@ -80,7 +81,7 @@ func (as *ApiSuite) TestDeleteBanner(t provider.T) {
 		apitest.New().
 			Handler(as.router).
 			Deletef("%s/%d", path, 2).
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Status(http.StatusForbidden).
 			End()
@@ -6,6 +6,7 @@ import (
 	"bannersrv/internal/app/delivery/http/middleware"
 	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
 	"bannersrv/internal/pkg/types"
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -29,8 +30,8 @@ func (as *ApiSuite) TestDeleteFilterBanner(t provider.T) {
 			TagIDs:    []types.ID{2, 4, 3},
 			IsActive:  true,
 		}
-		bannerID, err := as.bannerRepository.CreateBanner(bnr.FeatureID, bnr.TagIDs,
-			types.Content(bnr.Content), bnr.IsActive)
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), bnr.FeatureID, bnr.TagIDs,
+			types.Content(bnr.Content), bnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
@@ -38,7 +39,7 @@ func (as *ApiSuite) TestDeleteFilterBanner(t provider.T) {
 			Handler(as.router).
 			Delete(path).
 			Query(bh.FeatureIDParam, "1").Query(bh.TagIDParam, "2").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusNoContent).
 			End()
@@ -65,12 +66,12 @@ func (as *ApiSuite) TestDeleteFilterBanner(t provider.T) {
 			IsActive:  true,
 		}
 
-		firstBannerID, err := as.bannerRepository.CreateBanner(firstBnr.FeatureID, firstBnr.TagIDs,
-			types.Content(firstBnr.Content), firstBnr.IsActive)
+		firstBannerID, err := as.bannerRepository.CreateBanner(context.Background(), firstBnr.FeatureID, firstBnr.TagIDs,
+			types.Content(firstBnr.Content), firstBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
-		secondBannerID, err := as.bannerRepository.CreateBanner(secondBnr.FeatureID, secondBnr.TagIDs,
-			types.Content(secondBnr.Content), secondBnr.IsActive)
+		secondBannerID, err := as.bannerRepository.CreateBanner(context.Background(), secondBnr.FeatureID, secondBnr.TagIDs,
+			types.Content(secondBnr.Content), secondBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
@@ -78,7 +79,7 @@ func (as *ApiSuite) TestDeleteFilterBanner(t provider.T) {
 			Handler(as.router).
 			Delete(path).
 			Query(bh.FeatureIDParam, "25").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusNoContent).
 			End()
@@ -106,12 +107,12 @@ func (as *ApiSuite) TestDeleteFilterBanner(t provider.T) {
 			IsActive:  true,
 		}
 
-		firstBannerID, err := as.bannerRepository.CreateBanner(firstBnr.FeatureID, firstBnr.TagIDs,
-			types.Content(firstBnr.Content), firstBnr.IsActive)
+		firstBannerID, err := as.bannerRepository.CreateBanner(context.Background(), firstBnr.FeatureID, firstBnr.TagIDs,
+			types.Content(firstBnr.Content), firstBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
-		secondBannerID, err := as.bannerRepository.CreateBanner(secondBnr.FeatureID, secondBnr.TagIDs,
-			types.Content(secondBnr.Content), secondBnr.IsActive)
+		secondBannerID, err := as.bannerRepository.CreateBanner(context.Background(), secondBnr.FeatureID, secondBnr.TagIDs,
+			types.Content(secondBnr.Content), secondBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
@@ -119,7 +120,7 @@ func (as *ApiSuite) TestDeleteFilterBanner(t provider.T) {
 			Handler(as.router).
 			Delete(path).
 			Query(bh.TagIDParam, "45").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusNoContent).
 			End()
@@ -138,7 +139,7 @@ func (as *ApiSuite) TestDeleteFilterBanner(t provider.T) {
 			Handler(as.router).
 			Delete(path).
 			Query(bh.FeatureIDParam, "100").Query(bh.TagIDParam, "2").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusNotFound).
 			End()
@@ -149,7 +150,7 @@ func (as *ApiSuite) TestDeleteFilterBanner(t provider.T) {
 		apitest.New().
 			Handler(as.router).
 			Delete(path).
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
@@ -161,7 +162,7 @@ func (as *ApiSuite) TestDeleteFilterBanner(t provider.T) {
 			Handler(as.router).
 			Delete(path).
 			Query(bh.FeatureIDParam, "mir").Query(bh.TagIDParam, "2").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
@@ -171,7 +172,7 @@ func (as *ApiSuite) TestDeleteFilterBanner(t provider.T) {
 			Handler(as.router).
 			Delete(path).
 			Query(bh.FeatureIDParam, "2").Query(bh.TagIDParam, "mir").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
@@ -192,7 +193,7 @@ func (as *ApiSuite) TestDeleteFilterBanner(t provider.T) {
 		apitest.New().
 			Handler(as.router).
 			Delete(path).
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Status(http.StatusForbidden).
 			End()
@@ -0,0 +1,311 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/banner/entity"
+	cmid "bannersrv/internal/caches/delivery/middleware"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+// publishDraft одобряет другим админом черновик из ответа на обновление баннера и публикует его
+func (as *ApiSuite) publishDraft(t provider.T, updateResult apitest.Result) {
+	var draft response.DraftID
+	updateResult.JSON(&draft)
+
+	apitest.New().
+		Handler(as.router).
+		Postf("/api/v1/draft/%d/approve", draft.DraftID).
+		Header(middleware.TokenHeaderField, as.adminToken(t)).
+		Expect(t).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New().
+		Handler(as.router).
+		Postf("/api/v1/draft/%d/publish", draft.DraftID).
+		Header(middleware.TokenHeaderField, as.adminToken(t)).
+		Expect(t).
+		Status(http.StatusOK).
+		End()
+}
+
+func (as *ApiSuite) TestDrafts(t provider.T) {
+	t.Title("Тестирование апи методов черновиков: GET /banner/{id}/drafts, /draft/{id}/...")
+	const userPath = "/api/v1/user_banner"
+
+	t.Run("Успешная публикация черновика после одобрения", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 82, []types.ID{1},
+			`{"title": "published"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		author := as.adminToken(t)
+		reviewer := as.adminToken(t)
+
+		t.NewStep("Создание черновика")
+		resp := apitest.New().
+			Handler(as.router).
+			Patchf("/api/v1/banner/%d", bannerID).
+			Body(`{"content": {"title": "draft"}}`).
+			Header(middleware.TokenHeaderField, author).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var draft response.DraftID
+		resp.JSON(&draft)
+
+		t.NewStep("Черновик не виден пользователю")
+		apitest.New().
+			Handler(as.router).
+			Get(userPath).
+			Query(bh.FeatureIDParam, "82").Query(bh.TagIDParam, "1").Query(cmid.UseLastRevisionParam, "true").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Body(`{"title": "published"}`).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Предпросмотр черновика")
+		resp = apitest.New().
+			Handler(as.router).
+			Getf("/api/v1/draft/%d", draft.DraftID).
+			Header(middleware.TokenHeaderField, reviewer).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var preview response.Draft
+		resp.JSON(&preview)
+
+		t.Require().Equal("pending", preview.Status)
+		t.Require().JSONEq(`{"title": "draft"}`, string(preview.Content))
+
+		t.NewStep("Попытка публикации неодобренного черновика")
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/draft/%d/publish", draft.DraftID).
+			Header(middleware.TokenHeaderField, reviewer).
+			Expect(t).
+			Status(http.StatusConflict).
+			End()
+
+		t.NewStep("Попытка одобрения черновика его автором")
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/draft/%d/approve", draft.DraftID).
+			Header(middleware.TokenHeaderField, author).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+
+		t.NewStep("Одобрение и публикация черновика")
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/draft/%d/approve", draft.DraftID).
+			Header(middleware.TokenHeaderField, reviewer).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		resp = apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/draft/%d/publish", draft.DraftID).
+			Header(middleware.TokenHeaderField, author).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var current response.CurrentVersion
+		resp.JSON(&current)
+
+		t.Require().EqualValues(2, current.Version)
+
+		apitest.New().
+			Handler(as.router).
+			Get(userPath).
+			Query(bh.FeatureIDParam, "82").Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Body(`{"title": "draft"}`).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Попытка повторной публикации черновика")
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/draft/%d/publish", draft.DraftID).
+			Header(middleware.TokenHeaderField, author).
+			Expect(t).
+			Status(http.StatusConflict).
+			End()
+	})
+
+	t.Run("Успешное отклонение черновика", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 83, []types.ID{1},
+			`{"title": "published"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		draft := types.Content(`{"title": "draft"}`)
+		_, draftID, err := as.bannerRepository.UpdateBannerWithDraft(context.Background(), &entity.BannerUpdate{
+			ID:        bannerID,
+			Content:   types.NewNullObject[types.Content](),
+			TagIDs:    types.NewNullObject[[]types.ID](),
+			FeatureID: (*types.NullableID)(types.NewNullObject[types.ID]()),
+			IsActive:  types.NewNullObject[bool](),
+		}, &draft, "author")
+		t.Require().NoError(err)
+
+		t.NewStep("Отклонение черновика")
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/draft/%d/reject", *draftID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/draft/%d/approve", *draftID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusConflict).
+			End()
+
+		t.NewStep("Получение списка черновиков")
+		resp := apitest.New().
+			Handler(as.router).
+			Getf("/api/v1/banner/%d/drafts", bannerID).
+			Query(bh.DraftStatusParam, "rejected").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var drafts []response.Draft
+		resp.JSON(&drafts)
+
+		t.Require().Len(drafts, 1)
+		t.Require().Equal(*draftID, drafts[0].ID)
+		t.Require().NotNil(drafts[0].Reviewer)
+	})
+
+	t.Run("Запись действий с черновиками в журнал изменений", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 132, []types.ID{1},
+			`{"title": "published"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		t.NewStep("Создание и одобрение черновика без изменения других полей")
+		resp := apitest.New().
+			Handler(as.router).
+			Patchf("/api/v1/banner/%d", bannerID).
+			Body(`{"content": {"title": "draft"}}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var draft response.DraftID
+		resp.JSON(&draft)
+
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/draft/%d/approve", draft.DraftID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Создание и отклонение черновика вместе с изменением баннера")
+		resp = apitest.New().
+			Handler(as.router).
+			Patchf("/api/v1/banner/%d", bannerID).
+			Body(`{"content": {"title": "rejected"}, "is_active": false}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		resp.JSON(&draft)
+
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/draft/%d/reject", draft.DraftID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка журнала изменений")
+		resp = apitest.New().
+			Handler(as.router).
+			Get("/api/v1/audit").
+			Query(bh.AuditBannerIDParam, strconv.FormatUint(uint64(bannerID), 10)).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var entries []response.AuditEntry
+		resp.JSON(&entries)
+
+		actions := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			actions = append(actions, entry.Action)
+		}
+
+		t.Require().Equal([]string{"reject", "draft", "update", "approve", "draft", "create"}, actions)
+	})
+
+	t.Run("Попытка работы с несуществующими черновиками", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/draft/100000").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusNotFound).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/banner/100000/drafts").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusNotFound).
+			End()
+	})
+
+	t.Run("Попытка получения черновиков с некорректными параметрами", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/banner/1/drafts").
+			Query(bh.DraftStatusParam, "mir").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+
+	t.Run("Попытка одобрения черновика пользователем с неверными правами", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/draft/1/approve").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+}
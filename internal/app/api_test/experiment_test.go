@@ -0,0 +1,212 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	cmid "bannersrv/internal/caches/delivery/middleware"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"net/http"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+func (as *ApiSuite) TestExperiment(t provider.T) {
+	t.Title("Тестирование апи методов экспериментов: POST /banner/{id}/experiment, /experiment/{id}/...")
+	const userPath = "/api/v1/user_banner"
+	const variantsBody = `{"variants": [
+		{"weight": 1, "content": {"title": "first"}},
+		{"weight": 1, "content": {"title": "second"}}
+	]}`
+
+	t.Run("Успешное проведение эксперимента", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 70, []types.ID{1},
+			`{"title": "control"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		t.NewStep("Создание эксперимента")
+		resp := apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/banner/%d/experiment", bannerID).
+			Body(variantsBody).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusCreated).
+			End()
+
+		var id response.ExperimentID
+		resp.JSON(&id)
+
+		t.NewStep("Повторное создание эксперимента")
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/banner/%d/experiment", bannerID).
+			Body(variantsBody).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusConflict).
+			End()
+
+		t.NewStep("Получение варианта пользователем")
+		first := apitest.New().
+			Handler(as.router).
+			Get(userPath).
+			Query(bh.FeatureIDParam, "70").Query(bh.TagIDParam, "1").
+			Query(bh.UserIDParam, "user-1").Query(cmid.UseLastRevisionParam, "true").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		variantID := first.Response.Header.Get(bh.VariantHeader)
+		t.Require().NotEmpty(variantID)
+
+		apitest.New().
+			Handler(as.router).
+			Get(userPath).
+			Query(bh.FeatureIDParam, "70").Query(bh.TagIDParam, "1").
+			Query(bh.UserIDParam, "user-1").Query(cmid.UseLastRevisionParam, "true").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Header(bh.VariantHeader, variantID).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Приостановка эксперимента")
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/experiment/%d/pause", id.ExperimentID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Get(userPath).
+			Query(bh.FeatureIDParam, "70").Query(bh.TagIDParam, "1").
+			Query(bh.UserIDParam, "user-1").Query(cmid.UseLastRevisionParam, "true").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Body(`{"title": "control"}`).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Завершение эксперимента")
+		resp = apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/experiment/%d/conclude", id.ExperimentID).
+			Body(`{"winner_variant_id": `+variantID+`}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var concluded response.ConcludedExperiment
+		resp.JSON(&concluded)
+		t.Require().EqualValues(2, concluded.Version)
+
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/experiment/%d/conclude", id.ExperimentID).
+			Body(`{"winner_variant_id": `+variantID+`}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusConflict).
+			End()
+	})
+
+	t.Run("Выбор варианта для баннера из кэша после создания и возобновления эксперимента", func(t provider.T) {
+		requestCached := func(t provider.T) *apitest.Response {
+			return apitest.New().
+				Handler(as.router).
+				Get(userPath).
+				Query(bh.FeatureIDParam, "130").Query(bh.TagIDParam, "1").
+				Query(bh.UserIDParam, "user-1").
+				Header(middleware.TokenHeaderField, as.userToken(t)).
+				Expect(t).
+				Status(http.StatusOK)
+		}
+
+		t.NewStep("Инициализация тестовых данных")
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 130, []types.ID{1},
+			`{"title": "control"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		requestCached(t).Body(`{"title": "control"}`).HeaderNotPresent(bh.VariantHeader).End()
+
+		t.NewStep("Создание эксперимента над закэшированным баннером")
+		resp := apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/banner/%d/experiment", bannerID).
+			Body(variantsBody).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusCreated).
+			End()
+
+		var id response.ExperimentID
+		resp.JSON(&id)
+
+		requestCached(t).HeaderPresent(bh.VariantHeader).End()
+
+		t.NewStep("Кэширование баннера приостановленного эксперимента")
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/experiment/%d/pause", id.ExperimentID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		requestCached(t).Body(`{"title": "control"}`).HeaderNotPresent(bh.VariantHeader).End()
+
+		t.NewStep("Возобновление эксперимента")
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/experiment/%d/resume", id.ExperimentID).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		requestCached(t).HeaderPresent(bh.VariantHeader).End()
+	})
+
+	t.Run("Попытка создать эксперимент для несуществующего баннера", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/banner/100/experiment").
+			Body(variantsBody).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusNotFound).
+			End()
+	})
+
+	t.Run("Попытка создать эксперимент с одним вариантом", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/banner/100/experiment").
+			Body(`{"variants": [{"weight": 1, "content": {"title": "first"}}]}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+
+	t.Run("Попытка управлять экспериментом пользователя с неверными правами", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/experiment/1/pause").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+}
@@ -0,0 +1,131 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/caches"
+	"bannersrv/internal/caches/repository"
+	cf "bannersrv/internal/caches/repository/failover"
+	cmem "bannersrv/internal/caches/repository/memory"
+	cr "bannersrv/internal/caches/repository/redis"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/logger"
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+var errorRedisDown = errors.New("redis is down")
+
+// switchableRedis кэш в Redis, недоступность которого переключается тестом
+type switchableRedis struct {
+	*cr.CashRedis
+	down *atomic.Bool
+}
+
+func (sr *switchableRedis) HaveCache(ctx context.Context, key string) (types.Content, error) {
+	if sr.down.Load() {
+		return "", errorRedisDown
+	}
+
+	return sr.CashRedis.HaveCache(ctx, key)
+}
+
+func (sr *switchableRedis) SetCache(ctx context.Context, key string, content types.Content,
+	ttl time.Duration, index string,
+) error {
+	if sr.down.Load() {
+		return errorRedisDown
+	}
+
+	return sr.CashRedis.SetCache(ctx, key, content, ttl, index)
+}
+
+func (sr *switchableRedis) DeleteIndexedCache(ctx context.Context, indexes ...string) error {
+	if sr.down.Load() {
+		return errorRedisDown
+	}
+
+	return sr.CashRedis.DeleteIndexedCache(ctx, indexes...)
+}
+
+// switchablePinger проверка доступности Redis, недоступность которого переключается тестом
+type switchablePinger struct {
+	client *redis.Client
+	down   *atomic.Bool
+}
+
+func (sp *switchablePinger) Ping(ctx context.Context) *redis.StatusCmd {
+	if sp.down.Load() {
+		return redis.NewStatusResult("", errorRedisDown)
+	}
+
+	return sp.client.Ping(ctx)
+}
+
+func (as *ApiSuite) TestFailoverCache(t provider.T) {
+	t.Title("Тестирование переключения кэша между Redis и памятью процесса")
+	t.NewStep("Инициализация кэша")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var down atomic.Bool
+
+	cache := cf.NewFailoverCache(
+		&switchableRedis{CashRedis: cr.NewCashRedis(as.rdsClient), down: &down},
+		cmem.NewMemoryCache(caches.MemoryConfig{MaxEntries: 100, MaxBytes: 1 << 20}, nil),
+		&switchablePinger{client: as.rdsClient, down: &down},
+		caches.FailoverConfig{CheckPeriod: 20, CheckTimeout: 100},
+		nil, &logger.EmptyLogger{},
+	)
+
+	go cache.Run(ctx)
+
+	t.Run("Кэш работает в памяти, пока Redis недоступен, и возвращается в Redis после восстановления",
+		func(t provider.T) {
+			t.Require().NoError(cache.SetCache(ctx, "failover-1", `{"title": "redis"}`, time.Minute,
+				"index-failover-1"))
+
+			down.Store(true)
+
+			_, err := cache.HaveCache(ctx, "failover-1")
+			t.Require().ErrorIs(err, repository.ErrorCacheMiss)
+
+			t.Require().NoError(cache.SetCache(ctx, "failover-1", `{"title": "memory"}`, time.Minute,
+				"index-failover-1"))
+
+			content, err := cache.HaveCache(ctx, "failover-1")
+			t.Require().NoError(err)
+			t.Require().EqualValues(`{"title": "memory"}`, content)
+
+			// Сброс кэша, пока Redis недоступен, применяется к Redis после его восстановления
+			t.Require().NoError(cache.DeleteIndexedCache(ctx, "index-failover-1"))
+
+			down.Store(false)
+
+			// Сначала в Redis сбрасываются индексы, и только затем кэш возвращается в Redis
+			deadline := time.Now().Add(2 * time.Second)
+
+			for {
+				t.Require().NoError(cache.SetCache(ctx, "failover-2", `{"title": "redis"}`, time.Minute,
+					"index-failover-2"))
+
+				if as.rdsClient.Exists(ctx, "failover-2").Val() != 0 {
+					break
+				}
+
+				if time.Now().After(deadline) {
+					t.Fatalf("cache didn't switch back to redis")
+				}
+
+				time.Sleep(20 * time.Millisecond)
+			}
+
+			t.Require().Zero(as.rdsClient.Exists(ctx, "failover-1").Val())
+		})
+}
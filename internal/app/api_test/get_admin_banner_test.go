@@ -6,9 +6,12 @@ import (
 	"bannersrv/internal/app/delivery/http/middleware"
 	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
 	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/banner/entity"
 	"bannersrv/internal/pkg/types"
+	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 	"github.com/steinfletcher/apitest"
@@ -26,8 +29,8 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			TagIDs:    []types.ID{2, 4, 3},
 			IsActive:  true,
 		}
-		bannerID, err := as.bannerRepository.CreateBanner(bnr.FeatureID, bnr.TagIDs,
-			types.Content(bnr.Content), bnr.IsActive)
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), bnr.FeatureID, bnr.TagIDs,
+			types.Content(bnr.Content), bnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
@@ -35,7 +38,7 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "1").Query(bh.TagIDParam, "2").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusOK).
 			End()
@@ -66,12 +69,12 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			IsActive:  true,
 		}
 
-		firstBannerID, err := as.bannerRepository.CreateBanner(firstBnr.FeatureID, firstBnr.TagIDs,
-			types.Content(firstBnr.Content), firstBnr.IsActive)
+		firstBannerID, err := as.bannerRepository.CreateBanner(context.Background(), firstBnr.FeatureID, firstBnr.TagIDs,
+			types.Content(firstBnr.Content), firstBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
-		secondBannerID, err := as.bannerRepository.CreateBanner(secondBnr.FeatureID, secondBnr.TagIDs,
-			types.Content(secondBnr.Content), secondBnr.IsActive)
+		secondBannerID, err := as.bannerRepository.CreateBanner(context.Background(), secondBnr.FeatureID, secondBnr.TagIDs,
+			types.Content(secondBnr.Content), secondBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
@@ -79,7 +82,7 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "25").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusOK).
 			End()
@@ -114,12 +117,12 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			IsActive:  true,
 		}
 
-		firstBannerID, err := as.bannerRepository.CreateBanner(firstBnr.FeatureID, firstBnr.TagIDs,
-			types.Content(firstBnr.Content), firstBnr.IsActive)
+		firstBannerID, err := as.bannerRepository.CreateBanner(context.Background(), firstBnr.FeatureID, firstBnr.TagIDs,
+			types.Content(firstBnr.Content), firstBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
-		secondBannerID, err := as.bannerRepository.CreateBanner(secondBnr.FeatureID, secondBnr.TagIDs,
-			types.Content(secondBnr.Content), secondBnr.IsActive)
+		secondBannerID, err := as.bannerRepository.CreateBanner(context.Background(), secondBnr.FeatureID, secondBnr.TagIDs,
+			types.Content(secondBnr.Content), secondBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
@@ -127,7 +130,7 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.TagIDParam, "45").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusOK).
 			End()
@@ -162,12 +165,12 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			IsActive:  true,
 		}
 
-		firstBannerID, err := as.bannerRepository.CreateBanner(firstBnr.FeatureID, firstBnr.TagIDs,
-			types.Content(firstBnr.Content), firstBnr.IsActive)
+		firstBannerID, err := as.bannerRepository.CreateBanner(context.Background(), firstBnr.FeatureID, firstBnr.TagIDs,
+			types.Content(firstBnr.Content), firstBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
-		_, err = as.bannerRepository.CreateBanner(secondBnr.FeatureID, secondBnr.TagIDs,
-			types.Content(secondBnr.Content), secondBnr.IsActive)
+		_, err = as.bannerRepository.CreateBanner(context.Background(), secondBnr.FeatureID, secondBnr.TagIDs,
+			types.Content(secondBnr.Content), secondBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
@@ -175,7 +178,7 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "31").Query(bh.LimitParam, "1").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusOK).
 			End()
@@ -206,12 +209,12 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			IsActive:  true,
 		}
 
-		_, err := as.bannerRepository.CreateBanner(firstBnr.FeatureID, firstBnr.TagIDs,
-			types.Content(firstBnr.Content), firstBnr.IsActive)
+		_, err := as.bannerRepository.CreateBanner(context.Background(), firstBnr.FeatureID, firstBnr.TagIDs,
+			types.Content(firstBnr.Content), firstBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
-		secondBannerID, err := as.bannerRepository.CreateBanner(secondBnr.FeatureID, secondBnr.TagIDs,
-			types.Content(secondBnr.Content), secondBnr.IsActive)
+		secondBannerID, err := as.bannerRepository.CreateBanner(context.Background(), secondBnr.FeatureID, secondBnr.TagIDs,
+			types.Content(secondBnr.Content), secondBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
@@ -219,7 +222,7 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "32").Query(bh.OffsetParam, "1").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusOK).
 			End()
@@ -250,12 +253,12 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			IsActive:  true,
 		}
 
-		_, err := as.bannerRepository.CreateBanner(firstBnr.FeatureID, firstBnr.TagIDs,
-			types.Content(firstBnr.Content), firstBnr.IsActive)
+		_, err := as.bannerRepository.CreateBanner(context.Background(), firstBnr.FeatureID, firstBnr.TagIDs,
+			types.Content(firstBnr.Content), firstBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
-		_, err = as.bannerRepository.CreateBanner(secondBnr.FeatureID, secondBnr.TagIDs,
-			types.Content(secondBnr.Content), secondBnr.IsActive)
+		_, err = as.bannerRepository.CreateBanner(context.Background(), secondBnr.FeatureID, secondBnr.TagIDs,
+			types.Content(secondBnr.Content), secondBnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
@@ -263,7 +266,7 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "100").Query(bh.TagIDParam, "2").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusOK).
 			End()
@@ -276,13 +279,178 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 		t.Require().Len(bnrs, 0)
 	})
 
+	t.Run("Успешное получение списка баннеров по состоянию окна показа", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+		startAt := time.Now().Add(time.Hour)
+		endAt := time.Now().Add(-time.Hour)
+
+		scheduledID, err := as.bannerRepository.CreateBanner(context.Background(), 60, []types.ID{1},
+			`{"title":"banner"}`, true, &entity.Schedule{StartAt: &startAt}, 0)
+		t.Require().NoError(err)
+
+		expiredID, err := as.bannerRepository.CreateBanner(context.Background(), 60, []types.ID{2},
+			`{"title":"banner"}`, true, &entity.Schedule{EndAt: &endAt}, 0)
+		t.Require().NoError(err)
+
+		liveID, err := as.bannerRepository.CreateBanner(context.Background(), 60, []types.ID{3},
+			`{"title":"banner"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		t.NewStep("Тестирование")
+		for state, expectedID := range map[entity.ScheduleState]types.ID{
+			entity.ScheduleScheduled: scheduledID,
+			entity.ScheduleExpired:   expiredID,
+			entity.ScheduleLive:      liveID,
+		} {
+			resp := apitest.New().
+				Handler(as.router).
+				Get(path).
+				Query(bh.FeatureIDParam, "60").Query(bh.StateParam, string(state)).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
+				Expect(t).
+				Status(http.StatusOK).
+				End()
+
+			var bnrs []response.Banner
+
+			resp.JSON(&bnrs)
+
+			t.Require().Len(bnrs, 1)
+			t.Require().EqualValues(expectedID, bnrs[0].ID)
+		}
+	})
+
+	t.Run("Успешное получение списка баннеров страницами по курсору", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+		bannerIDs := make([]types.ID, 0, 3)
+
+		for _, tagID := range []types.ID{1, 2, 3} {
+			bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 110, []types.ID{tagID},
+				`{"title": "page"}`, true, nil, 0)
+			t.Require().NoError(err)
+
+			bannerIDs = append(bannerIDs, bannerID)
+		}
+
+		t.NewStep("Тестирование первой страницы")
+		resp := apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.FeatureIDParam, "110").Query(bh.SortParam, "created_at").Query(bh.OrderParam, "desc").
+			Query(bh.LimitParam, "2").Query(bh.WithTotalParam, "true").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			HeaderPresent(bh.NextCursorHeader).
+			Header(bh.TotalCountHeader, "3").
+			End()
+
+		var bnrs []response.Banner
+
+		resp.JSON(&bnrs)
+		t.Require().Len(bnrs, 2)
+		t.Require().Equal(bannerIDs[2], bnrs[0].ID)
+		t.Require().Equal(bannerIDs[1], bnrs[1].ID)
+
+		t.NewStep("Тестирование следующей страницы по курсору")
+		resp = apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.FeatureIDParam, "110").Query(bh.LimitParam, "2").
+			Query(bh.CursorParam, resp.Response.Header.Get(bh.NextCursorHeader)).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			HeaderNotPresent(bh.NextCursorHeader).
+			HeaderNotPresent(bh.TotalCountHeader).
+			End()
+
+		resp.JSON(&bnrs)
+		t.Require().Len(bnrs, 1)
+		t.Require().Equal(bannerIDs[0], bnrs[0].ID)
+	})
+
+	t.Run("Успешное получение списка баннеров по нескольким фичам и активности", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+		_, err := as.bannerRepository.CreateBanner(context.Background(), 111, []types.ID{1},
+			`{"title": "active"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		inactiveID, err := as.bannerRepository.CreateBanner(context.Background(), 112, []types.ID{1},
+			`{"title": "inactive"}`, false, nil, 0)
+		t.Require().NoError(err)
+
+		t.NewStep("Тестирование")
+		resp := apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.FeatureIDParam, "111,112").Query(bh.IsActiveParam, "false").
+			Query(bh.CreatedFromParam, time.Now().Add(-time.Hour).Format(time.RFC3339)).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+		var bnrs []response.Banner
+
+		resp.JSON(&bnrs)
+		t.Require().Len(bnrs, 1)
+		t.Require().Equal(inactiveID, bnrs[0].ID)
+	})
+
+	t.Run("Попытка получить список баннеров с некорректным курсором", func(t provider.T) {
+		t.NewStep("Тестирование неверного курсора")
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.CursorParam, "mir").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+
+		t.NewStep("Получение курсора первой страницы")
+		resp := apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.LimitParam, "1").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			HeaderPresent(bh.NextCursorHeader).
+			End()
+
+		cursor := resp.Response.Header.Get(bh.NextCursorHeader)
+
+		t.NewStep("Тестирование курсора вместе со смещением")
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.CursorParam, cursor).Query(bh.OffsetParam, "1").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+
+		t.NewStep("Тестирование сортировки, не совпадающей с курсором")
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.CursorParam, cursor).Query(bh.SortParam, "updated_at").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+
 	t.Run("Попытка получить список баннеров с неверным типом параметра в строке запроса", func(t provider.T) {
 		t.NewStep("Тестирование неверного типа feature id")
 		apitest.New().
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "mir").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
@@ -292,7 +460,7 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.TagIDParam, "mir").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
@@ -302,7 +470,7 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.LimitParam, "mir").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
@@ -312,7 +480,17 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.OffsetParam, "mir").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+
+		t.NewStep("Тестирование неверного значения state")
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.StateParam, "mir").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
@@ -333,7 +511,7 @@ func (as *ApiSuite) TestGetAdminBanner(t provider.T) {
 		apitest.New().
 			Handler(as.router).
 			Get(path).
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Status(http.StatusForbidden).
 			End()
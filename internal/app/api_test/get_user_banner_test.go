@@ -8,7 +8,9 @@ import (
 	"bannersrv/internal/banner/entity"
 	cmid "bannersrv/internal/caches/delivery/middleware"
 	"bannersrv/internal/pkg/types"
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 	"github.com/steinfletcher/apitest"
@@ -58,7 +60,8 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 	}
 
 	for key, bn := range bannerList {
-		id, err := as.bannerRepository.CreateBanner(bn.FeatureID, bn.TagIDs, bannerContentList[key], bn.IsActive)
+		id, err := as.bannerRepository.CreateBanner(context.Background(), bn.FeatureID, bn.TagIDs,
+			bannerContentList[key], bn.IsActive, nil, 0)
 		t.Require().NoError(err)
 		bn.ID = id
 	}
@@ -68,7 +71,7 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "1").Query(bh.TagIDParam, "2").
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Body(string(bannerContentList[activeBanner])).
 			Status(http.StatusOK).
@@ -80,13 +83,13 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "3").Query(bh.TagIDParam, "1").
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Body(string(bannerContentList[cashedBanner])).
 			Status(http.StatusOK).
 			End()
 
-		_, err := as.bannerRepository.UpdateBanner(&entity.BannerUpdate{
+		_, err := as.bannerRepository.UpdateBanner(context.Background(), &entity.BannerUpdate{
 			ID:        bannerList[cashedBanner].ID,
 			Content:   types.NewObject[types.Content](updatedContent),
 			TagIDs:    types.NewNullObject[[]types.ID](),
@@ -99,7 +102,7 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "3").Query(bh.TagIDParam, "1").Query(cmid.UseLastRevisionParam, "true").
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Body(updatedContent).
 			Status(http.StatusOK).
@@ -107,7 +110,7 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 	})
 
 	t.Run("Успешное получение активного баннера с указанной версией", func(t provider.T) {
-		_, err := as.bannerRepository.UpdateBanner(&entity.BannerUpdate{
+		_, err := as.bannerRepository.UpdateBanner(context.Background(), &entity.BannerUpdate{
 			ID:        bannerList[cashedBanner].ID,
 			Content:   types.NewObject[types.Content](updatedContent),
 			TagIDs:    types.NewNullObject[[]types.ID](),
@@ -121,7 +124,7 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 			Get(path).
 			Query(bh.FeatureIDParam, "3").Query(bh.TagIDParam, "1").
 			Query(bh.VersionParam, "1").Query(cmid.UseLastRevisionParam, "true").
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Body(string(bannerContentList[cashedBanner])).
 			Status(http.StatusOK).
@@ -133,13 +136,13 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "4").Query(bh.TagIDParam, "1").
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Body(string(bannerContentList[otherCashedBanner])).
 			Status(http.StatusOK).
 			End()
 
-		_, err := as.bannerRepository.UpdateBanner(&entity.BannerUpdate{
+		_, err := as.bannerRepository.UpdateBanner(context.Background(), &entity.BannerUpdate{
 			ID:        bannerList[otherCashedBanner].ID,
 			Content:   types.NewObject[types.Content](updatedContent),
 			TagIDs:    types.NewNullObject[[]types.ID](),
@@ -152,7 +155,7 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "4").Query(bh.TagIDParam, "1").
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Body(string(bannerContentList[otherCashedBanner])).
 			Status(http.StatusOK).
@@ -164,7 +167,38 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "2").Query(bh.TagIDParam, "4").
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusNotFound).
+			End()
+	})
+
+	t.Run("Попытка получения баннера вне окна показа", func(t provider.T) {
+		startAt := time.Now().Add(time.Hour)
+		endAt := time.Now().Add(-time.Hour)
+
+		_, err := as.bannerRepository.CreateBanner(context.Background(), 10, []types.ID{1},
+			`{"title": "scheduled_banner"}`, true, &entity.Schedule{StartAt: &startAt}, 0)
+		t.Require().NoError(err)
+
+		_, err = as.bannerRepository.CreateBanner(context.Background(), 11, []types.ID{1},
+			`{"title": "expired_banner"}`, true, &entity.Schedule{EndAt: &endAt}, 0)
+		t.Require().NoError(err)
+
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.FeatureIDParam, "10").Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusNotFound).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.FeatureIDParam, "11").Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Status(http.StatusNotFound).
 			End()
@@ -175,7 +209,7 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "3").Query(bh.TagIDParam, "4").
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Status(http.StatusNotFound).
 			End()
@@ -196,7 +230,7 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "3").Query(bh.TagIDParam, "4").
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusForbidden).
 			End()
@@ -207,7 +241,7 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "mir").Query(bh.TagIDParam, "4").
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
@@ -216,7 +250,7 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "4").Query(bh.TagIDParam, "mir").
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
@@ -227,7 +261,7 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.TagIDParam, "4").
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
@@ -236,7 +270,98 @@ func (as *ApiSuite) TestGetUserBanner(t provider.T) {
 			Handler(as.router).
 			Get(path).
 			Query(bh.FeatureIDParam, "3").
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+}
+
+func (as *ApiSuite) TestGetUserBannerByTags(t provider.T) {
+	t.Title("Тестирование выбора баннера по нескольким тэгам: GET /user_banner")
+	const path = "/api/v1/user_banner"
+
+	t.Run("Выбор баннера с наибольшим приоритетом", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		firstID, err := as.bannerRepository.CreateBanner(context.Background(), 65, []types.ID{1},
+			`{"title": "first"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		secondID, err := as.bannerRepository.CreateBanner(context.Background(), 65, []types.ID{2},
+			`{"title": "second"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		_, err = as.bannerRepository.CreateBanner(context.Background(), 65, []types.ID{3},
+			`{"title": "third"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		t.NewStep("Тестирование")
+
+		// При равных приоритетах выбирается баннер, созданный раньше
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.FeatureIDParam, "65").Query(bh.TagIDParam, "3").Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Body(`{"title": "first"}`).
+			Status(http.StatusOK).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Patchf("/api/v1/banner/%d", secondID).
+			Body(`{"priority": 5}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.FeatureIDParam, "65").Query(bh.TagIDParam, "1,2").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Body(`{"title": "second"}`).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+
+		// Набор тэгов кэшируется независимо от порядка тэгов в запросе
+		t.Require().EqualValues(1, as.rdsClient.Exists(context.Background(), "65-1,2").Val())
+
+		apitest.New().
+			Handler(as.router).
+			Patchf("/api/v1/banner/%d", firstID).
+			Body(`{"priority": 10}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		t.Require().Zero(as.rdsClient.Exists(context.Background(), "65-1,2").Val())
+
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.FeatureIDParam, "65").Query(bh.TagIDParam, "2").Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Body(`{"title": "first"}`).
+			Status(http.StatusOK).
+			End()
+	})
+
+	t.Run("Попытка получения баннера с некорректным списком тэгов", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.FeatureIDParam, "65").Query(bh.TagIDParam, "1,mir").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
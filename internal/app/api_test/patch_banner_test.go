@@ -4,10 +4,14 @@ package api_test
 
 import (
 	"bannersrv/internal/app/delivery/http/middleware"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
 	"bannersrv/internal/banner/entity"
 	"bannersrv/internal/pkg/types"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 	"github.com/steinfletcher/apitest"
@@ -27,28 +31,30 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 			IsActive:  true,
 		}
 
-		bannerID, err := as.bannerRepository.CreateBanner(25, []types.ID{10},
-			`{}`, true)
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 25, []types.ID{10},
+			`{}`, true, nil, 0)
 		t.Require().NoError(err)
 
 		body, err := json.Marshal(bnr)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
-		apitest.New().
+		resp := apitest.New().
 			Handler(as.router).
 			Patchf("%s/%d", path, bannerID).
 			Body(string(body)).
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusOK).
 			End()
 
+		as.publishDraft(t, resp)
+
 		t.NewStep("Проверка результатов")
-		bnrs, err := as.bannerRepository.GetBanners(&entity.BannerInfo{
+		bnrs, err := as.bannerRepository.GetBanners(context.Background(), &entity.BannerInfo{
 			FeatureID: (*types.NullableID)(types.NewObject(bnr.FeatureID)),
 			TagID:     (*types.NullableID)(types.NewObject(bnr.TagIDs[0])),
-		}, 0, 100)
+		}, nil, 0, 100)
 		t.Require().NoError(err)
 
 		t.Require().Len(bnrs, 1)
@@ -69,12 +75,12 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 		body, err := json.Marshal(bnr)
 		t.Require().NoError(err)
 
-		_, err = as.bannerRepository.CreateBanner(bnr.FeatureID, bnr.TagIDs,
-			types.Content(bnr.Content), bnr.IsActive)
+		_, err = as.bannerRepository.CreateBanner(context.Background(), bnr.FeatureID, bnr.TagIDs,
+			types.Content(bnr.Content), bnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
-		bannerIDToUpdate, err := as.bannerRepository.CreateBanner(bnr.FeatureID+1, bnr.TagIDs,
-			types.Content(bnr.Content), bnr.IsActive)
+		bannerIDToUpdate, err := as.bannerRepository.CreateBanner(context.Background(), bnr.FeatureID+1, bnr.TagIDs,
+			types.Content(bnr.Content), bnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
@@ -82,7 +88,7 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 			Handler(as.router).
 			Patchf("%s/%d", path, bannerIDToUpdate).
 			Body(string(body)).
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusConflict).
 			End()
@@ -98,8 +104,8 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 			IsActive:  true,
 		}
 
-		bannerID, err := as.bannerRepository.CreateBanner(bnr.FeatureID, bnr.TagIDs,
-			types.Content(bnr.Content), bnr.IsActive)
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), bnr.FeatureID, bnr.TagIDs,
+			types.Content(bnr.Content), bnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.WithNewStep("Тестирование только с полем feature_id", func(ctx provider.StepCtx) {
@@ -113,16 +119,16 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 						"feature_id": 15
 					}
 				`).
-				Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
 				Expect(t).
 				Status(http.StatusOK).
 				End()
 
 			t.NewStep("Проверка результатов")
-			bnrs, err := as.bannerRepository.GetBanners(&entity.BannerInfo{
+			bnrs, err := as.bannerRepository.GetBanners(context.Background(), &entity.BannerInfo{
 				FeatureID: (*types.NullableID)(types.NewObject(types.ID(15))),
 				TagID:     (*types.NullableID)(types.NewObject(bnr.TagIDs[0])),
-			}, 0, 100)
+			}, nil, 0, 100)
 			t.Require().NoError(err)
 
 			t.Require().Len(bnrs, 1)
@@ -144,16 +150,16 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 						"is_active": false
 					}
 				`).
-				Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
 				Expect(t).
 				Status(http.StatusOK).
 				End()
 
 			t.NewStep("Проверка результатов")
-			bnrs, err := as.bannerRepository.GetBanners(&entity.BannerInfo{
+			bnrs, err := as.bannerRepository.GetBanners(context.Background(), &entity.BannerInfo{
 				FeatureID: (*types.NullableID)(types.NewObject(types.ID(15))),
 				TagID:     (*types.NullableID)(types.NewObject(bnr.TagIDs[0])),
-			}, 0, 100)
+			}, nil, 0, 100)
 			t.Require().NoError(err)
 
 			t.Require().Len(bnrs, 1)
@@ -175,16 +181,16 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 						"tag_ids": [98, 23]
 					}
 				`).
-				Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
 				Expect(t).
 				Status(http.StatusOK).
 				End()
 
 			t.NewStep("Проверка результатов")
-			bnrs, err := as.bannerRepository.GetBanners(&entity.BannerInfo{
+			bnrs, err := as.bannerRepository.GetBanners(context.Background(), &entity.BannerInfo{
 				FeatureID: (*types.NullableID)(types.NewObject(types.ID(15))),
 				TagID:     (*types.NullableID)(types.NewObject(types.ID(23))),
-			}, 0, 100)
+			}, nil, 0, 100)
 			t.Require().NoError(err)
 
 			t.Require().Len(bnrs, 1)
@@ -197,7 +203,7 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 
 		t.WithNewStep("Тестирование только с полем tag_ids", func(ctx provider.StepCtx) {
 			t.NewStep("Тестирование")
-			apitest.New().
+			resp := apitest.New().
 				Handler(as.router).
 				Patchf("%s/%d", path, bannerID).
 				Body(
@@ -206,16 +212,18 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 						"content": {"title":21}
 					}
 				`).
-				Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
 				Expect(t).
 				Status(http.StatusOK).
 				End()
 
+			as.publishDraft(t, resp)
+
 			t.NewStep("Проверка результатов")
-			bnrs, err := as.bannerRepository.GetBanners(&entity.BannerInfo{
+			bnrs, err := as.bannerRepository.GetBanners(context.Background(), &entity.BannerInfo{
 				FeatureID: (*types.NullableID)(types.NewObject(types.ID(15))),
 				TagID:     (*types.NullableID)(types.NewObject(types.ID(23))),
-			}, 0, 100)
+			}, nil, 0, 100)
 			t.Require().NoError(err)
 
 			t.Require().Len(bnrs, 1)
@@ -245,13 +253,13 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 			IsActive:  true,
 		}
 
-		bannerID, err := as.bannerRepository.CreateBanner(bnr.FeatureID, bnr.TagIDs,
-			types.Content(bnr.Content), bnr.IsActive)
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), bnr.FeatureID, bnr.TagIDs,
+			types.Content(bnr.Content), bnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.WithNewStep("Тестирование", func(ctx provider.StepCtx) {
 			t.NewStep("Добавление второй версии")
-			apitest.New().
+			resp := apitest.New().
 				Handler(as.router).
 				Patchf("%s/%d", path, bannerID).
 				Body(
@@ -260,16 +268,18 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 						"content": `+secondContent+`
 					}
 				`).
-				Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
 				Expect(t).
 				Status(http.StatusOK).
 				End()
 
+			as.publishDraft(t, resp)
+
 			t.NewStep("Проверка результатов")
-			bnrs, err := as.bannerRepository.GetBanners(&entity.BannerInfo{
+			bnrs, err := as.bannerRepository.GetBanners(context.Background(), &entity.BannerInfo{
 				FeatureID: (*types.NullableID)(types.NewObject(bnr.FeatureID)),
 				TagID:     (*types.NullableID)(types.NewObject(bnr.TagIDs[0])),
-			}, 0, 100)
+			}, nil, 0, 100)
 			t.Require().NoError(err)
 
 			t.Require().Len(bnrs, 1)
@@ -277,7 +287,7 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 			t.Require().EqualValues(secondContent, bnrs[0].Versions[1].Content)
 
 			t.NewStep("Добавление третьей версии")
-			apitest.New().
+			resp = apitest.New().
 				Handler(as.router).
 				Patchf("%s/%d", path, bannerID).
 				Body(
@@ -286,16 +296,18 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 						"content": `+thirdContent+`
 					}
 				`).
-				Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
 				Expect(t).
 				Status(http.StatusOK).
 				End()
 
+			as.publishDraft(t, resp)
+
 			t.NewStep("Проверка результатов")
-			bnrs, err = as.bannerRepository.GetBanners(&entity.BannerInfo{
+			bnrs, err = as.bannerRepository.GetBanners(context.Background(), &entity.BannerInfo{
 				FeatureID: (*types.NullableID)(types.NewObject(bnr.FeatureID)),
 				TagID:     (*types.NullableID)(types.NewObject(bnr.TagIDs[0])),
-			}, 0, 100)
+			}, nil, 0, 100)
 			t.Require().NoError(err)
 
 			t.Require().Len(bnrs, 1)
@@ -304,7 +316,7 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 			t.Require().EqualValues(secondContent, bnrs[0].Versions[2].Content)
 
 			t.NewStep("Добавление четвёртой версии")
-			apitest.New().
+			resp = apitest.New().
 				Handler(as.router).
 				Patchf("%s/%d", path, bannerID).
 				Body(
@@ -313,16 +325,18 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 						"content": `+fourthContent+`
 					}
 				`).
-				Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
 				Expect(t).
 				Status(http.StatusOK).
 				End()
 
+			as.publishDraft(t, resp)
+
 			t.NewStep("Проверка результатов")
-			bnrs, err = as.bannerRepository.GetBanners(&entity.BannerInfo{
+			bnrs, err = as.bannerRepository.GetBanners(context.Background(), &entity.BannerInfo{
 				FeatureID: (*types.NullableID)(types.NewObject(bnr.FeatureID)),
 				TagID:     (*types.NullableID)(types.NewObject(bnr.TagIDs[0])),
-			}, 0, 100)
+			}, nil, 0, 100)
 			t.Require().NoError(err)
 
 			t.Require().Len(bnrs, 1)
@@ -335,6 +349,112 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 
 	})
 
+	t.Run("Выключение баннера сбрасывает его кэш, включая записи с версией", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 97, []types.ID{1, 2},
+			`{"title": "cached"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		for _, version := range []string{"", "1"} {
+			req := apitest.New().
+				Handler(as.router).
+				Get("/api/v1/user_banner").
+				Query(bh.FeatureIDParam, "97").
+				Query(bh.TagIDParam, "2")
+
+			if version != "" {
+				req = req.Query(bh.VersionParam, version)
+			}
+
+			req.Header(middleware.TokenHeaderField, as.userToken(t)).
+				Expect(t).
+				Body(`{"title": "cached"}`).
+				Status(http.StatusOK).
+				End()
+		}
+
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Patchf("%s/%d", path, bannerID).
+			Body(`{"is_active": false}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+		for _, version := range []string{"", "1"} {
+			req := apitest.New().
+				Handler(as.router).
+				Get("/api/v1/user_banner").
+				Query(bh.FeatureIDParam, "97").
+				Query(bh.TagIDParam, "2")
+
+			if version != "" {
+				req = req.Query(bh.VersionParam, version)
+			}
+
+			req.Header(middleware.TokenHeaderField, as.userToken(t)).
+				Expect(t).
+				Status(http.StatusNotFound).
+				End()
+		}
+	})
+
+	t.Run("Успешное изменение и снятие окна показа баннера", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+		startAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		endAt := startAt.Add(time.Hour)
+
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 133, []types.ID{1},
+			`{"title": "scheduled"}`, true, &entity.Schedule{StartAt: &startAt, EndAt: &endAt}, 0)
+		t.Require().NoError(err)
+
+		getBanner := func() *entity.Banner {
+			bnrs, err := as.bannerRepository.GetBanners(context.Background(), &entity.BannerInfo{
+				FeatureID: (*types.NullableID)(types.NewObject[types.ID](133)),
+			}, nil, 0, 100)
+			t.Require().NoError(err)
+			t.Require().Len(bnrs, 1)
+
+			return &bnrs[0]
+		}
+
+		t.NewStep("Изменение одной границы окна")
+		endAt = endAt.Add(time.Hour)
+
+		apitest.New().
+			Handler(as.router).
+			Patchf("%s/%d", path, bannerID).
+			Body(fmt.Sprintf(`{"end_at": %q}`, endAt.Format(time.RFC3339))).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		bnr := getBanner()
+		t.Require().NotNil(bnr.StartAt)
+		t.Require().True(startAt.Equal(*bnr.StartAt))
+		t.Require().NotNil(bnr.EndAt)
+		t.Require().True(endAt.Equal(*bnr.EndAt))
+
+		t.NewStep("Снятие окна показа")
+		apitest.New().
+			Handler(as.router).
+			Patchf("%s/%d", path, bannerID).
+			Body(`{"start_at": null, "end_at": null}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		bnr = getBanner()
+		t.Require().Nil(bnr.StartAt)
+		t.Require().Nil(bnr.EndAt)
+	})
+
 	t.Run("Попытка обновить баннер с неверным типом полей в теле запроса", func(t provider.T) {
 		t.NewStep("Инициализация тестовых данных")
 
@@ -345,8 +465,8 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 			IsActive:  true,
 		}
 
-		bannerID, err := as.bannerRepository.CreateBanner(bnr.FeatureID, bnr.TagIDs,
-			types.Content(bnr.Content), bnr.IsActive)
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), bnr.FeatureID, bnr.TagIDs,
+			types.Content(bnr.Content), bnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
@@ -364,7 +484,7 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 						  ]
 					}
 				`).
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
@@ -375,7 +495,7 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 		apitest.New().
 			Handler(as.router).
 			Patchf("%s/more", path).
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusBadRequest).
 			End()
@@ -391,8 +511,8 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 			IsActive:  true,
 		}
 
-		bannerID, err := as.bannerRepository.CreateBanner(bnr.FeatureID, bnr.TagIDs,
-			types.Content(bnr.Content), bnr.IsActive)
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), bnr.FeatureID, bnr.TagIDs,
+			types.Content(bnr.Content), bnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
@@ -425,8 +545,8 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 			IsActive:  true,
 		}
 
-		bannerID, err := as.bannerRepository.CreateBanner(bnr.FeatureID, bnr.TagIDs,
-			types.Content(bnr.Content), bnr.IsActive)
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), bnr.FeatureID, bnr.TagIDs,
+			types.Content(bnr.Content), bnr.IsActive, nil, 0)
 		t.Require().NoError(err)
 
 		t.NewStep("Тестирование")
@@ -444,7 +564,7 @@ func (as *ApiSuite) TestUpdateBanner(t provider.T) {
 						  ]
 					}
 				`).
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Status(http.StatusForbidden).
 			End()
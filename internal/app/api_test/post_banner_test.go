@@ -4,7 +4,10 @@ package api_test
 
 import (
 	"bannersrv/internal/app/delivery/http/middleware"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
 	"bannersrv/internal/pkg/types"
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -43,7 +46,7 @@ func (as *ApiSuite) TestCreateBanner(t provider.T) {
 			Handler(as.router).
 			Post(path).
 			Body(string(body)).
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusCreated).
 			End()
@@ -52,11 +55,62 @@ func (as *ApiSuite) TestCreateBanner(t provider.T) {
 		var id BannerID
 		resp.JSON(&id)
 
-		_, err = as.bannerRepository.GetBanner(bnr.FeatureID, bnr.TagIDs[0],
+		_, err = as.bannerRepository.GetBanner(context.Background(), bnr.FeatureID, bnr.TagIDs[:1],
 			types.NullableObject[uint32]{IsNull: false, Value: 1})
 		t.Require().NoError(err)
 	})
 
+	t.Run("Создание баннеров с приоритетом", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body(`{"feature_id": 134, "tag_ids": [1], "content": {}, "is_active": true, "priority": 7}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusCreated).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/banner/batch").
+			Body(`{"operations": [
+				{"action": "create", "banner": {"feature_id": 135, "tag_ids": [1], "content": {}, "is_active": true,
+					"priority": -3}}
+			]}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+		for featureID, priority := range map[string]int32{"134": 7, "135": -3} {
+			resp := apitest.New().
+				Handler(as.router).
+				Get(path).
+				Query(bh.FeatureIDParam, featureID).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
+				Expect(t).
+				Status(http.StatusOK).
+				End()
+
+			var bnrs []response.Banner
+			resp.JSON(&bnrs)
+
+			t.Require().Len(bnrs, 1)
+			t.Require().Equal(priority, bnrs[0].Priority)
+		}
+
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body(`{"feature_id": 136, "tag_ids": [1], "content": {}, "is_active": true, "priority": 2147483648}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+
 	t.Run("Попытка создать баннер с существующими feature и tag ids", func(t provider.T) {
 		t.NewStep("Инициализация тестовых данных")
 		bnr := &createBanner{
@@ -72,7 +126,7 @@ func (as *ApiSuite) TestCreateBanner(t provider.T) {
 			Handler(as.router).
 			Post(path).
 			Body(string(body)).
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusCreated).
 			End()
@@ -80,7 +134,7 @@ func (as *ApiSuite) TestCreateBanner(t provider.T) {
 		var id BannerID
 		resp.JSON(&id)
 
-		_, err = as.bannerRepository.GetBanner(bnr.FeatureID, bnr.TagIDs[0],
+		_, err = as.bannerRepository.GetBanner(context.Background(), bnr.FeatureID, bnr.TagIDs[:1],
 			types.NullableObject[uint32]{IsNull: false, Value: 1})
 		t.Require().NoError(err)
 
@@ -89,7 +143,7 @@ func (as *ApiSuite) TestCreateBanner(t provider.T) {
 			Handler(as.router).
 			Post(path).
 			Body(string(body)).
-			Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
 			Expect(t).
 			Status(http.StatusConflict).
 			End()
@@ -111,7 +165,7 @@ func (as *ApiSuite) TestCreateBanner(t provider.T) {
 						]
 					}
 				`).
-				Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
 				Expect(t).
 				Status(http.StatusBadRequest).
 				End()
@@ -130,7 +184,7 @@ func (as *ApiSuite) TestCreateBanner(t provider.T) {
 						]
 					}
 				`).
-				Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
 				Expect(t).
 				Status(http.StatusBadRequest).
 				End()
@@ -149,7 +203,7 @@ func (as *ApiSuite) TestCreateBanner(t provider.T) {
 						]
 					}
 				`).
-				Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
 				Expect(t).
 				Status(http.StatusBadRequest).
 				End()
@@ -166,7 +220,7 @@ func (as *ApiSuite) TestCreateBanner(t provider.T) {
 						"is_active": true
 					}
 				`).
-				Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
 				Expect(t).
 				Status(http.StatusBadRequest).
 				End()
@@ -186,7 +240,7 @@ func (as *ApiSuite) TestCreateBanner(t provider.T) {
 						  ]
 					}
 				`).
-				Header(middleware.TokenHeaderField, string(as.authService.GetAdminToken())).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
 				Expect(t).
 				Status(http.StatusBadRequest).
 				End()
@@ -230,7 +284,7 @@ func (as *ApiSuite) TestCreateBanner(t provider.T) {
 						  ]
 					}
 				`).
-			Header(middleware.TokenHeaderField, string(as.authService.GetUserToken())).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
 			Expect(t).
 			Status(http.StatusForbidden).
 			End()
@@ -0,0 +1,120 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app/config"
+	"bannersrv/internal/app/delivery/http/middleware"
+	v1 "bannersrv/internal/app/delivery/http/v1"
+	tm "bannersrv/internal/token/delivery/middleware"
+	"bannersrv/pkg/logger"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+// rateLimitedRouter возвращает роутер с единственным пользовательским методом, ограниченным лимитом cfg
+func (as *ApiSuite) rateLimitedRouter(t provider.T, cfg config.RateLimitGroup) *gin.Engine {
+	router, err := v1.NewRouter("/api", v1.Routes{
+		v1.Route{
+			Method:  http.MethodGet,
+			Pattern: "/limited",
+			HandlerFunc: func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			},
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken,
+				middleware.IPRateLimit(as.rateLimiter, middleware.UserGroup, cfg, nil),
+				tm.WithUserToken(as.tokenService),
+				middleware.SubjectRateLimit(as.rateLimiter, middleware.UserGroup, cfg, nil),
+			},
+		},
+	}, config.Release, 0, nil, &logger.EmptyLogger{}, nil)
+	t.Require().NoError(err)
+
+	return router
+}
+
+func (as *ApiSuite) TestRateLimit(t provider.T) {
+	t.Title("Тестирование ограничения частоты запросов")
+
+	t.Run("Превышение лимита токена", func(t provider.T) {
+		router := as.rateLimitedRouter(t, config.RateLimitGroup{TokenLimit: 2, Period: 60000})
+		token := as.userToken(t)
+
+		for range 2 {
+			apitest.New().
+				Handler(router).
+				Get("/api/v1/limited").
+				Header(middleware.TokenHeaderField, token).
+				Expect(t).
+				Status(http.StatusOK).
+				End()
+		}
+
+		apitest.New().
+			Handler(router).
+			Get("/api/v1/limited").
+			Header(middleware.TokenHeaderField, token).
+			Expect(t).
+			HeaderPresent(middleware.RetryAfterHeader).
+			Status(http.StatusTooManyRequests).
+			End()
+
+		apitest.New().
+			Handler(router).
+			Get("/api/v1/limited").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+	})
+
+	t.Run("Превышение лимита IP адреса", func(t provider.T) {
+		router := as.rateLimitedRouter(t, config.RateLimitGroup{IPLimit: 3, Period: 60000})
+
+		for range 2 {
+			apitest.New().
+				Handler(router).
+				Get("/api/v1/limited").
+				Header(middleware.TokenHeaderField, as.userToken(t)).
+				Expect(t).
+				Status(http.StatusOK).
+				End()
+		}
+
+		t.NewStep("Тестирование подбора токенов")
+		apitest.New().
+			Handler(router).
+			Get("/api/v1/limited").
+			Header(middleware.TokenHeaderField, "ip-token-invalid").
+			Expect(t).
+			Status(http.StatusUnauthorized).
+			End()
+
+		apitest.New().
+			Handler(router).
+			Get("/api/v1/limited").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			HeaderPresent(middleware.RetryAfterHeader).
+			Status(http.StatusTooManyRequests).
+			End()
+
+		t.NewStep("Тестирование подмены адреса в заголовке X-Forwarded-For")
+		for i := range 3 {
+			apitest.New().
+				Handler(router).
+				Get("/api/v1/limited").
+				Header(middleware.TokenHeaderField, as.userToken(t)).
+				Header("X-Forwarded-For", fmt.Sprintf("10.0.0.%d", i+1)).
+				Expect(t).
+				HeaderPresent(middleware.RetryAfterHeader).
+				Status(http.StatusTooManyRequests).
+				End()
+		}
+	})
+}
@@ -0,0 +1,139 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+func (as *ApiSuite) TestRollbackBanner(t provider.T) {
+	t.Title("Тестирование апи метода RollbackBanner: POST /banner/{id}/rollback")
+	t.NewStep("Инициализация тестовых данных")
+	const userPath = "/api/v1/user_banner"
+
+	bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 81, []types.ID{1, 2},
+		`{"title": "version_1"}`, true, nil, 0)
+	t.Require().NoError(err)
+
+	// Создаётся пять версий, при глобальном ограничении в три версии две первые переносятся в архив
+	for version := 2; version <= 5; version++ {
+		_, err := as.bannerRepository.UpdateBanner(context.Background(), &entity.BannerUpdate{
+			ID:        bannerID,
+			Content:   types.NewObject[types.Content](types.Content(fmt.Sprintf(`{"title": "version_%d"}`, version))),
+			TagIDs:    types.NewNullObject[[]types.ID](),
+			FeatureID: (*types.NullableID)(types.NewNullObject[types.ID]()),
+			IsActive:  types.NewNullObject[bool](),
+		})
+		t.Require().NoError(err)
+	}
+
+	t.Run("Успешный откат баннера со сбросом кэша", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Get(userPath).
+			Query(bh.FeatureIDParam, "81").Query(bh.TagIDParam, "2").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Body(`{"title": "version_5"}`).
+			Status(http.StatusOK).
+			End()
+
+		resp := apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/banner/%d/rollback", bannerID).
+			Body(`{"version": 4}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var current response.CurrentVersion
+		resp.JSON(&current)
+
+		t.Require().EqualValues(6, current.Version)
+
+		apitest.New().
+			Handler(as.router).
+			Get(userPath).
+			Query(bh.FeatureIDParam, "81").Query(bh.TagIDParam, "2").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Body(`{"title": "version_4"}`).
+			Status(http.StatusOK).
+			End()
+	})
+
+	t.Run("Попытка отката к текущей версии", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/banner/%d/rollback", bannerID).
+			Body(`{"version": 6}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusConflict).
+			End()
+	})
+
+	t.Run("Попытка отката к версии из архива", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/banner/%d/rollback", bannerID).
+			Body(`{"version": 1}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusConflict).
+			End()
+	})
+
+	t.Run("Попытка отката к несуществующей версии", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/banner/%d/rollback", bannerID).
+			Body(`{"version": 100}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusNotFound).
+			End()
+
+		apitest.New().
+			Handler(as.router).
+			Post("/api/v1/banner/100000/rollback").
+			Body(`{"version": 1}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusNotFound).
+			End()
+	})
+
+	t.Run("Попытка отката с некорректными данными", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/banner/%d/rollback", bannerID).
+			Body(`{"version": "mir"}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+
+	t.Run("Попытка отката пользователем с неверными правами", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Postf("/api/v1/banner/%d/rollback", bannerID).
+			Body(`{"version": 4}`).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+}
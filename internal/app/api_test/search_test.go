@@ -0,0 +1,153 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"net/http"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+func (as *ApiSuite) TestSearchBanners(t provider.T) {
+	t.Title("Тестирование апи метода SearchBanners: GET /banner/search")
+	const path = "/api/v1/banner/search"
+
+	t.Run("Успешный поиск баннеров по jsonpath и тексту", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 120, []types.ID{1},
+			`{"title": "Осенняя распродажа", "url": "https://search.example.com/autumn"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		_, err = as.bannerRepository.CreateBanner(context.Background(), 121, []types.ID{1},
+			`{"title": "Зимняя распродажа", "url": "https://search.example.com/winter"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		t.NewStep("Тестирование поиска по jsonpath")
+		resp := apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.SearchJSONPathParam, `$.url == "https://search.example.com/autumn"`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var matches []response.ContentMatch
+
+		resp.JSON(&matches)
+		t.Require().Len(matches, 1)
+		t.Require().Equal(bannerID, matches[0].BannerID)
+		t.Require().True(matches[0].Current)
+
+		t.NewStep("Тестирование полнотекстового поиска по фичам")
+		resp = apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.SearchTextParam, "распродажа").
+			Query(bh.FeatureIDParam, "120,121").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		resp.JSON(&matches)
+		t.Require().Len(matches, 2)
+	})
+
+	t.Run("Успешный поиск среди всех хранимых версий", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 122, []types.ID{1},
+			`{"title": "searchable old title"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		_, err = as.bannerRepository.UpdateBanner(context.Background(), &entity.BannerUpdate{
+			ID:        bannerID,
+			Content:   types.NewObject[types.Content](`{"title": "new title"}`),
+			TagIDs:    types.NewNullObject[[]types.ID](),
+			FeatureID: (*types.NullableID)(types.NewNullObject[types.ID]()),
+			IsActive:  types.NewNullObject[bool](),
+		})
+		t.Require().NoError(err)
+
+		t.NewStep("Тестирование поиска по текущей версии")
+		resp := apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.SearchTextParam, "searchable").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		var matches []response.ContentMatch
+
+		resp.JSON(&matches)
+		t.Require().Empty(matches)
+
+		t.NewStep("Тестирование поиска по всем версиям")
+		resp = apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.SearchTextParam, "searchable").Query(bh.SearchVersionsParam, "all").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		resp.JSON(&matches)
+		t.Require().Len(matches, 1)
+		t.Require().Equal(bannerID, matches[0].BannerID)
+		t.Require().EqualValues(1, matches[0].Version)
+		t.Require().False(matches[0].Current)
+	})
+
+	t.Run("Попытка поиска с некорректными параметрами", func(t provider.T) {
+		t.NewStep("Тестирование поиска без условий")
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+
+		t.NewStep("Тестирование некорректного jsonpath")
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.SearchJSONPathParam, "$.title ==").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+
+		t.NewStep("Тестирование неверного значения versions")
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.SearchTextParam, "title").Query(bh.SearchVersionsParam, "mir").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+
+	t.Run("Попытка поиска пользователем с неверными правами", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Get(path).
+			Query(bh.SearchTextParam, "title").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+}
@@ -0,0 +1,160 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app"
+	"bannersrv/internal/app/config"
+	"bannersrv/internal/app/delivery/http/middleware"
+	v1 "bannersrv/internal/app/delivery/http/v1"
+	"bannersrv/internal/banner"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
+	bu "bannersrv/internal/banner/usecase"
+	"bannersrv/internal/caches"
+	cmw "bannersrv/internal/caches/delivery/middleware"
+	cm "bannersrv/internal/caches/manager"
+	cr "bannersrv/internal/caches/repository/redis"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/logger"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+// staleTTLConfig баннеры в кэше устаревают почти сразу, но хранятся долго
+var staleTTLConfig = caches.TTLConfig{
+	Soft: 100,
+	Hard: 60000,
+}
+
+// newStaleRouter создаёт роутер, отдающий баннеры из rep через кэш с быстро устаревающими записями
+func (as *ApiSuite) newStaleRouter(t provider.T, rep banner.Repository) (*gin.Engine, *cm.CacheManager) {
+	l := &logger.EmptyLogger{}
+
+	cacheManager := cm.NewCacheManager(cr.NewCashRedis(as.rdsClient), staleTTLConfig, nil, l)
+	popularityManager := cm.NewPopularityManager(cr.NewPopularityRedis(as.rdsClient, popularityConfig),
+		popularityConfig, l)
+	usecase := bu.NewBannerUsecase(rep, cacheManager, popularityManager, nil, &coalescingConfig)
+
+	router, err := v1.NewRouter("/api", app.PrepareRoutes(bh.NewBannerHandlers(usecase), nil, cacheManager,
+		popularityManager, usecase, as.tokenService, nil,
+		middleware.NewRateLimits(as.rateLimiter, config.RateLimit{}, nil), nil, 0),
+		config.Release, 0, nil, l, nil)
+	t.Require().NoError(err)
+
+	return router, cacheManager
+}
+
+func (as *ApiSuite) TestStaleCache(t provider.T) {
+	t.Title("Тестирование отдачи устаревших баннеров из кэша")
+	const path = "/api/v1/user_banner"
+
+	t.Run("Устаревший баннер отдаётся сразу и обновляется в фоне", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		_, err := as.bannerRepository.CreateBanner(context.Background(), 88, []types.ID{1},
+			`{"title": "new"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		router, cacheManager := as.newStaleRouter(t, as.bannerRepository)
+		cacheManager.SetCache(context.Background(), 88, []types.ID{1}, nil, `{"title": "old"}`, nil, nil)
+
+		time.Sleep(150 * time.Millisecond)
+
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(router).
+			Get(path).
+			Query(bh.FeatureIDParam, "88").
+			Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Body(`{"title": "old"}`).
+			Header(cmw.StaleHeader, "true").
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+		deadline := time.Now().Add(2 * time.Second)
+
+		for {
+			entry, err := cacheManager.HaveCache(context.Background(), 88, []types.ID{1}, nil)
+			if err == nil && entry.Content == `{"title": "new"}` {
+				break
+			}
+
+			if time.Now().After(deadline) {
+				t.Fatalf("stale banner was not refreshed: %v, %v", entry, err)
+			}
+
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		apitest.New().
+			Handler(router).
+			Get(path).
+			Query(bh.FeatureIDParam, "88").
+			Query(bh.TagIDParam, "1").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Body(`{"title": "new"}`).
+			HeaderNotPresent(cmw.StaleHeader).
+			Status(http.StatusOK).
+			End()
+	})
+
+	t.Run("При недоступности базы отдаётся устаревший баннер", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		rep := &countingRepository{Repository: as.bannerRepository, fail: true}
+		router, cacheManager := as.newStaleRouter(t, rep)
+		cacheManager.SetCache(context.Background(), 88, []types.ID{2}, nil, `{"title": "stale"}`, nil, nil)
+
+		time.Sleep(150 * time.Millisecond)
+
+		t.NewStep("Тестирование")
+		for range 2 {
+			apitest.New().
+				Handler(router).
+				Get(path).
+				Query(bh.FeatureIDParam, "88").
+				Query(bh.TagIDParam, "2").
+				Header(middleware.TokenHeaderField, as.userToken(t)).
+				Expect(t).
+				Body(`{"title": "stale"}`).
+				Header(cmw.StaleHeader, "true").
+				Status(http.StatusOK).
+				End()
+
+			time.Sleep(150 * time.Millisecond)
+		}
+
+		t.NewStep("Проверка результатов")
+		t.Require().EqualValues(2, rep.calls.Load())
+
+		apitest.New().
+			Handler(router).
+			Get(path).
+			Query(bh.FeatureIDParam, "88").
+			Query(bh.TagIDParam, "2").
+			Query(cmw.UseLastRevisionParam, "true").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusInternalServerError).
+			End()
+
+		apitest.New().
+			Handler(router).
+			Get(path).
+			Query(bh.FeatureIDParam, "88").
+			Query(bh.TagIDParam, "3").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusInternalServerError).
+			End()
+	})
+}
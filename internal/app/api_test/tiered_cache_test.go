@@ -0,0 +1,102 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/caches"
+	"bannersrv/internal/caches/repository"
+	cr "bannersrv/internal/caches/repository/redis"
+	ct "bannersrv/internal/caches/repository/tiered"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/logger"
+	"context"
+	"time"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/pkg/errors"
+)
+
+const tieredCacheChannel = "tiered-cache-test"
+
+// waitCache ожидает, пока реплика не начнёт отдавать по ключу ожидаемый результат
+func waitCache(t provider.T, cache *ct.TieredCache, key string, expected types.Content, expectedErr error) {
+	deadline := time.Now().Add(2 * time.Second)
+
+	for {
+		content, err := cache.HaveCache(context.Background(), key)
+		if (expectedErr == nil && err == nil && content == expected) ||
+			(expectedErr != nil && errors.Is(err, expectedErr)) {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("cache with key %s returned %s and error %v", key, content, err)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func (as *ApiSuite) TestTieredCache(t provider.T) {
+	t.Title("Тестирование кэша в памяти с рассылкой изменений между репликами")
+	t.NewStep("Инициализация реплик")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := caches.LocalConfig{MaxEntries: 100, MaxBytes: 1 << 20, TTL: 60000, Channel: tieredCacheChannel}
+	remote := cr.NewCashRedis(as.rdsClient)
+
+	first := ct.NewTieredCache(remote, as.rdsClient, cfg, nil, &logger.EmptyLogger{})
+	second := ct.NewTieredCache(remote, as.rdsClient, cfg, nil, &logger.EmptyLogger{})
+
+	go first.Listen(ctx)
+	go second.Listen(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for as.rdsClient.PubSubNumSub(ctx, tieredCacheChannel).Val()[tieredCacheChannel] < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("replicas are not subscribed to channel %s", tieredCacheChannel)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// Подтверждение подписки обрабатывается репликой уже после её регистрации в Redis
+	time.Sleep(100 * time.Millisecond)
+
+	t.Run("Запись читается из памяти реплики без обращения к Redis", func(t provider.T) {
+		t.Require().NoError(first.SetCache(ctx, "tiered-1", `{"title": "first"}`, time.Minute, "index-tiered-1"))
+
+		// Сообщение о сохранении записи не должно удалить её из памяти второй реплики уже после чтения
+		time.Sleep(100 * time.Millisecond)
+		waitCache(t, second, "tiered-1", `{"title": "first"}`, nil)
+
+		t.Require().NoError(as.rdsClient.Del(ctx, "tiered-1").Err())
+
+		content, err := second.HaveCache(ctx, "tiered-1")
+		t.Require().NoError(err)
+		t.Require().EqualValues(`{"title": "first"}`, content)
+	})
+
+	t.Run("Сброс кэша на одной реплике удаляет записи из памяти остальных", func(t provider.T) {
+		t.Require().NoError(first.SetCache(ctx, "tiered-2", `{"title": "first"}`, time.Minute, "index-tiered-2"))
+		t.Require().NoError(first.SetCache(ctx, "tiered-2-1", `{"title": "first"}`, time.Minute, "index-tiered-2"))
+		waitCache(t, second, "tiered-2", `{"title": "first"}`, nil)
+		waitCache(t, second, "tiered-2-1", `{"title": "first"}`, nil)
+
+		t.Require().NoError(first.DeleteIndexedCache(ctx, "index-tiered-2"))
+
+		waitCache(t, second, "tiered-2", "", repository.ErrorCacheMiss)
+		waitCache(t, second, "tiered-2-1", "", repository.ErrorCacheMiss)
+	})
+
+	t.Run("Обновление записи на одной реплике удаляет прежнюю запись из памяти остальных", func(t provider.T) {
+		t.Require().NoError(first.SetCache(ctx, "tiered-3", `{"title": "first"}`, time.Minute, "index-tiered-3"))
+		waitCache(t, second, "tiered-3", `{"title": "first"}`, nil)
+
+		t.Require().NoError(first.SetCache(ctx, "tiered-3", `{"title": "second"}`, time.Minute, "index-tiered-3"))
+
+		waitCache(t, second, "tiered-3", `{"title": "second"}`, nil)
+	})
+}
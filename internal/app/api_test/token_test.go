@@ -0,0 +1,133 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/external/auth"
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/token"
+	"net/http"
+	"time"
+
+	au "bannersrv/external/auth/usecase"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+func (as *ApiSuite) TestTokens(t provider.T) {
+	t.Title("Тестирование проверки подписанных токенов")
+
+	const adminPath = "/api/v1/banner"
+
+	t.Run("Успешное использование токена, выданного методом /token/admin", func(t provider.T) {
+		var tok auth.Token
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/token/admin").
+			Expect(t).
+			Status(http.StatusOK).
+			End().
+			JSON(&tok)
+
+		apitest.New().
+			Handler(as.router).
+			Get(adminPath).
+			Header(middleware.TokenHeaderField, string(tok)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+	})
+
+	t.Run("Попытка использования токена с префиксом вместо подписи", func(t provider.T) {
+		apitest.New().
+			Handler(as.router).
+			Get(adminPath).
+			Header(middleware.TokenHeaderField, "admin-forged").
+			Expect(t).
+			Status(http.StatusUnauthorized).
+			End()
+	})
+
+	t.Run("Попытка использования токена, подписанного другим ключом", func(t provider.T) {
+		cfg := tokenConfig
+		cfg.Secret = "another-secret"
+
+		issuer, err := au.NewAuthUsecase(&cfg)
+		t.Require().NoError(err)
+
+		tok, err := issuer.GetAdminToken(nil)
+		t.Require().NoError(err)
+
+		apitest.New().
+			Handler(as.router).
+			Get(adminPath).
+			Header(middleware.TokenHeaderField, string(tok)).
+			Expect(t).
+			Status(http.StatusUnauthorized).
+			End()
+	})
+
+	t.Run("Попытка использования токена с другой аудиторией", func(t provider.T) {
+		cfg := tokenConfig
+		cfg.Audience = "another-service"
+
+		issuer, err := au.NewAuthUsecase(&cfg)
+		t.Require().NoError(err)
+
+		tok, err := issuer.GetAdminToken(nil)
+		t.Require().NoError(err)
+
+		apitest.New().
+			Handler(as.router).
+			Get(adminPath).
+			Header(middleware.TokenHeaderField, string(tok)).
+			Expect(t).
+			Status(http.StatusUnauthorized).
+			End()
+	})
+
+	t.Run("Попытка использования просроченного токена", func(t provider.T) {
+		tok, err := signTestToken(&tokenConfig, jwt.MapClaims{
+			"sub":  "expired",
+			"iss":  tokenConfig.Issuer,
+			"aud":  tokenConfig.Audience,
+			"exp":  time.Now().Add(-time.Minute).Unix(),
+			"role": tokenConfig.AdminRole,
+		})
+		t.Require().NoError(err)
+
+		apitest.New().
+			Handler(as.router).
+			Get(adminPath).
+			Header(middleware.TokenHeaderField, tok).
+			Expect(t).
+			Status(http.StatusUnauthorized).
+			End()
+	})
+
+	t.Run("Успешное использование токена с массивом ролей", func(t provider.T) {
+		tok, err := signTestToken(&tokenConfig, jwt.MapClaims{
+			"sub":  "multirole",
+			"iss":  tokenConfig.Issuer,
+			"aud":  tokenConfig.Audience,
+			"exp":  time.Now().Add(time.Minute).Unix(),
+			"role": []string{tokenConfig.UserRole, tokenConfig.AdminRole},
+		})
+		t.Require().NoError(err)
+
+		apitest.New().
+			Handler(as.router).
+			Get(adminPath).
+			Header(middleware.TokenHeaderField, tok).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+	})
+}
+
+func signTestToken(cfg *token.Config, claims jwt.MapClaims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.Secret))
+}
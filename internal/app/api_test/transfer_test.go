@@ -0,0 +1,308 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	br "bannersrv/internal/banner/repository"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+// exportBanners выгружает баннеры от имени админа с токеном token, проверяет последнюю строку выгрузки
+// и возвращает выгрузку и разобранные баннеры
+func (as *ApiSuite) exportBanners(t provider.T, token string) (string, []response.Banner) {
+	resp := apitest.New().
+		Handler(as.router).
+		Get("/api/v1/banner/export").
+		Header(middleware.TokenHeaderField, token).
+		Expect(t).
+		Header("Content-Type", "application/x-ndjson").
+		Status(http.StatusOK).
+		End()
+
+	body, err := io.ReadAll(resp.Response.Body)
+	t.Require().NoError(err)
+
+	lines := bytes.Split(bytes.TrimSpace(body), []byte("\n"))
+	bnrs := make([]response.Banner, 0, len(lines)-1)
+
+	for _, line := range lines[:len(lines)-1] {
+		var bnr response.Banner
+
+		t.Require().NoError(json.Unmarshal(line, &bnr))
+
+		bnrs = append(bnrs, bnr)
+	}
+
+	var trailer response.ExportTrailer
+
+	t.Require().NoError(json.Unmarshal(lines[len(lines)-1], &trailer))
+	t.Require().Equal(response.ExportTrailer{Done: true, Count: uint64(len(bnrs))}, trailer)
+
+	return string(body), bnrs
+}
+
+func (as *ApiSuite) TestExportBanners(t provider.T) {
+	t.Title("Тестирование апи метода ExportBanners: GET /banner/export")
+
+	t.NewStep("Инициализация тестовых данных")
+
+	firstID, err := as.bannerRepository.CreateBanner(context.Background(), 60, []types.ID{2, 1},
+		`{"title": "first"}`, true, nil, 0)
+	t.Require().NoError(err)
+
+	secondID, err := as.bannerRepository.CreateBanner(context.Background(), 61, []types.ID{1},
+		`{"title": "second"}`, false, nil, 0)
+	t.Require().NoError(err)
+
+	t.Run("Выгрузка всех баннеров", func(t provider.T) {
+		t.NewStep("Тестирование")
+		_, bnrs := as.exportBanners(t, as.adminToken(t))
+
+		t.NewStep("Проверка результатов")
+		t.Require().Len(bnrs, 2)
+		t.Require().Equal(firstID, bnrs[0].ID)
+		t.Require().EqualValues(60, bnrs[0].FeatureID)
+		t.Require().Equal([]types.ID{1, 2}, bnrs[0].TagIDs)
+		t.Require().True(bnrs[0].IsActive)
+		t.Require().Len(bnrs[0].Versions, 1)
+		t.Require().JSONEq(`{"title": "first"}`, string(bnrs[0].Versions[0].Content))
+		t.Require().Equal(secondID, bnrs[1].ID)
+		t.Require().False(bnrs[1].IsActive)
+	})
+
+	t.Run("Выгрузка админом с ограниченным доступом", func(t provider.T) {
+		t.NewStep("Тестирование")
+		_, bnrs := as.exportBanners(t, as.scopedAdminToken(t, &access.Scope{FeatureIDs: []types.ID{61}}))
+
+		t.NewStep("Проверка результатов")
+		t.Require().Len(bnrs, 1)
+		t.Require().Equal(secondID, bnrs[0].ID)
+	})
+}
+
+func (as *ApiSuite) TestImportBanners(t provider.T) {
+	t.Title("Тестирование апи метода ImportBanners: POST /banner/import")
+	const path = "/api/v1/banner/import"
+
+	t.Run("Замена всех баннеров выгрузкой", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 62, []types.ID{1},
+			`{"title": "exported"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		export, _ := as.exportBanners(t, as.adminToken(t))
+
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Query(bh.ImportModeParam, "replace").
+			Body(export).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Body(`{"mode": "replace", "dry_run": false, "applied": true,
+				"created": 1, "updated": 0, "deleted": 1, "errors": []}`).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+		_, bnrs := as.exportBanners(t, as.adminToken(t))
+		t.Require().Len(bnrs, 1)
+		t.Require().Equal(bannerID, bnrs[0].ID)
+
+		as.requestUserBanner(t, "62", "1", http.StatusOK)
+	})
+
+	t.Run("Проверочный импорт с конфликтом по паре фича-тэг", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		_, err := as.bannerRepository.CreateBanner(context.Background(), 63, []types.ID{1},
+			`{"title": "existing"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		body := `{"feature_id": 64, "tag_ids": [1], "is_active": true, "versions": [{"version": 1, "content": {}}]}
+{"feature_id": 63, "tag_ids": [1], "is_active": true, "versions": [{"version": 1, "content": {}}]}`
+
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Query(bh.ImportDryRunParam, "true").
+			Body(body).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Body(fmt.Sprintf(`{"mode": "create", "dry_run": true, "applied": false,
+				"created": 1, "updated": 0, "deleted": 0, "errors": [{"line": 2, "error": %q}]}`,
+				br.ErrorBannerConflictExists.Error())).
+			Status(http.StatusUnprocessableEntity).
+			End()
+
+		t.NewStep("Проверка результатов")
+		as.requestUserBanner(t, "64", "1", http.StatusNotFound)
+	})
+
+	t.Run("Замена баннера по идентификатору", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 65, []types.ID{1},
+			`{"title": "old"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		body := fmt.Sprintf(`{"banner_id": %d, "feature_id": 65, "tag_ids": [2], "is_active": true, `+
+			`"versions": [{"version": 1, "content": {"title": "old"}}, {"version": 2, "content": {"title": "new"}}]}`,
+			bannerID)
+
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Query(bh.ImportModeParam, "upsert").
+			Body(body).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Body(`{"mode": "upsert", "dry_run": false, "applied": true,
+				"created": 0, "updated": 1, "deleted": 0, "errors": []}`).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+		as.requestUserBanner(t, "65", "1", http.StatusNotFound)
+
+		apitest.New().
+			Handler(as.router).
+			Get("/api/v1/user_banner").
+			Query(bh.FeatureIDParam, "65").
+			Query(bh.TagIDParam, "2").
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Body(`{"title": "new"}`).
+			Status(http.StatusOK).
+			End()
+	})
+
+	t.Run("Замена баннера с архивными версиями", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		bannerID, err := as.bannerRepository.CreateBanner(context.Background(), 137, []types.ID{1},
+			`{"title": "old_1"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		importBody := func(prefix string) string {
+			return fmt.Sprintf(`{"banner_id": %d, "feature_id": 137, "tag_ids": [1], "is_active": true, `+
+				`"retention": 1, "versions": [{"version": 1, "content": {"title": "%[2]s_1"}}, `+
+				`{"version": 2, "content": {"title": "%[2]s_2"}}, {"version": 3, "content": {"title": "%[2]s_3"}}]}`,
+				bannerID, prefix)
+		}
+
+		for _, prefix := range []string{"old", "new"} {
+			apitest.New().
+				Handler(as.router).
+				Post(path).
+				Query(bh.ImportModeParam, "upsert").
+				Body(importBody(prefix)).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
+				Expect(t).
+				Body(`{"mode": "upsert", "dry_run": false, "applied": true,
+					"created": 0, "updated": 1, "deleted": 0, "errors": []}`).
+				Status(http.StatusOK).
+				End()
+		}
+
+		t.NewStep("Проверка результатов")
+		for version := 1; version <= 2; version++ {
+			resp := apitest.New().
+				Handler(as.router).
+				Getf("/api/v1/banner/%d/versions/%d", bannerID, version).
+				Header(middleware.TokenHeaderField, as.adminToken(t)).
+				Expect(t).
+				Status(http.StatusOK).
+				End()
+
+			var archived response.Version
+			resp.JSON(&archived)
+
+			t.Require().True(archived.Archived)
+			t.Require().JSONEq(fmt.Sprintf(`{"title": "new_%d"}`, version), string(archived.Content))
+		}
+
+		as.requestUserBanner(t, "137", "1", http.StatusOK)
+	})
+
+	t.Run("Импорт с некорректной строкой", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body("{\n").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusUnprocessableEntity).
+			End()
+	})
+
+	t.Run("Попытка импорта оборванной выгрузки", func(t provider.T) {
+		const line = `{"feature_id": 67, "tag_ids": [1], "is_active": true, "versions": [{"version": 1, "content": {}}]}`
+
+		t.NewStep("Тестирование выгрузки, оборванной с ошибкой")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body(line+"\n"+`{"done": false, "count": 1, "error": "request processing time exceeded"}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+
+		t.NewStep("Тестирование выгрузки с другим числом баннеров")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body(line+"\n"+`{"done": true, "count": 2}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+
+		as.requestUserBanner(t, "67", "1", http.StatusNotFound)
+	})
+
+	t.Run("Попытка замены баннеров админом с ограниченным доступом", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Query(bh.ImportModeParam, "replace").
+			Body(`{"feature_id": 66, "tag_ids": [1], "is_active": true, "versions": [{"version": 1, "content": {}}]}`).
+			Header(middleware.TokenHeaderField, as.scopedAdminToken(t, &access.Scope{FeatureIDs: []types.ID{66}})).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+
+	t.Run("Попытка импорта с некорректным режимом", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Query(bh.ImportModeParam, "merge").
+			Body(`{"feature_id": 66, "tag_ids": [1], "is_active": true, "versions": [{"version": 1, "content": {}}]}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+}
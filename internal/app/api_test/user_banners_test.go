@@ -0,0 +1,89 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"net/http"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+func (as *ApiSuite) TestGetUserBanners(t provider.T) {
+	t.Title("Тестирование апи метода GetUserBanners: POST /user_banners")
+	const path = "/api/v1/user_banners"
+
+	t.Run("Получение баннеров нескольких фич", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		_, err := as.bannerRepository.CreateBanner(context.Background(), 70, []types.ID{5},
+			`{"title": "first"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		_, err = as.bannerRepository.CreateBanner(context.Background(), 71, []types.ID{5},
+			`{"title": "second"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		_, err = as.bannerRepository.CreateBanner(context.Background(), 72, []types.ID{5},
+			`{"title": "inactive"}`, false, nil, 0)
+		t.Require().NoError(err)
+
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body(`{"tag_id": 5, "feature_ids": [70, 71, 72, 73]}`).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Body(`{
+				"70": {"status": 200, "content": {"title": "first"}},
+				"71": {"status": 200, "content": {"title": "second"}},
+				"72": {"status": 404},
+				"73": {"status": 404}
+			}`).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+		t.Require().EqualValues(2, as.rdsClient.Exists(context.Background(), "70-5", "71-5").Val())
+
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body(`{"tag_id": 5, "feature_ids": [70, 70, 71], "use_last_revision": true}`).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Body(`{
+				"70": {"status": 200, "content": {"title": "first"}},
+				"71": {"status": 200, "content": {"title": "second"}}
+			}`).
+			Status(http.StatusOK).
+			End()
+	})
+
+	t.Run("Попытка получения баннеров без фич", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body(`{"tag_id": 5, "feature_ids": []}`).
+			Header(middleware.TokenHeaderField, as.userToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+
+	t.Run("Попытка получения баннеров без авторизации", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Body(`{"tag_id": 5, "feature_ids": [70]}`).
+			Expect(t).
+			Status(http.StatusUnauthorized).
+			End()
+	})
+}
@@ -0,0 +1,149 @@
+//go:build integration
+
+package api_test
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
+	cm "bannersrv/internal/caches/manager"
+	cr "bannersrv/internal/caches/repository/redis"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/logger"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/steinfletcher/apitest"
+)
+
+// requestUserBanner запрашивает баннер пользователем и проверяет статус ответа
+func (as *ApiSuite) requestUserBanner(t provider.T, featureID, tagID string, status int) {
+	apitest.New().
+		Handler(as.router).
+		Get("/api/v1/user_banner").
+		Query(bh.FeatureIDParam, featureID).
+		Query(bh.TagIDParam, tagID).
+		Header(middleware.TokenHeaderField, as.userToken(t)).
+		Expect(t).
+		Status(status).
+		End()
+}
+
+func (as *ApiSuite) TestWarmCache(t provider.T) {
+	t.Title("Тестирование апи метода WarmCache: POST /cache/warm")
+	const path = "/api/v1/cache/warm"
+
+	t.Run("Прогрев кэша самыми запрашиваемыми активными баннерами", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		_, err := as.bannerRepository.CreateBanner(context.Background(), 96, []types.ID{1},
+			`{"title": "popular"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		_, err = as.bannerRepository.CreateBanner(context.Background(), 96, []types.ID{2},
+			`{"title": "cached"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		inactiveID, err := as.bannerRepository.CreateBanner(context.Background(), 96, []types.ID{3},
+			`{"title": "inactive"}`, true, nil, 0)
+		t.Require().NoError(err)
+
+		for _, tagID := range []string{"1", "2", "3"} {
+			as.requestUserBanner(t, "96", tagID, http.StatusOK)
+		}
+
+		// Запросы несуществующих баннеров не учитываются
+		as.requestUserBanner(t, "96", "4", http.StatusNotFound)
+
+		apitest.New().
+			Handler(as.router).
+			Patchf("/api/v1/banner/%d", inactiveID).
+			Body(`{"is_active": false}`).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusOK).
+			End()
+
+		// Кэш баннера по первому тэгу потерян, например после сброса Redis
+		t.Require().NoError(as.rdsClient.Del(context.Background(), "96-1").Err())
+
+		// Запросы сохраняются в Redis в фоне
+		time.Sleep(200 * time.Millisecond)
+
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Body(`{"warmed": 1, "cached": 1, "skipped": 1}`).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+		cacheManager := cm.NewCacheManager(cr.NewCashRedis(as.rdsClient), ttlConfig, nil, &logger.EmptyLogger{})
+		entry, err := cacheManager.HaveCache(context.Background(), 96, []types.ID{1}, nil)
+		t.Require().NoError(err)
+		t.Require().EqualValues(`{"title": "popular"}`, entry.Content)
+
+		t.Require().Zero(as.rdsClient.Exists(context.Background(), "96-3").Val())
+	})
+
+	t.Run("Прогрев ограничен числом самых популярных пар", func(t provider.T) {
+		t.NewStep("Инициализация тестовых данных")
+
+		for _, tagID := range []types.ID{1, 2} {
+			_, err := as.bannerRepository.CreateBanner(context.Background(), 94, []types.ID{tagID},
+				`{"title": "popular"}`, true, nil, 0)
+			t.Require().NoError(err)
+		}
+
+		as.requestUserBanner(t, "94", "1", http.StatusOK)
+		for range 3 {
+			as.requestUserBanner(t, "94", "2", http.StatusOK)
+		}
+
+		time.Sleep(200 * time.Millisecond)
+		t.Require().NoError(as.rdsClient.Del(context.Background(), "94-1", "94-2").Err())
+
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Query(bh.LimitParam, "1").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Body(`{"warmed": 1, "cached": 0, "skipped": 0}`).
+			Status(http.StatusOK).
+			End()
+
+		t.NewStep("Проверка результатов")
+		t.Require().EqualValues(1, as.rdsClient.Exists(context.Background(), "94-2").Val())
+		t.Require().Zero(as.rdsClient.Exists(context.Background(), "94-1").Val())
+	})
+
+	t.Run("Попытка прогрева админом с ограниченным доступом", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Header(middleware.TokenHeaderField, as.scopedAdminToken(t, &access.Scope{FeatureIDs: []types.ID{96}})).
+			Expect(t).
+			Status(http.StatusForbidden).
+			End()
+	})
+
+	t.Run("Попытка прогрева с некорректным лимитом", func(t provider.T) {
+		t.NewStep("Тестирование")
+		apitest.New().
+			Handler(as.router).
+			Post(path).
+			Query(bh.LimitParam, "many").
+			Header(middleware.TokenHeaderField, as.adminToken(t)).
+			Expect(t).
+			Status(http.StatusBadRequest).
+			End()
+	})
+}
@@ -4,7 +4,13 @@ import (
 	ah "bannersrv/external/auth/delivery/http/v1/handlers"
 	au "bannersrv/external/auth/usecase"
 	"bannersrv/internal/app/config"
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/banner"
+	"bannersrv/internal/caches"
+	"bannersrv/internal/pkg/lock"
+	"bannersrv/internal/pkg/metrics"
 	"bannersrv/internal/pkg/metrics/prometheus"
+	"bannersrv/internal/pkg/ratelimit"
 	"bannersrv/pkg/logger"
 	"bannersrv/pkg/server"
 	"context"
@@ -14,15 +20,23 @@ import (
 	"syscall"
 	"time"
 
+	kh "bannersrv/internal/apikey/delivery/http/v1/handlers"
+	kp "bannersrv/internal/apikey/repository/postgres"
+	ku "bannersrv/internal/apikey/usecase"
 	v1 "bannersrv/internal/app/delivery/http/v1"
 	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
 	bp "bannersrv/internal/banner/repository/postgres"
 	bu "bannersrv/internal/banner/usecase"
 	cm "bannersrv/internal/caches/manager"
+	cf "bannersrv/internal/caches/repository/failover"
+	cmem "bannersrv/internal/caches/repository/memory"
 	cr "bannersrv/internal/caches/repository/redis"
+	ct "bannersrv/internal/caches/repository/tiered"
+	tu "bannersrv/internal/token/usecase"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
 	"github.com/redis/go-redis/v9"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -65,20 +79,53 @@ func initDatabases(cfg *config.Config, l logger.Interface) *databases {
 
 	rds := redis.NewClient(opt)
 
+	// Без Redis сервис запускается с кэшем в памяти и переключается на Redis, когда тот станет доступен
 	if err = rds.Ping(context.Background()).Err(); err != nil {
-		pg.Close()
-		l.Fatal("[App] Init - can't check connection to redis with error: %s", err)
+		l.Warn("[App] Init - can't check connection to redis, cache works in memory until it is available: %s", err)
+	} else {
+		l.Info("[App] Init - success check connection to redis")
 	}
 
-	l.Info("[App] Init - success check connection to redis")
-
 	return &databases{
 		pg:  pg,
 		rds: rds,
 	}
 }
 
-func initRoutes(mode config.Mode, dbs *databases, l logger.Interface) (*gin.Engine, error) {
+// initCacheRepository создаёт выбранное в конфигурации хранилище кэша баннеров. Кэш в Redis при его
+// недоступности переключается на кэш в памяти процесса, а перед самим Redis может стоять кэш в памяти,
+// включаемый заданием его размера.
+func initCacheRepository(ctx context.Context, cfg *config.Config, rds *redis.Client,
+	metricsManager metrics.Manager, l logger.Interface,
+) (caches.Repository, error) {
+	memoryCache := cmem.NewMemoryCache(cfg.Cache.Memory, metricsManager)
+
+	switch cfg.Cache.Backend {
+	case caches.MemoryBackend:
+		return memoryCache, nil
+	case caches.RedisBackend:
+	default:
+		return nil, errors.Errorf("unknown cache backend %s", cfg.Cache.Backend)
+	}
+
+	redisCache := cr.NewCashRedis(rds)
+
+	var primary caches.Repository = redisCache
+
+	if cfg.Cache.Local.MaxEntries != 0 {
+		tieredCache := ct.NewTieredCache(redisCache, rds, cfg.Cache.Local, metricsManager, l)
+		go tieredCache.Listen(ctx)
+
+		primary = tieredCache
+	}
+
+	failoverCache := cf.NewFailoverCache(primary, memoryCache, rds, cfg.Cache.Failover, metricsManager, l)
+	go failoverCache.Run(ctx)
+
+	return failoverCache, nil
+}
+
+func initRoutes(ctx context.Context, cfg *config.Config, dbs *databases, l logger.Interface) (*gin.Engine, error) {
 	// metrics
 	metricsManager := prometheus.NewPrometheusMetrics("main")
 	if err := metricsManager.SetupMonitoring(); err != nil {
@@ -87,21 +134,79 @@ func initRoutes(mode config.Mode, dbs *databases, l logger.Interface) (*gin.Engi
 
 	// Repository
 	bannerRepository := bp.NewBannerRepository(dbs.pg)
-	cacheRepository := cr.NewCashRedis(dbs.rds)
+	apiKeyRepository := kp.NewAPIKeyRepository(dbs.pg)
+
+	cacheRepository, err := initCacheRepository(ctx, cfg, dbs.rds, metricsManager, l)
+	if err != nil {
+		return nil, err
+	}
 
 	// Use-cases
-	bannerUsecase := bu.NewBannerUsecase(bannerRepository)
-	cacheManager := cm.NewCacheManager(cacheRepository)
-	authService := au.NewAuthUsecase()
+	cacheManager := cm.NewCacheManager(cacheRepository, cfg.Cache.TTL, metricsManager, l)
+	popularityManager := cm.NewPopularityManager(cr.NewPopularityRedis(dbs.rds, cfg.Cache.Popularity),
+		cfg.Cache.Popularity, l)
+
+	go popularityManager.Run(ctx)
+
+	// Запросы баннера пользователем объединяются между репликами только при включённой блокировке
+	var locker bu.Locker
+	if cfg.Coalescing.Lock {
+		locker = lock.NewRedisLocker(dbs.rds)
+	}
+
+	bannerUsecase := bu.NewBannerUsecase(bannerRepository, cacheManager, popularityManager, locker, &cfg.Coalescing)
+
+	// После деплоя или сброса Redis популярные баннеры сохраняются в кэш до первых запросов пользователей
+	if cfg.Cache.Warm.OnStartup {
+		go warmCache(ctx, bannerUsecase, cfg.Cache.Warm.Limit, l)
+	}
+
+	apiKeyUsecase := ku.NewAPIKeyUsecase(apiKeyRepository)
+
+	jwtService, err := tu.NewJWTUsecase(&cfg.Token)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create token service")
+	}
+
+	tokenService := tu.NewCompositeUsecase(jwtService, tu.NewAPIKeyUsecase(apiKeyRepository, &cfg.Token))
 
 	// Handlers
-	bannerHandlers := bh.NewBannerHandlers(bannerUsecase, cacheManager)
-	authHandlers := ah.NewAuthHandlers(authService)
+	bannerHandlers := bh.NewBannerHandlers(bannerUsecase)
+	apiKeyHandlers := kh.NewAPIKeyHandlers(apiKeyUsecase)
+
+	// Выдача токенов без проверки доступна только в режиме отладки
+	var authHandlers *ah.AuthHandlers
+
+	if cfg.Mode.IsDebug() {
+		authService, err := au.NewAuthUsecase(&cfg.Token)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't create token issuer")
+		}
+
+		authHandlers = ah.NewAuthHandlers(authService)
+	}
+
+	// Middlewares
+	rateLimits := middleware.NewRateLimits(ratelimit.NewRedisLimiter(dbs.rds), cfg.RateLimit, metricsManager)
 
 	// routes
-	routes := PrepareRoutes(bannerHandlers, cacheManager, authService, authHandlers)
+	routes := PrepareRoutes(bannerHandlers, apiKeyHandlers, cacheManager, popularityManager, bannerUsecase,
+		tokenService, authHandlers, rateLimits, metricsManager, time.Duration(cfg.TransferTimeout)*time.Millisecond)
+
+	return v1.NewRouter("/api", routes, cfg.Mode,
+		time.Duration(cfg.RequestTimeout)*time.Millisecond, cfg.TrustedProxies, l, metricsManager)
+}
 
-	return v1.NewRouter("/api", routes, mode, l, metricsManager)
+func warmCache(ctx context.Context, usecase banner.Usecase, limit uint64, l logger.Interface) {
+	result, err := usecase.WarmCache(ctx, &limit)
+	if err != nil {
+		l.Error(errors.Wrap(err, "[App] Init - can't warm cache"))
+
+		return
+	}
+
+	l.Info("[App] Init - cache was warmed with %d banners, %d were already cached, %d were skipped",
+		result.Warmed, result.Cached, result.Skipped)
 }
 
 func Run(cfg *config.Config) {
@@ -121,8 +226,11 @@ func Run(cfg *config.Config) {
 	dbs := initDatabases(cfg, l)
 	defer dbs.pg.Close()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// Routes
-	router, err := initRoutes(cfg.Mode, dbs, l)
+	router, err := initRoutes(ctx, cfg, dbs, l)
 	if err != nil {
 		l.Fatal("[App] Init - init handler error: %s", err)
 	}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"bannersrv/internal/banner"
+	"bannersrv/internal/caches"
+	"bannersrv/internal/token"
 	"bannersrv/pkg/logger"
 
 	"github.com/ilyakaznacheev/cleanenv"
@@ -18,11 +21,18 @@ const (
 
 type (
 	Config struct {
-		Port       string     `yaml:"port"`
-		Postgres   PG         `yaml:"postgres"`
-		Redis      Redis      `yaml:"redis"`
-		LoggerInfo LoggerInfo `yaml:"logger"`
-		Mode       Mode       `yaml:"mode"`
+		Port            string                  `yaml:"port"`
+		Postgres        PG                      `yaml:"postgres"`
+		Redis           Redis                   `yaml:"redis"`
+		LoggerInfo      LoggerInfo              `yaml:"logger"`
+		Mode            Mode                    `yaml:"mode"`
+		RequestTimeout  uint64                  `yaml:"request_timeout" default:"0"`
+		TransferTimeout uint64                  `yaml:"transfer_timeout" default:"600000"`
+		Token           token.Config            `yaml:"token"`
+		RateLimit       RateLimit               `yaml:"rate_limit"`
+		TrustedProxies  []string                `yaml:"trusted_proxies"`
+		Cache           caches.Config           `yaml:"cache"`
+		Coalescing      banner.CoalescingConfig `yaml:"coalescing"`
 	}
 
 	LoggerInfo struct {
@@ -43,8 +53,27 @@ type (
 	Redis struct {
 		URL string `yaml:"url"`
 	}
+
+	// RateLimit ограничения частоты запросов для пользовательских и админских методов
+	RateLimit struct {
+		User  RateLimitGroup `yaml:"user"`
+		Admin RateLimitGroup `yaml:"admin"`
+	}
+
+	// RateLimitGroup число запросов за период Period миллисекунд от одного владельца токена и с одного IP адреса,
+	// нулевое значение отключает соответствующее ограничение
+	RateLimitGroup struct {
+		TokenLimit uint64 `yaml:"token_limit" default:"0"`
+		IPLimit    uint64 `yaml:"ip_limit" default:"0"`
+		Period     uint64 `yaml:"period" default:"1000"`
+	}
 )
 
+// IsDebug возвращает true для режимов отладки
+func (m Mode) IsDebug() bool {
+	return m == Debug || m == DebugProf
+}
+
 func NewConfig(path string) (*Config, error) {
 	cfg := &Config{}
 
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"bannersrv/internal/pkg/audit"
 	"bannersrv/internal/pkg/types"
 	"bannersrv/pkg/logger"
 	"time"
@@ -15,6 +16,7 @@ const (
 	RequestID logger.Field = "request_id"
 	Method    logger.Field = "method"
 	URL       logger.Field = "url"
+	Subject   logger.Field = "subject"
 
 	LoggerField types.ContextField = "logger"
 )
@@ -36,6 +38,7 @@ func RequestLogger(l logger.Interface) gin.HandlerFunc {
 
 		lg := l.With(URL, path).With(RequestID, requestID).With(Method, method)
 		c.Set(string(LoggerField), lg)
+		c.Request = c.Request.WithContext(audit.WithRequestID(c.Request.Context(), requestID.String()))
 
 		clientIP := c.ClientIP()
 
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"bannersrv/internal/app/config"
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/pkg/metrics"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const RetryAfterHeader = "Retry-After"
+
+const (
+	UserGroup  = "user"
+	AdminGroup = "admin"
+)
+
+const (
+	tokenLimit = "token"
+	ipLimit    = "ip"
+)
+
+var ErrorRateLimitExceeded = errors.New("too many requests, try again later")
+
+// RateLimiter списывает запрос из общего для реплик лимита key, допускающего limit запросов за period
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit uint64, period time.Duration) (bool, time.Duration, error)
+}
+
+// RateLimits ограничители частоты запросов для групп методов. Ограничители по IP адресу вызываются
+// до проверки токена, чтобы перебор токенов тоже ограничивался, а ограничители по владельцу токена --
+// после проверки, чтобы непроверенные токены не создавали новые лимиты.
+type RateLimits struct {
+	UserIP  gin.HandlerFunc
+	User    gin.HandlerFunc
+	AdminIP gin.HandlerFunc
+	Admin   gin.HandlerFunc
+}
+
+func NewRateLimits(limiter RateLimiter, cfg config.RateLimit, metricsManager metrics.Manager) RateLimits {
+	return RateLimits{
+		UserIP:  IPRateLimit(limiter, UserGroup, cfg.User, metricsManager),
+		User:    SubjectRateLimit(limiter, UserGroup, cfg.User, metricsManager),
+		AdminIP: IPRateLimit(limiter, AdminGroup, cfg.Admin, metricsManager),
+		Admin:   SubjectRateLimit(limiter, AdminGroup, cfg.Admin, metricsManager),
+	}
+}
+
+// IPRateLimit ограничивает частоту запросов группы group с одного IP адреса. Адрес берётся из заголовков
+// прокси, только если запрос пришёл от доверенного прокси, настроенного в роутере.
+func IPRateLimit(limiter RateLimiter, group string, cfg config.RateLimitGroup,
+	metricsManager metrics.Manager,
+) gin.HandlerFunc {
+	return rateLimit(limiter, group, ipLimit, cfg.IPLimit, cfg.Period, metricsManager, (*gin.Context).ClientIP)
+}
+
+// SubjectRateLimit ограничивает частоту запросов группы group одного владельца токена.
+// Должен вызываться после проверки токена, сохраняющей владельца в контекст запроса.
+func SubjectRateLimit(limiter RateLimiter, group string, cfg config.RateLimitGroup,
+	metricsManager metrics.Manager,
+) gin.HandlerFunc {
+	return rateLimit(limiter, group, tokenLimit, cfg.TokenLimit, cfg.Period, metricsManager, hashSubject)
+}
+
+// rateLimit ограничивает частоту запросов группы group с одним ключом лимита name, который возвращает key.
+// При превышении лимита отправляет 429 с заголовком Retry-After. Если лимит не удалось проверить,
+// запрос пропускается, чтобы недоступность Redis не останавливала сервис.
+func rateLimit(limiter RateLimiter, group, name string, limit, periodMs uint64, metricsManager metrics.Manager,
+	key func(c *gin.Context) string,
+) gin.HandlerFunc {
+	period := time.Duration(periodMs) * time.Millisecond
+
+	return func(c *gin.Context) {
+		if limit == 0 {
+			c.Next()
+
+			return
+		}
+
+		l := GetLogger(c)
+
+		allowed, retryAfter, err := limiter.Allow(c.Request.Context(),
+			fmt.Sprintf("rate-%s-%s-%s", group, name, key(c)), limit, period)
+		if err != nil {
+			l.Error(errors.Wrapf(err, "can't check %s rate limit of %s requests", name, group))
+			c.Next()
+
+			return
+		}
+
+		if !allowed {
+			if metricsManager != nil {
+				metricsManager.GetRateLimitHits().WithLabelValues(group, name, c.FullPath()).Inc()
+			}
+
+			c.Header(RetryAfterHeader, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			tools.SendError(c, ErrorRateLimitExceeded, http.StatusTooManyRequests, l)
+			l.Warn("%s rate limit of %s requests exceeded, retry after %s", name, group, retryAfter)
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// hashSubject возвращает хэш владельца токена запроса, чтобы произвольные строки из токенов
+// не попадали в ключи Redis
+func hashSubject(c *gin.Context) string {
+	hash := sha256.Sum256([]byte(GetSubject(c)))
+
+	return hex.EncodeToString(hash[:])
+}
@@ -11,7 +11,8 @@ import (
 const (
 	TokenHeaderField string = "token"
 
-	TokenField types.ContextField = "token"
+	TokenField   types.ContextField = "token"
+	SubjectField types.ContextField = "subject"
 )
 
 // RequestToken проверяет, что в заголовке запроса передан токен и сохраняет его в контекст запроса.
@@ -29,7 +30,7 @@ func RequestToken(c *gin.Context) {
 
 	c.Set(string(TokenField), token)
 
-	l.Info("handle request with token %s", token)
+	l.Info("handle request with token")
 	c.Next()
 }
 
@@ -44,3 +45,16 @@ func GetToken(c *gin.Context) string {
 
 	return ""
 }
+
+// GetSubject возвращает идентификатор владельца токена, сохранённый в контекст после проверки токена
+func GetSubject(c *gin.Context) string {
+	if subject, ok := c.Get(string(SubjectField)); ok {
+		if sb, ok := subject.(string); ok {
+			return sb
+		}
+
+		return ""
+	}
+
+	return ""
+}
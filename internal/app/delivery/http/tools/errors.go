@@ -1,7 +1,10 @@
 package tools
 
 import (
+	"bannersrv/internal/pkg/access"
 	"bannersrv/pkg/logger"
+	"context"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -22,3 +25,42 @@ func SendErrorStatus(c *gin.Context, err error, code int, l logger.Interface) {
 	c.AbortWithStatus(code)
 	l.Info("error %s was sent with status code %d", err, code)
 }
+
+// StatusClientClosedRequest нестандартный код ответа (используется nginx) для запросов,
+// соединение которых было закрыто клиентом до отправки ответа.
+const StatusClientClosedRequest = 499
+
+var (
+	ErrorRequestTimeout  = errors.New("request processing time exceeded")
+	ErrorRequestCanceled = errors.New("request was canceled by client")
+)
+
+// SendContextError отправляет ответ с ошибкой, если err вызвана истечением времени обработки
+// или отменой контекста запроса. Возвращает true, если ответ был отправлен.
+func SendContextError(c *gin.Context, err error, l logger.Interface) bool {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		SendError(c, ErrorRequestTimeout, http.StatusGatewayTimeout, l)
+	case errors.Is(err, context.Canceled):
+		SendErrorStatus(c, ErrorRequestCanceled, StatusClientClosedRequest, l)
+	default:
+		return false
+	}
+
+	l.Warn(errors.Wrap(err, "request context is done"))
+
+	return true
+}
+
+// SendAccessError отправляет ответ с ошибкой, если err вызвана отсутствием у админа доступа к фиче баннера
+// или запретом изменений. Возвращает true, если ответ был отправлен.
+func SendAccessError(c *gin.Context, err error, l logger.Interface) bool {
+	if !errors.Is(err, access.ErrorFeatureForbidden) && !errors.Is(err, access.ErrorReadOnly) &&
+		!errors.Is(err, access.ErrorFullAccess) {
+		return false
+	}
+
+	SendErrorStatus(c, err, http.StatusForbidden, l)
+
+	return true
+}
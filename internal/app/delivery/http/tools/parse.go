@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -74,6 +76,35 @@ func ParseQueryParamToTypesID(c *gin.Context, param string, notPresentedError er
 	return nil, notPresentedError
 }
 
+// ParseQueryParamToTypesIDs преобразует повторяющийся параметр запроса в список types.ID,
+// в каждом повторе значения также могут быть перечислены через запятую
+// Если ошибка notPresentedError установлена в nil, то будет возвращаться nil в качестве ошибки и в качестве значения
+func ParseQueryParamToTypesIDs(c *gin.Context, param string, notPresentedError error,
+	incorrectTypeError error, l logger.Interface,
+) ([]types.ID, error) {
+	rawFields, ok := c.GetQueryArray(param)
+	if !ok {
+		return nil, notPresentedError
+	}
+
+	ids := make([]types.ID, 0, len(rawFields))
+
+	for _, rawField := range rawFields {
+		for _, rawID := range strings.Split(rawField, ",") {
+			id, err := strconv.ParseUint(rawID, base10, size32)
+			if err != nil {
+				l.Error(errors.Wrapf(err, "can't parse query field %s with value %s", param, rawField))
+
+				return nil, incorrectTypeError
+			}
+
+			ids = append(ids, types.ID(id))
+		}
+	}
+
+	return ids, nil
+}
+
 // ParseQueryParamToUint32 преобразует параметр запроса в uint32
 // Если ошибка notPresentedError установлена в nil, то будет возвращаться nil в качестве ошибки и в качестве значения
 func ParseQueryParamToUint32(c *gin.Context, param string, notPresentedError error,
@@ -113,3 +144,22 @@ func ParseQueryParamToUint64(c *gin.Context, param string, notPresentedError err
 
 	return nil, notPresentedError
 }
+
+// ParseQueryParamToTime преобразует параметр запроса в формате RFC 3339 во время
+// Если ошибка notPresentedError установлена в nil, то будет возвращаться nil в качестве ошибки и в качестве значения
+func ParseQueryParamToTime(c *gin.Context, param string, notPresentedError error,
+	incorrectTypeError error, l logger.Interface,
+) (*time.Time, error) {
+	if rawField, ok := c.GetQuery(param); ok {
+		value, err := time.Parse(time.RFC3339, rawField)
+		if err != nil {
+			l.Error(errors.Wrapf(err, "can't parse query field %s with value %s", param, rawField))
+
+			return nil, incorrectTypeError
+		}
+
+		return &value, nil
+	}
+
+	return nil, notPresentedError
+}
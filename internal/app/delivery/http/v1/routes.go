@@ -5,24 +5,31 @@ import (
 	"bannersrv/internal/app/delivery/http/middleware"
 	"bannersrv/internal/pkg/metrics"
 	"bannersrv/pkg/logger"
+	"time"
 
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const version = "v1"
 
+// Route метод апи. Timeout задаёт методу собственное ограничение времени обработки вместо общего,
+// нулевое значение оставляет общее ограничение.
 type Route struct {
 	Method      string
 	Pattern     string
 	HandlerFunc gin.HandlerFunc
 	Middlewares []gin.HandlerFunc
+	Timeout     time.Duration
 }
 
 type Routes []Route
 
-func NewRouter(root string, routes Routes, mode config.Mode,
+// NewRouter создаёт роутер с методами routes. IP адрес клиента берётся из заголовков прокси,
+// только если запрос пришёл с адреса из trustedProxies.
+func NewRouter(root string, routes Routes, mode config.Mode, requestTimeout time.Duration, trustedProxies []string,
 	l logger.Interface, metricsManager metrics.Manager,
 ) (*gin.Engine, error) {
 	if mode == config.Release || mode == config.ReleaseProf {
@@ -31,6 +38,10 @@ func NewRouter(root string, routes Routes, mode config.Mode,
 
 	router := gin.New()
 
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, errors.Wrap(err, "can't set trusted proxies")
+	}
+
 	promHandler := promhttp.Handler()
 
 	router.GET("/metrics", func(c *gin.Context) {
@@ -42,8 +53,13 @@ func NewRouter(root string, routes Routes, mode config.Mode,
 	v1 := rt.Group(version)
 
 	for _, route := range routes {
-		route.Middlewares = append(route.Middlewares, route.HandlerFunc)
-		v1.Handle(route.Method, route.Pattern, route.Middlewares...)
+		timeout := requestTimeout
+		if route.Timeout != 0 {
+			timeout = route.Timeout
+		}
+
+		handlers := append([]gin.HandlerFunc{middleware.RequestTimeout(timeout)}, route.Middlewares...)
+		v1.Handle(route.Method, route.Pattern, append(handlers, route.HandlerFunc)...)
 	}
 
 	if mode == config.DebugProf || mode == config.ReleaseProf {
@@ -4,6 +4,7 @@ import (
 	"bannersrv/internal/app/config"
 	"bannersrv/internal/app/delivery/http/middleware"
 	"bannersrv/internal/caches"
+	"bannersrv/internal/pkg/metrics"
 	"bannersrv/internal/pkg/prepare"
 	"bannersrv/internal/token"
 	"bannersrv/pkg/logger"
@@ -11,9 +12,11 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	ah "bannersrv/external/auth/delivery/http/v1/handlers"
 
+	kh "bannersrv/internal/apikey/delivery/http/v1/handlers"
 	v1 "bannersrv/internal/app/delivery/http/v1"
 	bh "bannersrv/internal/banner/delivery/http/v1/handlers"
 
@@ -63,10 +66,12 @@ func prepareLogger(cfg config.LoggerInfo) (*logger.Logger, *os.File) {
 	return l, logFile
 }
 
-func PrepareRoutes(bannerHandlers *bh.BannerHandlers, cache caches.Manager,
-	tokenService token.Service, authHandlers *ah.AuthHandlers,
+func PrepareRoutes(bannerHandlers *bh.BannerHandlers, apiKeyHandlers *kh.APIKeyHandlers, cache caches.Manager,
+	popularity caches.PopularityTracker, refresher caches.Refresher, tokenService token.Service,
+	authHandlers *ah.AuthHandlers, limits middleware.RateLimits, metricsManager metrics.Manager,
+	transferTimeout time.Duration,
 ) v1.Routes {
-	return v1.Routes{
+	routes := v1.Routes{
 		// "Swagger"
 		v1.Route{
 			Method:      http.MethodGet,
@@ -79,7 +84,9 @@ func PrepareRoutes(bannerHandlers *bh.BannerHandlers, cache caches.Manager,
 			Method:      http.MethodPost,
 			Pattern:     "/banner",
 			HandlerFunc: bannerHandlers.CreateBanner,
-			Middlewares: []gin.HandlerFunc{middleware.RequestToken, tm.WithAdminToken(tokenService)},
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
 		},
 
 		// "GetAdminBanner"
@@ -87,7 +94,51 @@ func PrepareRoutes(bannerHandlers *bh.BannerHandlers, cache caches.Manager,
 			Method:      http.MethodGet,
 			Pattern:     "/banner",
 			HandlerFunc: bannerHandlers.GetAdminBanner,
-			Middlewares: []gin.HandlerFunc{middleware.RequestToken, tm.WithAdminToken(tokenService)},
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "SearchBanners"
+		v1.Route{
+			Method:      http.MethodGet,
+			Pattern:     "/banner/search",
+			HandlerFunc: bannerHandlers.SearchBanners,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "BatchBanners"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/banner/batch",
+			HandlerFunc: bannerHandlers.BatchBanners,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "ExportBanners"
+		v1.Route{
+			Method:      http.MethodGet,
+			Pattern:     "/banner/export",
+			HandlerFunc: bannerHandlers.ExportBanners,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+			Timeout: transferTimeout,
+		},
+
+		// "ImportBanners"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/banner/import",
+			HandlerFunc: bannerHandlers.ImportBanners,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+			Timeout: transferTimeout,
 		},
 
 		// "DeleteBanner"
@@ -95,7 +146,9 @@ func PrepareRoutes(bannerHandlers *bh.BannerHandlers, cache caches.Manager,
 			Method:      http.MethodDelete,
 			Pattern:     "/banner/:" + bh.BannerIDField,
 			HandlerFunc: bannerHandlers.DeleteBanner,
-			Middlewares: []gin.HandlerFunc{middleware.RequestToken, tm.WithAdminToken(tokenService)},
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
 		},
 
 		// "UpdateBanner"
@@ -103,7 +156,9 @@ func PrepareRoutes(bannerHandlers *bh.BannerHandlers, cache caches.Manager,
 			Method:      http.MethodPatch,
 			Pattern:     "/banner/:" + bh.BannerIDField,
 			HandlerFunc: bannerHandlers.UpdateBanner,
-			Middlewares: []gin.HandlerFunc{middleware.RequestToken, tm.WithAdminToken(tokenService)},
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
 		},
 
 		// "GetUserBanner"
@@ -112,8 +167,18 @@ func PrepareRoutes(bannerHandlers *bh.BannerHandlers, cache caches.Manager,
 			Pattern:     "/user_banner",
 			HandlerFunc: bannerHandlers.GetUserBanner,
 			Middlewares: []gin.HandlerFunc{
-				middleware.RequestToken,
-				tm.WithUserToken(tokenService), cm.CacheBanner(cache),
+				middleware.RequestToken, limits.UserIP, tm.WithUserToken(tokenService), limits.User,
+				cm.TrackPopularity(popularity), cm.CacheBanner(cache, refresher, metricsManager),
+			},
+		},
+
+		// "GetUserBanners"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/user_banners",
+			HandlerFunc: bannerHandlers.GetUserBanners,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.UserIP, tm.WithUserToken(tokenService), limits.User,
 			},
 		},
 
@@ -122,22 +187,291 @@ func PrepareRoutes(bannerHandlers *bh.BannerHandlers, cache caches.Manager,
 			Method:      http.MethodDelete,
 			Pattern:     "/filter_banner",
 			HandlerFunc: bannerHandlers.DeleteFilterBanner,
-			Middlewares: []gin.HandlerFunc{middleware.RequestToken, tm.WithAdminToken(tokenService)},
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "GetBannerVersions"
+		v1.Route{
+			Method:      http.MethodGet,
+			Pattern:     "/banner/:" + bh.BannerIDField + "/versions",
+			HandlerFunc: bannerHandlers.GetBannerVersions,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "GetBannerVersion"
+		v1.Route{
+			Method:      http.MethodGet,
+			Pattern:     "/banner/:" + bh.BannerIDField + "/versions/:" + bh.VersionField,
+			HandlerFunc: bannerHandlers.GetBannerVersion,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "RollbackBanner"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/banner/:" + bh.BannerIDField + "/rollback",
+			HandlerFunc: bannerHandlers.RollbackBanner,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "GetDefaultRetention"
+		v1.Route{
+			Method:      http.MethodGet,
+			Pattern:     "/settings/retention",
+			HandlerFunc: bannerHandlers.GetDefaultRetention,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "SetDefaultRetention"
+		v1.Route{
+			Method:      http.MethodPut,
+			Pattern:     "/settings/retention",
+			HandlerFunc: bannerHandlers.SetDefaultRetention,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+			Timeout: transferTimeout,
+		},
+
+		// "GetDrafts"
+		v1.Route{
+			Method:      http.MethodGet,
+			Pattern:     "/banner/:" + bh.BannerIDField + "/drafts",
+			HandlerFunc: bannerHandlers.GetDrafts,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "GetDraft"
+		v1.Route{
+			Method:      http.MethodGet,
+			Pattern:     "/draft/:" + bh.DraftIDField,
+			HandlerFunc: bannerHandlers.GetDraft,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "ApproveDraft"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/draft/:" + bh.DraftIDField + "/approve",
+			HandlerFunc: bannerHandlers.ApproveDraft,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "RejectDraft"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/draft/:" + bh.DraftIDField + "/reject",
+			HandlerFunc: bannerHandlers.RejectDraft,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "PublishDraft"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/draft/:" + bh.DraftIDField + "/publish",
+			HandlerFunc: bannerHandlers.PublishDraft,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "GetAudit"
+		v1.Route{
+			Method:      http.MethodGet,
+			Pattern:     "/audit",
+			HandlerFunc: bannerHandlers.GetAudit,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "CreateExperiment"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/banner/:" + bh.BannerIDField + "/experiment",
+			HandlerFunc: bannerHandlers.CreateExperiment,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "GetExperiment"
+		v1.Route{
+			Method:      http.MethodGet,
+			Pattern:     "/experiment/:" + bh.ExperimentIDField,
+			HandlerFunc: bannerHandlers.GetExperiment,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "PauseExperiment"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/experiment/:" + bh.ExperimentIDField + "/pause",
+			HandlerFunc: bannerHandlers.PauseExperiment,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "ResumeExperiment"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/experiment/:" + bh.ExperimentIDField + "/resume",
+			HandlerFunc: bannerHandlers.ResumeExperiment,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "ConcludeExperiment"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/experiment/:" + bh.ExperimentIDField + "/conclude",
+			HandlerFunc: bannerHandlers.ConcludeExperiment,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "WarmCache"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/cache/warm",
+			HandlerFunc: bannerHandlers.WarmCache,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "InspectCache"
+		v1.Route{
+			Method:      http.MethodGet,
+			Pattern:     "/cache/entry",
+			HandlerFunc: bannerHandlers.InspectCache,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "PurgeCache"
+		v1.Route{
+			Method:      http.MethodDelete,
+			Pattern:     "/cache",
+			HandlerFunc: bannerHandlers.PurgeCache,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
 		},
 
-		// Для эмуляции сервиса выдачи токенов
-		// "GetAdminToken"
+		// "GetFeatureCacheTTL"
 		v1.Route{
 			Method:      http.MethodGet,
-			Pattern:     "/token/admin",
-			HandlerFunc: authHandlers.GetAdminToken,
+			Pattern:     "/feature/:" + bh.FeatureIDField + "/cache_ttl",
+			HandlerFunc: bannerHandlers.GetFeatureCacheTTL,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "SetFeatureCacheTTL"
+		v1.Route{
+			Method:      http.MethodPut,
+			Pattern:     "/feature/:" + bh.FeatureIDField + "/cache_ttl",
+			HandlerFunc: bannerHandlers.SetFeatureCacheTTL,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "DeleteFeatureCacheTTL"
+		v1.Route{
+			Method:      http.MethodDelete,
+			Pattern:     "/feature/:" + bh.FeatureIDField + "/cache_ttl",
+			HandlerFunc: bannerHandlers.DeleteFeatureCacheTTL,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "CreateAPIKey"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/api_key",
+			HandlerFunc: apiKeyHandlers.CreateAPIKey,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
 		},
 
-		// "GetUserToken"
+		// "GetAPIKeys"
 		v1.Route{
 			Method:      http.MethodGet,
-			Pattern:     "/token/user",
-			HandlerFunc: authHandlers.GetUserToken,
+			Pattern:     "/api_key",
+			HandlerFunc: apiKeyHandlers.GetAPIKeys,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "RotateAPIKey"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/api_key/:" + kh.APIKeyIDField + "/rotate",
+			HandlerFunc: apiKeyHandlers.RotateAPIKey,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
+		},
+
+		// "RevokeAPIKey"
+		v1.Route{
+			Method:      http.MethodPost,
+			Pattern:     "/api_key/:" + kh.APIKeyIDField + "/revoke",
+			HandlerFunc: apiKeyHandlers.RevokeAPIKey,
+			Middlewares: []gin.HandlerFunc{
+				middleware.RequestToken, limits.AdminIP, tm.WithAdminToken(tokenService), limits.Admin,
+			},
 		},
 	}
+
+	// Для эмуляции сервиса выдачи токенов, обработчики передаются только в режиме отладки
+	if authHandlers != nil {
+		routes = append(routes,
+			// "GetAdminToken"
+			v1.Route{
+				Method:      http.MethodGet,
+				Pattern:     "/token/admin",
+				HandlerFunc: authHandlers.GetAdminToken,
+			},
+
+			// "GetUserToken"
+			v1.Route{
+				Method:      http.MethodGet,
+				Pattern:     "/token/user",
+				HandlerFunc: authHandlers.GetUserToken,
+			},
+		)
+	}
+
+	return routes
 }
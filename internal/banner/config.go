@@ -0,0 +1,12 @@
+package banner
+
+// CoalescingConfig параметры объединения одинаковых одновременных запросов баннера пользователем при промахе кэша.
+// Общий запрос к базе выполняется не дольше Timeout миллисекунд, 0 -- без ограничения.
+// Если Lock равен true, запросы объединяются и между репликами блокировкой в Redis на LockTTL миллисекунд:
+// пока блокировка захвачена, остальные реплики ожидают появления баннера в кэше.
+// Фоновое обновление устаревшего баннера длится не дольше LockTTL и пропускается, если блокировка занята.
+type CoalescingConfig struct {
+	Timeout uint64 `yaml:"timeout" default:"1000"`
+	Lock    bool   `yaml:"lock" default:"false"`
+	LockTTL uint64 `yaml:"lock_ttl" default:"500"`
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/pkg/slices"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	AuditBannerIDParam = "banner_id"
+	AuditActorParam    = "actor"
+	AuditFromParam     = "from"
+	AuditToParam       = "to"
+)
+
+// GetAudit
+//
+//	@Summary		Получение журнала изменений баннеров.
+//	@Description	|
+//					Возвращает действия админов над баннерами от новых к старым с состоянием баннера
+//					до и после изменения. Время фильтрации задаётся в формате RFC 3339, граница to не включается.
+//	@Tags			audit
+//	@Param			banner_id	query	integer	false	"Идентификатор баннера"
+//	@Param			actor		query	string	false	"Админ, выполнивший действие"
+//	@Param			from		query	string	false	"Начало периода"	Format(date-time)
+//	@Param			to			query	string	false	"Конец периода"		Format(date-time)
+//	@Param			limit		query	integer	false	"Лимит"
+//	@Param			offset		query	integer	false	"Оффсет"
+//	@Produce		json
+//	@Success		200	{array}		response.AuditEntry	"Список записей журнала"
+//	@Failure		400	{object}	tools.Error			"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/audit [get]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) GetAudit(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	var filter entity.AuditFilter
+
+	var err error
+
+	filter.BannerID, err = tools.ParseQueryParamToTypesID(c, AuditBannerIDParam, nil, ErrorBannerIDIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	if actor, ok := c.GetQuery(AuditActorParam); ok {
+		filter.Actor = &actor
+	}
+
+	filter.From, err = tools.ParseQueryParamToTime(c, AuditFromParam, nil, ErrorFromIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	filter.To, err = tools.ParseQueryParamToTime(c, AuditToParam, nil, ErrorToIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	limit, err := tools.ParseQueryParamToUint64(c, LimitParam, nil, ErrorLimitIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	offset, err := tools.ParseQueryParamToUint64(c, OffsetParam, nil, ErrorOffsetIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	entries, err := bh.usecase.GetAudit(c.Request.Context(), &filter, offset, limit)
+	if err != nil {
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't get audit"))
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, slices.Map(entries, func(entry *models.AuditEntry) response.AuditEntry {
+		return *response.FromModelAuditEntry(entry)
+	}), l)
+}
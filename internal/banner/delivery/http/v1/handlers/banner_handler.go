@@ -6,8 +6,8 @@ import (
 	"bannersrv/internal/banner"
 	"bannersrv/internal/banner/delivery/http/v1/models/request"
 	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/banner/entity"
 	"bannersrv/internal/banner/models"
-	"bannersrv/internal/caches"
 	"bannersrv/internal/pkg/types"
 	"bannersrv/pkg/slices"
 	"net/http"
@@ -27,15 +27,21 @@ const (
 	VersionParam   = "version"
 	LimitParam     = "limit"
 	OffsetParam    = "offset"
+	StateParam     = "state"
+	UserIDParam    = "user_id"
+)
+
+const (
+	ExperimentHeader = "X-Experiment-Id"
+	VariantHeader    = "X-Experiment-Variant"
 )
 
 type BannerHandlers struct {
 	usecase banner.Usecase
-	cache   caches.Manager
 }
 
-func NewBannerHandlers(usecase banner.Usecase, cache caches.Manager) *BannerHandlers {
-	return &BannerHandlers{usecase: usecase, cache: cache}
+func NewBannerHandlers(usecase banner.Usecase) *BannerHandlers {
+	return &BannerHandlers{usecase: usecase}
 }
 
 // CreateBanner
@@ -52,6 +58,7 @@ func NewBannerHandlers(usecase banner.Usecase, cache caches.Manager) *BannerHand
 //	@Failure		403	"Пользователь не имеет доступа"
 //	@Failure		409	"Баннер с указанной парой id фичи и ia тэга уже существует"
 //	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
 //	@Router			/banner [post]
 //
 //	@Security		AdminToken
@@ -66,8 +73,8 @@ func (bh *BannerHandlers) CreateBanner(c *gin.Context) {
 		return
 	}
 
-	createdID, err := bh.usecase.CreateBanner(createBanner.TagsIDs, createBanner.FeatureID,
-		createBanner.Content, createBanner.IsActive)
+	createdID, err := bh.usecase.CreateBanner(c.Request.Context(), createBanner.TagsIDs, createBanner.FeatureID,
+		createBanner.Content, createBanner.IsActive, createBanner.ToScheduleModel(), createBanner.Priority)
 	if err != nil {
 		if errors.Is(err, br.ErrorBannerConflictExists) {
 			tools.SendErrorStatus(c, err, http.StatusConflict, l)
@@ -75,6 +82,20 @@ func (bh *BannerHandlers) CreateBanner(c *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, br.ErrorBannerScheduleIncorrect) {
+			tools.SendError(c, br.ErrorBannerScheduleIncorrect, http.StatusBadRequest, l)
+
+			return
+		}
+
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
 		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
 		l.Error(errors.Wrapf(err, "can't create banner"))
 
@@ -97,6 +118,7 @@ func (bh *BannerHandlers) CreateBanner(c *gin.Context) {
 //	@Failure		403	"Пользователь не имеет доступа"
 //	@Failure		404	"Баннер с данным id не найден"
 //	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
 //	@Router			/banner/{id} [delete]
 //
 //	@Security		AdminToken
@@ -111,13 +133,21 @@ func (bh *BannerHandlers) DeleteBanner(c *gin.Context) {
 		return
 	}
 
-	if err := bh.usecase.DeleteBanner(types.ID(id)); err != nil {
+	if err := bh.usecase.DeleteBanner(c.Request.Context(), types.ID(id)); err != nil {
 		if errors.Is(err, br.ErrorBannerNotFound) {
 			tools.SendErrorStatus(c, err, http.StatusNotFound, l)
 
 			return
 		}
 
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
 		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
 		l.Error(errors.Wrapf(err, "can't delete banner"))
 
@@ -130,19 +160,22 @@ func (bh *BannerHandlers) DeleteBanner(c *gin.Context) {
 // UpdateBanner
 //
 //	@Summary		Обновление баннера.
-//	@Description	Обновляет информацию о баннере по его id.
+//	@Description	|
+//					Обновляет информацию о баннере по его id. Новое содержимое не публикуется сразу,
+//					а сохраняется как черновик, который должен одобрить другой админ.
 //	@Tags			banner
 //	@Param			id	path	integer	true	"Идентификатор баннера"
 //	@Accept			json
 //	@Param			request	body	request.UpdateBanner	true	"Информация об обновлении"
 //	@Produce		json
-//	@Success		200	"Баннер успешно обновлён"
-//	@Failure		400	{object}	tools.Error	"Некорректные данные"
+//	@Success		200	{object}	response.DraftID	"Баннер обновлён, при изменении содержимого создан черновик"
+//	@Failure		400	{object}	tools.Error			"Некорректные данные"
 //	@Failure		401	"Пользователь не авторизован"
 //	@Failure		403	"Пользователь не имеет доступа"
 //	@Failure		404	"Баннер с данным id не найден"
 //	@Failure		409	"Баннер с указанной парой id фичи и ia тэга уже существует"
 //	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
 //	@Router			/banner/{id} [patch]
 //
 //	@Security		AdminToken
@@ -165,7 +198,9 @@ func (bh *BannerHandlers) UpdateBanner(c *gin.Context) {
 		return
 	}
 
-	if err := bh.usecase.UpdateBanner(types.ID(id), updateBanner.ToModel()); err != nil {
+	draftID, err := bh.usecase.UpdateBanner(c.Request.Context(), types.ID(id), updateBanner.ToModel(),
+		middleware.GetSubject(c))
+	if err != nil {
 		if errors.Is(err, br.ErrorBannerNotFound) {
 			tools.SendErrorStatus(c, err, http.StatusNotFound, l)
 
@@ -178,12 +213,32 @@ func (bh *BannerHandlers) UpdateBanner(c *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, br.ErrorBannerScheduleIncorrect) {
+			tools.SendError(c, br.ErrorBannerScheduleIncorrect, http.StatusBadRequest, l)
+
+			return
+		}
+
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
 		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
 		l.Error(errors.Wrapf(err, "can't update banner"))
 
 		return
 	}
 
+	if draftID != nil {
+		tools.SendStatus(c, http.StatusOK, &response.DraftID{DraftID: *draftID}, l)
+
+		return
+	}
+
 	tools.SendStatus(c, http.StatusOK, nil, l)
 }
 
@@ -192,27 +247,38 @@ func (bh *BannerHandlers) UpdateBanner(c *gin.Context) {
 //	@Summary		Получение баннера для пользователя.
 //	@Description	|
 //					Возвращает баннер на основании тэга группы пользователей, фичи и версии, если версия не указана,
-//					то вернётся последняя.
+//					то вернётся последняя. Пользователь может входить в несколько групп: тэги передаются повторением
+//					tag_id или через запятую, и из баннеров фичи по этим тэгам выбирается баннер с наибольшим
+//					приоритетом, а при равных приоритетах -- созданный раньше.
+//					Если для баннера запущен эксперимент и передан идентификатор пользователя,
+//					то вернётся содержимое варианта эксперимента, закреплённого за пользователем.
+//					Без use_last_revision баннер может быть отдан из кэша. Если баннер в кэше устарел, он отдаётся
+//					с заголовком X-Cache-Stale и обновляется в фоне, в том числе пока база недоступна.
 //
 //	@Tags			banner
-//	@Param			tag_id				query	integer	true	"Идентификатор тэга группы пользователей"
-//	@Param			feature_id			query	integer	true	"Идентификатор фичи"
-//	@Param			version				query	integer	false	"Версия баннера"
-//	@Param			use_last_revision	query	boolean	false	"Получать актуальную информацию"
+//	@Param			tag_id				query	[]integer	true	"Идентификаторы тэгов групп пользователя"	collectionFormat(multi)
+//	@Param			feature_id			query	integer		true	"Идентификатор фичи"
+//	@Param			version				query	integer		false	"Версия баннера"
+//	@Param			user_id				query	string		false	"Идентификатор пользователя для выбора варианта эксперимента"
+//	@Param			use_last_revision	query	boolean		false	"Получать актуальную информацию"
 //	@Produce		json
 //	@Success		200	{object}	any			"JSON-отображение баннера"
+//	@Header			200	{integer}	X-Experiment-Id			"Идентификатор эксперимента, если был выдан его вариант"
+//	@Header			200	{integer}	X-Experiment-Variant	"Идентификатор выданного варианта эксперимента"
+//	@Header			200	{boolean}	X-Cache-Stale			"Баннер отдан из кэша после истечения времени свежести"
 //	@Failure		400	{object}	tools.Error	"Некорректные данные"
 //	@Failure		401	"Пользователь не авторизован"
 //	@Failure		403	"Пользователь не имеет доступа"
 //	@Failure		404	"Баннер с указанными тэгом и фичёй не найден"
 //	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
 //	@Router			/user_banner [get]
 //
 //	@Security		UserToken
 func (bh *BannerHandlers) GetUserBanner(c *gin.Context) {
 	l := middleware.GetLogger(c)
 
-	tagID, err := tools.ParseQueryParamToTypesID(c, TagIDParam,
+	tagIDs, err := tools.ParseQueryParamToTypesIDs(c, TagIDParam,
 		ErrorTagIDNotPresented, ErrorTagIDIncorrectType, l)
 	if err != nil {
 		tools.SendError(c, err, http.StatusBadRequest, l)
@@ -235,7 +301,12 @@ func (bh *BannerHandlers) GetUserBanner(c *gin.Context) {
 		return
 	}
 
-	content, err := bh.usecase.GetUserBanner(*featureID, *tagID, version)
+	var userID *string
+	if rawUserID, ok := c.GetQuery(UserIDParam); ok && rawUserID != "" {
+		userID = &rawUserID
+	}
+
+	content, err := bh.usecase.GetUserBanner(c.Request.Context(), *featureID, tagIDs, version, userID)
 	if err != nil {
 		if errors.Is(err, br.ErrorBannerNotFound) {
 			tools.SendErrorStatus(c, err, http.StatusNotFound, l)
@@ -243,53 +314,129 @@ func (bh *BannerHandlers) GetUserBanner(c *gin.Context) {
 			return
 		}
 
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
 		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
 		l.Error(errors.Wrapf(err, "can't get banner for user"))
 
 		return
 	}
 
-	tools.SendStatus(c, http.StatusOK, content, l)
+	if content.VariantID != nil {
+		c.Header(ExperimentHeader, strconv.FormatUint(uint64(*content.ExperimentID), 10))
+		c.Header(VariantHeader, strconv.FormatUint(uint64(*content.VariantID), 10))
+	}
 
-	if err := bh.cache.SetCache(*featureID, *tagID, version, types.Content(content)); err != nil {
-		l.Error(errors.Wrapf(err,
-			"can't cache banner with feature id %d, tag id %d and version %d", featureID, tagID, version))
+	tools.SendStatus(c, http.StatusOK, content.Content, l)
+}
+
+// GetUserBanners
+//
+//	@Summary		Получение баннеров нескольких фич для пользователя.
+//	@Description	|
+//					Возвращает последние версии баннеров фич по тэгу группы пользователей, сопоставляя каждой
+//					запрошенной фиче её баннер или статус 404, если баннер не найден. Если для баннера запущен
+//					эксперимент и передан идентификатор пользователя, то вернётся содержимое варианта эксперимента,
+//					закреплённого за пользователем. Без use_last_revision баннеры отдаются из кэша, устаревшие
+//					помечаются stale и обновляются в фоне, а остальные запрашиваются из базы одним запросом.
+//
+//	@Tags			banner
+//	@Accept			json
+//	@Param			request	body	request.GetUserBanners	true	"Тэг и фичи баннеров"
+//	@Produce		json
+//	@Success		200	{object}	map[string]response.UserBanner	"Баннеры по идентификаторам фич"
+//	@Failure		400	{object}	tools.Error						"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/user_banners [post]
+//
+//	@Security		UserToken
+func (bh *BannerHandlers) GetUserBanners(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение значения тела запроса
+	var getUserBanners request.GetUserBanners
+	if code, err := tools.ParseRequestBody(c.Request.Body, &getUserBanners,
+		request.ValidateGetUserBanners, l); err != nil {
+		tools.SendError(c, err, code, l)
 
 		return
 	}
 
-	l.Info("banner with feature id %d, tag id %d and version %d was cached", featureID, tagID, version)
+	if getUserBanners.UserID != nil && *getUserBanners.UserID == "" {
+		getUserBanners.UserID = nil
+	}
+
+	banners, err := bh.usecase.GetUserBanners(c.Request.Context(), getUserBanners.TagID,
+		getUserBanners.FeatureIDs, getUserBanners.UserID, getUserBanners.UseLastRevision)
+	if err != nil {
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't get banners for user"))
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, response.FromModelUserBanners(getUserBanners.FeatureIDs, banners), l)
 }
 
 // GetAdminBanner
 //
 //	@Summary		Получение всех баннеров c фильтрацией по фиче и/или тегу
-//	@Description	Возвращает список баннеров на основе фильтра по фиче и/или тегу.
+//	@Description	|
+//					Возвращает страницу баннеров на основе фильтра по фичам, тэгам, состоянию окна показа, активности
+//					и времени создания или изменения. Фичи и тэги можно перечислить повторением параметра или через
+//					запятую, баннер подходит, если у него есть хотя бы одна из фич и хотя бы один из тэгов.
+//					Баннеры упорядочены по полю sort, а при равенстве -- по идентификатору. Если после страницы есть
+//					ещё баннеры, в заголовке X-Next-Cursor возвращается курсор следующей страницы. Курсор хранит
+//					сортировку, поэтому следующую страницу можно запросить по курсору и фильтру без sort и order,
+//					а вместе с курсором нельзя передать offset. С with_total в заголовке X-Total-Count
+//					возвращается число всех баннеров фильтра.
 //	@Tags			banner
-//	@Param			tag_id		query	integer	false	"Идентификатор тэга группы пользователей"
-//	@Param			feature_id	query	integer	false	"Идентификатор фичи"
-//	@Param			limit		query	integer	false	"Лимит"
-//	@Param			offset		query	integer	false	"Оффсет"
+//	@Param			tag_id			query	[]integer	false	"Идентификаторы тэгов группы пользователей"	collectionFormat(multi)
+//	@Param			feature_id		query	[]integer	false	"Идентификаторы фич"	collectionFormat(multi)
+//	@Param			state			query	string		false	"Состояние окна показа"	Enums(scheduled, live, expired)
+//	@Param			is_active		query	boolean		false	"Активность баннера"
+//	@Param			created_from	query	string		false	"Начало диапазона времени создания в формате RFC 3339"
+//	@Param			created_to		query	string		false	"Конец диапазона времени создания (не включая) в формате RFC 3339"
+//	@Param			updated_from	query	string		false	"Начало диапазона времени изменения в формате RFC 3339"
+//	@Param			updated_to		query	string		false	"Конец диапазона времени изменения (не включая) в формате RFC 3339"
+//	@Param			sort			query	string		false	"Поле сортировки, по умолчанию id"	Enums(id, created_at, updated_at)
+//	@Param			order			query	string		false	"Направление сортировки, по умолчанию asc"	Enums(asc, desc)
+//	@Param			cursor			query	string		false	"Курсор из заголовка X-Next-Cursor предыдущей страницы"
+//	@Param			with_total		query	boolean		false	"Вернуть число всех баннеров фильтра"
+//	@Param			limit			query	integer		false	"Лимит"
+//	@Param			offset			query	integer		false	"Оффсет"
 //	@Produce		json
 //	@Success		200	{array}		response.Banner	"Список баннеров успешно отфильтрован"
+//	@Header			200	{string}	X-Next-Cursor	"Курсор следующей страницы"
+//	@Header			200	{integer}	X-Total-Count	"Число всех баннеров фильтра"
 //	@Failure		400	{object}	tools.Error		"Некорректные данные"
 //	@Failure		401	"Пользователь не авторизован"
 //	@Failure		403	"Пользователь не имеет доступа"
 //	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
 //	@Router			/banner [get]
 //
 //	@Security		AdminToken
 func (bh *BannerHandlers) GetAdminBanner(c *gin.Context) {
 	l := middleware.GetLogger(c)
 
-	tagID, err := tools.ParseQueryParamToTypesID(c, TagIDParam, nil, ErrorTagIDIncorrectType, l)
+	filter, err := parseBannerFilter(c, l)
 	if err != nil {
 		tools.SendError(c, err, http.StatusBadRequest, l)
 
 		return
 	}
 
-	featureID, err := tools.ParseQueryParamToTypesID(c, FeatureIDParam, nil, ErrorFeatureIDIncorrectType, l)
+	order, err := parseBannerOrder(c, l)
 	if err != nil {
 		tools.SendError(c, err, http.StatusBadRequest, l)
 
@@ -310,15 +457,48 @@ func (bh *BannerHandlers) GetAdminBanner(c *gin.Context) {
 		return
 	}
 
-	banners, err := bh.usecase.GetAdminBanners(featureID, tagID, offset, limit)
+	withTotal := false
+	if rawWithTotal, ok := c.GetQuery(WithTotalParam); ok {
+		if withTotal, err = strconv.ParseBool(rawWithTotal); err != nil {
+			tools.SendError(c, ErrorWithTotalIncorrectType, http.StatusBadRequest, l)
+
+			return
+		}
+	}
+
+	list, err := bh.usecase.GetAdminBanners(c.Request.Context(), filter, order, offset, limit, withTotal)
 	if err != nil {
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
 		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
 		l.Error(errors.Wrapf(err, "can't get banners for admin"))
 
 		return
 	}
 
-	tools.SendStatus(c, http.StatusOK, slices.Map(banners, func(banner *models.Banner) response.Banner {
+	if list.Next != nil {
+		cursor, err := encodeBannerCursor(order, list.Next)
+		if err != nil {
+			tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+			l.Error(errors.Wrapf(err, "can't encode next page cursor"))
+
+			return
+		}
+
+		c.Header(NextCursorHeader, cursor)
+	}
+
+	if list.Total != nil {
+		c.Header(TotalCountHeader, strconv.FormatUint(*list.Total, 10))
+	}
+
+	tools.SendStatus(c, http.StatusOK, slices.Map(list.Banners, func(banner *models.Banner) response.Banner {
 		return *response.FromModelBanner(banner)
 	}), l)
 }
@@ -337,6 +517,7 @@ func (bh *BannerHandlers) GetAdminBanner(c *gin.Context) {
 //	@Failure		403	"Пользователь не имеет доступа"
 //	@Failure		404	"Баннер с указанными тэгом и фичёй не найден"
 //	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
 //	@Router			/filter_banner [delete]
 //
 //	@Security		AdminToken
@@ -363,13 +544,21 @@ func (bh *BannerHandlers) DeleteFilterBanner(c *gin.Context) {
 		return
 	}
 
-	if err := bh.usecase.DeleteFilteredBanner(featureID, tagID); err != nil {
+	if err := bh.usecase.DeleteFilteredBanner(c.Request.Context(), featureID, tagID); err != nil {
 		if errors.Is(err, br.ErrorBannerNotFound) {
 			tools.SendErrorStatus(c, err, http.StatusNotFound, l)
 
 			return
 		}
 
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
 		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
 		l.Error(errors.Wrapf(err, "can't delete filtered banners"))
 
@@ -378,3 +567,19 @@ func (bh *BannerHandlers) DeleteFilterBanner(c *gin.Context) {
 
 	tools.SendStatus(c, http.StatusNoContent, nil, l)
 }
+
+func parseScheduleState(c *gin.Context) (*entity.ScheduleState, error) {
+	rawState, ok := c.GetQuery(StateParam)
+	if !ok {
+		return nil, nil
+	}
+
+	state := entity.ScheduleState(rawState)
+
+	switch state {
+	case entity.ScheduleScheduled, entity.ScheduleLive, entity.ScheduleExpired:
+		return &state, nil
+	default:
+		return nil, ErrorStateIncorrectValue
+	}
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/logger"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	IsActiveParam    = "is_active"
+	CreatedFromParam = "created_from"
+	CreatedToParam   = "created_to"
+	UpdatedFromParam = "updated_from"
+	UpdatedToParam   = "updated_to"
+	SortParam        = "sort"
+	OrderParam       = "order"
+	CursorParam      = "cursor"
+	WithTotalParam   = "with_total"
+)
+
+const (
+	orderAsc  = "asc"
+	orderDesc = "desc"
+)
+
+const (
+	NextCursorHeader = "X-Next-Cursor"
+	TotalCountHeader = "X-Total-Count"
+)
+
+// bannerCursor курсор списка баннеров. Вместе с позицией в курсоре хранится порядок списка,
+// чтобы следующую страницу можно было запросить по одному курсору.
+type bannerCursor struct {
+	Sort entity.BannerSort `json:"s"`
+	Desc bool              `json:"d,omitempty"`
+	ID   types.ID          `json:"i"`
+	Time *time.Time        `json:"t,omitempty"`
+}
+
+// encodeBannerCursor возвращает непрозрачную строку курсора для заголовка ответа
+func encodeBannerCursor(order *entity.BannerOrder, cursor *entity.BannerCursor) (string, error) {
+	data, err := json.Marshal(&bannerCursor{Sort: order.Sort, Desc: order.Desc, ID: cursor.ID, Time: cursor.Time})
+	if err != nil {
+		return "", errors.Wrap(err, "can't marshal banner cursor")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeBannerCursor разбирает строку курсора и проверяет, что в нём есть время для сортировки по времени
+func decodeBannerCursor(rawCursor string) (*bannerCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(rawCursor)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't decode banner cursor")
+	}
+
+	var cursor bannerCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, errors.Wrap(err, "can't unmarshal banner cursor")
+	}
+
+	switch cursor.Sort {
+	case entity.BannerSortID:
+		cursor.Time = nil
+	case entity.BannerSortCreatedAt, entity.BannerSortUpdatedAt:
+		if cursor.Time == nil {
+			return nil, errors.Errorf("banner cursor sorted by %s has no time", cursor.Sort)
+		}
+	default:
+		return nil, errors.Errorf("banner cursor has unknown sort %s", cursor.Sort)
+	}
+
+	return &cursor, nil
+}
+
+// parseBannerFilter разбирает параметры фильтра списка баннеров. Фичи и тэги можно перечислить
+// повторением параметра или через запятую.
+func parseBannerFilter(c *gin.Context, l logger.Interface) (*entity.BannerInfo, error) {
+	var (
+		filter = &entity.BannerInfo{}
+		err    error
+	)
+
+	if filter.Tags, err = tools.ParseQueryParamToTypesIDs(c, TagIDParam, nil, ErrorTagIDIncorrectType, l); err != nil {
+		return nil, err
+	}
+
+	filter.Features, err = tools.ParseQueryParamToTypesIDs(c, FeatureIDParam, nil, ErrorFeatureIDIncorrectType, l)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := parseScheduleState(c)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.State = types.ObjectFromPointer(state)
+
+	if rawIsActive, ok := c.GetQuery(IsActiveParam); ok {
+		isActive, err := strconv.ParseBool(rawIsActive)
+		if err != nil {
+			return nil, ErrorIsActiveIncorrectType
+		}
+
+		filter.IsActive = &isActive
+	}
+
+	timeParams := []struct {
+		param string
+		value **time.Time
+		err   error
+	}{
+		{CreatedFromParam, &filter.CreatedFrom, ErrorCreatedFromIncorrectType},
+		{CreatedToParam, &filter.CreatedTo, ErrorCreatedToIncorrectType},
+		{UpdatedFromParam, &filter.UpdatedFrom, ErrorUpdatedFromIncorrectType},
+		{UpdatedToParam, &filter.UpdatedTo, ErrorUpdatedToIncorrectType},
+	}
+
+	for _, timeParam := range timeParams {
+		if *timeParam.value, err = tools.ParseQueryParamToTime(c, timeParam.param, nil, timeParam.err, l); err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
+
+// parseBannerOrder разбирает сортировку и курсор списка баннеров. Без параметров сортировки
+// используется порядок из курсора, а без курсора -- возрастание идентификатора.
+func parseBannerOrder(c *gin.Context, l logger.Interface) (*entity.BannerOrder, error) {
+	order := &entity.BannerOrder{Sort: entity.BannerSortID}
+
+	rawCursor, withCursor := c.GetQuery(CursorParam)
+	if withCursor {
+		if _, ok := c.GetQuery(OffsetParam); ok {
+			return nil, ErrorCursorWithOffset
+		}
+
+		cursor, err := decodeBannerCursor(rawCursor)
+		if err != nil {
+			l.Error(errors.Wrapf(err, "can't parse query field %s with value %s", CursorParam, rawCursor))
+
+			return nil, ErrorCursorIncorrect
+		}
+
+		order.Sort, order.Desc = cursor.Sort, cursor.Desc
+		order.Cursor = &entity.BannerCursor{ID: cursor.ID, Time: cursor.Time}
+	}
+
+	if rawSort, ok := c.GetQuery(SortParam); ok {
+		sort := entity.BannerSort(rawSort)
+
+		switch sort {
+		case entity.BannerSortID, entity.BannerSortCreatedAt, entity.BannerSortUpdatedAt:
+		default:
+			return nil, ErrorSortIncorrectValue
+		}
+
+		if withCursor && sort != order.Sort {
+			return nil, ErrorCursorOrderMismatch
+		}
+
+		order.Sort = sort
+	}
+
+	if rawOrder, ok := c.GetQuery(OrderParam); ok {
+		if rawOrder != orderAsc && rawOrder != orderDesc {
+			return nil, ErrorOrderIncorrectValue
+		}
+
+		if withCursor && (rawOrder == orderDesc) != order.Desc {
+			return nil, ErrorCursorOrderMismatch
+		}
+
+		order.Desc = rawOrder == orderDesc
+	}
+
+	return order, nil
+}
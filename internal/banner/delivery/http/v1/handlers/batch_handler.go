@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/banner/delivery/http/v1/models/request"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/pkg/logger"
+	"net/http"
+
+	br "bannersrv/internal/banner/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// batchSuccessStatus статусы успешных операций, такие же, как у отдельных запросов
+var batchSuccessStatus = map[entity.BatchAction]int{
+	entity.BatchCreate: http.StatusCreated,
+	entity.BatchUpdate: http.StatusOK,
+	entity.BatchDelete: http.StatusNoContent,
+}
+
+// BatchBanners
+//
+//	@Summary		Пакетное изменение баннеров.
+//	@Description	|
+//					Выполняет по порядку операции создания, изменения и удаления баннеров в одной транзакции.
+//					Поля баннера в операции такие же, как в POST /banner и PATCH /banner/{id}, а новое содержимое
+//					изменяемого баннера сохраняется как черновик. В режиме atomic операции применяются, только если
+//					все они успешны, иначе ответ со статусом 422 содержит ошибки операций, а успешные операции
+//					отмечены статусом 424. В режиме best_effort применяются все успешные операции.
+//					Для каждой операции возвращается статус отдельного запроса с этим действием, а для конфликтов
+//					по паре фича-тэг -- тэги, по которым баннер конфликтует с другим баннером фичи.
+//	@Tags			banner
+//	@Accept			json
+//	@Param			request	body	request.BatchBanners	true	"Операции над баннерами"
+//	@Produce		json
+//	@Success		200	{object}	response.BatchResult	"Итоги операций"
+//	@Failure		400	{object}	tools.Error				"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		422	{object}	response.BatchResult	"Операции не применены из-за ошибок"
+//	@Failure		500	{object}	tools.Error				"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error				"Превышено время обработки запроса"
+//	@Router			/banner/batch [post]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) BatchBanners(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	var batch request.BatchBanners
+	if code, err := tools.ParseRequestBody(c.Request.Body, &batch, request.ValidateBatchBanners, l); err != nil {
+		tools.SendError(c, err, code, l)
+
+		return
+	}
+
+	operations := make([]models.BatchOperation, 0, len(batch.Operations))
+
+	for i := range batch.Operations {
+		operation, err := batch.Operations[i].ToModel()
+		if err != nil {
+			tools.SendError(c, errors.Wrapf(err, "in operation %d", i), http.StatusBadRequest, l)
+
+			return
+		}
+
+		operations = append(operations, *operation)
+	}
+
+	if batch.Mode == "" {
+		batch.Mode = request.BatchAtomic
+	}
+
+	result, err := bh.usecase.BatchBanners(c.Request.Context(), operations, middleware.GetSubject(c),
+		batch.Mode == request.BatchAtomic)
+	if err != nil {
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't execute batch of banner operations"))
+
+		return
+	}
+
+	batchResult := &response.BatchResult{
+		Mode:    batch.Mode,
+		Applied: result.Applied,
+		Results: make([]response.BatchOperationResult, len(result.Operations)),
+	}
+
+	for i := range result.Operations {
+		batchResult.Results[i] = batchOperationResult(operations[i].Action, &result.Operations[i], l)
+	}
+
+	if !result.Applied {
+		tools.SendStatus(c, http.StatusUnprocessableEntity, batchResult, l)
+
+		return
+	}
+
+	l.Info("batch of %d banner operations was applied in %s mode", len(operations), batch.Mode)
+	tools.SendStatus(c, http.StatusOK, batchResult, l)
+}
+
+// batchOperationResult возвращает итог операции со статусом, который вернул бы отдельный запрос.
+// Внутренние ошибки логируются и заменяются общей ошибкой сервера.
+func batchOperationResult(action entity.BatchAction, operation *models.BatchOperationResult,
+	l logger.Interface,
+) response.BatchOperationResult {
+	result := response.BatchOperationResult{
+		Action:         action,
+		Status:         batchSuccessStatus[action],
+		BannerID:       operation.ID,
+		DraftID:        operation.DraftID,
+		ConflictTagIDs: operation.ConflictTagIDs,
+	}
+
+	if operation.Err == nil {
+		return result
+	}
+
+	result.Error = operation.Err.Error()
+
+	switch {
+	case errors.Is(operation.Err, br.ErrorBannerConflictExists):
+		result.Status, result.Error = http.StatusConflict, br.ErrorBannerConflictExists.Error()
+	case errors.Is(operation.Err, br.ErrorBannerScheduleIncorrect):
+		result.Status, result.Error = http.StatusBadRequest, br.ErrorBannerScheduleIncorrect.Error()
+	case errors.Is(operation.Err, br.ErrorBannerNotFound):
+		result.Status, result.Error = http.StatusNotFound, br.ErrorBannerNotFound.Error()
+	case errors.Is(operation.Err, br.ErrorBatchRolledBack):
+		result.Status = http.StatusFailedDependency
+	case errors.Is(operation.Err, access.ErrorFeatureForbidden):
+		result.Status = http.StatusForbidden
+	default:
+		result.Status, result.Error = http.StatusInternalServerError, tools.ErrorServerError.Error()
+		l.Error(errors.Wrapf(operation.Err, "can't execute %s operation", action))
+	}
+
+	return result
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"net/http"
+
+	cr "bannersrv/internal/caches/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// InspectCache
+//
+//	@Summary		Получение записи кэша баннера.
+//	@Description	|
+//					Возвращает запись кэша баннера по фиче, тэгам и версии, если она указана,
+//					вместе со временем, до которого баннер считается свежим, и оставшимся временем жизни записи.
+//	@Tags			cache
+//	@Param			tag_id		query	[]integer	true	"Идентификаторы тэгов групп пользователя"	collectionFormat(multi)
+//	@Param			feature_id	query	integer		true	"Идентификатор фичи"
+//	@Param			version		query	integer		false	"Версия баннера"
+//	@Produce		json
+//	@Success		200	{object}	response.CacheEntry	"Запись кэша баннера"
+//	@Failure		400	{object}	tools.Error			"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Баннера нет в кэше"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/cache/entry [get]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) InspectCache(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	tagIDs, err := tools.ParseQueryParamToTypesIDs(c, TagIDParam,
+		ErrorTagIDNotPresented, ErrorTagIDIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	featureID, err := tools.ParseQueryParamToTypesID(c, FeatureIDParam,
+		ErrorFeatureIDNotPresented, ErrorFeatureIDIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	version, err := tools.ParseQueryParamToUint32(c, VersionParam, nil, ErrorVersionIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	entry, err := bh.usecase.InspectCache(c.Request.Context(), *featureID, tagIDs, version)
+	if err != nil {
+		if errors.Is(err, cr.ErrorCacheMiss) {
+			tools.SendErrorStatus(c, err, http.StatusNotFound, l)
+
+			return
+		}
+
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't inspect cache"))
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, response.FromModelCacheEntry(entry), l)
+}
+
+// PurgeCache
+//
+//	@Summary		Сброс кэша баннеров.
+//	@Description	|
+//					Удаляет из кэша все версии баннеров по фиче и/или тэгу, а без параметров -- весь кэш баннеров.
+//					Сброс кэша по тэгу или всего кэша доступен только админу с доступом ко всем фичам.
+//	@Tags			cache
+//	@Param			tag_id		query	integer	false	"Идентификатор тэга группы пользователей"
+//	@Param			feature_id	query	integer	false	"Идентификатор фичи"
+//	@Produce		json
+//	@Success		200	{object}	response.PurgeResult	"Кэш успешно сброшен"
+//	@Failure		400	{object}	tools.Error				"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/cache [delete]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) PurgeCache(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	tagID, err := tools.ParseQueryParamToTypesID(c, TagIDParam, nil, ErrorTagIDIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	featureID, err := tools.ParseQueryParamToTypesID(c, FeatureIDParam, nil, ErrorFeatureIDIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	purged, err := bh.usecase.PurgeCache(c.Request.Context(), featureID, tagID)
+	if err != nil {
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't purge cache"))
+
+		return
+	}
+
+	l.Info("cache of %d feature-tag pairs was purged", purged)
+	tools.SendStatus(c, http.StatusOK, &response.PurgeResult{Purged: purged}, l)
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/banner/delivery/http/v1/models/request"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/pkg/types"
+	"net/http"
+	"strconv"
+
+	br "bannersrv/internal/banner/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const FeatureIDField = "feature_id"
+
+// GetFeatureCacheTTL
+//
+//	@Summary		Получение времени жизни баннеров фичи в кэше.
+//	@Description	Возвращает время жизни в кэше, заданное для баннеров фичи.
+//	@Tags			cache
+//	@Param			feature_id	path	integer	true	"Идентификатор фичи"
+//	@Produce		json
+//	@Success		200	{object}	response.FeatureCacheTTL	"Время жизни баннеров фичи в кэше"
+//	@Failure		400	{object}	tools.Error					"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Для фичи используется время жизни по умолчанию"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/feature/{feature_id}/cache_ttl [get]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) GetFeatureCacheTTL(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	featureID, err := strconv.ParseUint(c.Param(FeatureIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get feature id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	ttl, err := bh.usecase.GetFeatureCacheTTL(c.Request.Context(), types.ID(featureID))
+	if err != nil {
+		if errors.Is(err, br.ErrorCacheTTLNotFound) {
+			tools.SendErrorStatus(c, err, http.StatusNotFound, l)
+
+			return
+		}
+
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't get feature cache ttl"))
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, response.FromModelFeatureCacheTTL(ttl), l)
+}
+
+// SetFeatureCacheTTL
+//
+//	@Summary		Изменение времени жизни баннеров фичи в кэше.
+//	@Description	|
+//					Задаёт время жизни в кэше для баннеров фичи, для которых не задано собственное время жизни,
+//					и сбрасывает кэш баннеров фичи. Устаревший баннер отдаётся из кэша с обновлением в фоне,
+//					пока не истечёт жёсткое время жизни из конфига сервиса или заданное, если оно больше.
+//	@Tags			cache
+//	@Param			feature_id	path	integer	true	"Идентификатор фичи"
+//	@Accept			json
+//	@Param			request	body	request.SetCacheTTL	true	"Время жизни в кэше"
+//	@Produce		json
+//	@Success		200	"Время жизни успешно изменено"
+//	@Failure		400	{object}	tools.Error	"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/feature/{feature_id}/cache_ttl [put]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) SetFeatureCacheTTL(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	featureID, err := strconv.ParseUint(c.Param(FeatureIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get feature id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	// Получение значения тела запроса
+	var setCacheTTL request.SetCacheTTL
+	if code, err := tools.ParseRequestBody(c.Request.Body, &setCacheTTL,
+		request.ValidateSetCacheTTL, l); err != nil {
+		tools.SendError(c, err, code, l)
+
+		return
+	}
+
+	if err := bh.usecase.SetFeatureCacheTTL(c.Request.Context(), types.ID(featureID),
+		setCacheTTL.CacheTTL); err != nil {
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't set feature cache ttl"))
+
+		return
+	}
+
+	l.Info("cache ttl of feature %d was set to %d ms", featureID, setCacheTTL.CacheTTL)
+	tools.SendStatus(c, http.StatusOK, nil, l)
+}
+
+// DeleteFeatureCacheTTL
+//
+//	@Summary		Сброс времени жизни баннеров фичи в кэше.
+//	@Description	Возвращает баннерам фичи время жизни в кэше по умолчанию и сбрасывает их кэш.
+//	@Tags			cache
+//	@Param			feature_id	path	integer	true	"Идентификатор фичи"
+//	@Success		204	"Время жизни успешно сброшено"
+//	@Failure		400	{object}	tools.Error	"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Для фичи уже используется время жизни по умолчанию"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/feature/{feature_id}/cache_ttl [delete]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) DeleteFeatureCacheTTL(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	featureID, err := strconv.ParseUint(c.Param(FeatureIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get feature id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	if err := bh.usecase.DeleteFeatureCacheTTL(c.Request.Context(), types.ID(featureID)); err != nil {
+		if errors.Is(err, br.ErrorCacheTTLNotFound) {
+			tools.SendErrorStatus(c, err, http.StatusNotFound, l)
+
+			return
+		}
+
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't delete feature cache ttl"))
+
+		return
+	}
+
+	l.Info("cache ttl of feature %d was reset", featureID)
+	tools.SendStatus(c, http.StatusNoContent, nil, l)
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/logger"
+	"bannersrv/pkg/slices"
+	"net/http"
+	"strconv"
+
+	br "bannersrv/internal/banner/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const DraftIDField = "id"
+
+const DraftStatusParam = "status"
+
+// GetDrafts
+//
+//	@Summary		Получение черновиков баннера.
+//	@Description	Возвращает черновики содержимого баннера от новых к старым с фильтрацией по состоянию.
+//	@Tags			draft
+//	@Param			id		path	integer	true	"Идентификатор баннера"
+//	@Param			status	query	string	false	"Состояние черновика"	Enums(pending, approved, rejected, published)
+//	@Param			limit	query	integer	false	"Лимит"
+//	@Param			offset	query	integer	false	"Оффсет"
+//	@Produce		json
+//	@Success		200	{array}		response.Draft	"Список черновиков баннера"
+//	@Failure		400	{object}	tools.Error		"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Баннер с данным id не найден"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/banner/{id}/drafts [get]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) GetDrafts(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(BannerIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get banner id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	status, err := parseDraftStatus(c)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	limit, err := tools.ParseQueryParamToUint64(c, LimitParam, nil, ErrorLimitIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	offset, err := tools.ParseQueryParamToUint64(c, OffsetParam, nil, ErrorOffsetIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	drafts, err := bh.usecase.GetDrafts(c.Request.Context(), types.ID(id), status, offset, limit)
+	if err != nil {
+		if errors.Is(err, br.ErrorBannerNotFound) {
+			tools.SendErrorStatus(c, err, http.StatusNotFound, l)
+
+			return
+		}
+
+		sendDraftError(c, err, l, "can't get drafts")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, slices.Map(drafts, func(draft *models.Draft) response.Draft {
+		return *response.FromModelDraft(draft)
+	}), l)
+}
+
+// GetDraft
+//
+//	@Summary		Предпросмотр черновика.
+//	@Description	Возвращает черновик содержимого баннера вместе с его состоянием.
+//	@Tags			draft
+//	@Param			id	path	integer	true	"Идентификатор черновика"
+//	@Produce		json
+//	@Success		200	{object}	response.Draft	"Черновик успешно получен"
+//	@Failure		400	{object}	tools.Error		"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Черновик с данным id не найден"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/draft/{id} [get]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) GetDraft(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(DraftIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get draft id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	draft, err := bh.usecase.GetDraft(c.Request.Context(), types.ID(id))
+	if err != nil {
+		sendDraftError(c, err, l, "can't get draft")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, response.FromModelDraft(draft), l)
+}
+
+// ApproveDraft
+//
+//	@Summary		Одобрение черновика.
+//	@Description	Одобряет черновик на рассмотрении. Автор черновика не может одобрить его сам.
+//	@Tags			draft
+//	@Param			id	path	integer	true	"Идентификатор черновика"
+//	@Produce		json
+//	@Success		200	"Черновик успешно одобрен"
+//	@Failure		400	{object}	tools.Error	"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа или является автором черновика"
+//	@Failure		404	"Черновик с данным id не найден"
+//	@Failure		409	"Черновик уже рассмотрен"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/draft/{id}/approve [post]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) ApproveDraft(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(DraftIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get draft id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	if err := bh.usecase.ApproveDraft(c.Request.Context(), types.ID(id), middleware.GetSubject(c)); err != nil {
+		sendDraftError(c, err, l, "can't approve draft")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, nil, l)
+}
+
+// RejectDraft
+//
+//	@Summary		Отклонение черновика.
+//	@Description	Отклоняет черновик на рассмотрении, отклонённый черновик не может быть опубликован.
+//	@Tags			draft
+//	@Param			id	path	integer	true	"Идентификатор черновика"
+//	@Produce		json
+//	@Success		200	"Черновик успешно отклонён"
+//	@Failure		400	{object}	tools.Error	"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Черновик с данным id не найден"
+//	@Failure		409	"Черновик уже рассмотрен"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/draft/{id}/reject [post]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) RejectDraft(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(DraftIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get draft id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	if err := bh.usecase.RejectDraft(c.Request.Context(), types.ID(id), middleware.GetSubject(c)); err != nil {
+		sendDraftError(c, err, l, "can't reject draft")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, nil, l)
+}
+
+// PublishDraft
+//
+//	@Summary		Публикация черновика.
+//	@Description	|
+//					Делает содержимое одобренного черновика новой версией баннера
+//					и сбрасывает кэш баннера.
+//	@Tags			draft
+//	@Param			id	path	integer	true	"Идентификатор черновика"
+//	@Produce		json
+//	@Success		200	{object}	response.CurrentVersion	"Черновик успешно опубликован"
+//	@Failure		400	{object}	tools.Error				"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Черновик с данным id или его баннер не найден"
+//	@Failure		409	"Черновик не одобрен"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/draft/{id}/publish [post]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) PublishDraft(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(DraftIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get draft id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	published, err := bh.usecase.PublishDraft(c.Request.Context(), types.ID(id))
+	if err != nil {
+		if errors.Is(err, br.ErrorBannerNotFound) {
+			tools.SendErrorStatus(c, err, http.StatusNotFound, l)
+
+			return
+		}
+
+		sendDraftError(c, err, l, "can't publish draft")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, &response.CurrentVersion{Version: published.Version}, l)
+}
+
+func sendDraftError(c *gin.Context, err error, l logger.Interface, message string) {
+	switch {
+	case errors.Is(err, br.ErrorDraftNotFound):
+		tools.SendErrorStatus(c, err, http.StatusNotFound, l)
+	case errors.Is(err, br.ErrorDraftSelfApproval):
+		tools.SendErrorStatus(c, err, http.StatusForbidden, l)
+	case errors.Is(err, br.ErrorDraftStatusConflict):
+		tools.SendErrorStatus(c, err, http.StatusConflict, l)
+	default:
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrap(err, message))
+	}
+}
+
+func parseDraftStatus(c *gin.Context) (*entity.DraftStatus, error) {
+	rawStatus, ok := c.GetQuery(DraftStatusParam)
+	if !ok {
+		return nil, nil
+	}
+
+	status := entity.DraftStatus(rawStatus)
+
+	switch status {
+	case entity.DraftPending, entity.DraftApproved, entity.DraftRejected, entity.DraftPublished:
+		return &status, nil
+	default:
+		return nil, ErrorDraftStatusIncorrect
+	}
+}
@@ -8,9 +8,36 @@ var (
 	ErrorTagIDIncorrectType     = errors.New("tag id have incorrect type")
 	ErrorFeatureIDIncorrectType = errors.New("feature id have incorrect type")
 
-	ErrorLimitIncorrectType   = errors.New("limit have incorrect type")
-	ErrorOffsetIncorrectType  = errors.New("offset have incorrect type")
-	ErrorVersionIncorrectType = errors.New("version have incorrect type")
+	ErrorLimitIncorrectType    = errors.New("limit have incorrect type")
+	ErrorOffsetIncorrectType   = errors.New("offset have incorrect type")
+	ErrorVersionIncorrectType  = errors.New("version have incorrect type")
+	ErrorStateIncorrectValue   = errors.New("state must be one of scheduled, live or expired")
+	ErrorDraftStatusIncorrect  = errors.New("status must be one of pending, approved, rejected or published")
+	ErrorBannerIDIncorrectType = errors.New("banner id have incorrect type")
+	ErrorFromIncorrectType     = errors.New("from must be a date-time in RFC 3339 format")
+	ErrorToIncorrectType       = errors.New("to must be a date-time in RFC 3339 format")
+
+	ErrorIsActiveIncorrectType    = errors.New("is active have incorrect type")
+	ErrorWithTotalIncorrectType   = errors.New("with total have incorrect type")
+	ErrorCreatedFromIncorrectType = errors.New("created from must be a date-time in RFC 3339 format")
+	ErrorCreatedToIncorrectType   = errors.New("created to must be a date-time in RFC 3339 format")
+	ErrorUpdatedFromIncorrectType = errors.New("updated from must be a date-time in RFC 3339 format")
+	ErrorUpdatedToIncorrectType   = errors.New("updated to must be a date-time in RFC 3339 format")
+	ErrorSortIncorrectValue       = errors.New("sort must be one of id, created_at or updated_at")
+	ErrorOrderIncorrectValue      = errors.New("order must be one of asc or desc")
+	ErrorCursorIncorrect          = errors.New("cursor is incorrect")
+	ErrorCursorOrderMismatch      = errors.New("sort and order must match the cursor")
+	ErrorCursorWithOffset         = errors.New("cursor and offset can't be used together")
+
+	ErrorImportModeIncorrectValue = errors.New("mode must be one of create, upsert or replace")
+	ErrorDryRunIncorrectType      = errors.New("dry run have incorrect type")
+	ErrorImportLineTooLong        = errors.New("import line is too long")
+	ErrorImportEmpty              = errors.New("import has no banners")
+	ErrorImportIncomplete         = errors.New("export is incomplete")
+	ErrorImportTrailerNotLast     = errors.New("export trailer must be the last line")
+
+	ErrorSearchNotPresented           = errors.New("jsonpath or text must be presented in query")
+	ErrorSearchVersionsIncorrectValue = errors.New("versions must be one of current or all")
 
 	ErrorParamsNotPresented = errors.New("feature id and tag id not presented in query")
 )
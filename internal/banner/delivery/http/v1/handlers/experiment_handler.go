@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/banner/delivery/http/v1/models/request"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/logger"
+	"net/http"
+	"strconv"
+
+	br "bannersrv/internal/banner/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const ExperimentIDField = "id"
+
+// CreateExperiment
+//
+//	@Summary		Создание эксперимента для баннера.
+//	@Description	|
+//					Запускает A/B эксперимент над содержимым баннера с набором вариантов содержимого и их весами.
+//					У баннера может быть только один незавершённый эксперимент.
+//	@Tags			experiment
+//	@Param			id	path	integer	true	"Идентификатор баннера"
+//	@Accept			json
+//	@Param			request	body	request.CreateExperiment	true	"Варианты эксперимента"
+//	@Produce		json
+//	@Success		201	{object}	response.ExperimentID	"Эксперимент успешно создан"
+//	@Failure		400	{object}	tools.Error				"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Баннер с данным id не найден"
+//	@Failure		409	"У баннера уже есть незавершённый эксперимент"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/banner/{id}/experiment [post]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) CreateExperiment(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(BannerIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get banner id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	// Получение значения тела запроса
+	var createExperiment request.CreateExperiment
+	if code, err := tools.ParseRequestBody(c.Request.Body, &createExperiment,
+		request.ValidateCreateExperiment, l); err != nil {
+		tools.SendError(c, err, code, l)
+
+		return
+	}
+
+	createdID, err := bh.usecase.CreateExperiment(c.Request.Context(), types.ID(id), createExperiment.ToModel())
+	if err != nil {
+		if errors.Is(err, br.ErrorBannerNotFound) {
+			tools.SendErrorStatus(c, err, http.StatusNotFound, l)
+
+			return
+		}
+
+		sendExperimentError(c, err, l, "can't create experiment")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusCreated, &response.ExperimentID{ExperimentID: createdID}, l)
+}
+
+// GetExperiment
+//
+//	@Summary		Получение эксперимента.
+//	@Description	Возвращает состояние эксперимента и его варианты.
+//	@Tags			experiment
+//	@Param			id	path	integer	true	"Идентификатор эксперимента"
+//	@Produce		json
+//	@Success		200	{object}	response.Experiment	"Эксперимент успешно получен"
+//	@Failure		400	{object}	tools.Error			"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Эксперимент с данным id не найден"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/experiment/{id} [get]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) GetExperiment(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(ExperimentIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get experiment id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	experiment, err := bh.usecase.GetExperiment(c.Request.Context(), types.ID(id))
+	if err != nil {
+		sendExperimentError(c, err, l, "can't get experiment")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, response.FromModelExperiment(experiment), l)
+}
+
+// PauseExperiment
+//
+//	@Summary		Приостановка эксперимента.
+//	@Description	Приостанавливает эксперимент, пока он приостановлен пользователи получают основную версию баннера.
+//	@Tags			experiment
+//	@Param			id	path	integer	true	"Идентификатор эксперимента"
+//	@Produce		json
+//	@Success		200	"Эксперимент успешно приостановлен"
+//	@Failure		400	{object}	tools.Error	"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Эксперимент с данным id не найден"
+//	@Failure		409	"Эксперимент не запущен"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/experiment/{id}/pause [post]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) PauseExperiment(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(ExperimentIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get experiment id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	if err := bh.usecase.PauseExperiment(c.Request.Context(), types.ID(id)); err != nil {
+		sendExperimentError(c, err, l, "can't pause experiment")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, nil, l)
+}
+
+// ResumeExperiment
+//
+//	@Summary		Возобновление эксперимента.
+//	@Description	Возобновляет приостановленный эксперимент.
+//	@Tags			experiment
+//	@Param			id	path	integer	true	"Идентификатор эксперимента"
+//	@Produce		json
+//	@Success		200	"Эксперимент успешно возобновлён"
+//	@Failure		400	{object}	tools.Error	"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Эксперимент с данным id не найден"
+//	@Failure		409	"Эксперимент не приостановлен"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/experiment/{id}/resume [post]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) ResumeExperiment(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(ExperimentIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get experiment id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	if err := bh.usecase.ResumeExperiment(c.Request.Context(), types.ID(id)); err != nil {
+		sendExperimentError(c, err, l, "can't resume experiment")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, nil, l)
+}
+
+// ConcludeExperiment
+//
+//	@Summary		Завершение эксперимента.
+//	@Description	Завершает эксперимент и делает содержимое победившего варианта новой версией баннера.
+//	@Tags			experiment
+//	@Param			id	path	integer	true	"Идентификатор эксперимента"
+//	@Accept			json
+//	@Param			request	body	request.ConcludeExperiment	true	"Победивший вариант"
+//	@Produce		json
+//	@Success		200	{object}	response.ConcludedExperiment	"Эксперимент успешно завершён"
+//	@Failure		400	{object}	tools.Error						"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Эксперимент или вариант не найден"
+//	@Failure		409	"Эксперимент уже завершён"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/experiment/{id}/conclude [post]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) ConcludeExperiment(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(ExperimentIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get experiment id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	// Получение значения тела запроса
+	var concludeExperiment request.ConcludeExperiment
+	if code, err := tools.ParseRequestBody(c.Request.Body, &concludeExperiment,
+		request.ValidateConcludeExperiment, l); err != nil {
+		tools.SendError(c, err, code, l)
+
+		return
+	}
+
+	version, err := bh.usecase.ConcludeExperiment(c.Request.Context(), types.ID(id),
+		concludeExperiment.WinnerVariantID)
+	if err != nil {
+		sendExperimentError(c, err, l, "can't conclude experiment")
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, &response.ConcludedExperiment{Version: version}, l)
+}
+
+func sendExperimentError(c *gin.Context, err error, l logger.Interface, message string) {
+	switch {
+	case errors.Is(err, br.ErrorExperimentNotFound), errors.Is(err, br.ErrorVariantNotFound):
+		tools.SendErrorStatus(c, err, http.StatusNotFound, l)
+	case errors.Is(err, br.ErrorExperimentConflictExists), errors.Is(err, br.ErrorExperimentStatusConflict):
+		tools.SendErrorStatus(c, err, http.StatusConflict, l)
+	default:
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrap(err, message))
+	}
+}
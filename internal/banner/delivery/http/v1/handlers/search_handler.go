@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/pkg/slices"
+	"net/http"
+
+	br "bannersrv/internal/banner/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	SearchJSONPathParam = "jsonpath"
+	SearchTextParam     = "text"
+	SearchVersionsParam = "versions"
+)
+
+const (
+	searchCurrentVersions = "current"
+	searchAllVersions     = "all"
+)
+
+// SearchBanners
+//
+//	@Summary		Поиск баннеров по содержимому.
+//	@Description	|
+//					Возвращает версии баннеров, содержимое которых подходит под предикат JSONPath (например,
+//					$.title == "Скидки" или exists($.** ? (@ like_regex "example.com"))) и полнотекстовый запрос
+//					по строковым значениям содержимого в синтаксисе websearch_to_tsquery. Нужно задать хотя бы
+//					одно из условий, а если заданы оба, должны выполняться оба. По умолчанию ищется только текущая
+//					версия баннеров, с versions=all -- все хранимые версии, архивные версии не ищутся.
+//					Версии отсортированы по идентификатору баннера и от новых к старым.
+//	@Tags			banner
+//	@Param			jsonpath	query	string		false	"Предикат JSONPath над содержимым"
+//	@Param			text		query	string		false	"Полнотекстовый запрос по строковым значениям"
+//	@Param			versions	query	string		false	"Искомые версии, по умолчанию current"	Enums(current, all)
+//	@Param			feature_id	query	[]integer	false	"Идентификаторы фич"	collectionFormat(multi)
+//	@Param			limit		query	integer		false	"Лимит"
+//	@Param			offset		query	integer		false	"Оффсет"
+//	@Produce		json
+//	@Success		200	{array}		response.ContentMatch	"Подходящие версии баннеров"
+//	@Failure		400	{object}	tools.Error				"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/banner/search [get]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) SearchBanners(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	var search entity.ContentSearch
+
+	if jsonPath, ok := c.GetQuery(SearchJSONPathParam); ok {
+		search.JSONPath = &jsonPath
+	}
+
+	if text, ok := c.GetQuery(SearchTextParam); ok {
+		search.Text = &text
+	}
+
+	if search.JSONPath == nil && search.Text == nil {
+		tools.SendError(c, ErrorSearchNotPresented, http.StatusBadRequest, l)
+
+		return
+	}
+
+	switch c.DefaultQuery(SearchVersionsParam, searchCurrentVersions) {
+	case searchCurrentVersions:
+	case searchAllVersions:
+		search.AllVersions = true
+	default:
+		tools.SendError(c, ErrorSearchVersionsIncorrectValue, http.StatusBadRequest, l)
+
+		return
+	}
+
+	var err error
+
+	search.Features, err = tools.ParseQueryParamToTypesIDs(c, FeatureIDParam, nil, ErrorFeatureIDIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	limit, err := tools.ParseQueryParamToUint64(c, LimitParam, nil, ErrorLimitIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	offset, err := tools.ParseQueryParamToUint64(c, OffsetParam, nil, ErrorOffsetIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	matches, err := bh.usecase.SearchBanners(c.Request.Context(), &search, offset, limit)
+	if err != nil {
+		if errors.Is(err, br.ErrorSearchQueryIncorrect) {
+			tools.SendError(c, err, http.StatusBadRequest, l)
+
+			return
+		}
+
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't search banners by content"))
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, slices.Map(matches, func(match *models.ContentMatch) response.ContentMatch {
+		return *response.FromModelContentMatch(match)
+	}), l)
+}
@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/banner/delivery/http/v1/models/request"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/evjson"
+	"bannersrv/pkg/logger"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	br "bannersrv/internal/banner/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	ImportModeParam   = "mode"
+	ImportDryRunParam = "dry_run"
+)
+
+const (
+	ndjsonContentType = "application/x-ndjson"
+	// exportFlushLines число строк выгрузки, после которого накопленные строки отправляются клиенту
+	exportFlushLines = 100
+	// maxImportLineSize наибольший размер строки импорта в байтах
+	maxImportLineSize = 4 << 20
+)
+
+// ExportBanners
+//
+//	@Summary		Выгрузка баннеров.
+//	@Description	|
+//					Выгружает все баннеры, доступные админу, в формате NDJSON: по одному баннеру в строке
+//					с фичей, тэгами, флагом активности, настройками и всеми хранимыми версиями.
+//					Баннеры отправляются по мере чтения из базы в порядке идентификаторов.
+//					Выгрузка заканчивается строкой {"done": true, "count": N} с числом баннеров, а если выгрузка
+//					оборвалась после первой строки -- строкой {"done": false, "count": N, "error": "..."}.
+//					Выгрузка может быть загружена обратно через импорт баннеров.
+//	@Tags			banner
+//	@Produce		application/x-ndjson
+//	@Success		200	{array}		response.Banner	"Баннеры, по одному в строке"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/banner/export [get]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) ExportBanners(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	var exported uint64
+
+	encoder := json.NewEncoder(c.Writer)
+
+	err := bh.usecase.ExportBanners(c.Request.Context(), func(bnr *models.Banner) error {
+		if exported == 0 {
+			c.Header("Content-Type", ndjsonContentType)
+			c.Status(http.StatusOK)
+		}
+
+		if err := encoder.Encode(response.FromModelBanner(bnr)); err != nil {
+			return errors.Wrapf(err, "can't write banner with id %d", bnr.ID)
+		}
+
+		exported++
+		if exported%exportFlushLines == 0 {
+			c.Writer.Flush()
+		}
+
+		return nil
+	})
+	if err != nil {
+		// После первой строки статус уже отправлен, поэтому об обрыве сообщает последняя строка выгрузки
+		if exported != 0 {
+			trailer := &response.ExportTrailer{Count: exported, Error: tools.ErrorServerError.Error()}
+			if errors.Is(err, context.DeadlineExceeded) {
+				trailer.Error = tools.ErrorRequestTimeout.Error()
+			}
+
+			if err := encoder.Encode(trailer); err != nil {
+				l.Error(errors.Wrap(err, "can't write export trailer"))
+			}
+
+			c.Writer.Flush()
+			c.Abort()
+			l.Error(errors.Wrapf(err, "can't export banners after %d banners", exported))
+
+			return
+		}
+
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't export banners"))
+
+		return
+	}
+
+	if exported == 0 {
+		c.Header("Content-Type", ndjsonContentType)
+		c.Status(http.StatusOK)
+	}
+
+	if err := encoder.Encode(&response.ExportTrailer{Done: true, Count: exported}); err != nil {
+		l.Error(errors.Wrap(err, "can't write export trailer"))
+
+		return
+	}
+
+	c.Writer.Flush()
+	l.Info("%d banners were exported", exported)
+}
+
+// ImportBanners
+//
+//	@Summary		Импорт баннеров.
+//	@Description	|
+//					Загружает баннеры в формате выгрузки: по одному баннеру в строке NDJSON. Режимы импорта:
+//					create -- все баннеры создаются с новыми идентификаторами;
+//					upsert -- баннеры с существующими идентификаторами заменяются, остальные создаются;
+//					replace -- все баннеры удаляются и создаются заново из импорта.
+//					Режимы upsert и replace доступны только админу с доступом ко всем фичам.
+//					Импорт выполняется в одной транзакции и применяется, только если ни в одной строке нет ошибок,
+//					иначе возвращается отчёт с ошибками по строкам, в том числе с конфликтами по паре фича-тэг.
+//					С dry_run импорт только проверяется и не применяется.
+//					Строки с некорректным форматом отклоняют импорт без обращения к базе.
+//					Последняя строка выгрузки {"done": ..., "count": ...} пропускается, но импорт оборванной
+//					выгрузки или выгрузки с другим числом баннеров отклоняется.
+//	@Tags			banner
+//	@Accept			application/x-ndjson
+//	@Param			request	body	request.ImportBanner	true	"Баннеры, по одному в строке"
+//	@Param			mode	query	string					false	"Режим импорта, по умолчанию create"	Enums(create, upsert, replace)
+//	@Param			dry_run	query	boolean					false	"Только проверить импорт"
+//	@Produce		json
+//	@Success		200	{object}	response.ImportResult	"Итог импорта"
+//	@Failure		400	{object}	tools.Error				"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		422	{object}	response.ImportResult	"Отчёт с ошибками импорта по строкам"
+//	@Failure		500	{object}	tools.Error				"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error				"Превышено время обработки запроса"
+//	@Router			/banner/import [post]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) ImportBanners(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	mode, err := parseImportMode(c)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	dryRun := false
+	if rawDryRun, ok := c.GetQuery(ImportDryRunParam); ok {
+		if dryRun, err = strconv.ParseBool(rawDryRun); err != nil {
+			tools.SendError(c, ErrorDryRunIncorrectType, http.StatusBadRequest, l)
+
+			return
+		}
+	}
+
+	banners, lineErrors, err := parseImportBanners(c, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	if len(lineErrors) != 0 {
+		tools.SendStatus(c, http.StatusUnprocessableEntity, response.FromModelImportResult(
+			&models.ImportResult{Errors: lineErrors}, mode, dryRun), l)
+
+		return
+	}
+
+	result, err := bh.usecase.ImportBanners(c.Request.Context(), banners, mode, dryRun)
+	if err != nil {
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't import banners"))
+
+		return
+	}
+
+	for i := range result.Errors {
+		result.Errors[i].Err = importLineError(result.Errors[i], l)
+	}
+
+	if len(result.Errors) != 0 {
+		tools.SendStatus(c, http.StatusUnprocessableEntity, response.FromModelImportResult(result, mode, dryRun), l)
+
+		return
+	}
+
+	l.Info("banners were imported in %s mode: %d created, %d updated, %d deleted, applied %t",
+		mode, result.Created, result.Updated, result.Deleted, result.Applied)
+	tools.SendStatus(c, http.StatusOK, response.FromModelImportResult(result, mode, dryRun), l)
+}
+
+func parseImportMode(c *gin.Context) (entity.ImportMode, error) {
+	rawMode, ok := c.GetQuery(ImportModeParam)
+	if !ok {
+		return entity.ImportCreate, nil
+	}
+
+	mode := entity.ImportMode(rawMode)
+
+	switch mode {
+	case entity.ImportCreate, entity.ImportUpsert, entity.ImportReplace:
+		return mode, nil
+	default:
+		return "", ErrorImportModeIncorrectValue
+	}
+}
+
+// parseImportBanners читает баннеры из тела запроса построчно, пропуская пустые строки.
+// Ошибки формата возвращаются по строкам, а ошибка означает, что тело запроса нельзя прочитать.
+func parseImportBanners(c *gin.Context, l logger.Interface) ([]models.ImportBanner, []models.ImportLineError, error) {
+	banners := make([]models.ImportBanner, 0)
+	lineErrors := make([]models.ImportLineError, 0)
+
+	scanner := bufio.NewScanner(c.Request.Body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxImportLineSize)
+
+	var line, bannerLines, trailerLine uint64
+
+	for scanner.Scan() {
+		line++
+
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		if trailerLine != 0 {
+			return nil, nil, errors.Wrapf(ErrorImportTrailerNotLast, "line %d", trailerLine)
+		}
+
+		var trailer request.ImportTrailer
+		if json.Unmarshal(data, &trailer) == nil && trailer.Done != nil {
+			if !*trailer.Done || trailer.Count != bannerLines {
+				return nil, nil, errors.Wrapf(ErrorImportIncomplete, "%d banners of %d", bannerLines, trailer.Count)
+			}
+
+			trailerLine = line
+
+			continue
+		}
+
+		bannerLines++
+
+		var importBanner request.ImportBanner
+
+		if err := request.ValidateImportBanner(data); err != nil {
+			if errors.Is(err, evjson.ErrorInvalidJSON) {
+				err = tools.ErrorIncorrectBodyContent
+			}
+
+			lineErrors = append(lineErrors, models.ImportLineError{Line: line, Err: err})
+
+			continue
+		}
+
+		if err := json.Unmarshal(data, &importBanner); err != nil {
+			lineErrors = append(lineErrors, models.ImportLineError{Line: line, Err: tools.ErrorIncorrectBodyContent})
+
+			continue
+		}
+
+		banners = append(banners, *importBanner.ToModel(line))
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, nil, errors.Wrapf(ErrorImportLineTooLong, "line %d", line+1)
+		}
+
+		l.Error(errors.Wrapf(err, "can't read body"))
+
+		return nil, nil, tools.ErrorCannotReadBody
+	}
+
+	if len(banners) == 0 && len(lineErrors) == 0 {
+		return nil, nil, ErrorImportEmpty
+	}
+
+	return banners, lineErrors, nil
+}
+
+// importLineError возвращает ошибку строки импорта, которую можно показать админу.
+// Внутренние ошибки логируются и заменяются общей ошибкой сервера.
+func importLineError(lineError models.ImportLineError, l logger.Interface) error {
+	switch {
+	case errors.Is(lineError.Err, br.ErrorBannerConflictExists):
+		return br.ErrorBannerConflictExists
+	case errors.Is(lineError.Err, br.ErrorBannerScheduleIncorrect):
+		return br.ErrorBannerScheduleIncorrect
+	case errors.Is(lineError.Err, access.ErrorFeatureForbidden):
+		return lineError.Err
+	default:
+		l.Error(errors.Wrapf(lineError.Err, "can't import banner from line %d", lineError.Line))
+
+		return tools.ErrorServerError
+	}
+}
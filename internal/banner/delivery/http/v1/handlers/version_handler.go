@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/banner/delivery/http/v1/models/request"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/slices"
+	"net/http"
+	"strconv"
+
+	br "bannersrv/internal/banner/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const VersionField = "version"
+
+// GetBannerVersions
+//
+//	@Summary		Получение истории версий баннера.
+//	@Description	Возвращает версии баннера от новых к старым, включая перенесённые в архив.
+//	@Tags			banner
+//	@Param			id		path	integer	true	"Идентификатор баннера"
+//	@Param			limit	query	integer	false	"Лимит"
+//	@Param			offset	query	integer	false	"Оффсет"
+//	@Produce		json
+//	@Success		200	{array}		response.Version	"Список версий баннера"
+//	@Failure		400	{object}	tools.Error			"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Баннер с данным id не найден"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/banner/{id}/versions [get]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) GetBannerVersions(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(BannerIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get banner id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	limit, err := tools.ParseQueryParamToUint64(c, LimitParam, nil, ErrorLimitIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	offset, err := tools.ParseQueryParamToUint64(c, OffsetParam, nil, ErrorOffsetIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	versions, err := bh.usecase.GetBannerVersions(c.Request.Context(), types.ID(id), offset, limit)
+	if err != nil {
+		if errors.Is(err, br.ErrorBannerNotFound) {
+			tools.SendErrorStatus(c, err, http.StatusNotFound, l)
+
+			return
+		}
+
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't get banner versions"))
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, slices.Map(versions, func(version *models.Version) response.Version {
+		return *response.FromModelVersion(version)
+	}), l)
+}
+
+// GetBannerVersion
+//
+//	@Summary		Получение версии баннера.
+//	@Description	Возвращает версию баннера по её номеру, в том числе перенесённую в архив.
+//	@Tags			banner
+//	@Param			id		path	integer	true	"Идентификатор баннера"
+//	@Param			version	path	integer	true	"Версия баннера"
+//	@Produce		json
+//	@Success		200	{object}	response.Version	"Версия баннера"
+//	@Failure		400	{object}	tools.Error			"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Версия баннера не найдена"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/banner/{id}/versions/{version} [get]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) GetBannerVersion(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(BannerIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get banner id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	version, err := strconv.ParseUint(c.Param(VersionField), 10, 32)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get banner version"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	bannerVersion, err := bh.usecase.GetBannerVersion(c.Request.Context(), types.ID(id), uint32(version))
+	if err != nil {
+		if errors.Is(err, br.ErrorVersionNotFound) {
+			tools.SendErrorStatus(c, err, http.StatusNotFound, l)
+
+			return
+		}
+
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't get banner version"))
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, response.FromModelVersion(bannerVersion), l)
+}
+
+// RollbackBanner
+//
+//	@Summary		Откат баннера к предыдущей версии.
+//	@Description	|
+//					Делает содержимое указанной версии текущим, добавляя его как новую версию баннера,
+//					и сбрасывает кэш баннера. Версии, перенесённые в архив, недоступны для отката.
+//	@Tags			banner
+//	@Param			id	path	integer	true	"Идентификатор баннера"
+//	@Accept			json
+//	@Param			request	body	request.RollbackBanner	true	"Версия для отката"
+//	@Produce		json
+//	@Success		200	{object}	response.CurrentVersion	"Баннер успешно откачен"
+//	@Failure		400	{object}	tools.Error				"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		404	"Баннер или версия не найдены"
+//	@Failure		409	"Версия уже является текущей или перенесена в архив"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/banner/{id}/rollback [post]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) RollbackBanner(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение уникального идентификатора
+	id, err := strconv.ParseUint(c.Param(BannerIDField), 10, 64)
+	if err != nil {
+		tools.SendError(c, errors.Wrapf(err, "try get banner id"), http.StatusBadRequest, l)
+
+		return
+	}
+
+	// Получение значения тела запроса
+	var rollbackBanner request.RollbackBanner
+	if code, err := tools.ParseRequestBody(c.Request.Body, &rollbackBanner,
+		request.ValidateRollbackBanner, l); err != nil {
+		tools.SendError(c, err, code, l)
+
+		return
+	}
+
+	rollback, err := bh.usecase.RollbackBanner(c.Request.Context(), types.ID(id), rollbackBanner.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, br.ErrorBannerNotFound), errors.Is(err, br.ErrorVersionNotFound):
+			tools.SendErrorStatus(c, err, http.StatusNotFound, l)
+		case errors.Is(err, br.ErrorVersionArchived), errors.Is(err, br.ErrorVersionAlreadyCurrent):
+			tools.SendErrorStatus(c, err, http.StatusConflict, l)
+		default:
+			if tools.SendAccessError(c, err, l) {
+				return
+			}
+
+			if tools.SendContextError(c, err, l) {
+				return
+			}
+
+			tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+			l.Error(errors.Wrapf(err, "can't rollback banner"))
+		}
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, &response.CurrentVersion{Version: rollback.Version}, l)
+}
+
+// GetDefaultRetention
+//
+//	@Summary		Получение глобального числа хранимых версий.
+//	@Description	Возвращает число хранимых версий баннеров, для которых не задано собственное значение.
+//	@Tags			banner
+//	@Produce		json
+//	@Success		200	{object}	response.Retention	"Глобальное число хранимых версий"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/settings/retention [get]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) GetDefaultRetention(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	retention, err := bh.usecase.GetDefaultRetention(c.Request.Context())
+	if err != nil {
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't get default retention"))
+
+		return
+	}
+
+	tools.SendStatus(c, http.StatusOK, &response.Retention{Retention: retention}, l)
+}
+
+// SetDefaultRetention
+//
+//	@Summary		Изменение глобального числа хранимых версий.
+//	@Description	|
+//					Задаёт число хранимых версий баннеров, для которых не задано собственное значение,
+//					и переносит в архив их лишние версии. Доступно только админу с полным доступом ко всем фичам.
+//	@Tags			banner
+//	@Accept			json
+//	@Param			request	body	request.SetRetention	true	"Число хранимых версий"
+//	@Success		200	"Число хранимых версий успешно изменено"
+//	@Failure		400	{object}	tools.Error	"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/settings/retention [put]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) SetDefaultRetention(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	// Получение значения тела запроса
+	var setRetention request.SetRetention
+	if code, err := tools.ParseRequestBody(c.Request.Body, &setRetention,
+		request.ValidateSetRetention, l); err != nil {
+		tools.SendError(c, err, code, l)
+
+		return
+	}
+
+	if err := bh.usecase.SetDefaultRetention(c.Request.Context(), setRetention.Retention); err != nil {
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't set default retention"))
+
+		return
+	}
+
+	l.Info("default retention was set to %d", setRetention.Retention)
+	tools.SendStatus(c, http.StatusOK, nil, l)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/banner/delivery/http/v1/models/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// WarmCache
+//
+//	@Summary		Прогрев кэша баннеров.
+//	@Description	|
+//					Сохраняет в кэш баннеры самых запрашиваемых пользователями пар фича-тэг, которых ещё нет в кэше.
+//					Популярность пар считается по успешным запросам баннера пользователем.
+//					Неактивные баннеры, баннеры вне окна показа и баннеры с идущим экспериментом не кэшируются.
+//					Доступно только админу с доступом ко всем фичам.
+//	@Tags			cache
+//	@Param			limit	query	integer	false	"Число самых популярных пар"
+//	@Produce		json
+//	@Success		200	{object}	response.WarmResult	"Итог прогрева"
+//	@Failure		400	{object}	tools.Error			"Некорректные данные"
+//	@Failure		401	"Пользователь не авторизован"
+//	@Failure		403	"Пользователь не имеет доступа"
+//	@Failure		500	{object}	tools.Error	"Внутренняя ошибка сервера"
+//	@Failure		504	{object}	tools.Error	"Превышено время обработки запроса"
+//	@Router			/cache/warm [post]
+//
+//	@Security		AdminToken
+func (bh *BannerHandlers) WarmCache(c *gin.Context) {
+	l := middleware.GetLogger(c)
+
+	limit, err := tools.ParseQueryParamToUint64(c, LimitParam, nil, ErrorLimitIncorrectType, l)
+	if err != nil {
+		tools.SendError(c, err, http.StatusBadRequest, l)
+
+		return
+	}
+
+	result, err := bh.usecase.WarmCache(c.Request.Context(), limit)
+	if err != nil {
+		if tools.SendAccessError(c, err, l) {
+			return
+		}
+
+		if tools.SendContextError(c, err, l) {
+			return
+		}
+
+		tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+		l.Error(errors.Wrapf(err, "can't warm cache"))
+
+		return
+	}
+
+	l.Info("cache was warmed with %d banners", result.Warmed)
+	tools.SendStatus(c, http.StatusOK, response.FromModelWarmResult(result), l)
+}
@@ -5,6 +5,8 @@ import (
 	"bannersrv/internal/pkg/evjson"
 	"bannersrv/internal/pkg/types"
 	"encoding/json"
+	"math"
+	"time"
 
 	"github.com/miladibra10/vjson"
 )
@@ -18,6 +20,12 @@ type CreateBanner struct {
 	FeatureID types.ID `json:"feature_id" swaggertype:"integer" format:"uint64"`
 	// Идентификаторы тегов
 	TagsIDs []types.ID `json:"tag_ids"`
+	// Начало окна показа баннера
+	StartAt *time.Time `json:"start_at,omitempty" swaggertype:"string" format:"date-time"`
+	// Конец окна показа баннера
+	EndAt *time.Time `json:"end_at,omitempty" swaggertype:"string" format:"date-time"`
+	// Приоритет баннера при выборе среди баннеров нескольких тэгов пользователя, больше -- важнее
+	Priority int32 `json:"priority,omitempty" swaggertype:"integer" format:"int32"`
 }
 
 func ValidateCreateBanner(data []byte) error {
@@ -26,6 +34,9 @@ func ValidateCreateBanner(data []byte) error {
 		vjson.Boolean("is_active").Required(),
 		vjson.Integer("feature_id").Positive().Required(),
 		vjson.Array("tag_ids", vjson.Integer("id").Positive()).Required(),
+		vjson.String("start_at"),
+		vjson.String("end_at"),
+		vjson.Integer("priority").Range(math.MinInt32, math.MaxInt32),
 	)
 
 	return schema.ValidateBytes(data)
@@ -40,6 +51,16 @@ type UpdateBanner struct {
 	FeatureID *types.ID `json:"feature_id,omitempty" swaggertype:"integer" format:"uint64"`
 	// Идентификаторы тегов
 	TagsIDs []types.ID `json:"tag_ids,omitempty"`
+	// Начало окна показа баннера, null -- снять ограничение
+	StartAt types.Object[time.Time] `json:"start_at,omitempty" swaggertype:"string" format:"date-time"`
+	// Конец окна показа баннера, null -- снять ограничение
+	EndAt types.Object[time.Time] `json:"end_at,omitempty" swaggertype:"string" format:"date-time"`
+	// Число хранимых версий баннера, 0 -- использовать глобальное значение
+	Retention *uint32 `json:"retention,omitempty" swaggertype:"integer" format:"uint32"`
+	// Время жизни баннера в кэше в миллисекундах, 0 -- использовать значение фичи
+	CacheTTL *uint32 `json:"cache_ttl,omitempty" swaggertype:"integer" format:"uint32"`
+	// Приоритет баннера при выборе среди баннеров нескольких тэгов пользователя, больше -- важнее
+	Priority *int32 `json:"priority,omitempty" swaggertype:"integer" format:"int32"`
 }
 
 func ValidateUpdateBanner(data []byte) error {
@@ -48,6 +69,11 @@ func ValidateUpdateBanner(data []byte) error {
 		vjson.Boolean("is_active"),
 		vjson.Integer("feature_id").Positive(),
 		vjson.Array("tag_ids", vjson.Integer("id").Positive()),
+		vjson.String("start_at"),
+		vjson.String("end_at"),
+		vjson.Integer("retention").Min(0),
+		vjson.Integer("cache_ttl").Min(0),
+		vjson.Integer("priority").Range(math.MinInt32, math.MaxInt32),
 	)
 
 	return schema.ValidateBytes(data)
@@ -62,5 +88,17 @@ func (ub *UpdateBanner) ToModel() *models.BannerUpdate {
 			IsNull: len(ub.TagsIDs) == 0,
 			Value:  ub.TagsIDs,
 		},
+		StartAt:   ub.StartAt.ToNullableObject(),
+		EndAt:     ub.EndAt.ToNullableObject(),
+		Retention: types.ObjectFromPointer(ub.Retention),
+		CacheTTL:  types.ObjectFromPointer(ub.CacheTTL),
+		Priority:  types.ObjectFromPointer(ub.Priority),
+	}
+}
+
+func (cb *CreateBanner) ToScheduleModel() *models.Schedule {
+	return &models.Schedule{
+		StartAt: cb.StartAt,
+		EndAt:   cb.EndAt,
 	}
 }
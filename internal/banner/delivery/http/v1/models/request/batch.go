@@ -0,0 +1,109 @@
+package request
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/evjson"
+	"bannersrv/internal/pkg/types"
+	"encoding/json"
+
+	"github.com/miladibra10/vjson"
+	"github.com/pkg/errors"
+)
+
+// MaxBatchOperations наибольшее число операций в одном пакетном изменении баннеров
+const MaxBatchOperations = 500
+
+const (
+	// BatchAtomic операции применяются, только если все они успешны
+	BatchAtomic = "atomic"
+	// BatchBestEffort применяются все успешные операции
+	BatchBestEffort = "best_effort"
+)
+
+var (
+	ErrorBatchBannerNotPresented   = errors.New("banner not presented in operation")
+	ErrorBatchBannerIDNotPresented = errors.New("banner id not presented in operation")
+)
+
+type BatchOperation struct {
+	// Действие над баннером
+	Action entity.BatchAction `json:"action" swaggertype:"string" enums:"create,update,delete"`
+	// Идентификатор изменяемого или удаляемого баннера
+	BannerID *types.ID `json:"banner_id,omitempty" swaggertype:"integer" format:"uint64"`
+	// Поля создаваемого баннера, как в POST /banner, или изменяемые поля, как в PATCH /banner/{id}
+	Banner json.RawMessage `json:"banner,omitempty" swaggertype:"object" additionalProperties:"true"`
+}
+
+type BatchBanners struct {
+	// Режим применения операций, по умолчанию atomic
+	Mode string `json:"mode,omitempty" enums:"atomic,best_effort"`
+	// Операции над баннерами, выполняемые по порядку
+	Operations []BatchOperation `json:"operations"`
+}
+
+func ValidateBatchBanners(data []byte) error {
+	schema := evjson.NewSchema(
+		vjson.String("mode").Choices(BatchAtomic, BatchBestEffort),
+		vjson.Array("operations", vjson.Object("operation", vjson.NewSchema(
+			vjson.String("action").Choices(
+				string(entity.BatchCreate), string(entity.BatchUpdate), string(entity.BatchDelete),
+			).Required(),
+			vjson.Integer("banner_id").Positive(),
+			vjson.Object("banner", vjson.NewSchema()),
+		))).MinLength(1).MaxLength(MaxBatchOperations).Required(),
+	)
+
+	return schema.ValidateBytes(data)
+}
+
+// ToModel проверяет поля операции в зависимости от действия и преобразует её в модель
+func (bo *BatchOperation) ToModel() (*models.BatchOperation, error) {
+	operation := &models.BatchOperation{Action: bo.Action}
+
+	if bo.Action != entity.BatchCreate {
+		if bo.BannerID == nil {
+			return nil, ErrorBatchBannerIDNotPresented
+		}
+
+		operation.ID = *bo.BannerID
+	}
+
+	if bo.Action != entity.BatchDelete && bo.Banner == nil {
+		return nil, ErrorBatchBannerNotPresented
+	}
+
+	switch bo.Action {
+	case entity.BatchCreate:
+		if err := ValidateCreateBanner(bo.Banner); err != nil {
+			return nil, err
+		}
+
+		var createBanner CreateBanner
+		if err := json.Unmarshal(bo.Banner, &createBanner); err != nil {
+			return nil, errors.Wrap(err, "can't parse banner")
+		}
+
+		operation.Create = &models.BannerCreate{
+			FeatureID: createBanner.FeatureID,
+			TagIDs:    createBanner.TagsIDs,
+			Content:   createBanner.Content,
+			IsActive:  createBanner.IsActive,
+			Schedule:  createBanner.ToScheduleModel(),
+			Priority:  createBanner.Priority,
+		}
+	case entity.BatchUpdate:
+		if err := ValidateUpdateBanner(bo.Banner); err != nil {
+			return nil, err
+		}
+
+		var updateBanner UpdateBanner
+		if err := json.Unmarshal(bo.Banner, &updateBanner); err != nil {
+			return nil, errors.Wrap(err, "can't parse banner")
+		}
+
+		operation.Update = updateBanner.ToModel()
+	}
+
+	return operation, nil
+}
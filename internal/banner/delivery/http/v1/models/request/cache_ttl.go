@@ -0,0 +1,20 @@
+package request
+
+import (
+	"bannersrv/internal/pkg/evjson"
+
+	"github.com/miladibra10/vjson"
+)
+
+type SetCacheTTL struct {
+	// Время жизни баннеров фичи в кэше в миллисекундах
+	CacheTTL uint32 `json:"cache_ttl" swaggertype:"integer" format:"uint32"`
+}
+
+func ValidateSetCacheTTL(data []byte) error {
+	schema := evjson.NewSchema(
+		vjson.Integer("cache_ttl").Positive().Required(),
+	)
+
+	return schema.ValidateBytes(data)
+}
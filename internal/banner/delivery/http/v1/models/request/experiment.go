@@ -0,0 +1,58 @@
+package request
+
+import (
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/evjson"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/slices"
+	"encoding/json"
+
+	"github.com/miladibra10/vjson"
+)
+
+const minExperimentVariants = 2
+
+type Variant struct {
+	// Вес варианта, доля пользователей варианта равна его весу, делённому на сумму весов всех вариантов
+	Weight uint32 `json:"weight" swaggertype:"integer" format:"uint32"`
+	// Содержимое баннера в варианте
+	Content json.RawMessage `json:"content" swaggertype:"object" additionalProperties:"true"`
+}
+
+type CreateExperiment struct {
+	// Варианты содержимого баннера
+	Variants []Variant `json:"variants"`
+}
+
+func ValidateCreateExperiment(data []byte) error {
+	schema := evjson.NewSchema(
+		vjson.Array("variants", vjson.Object("variant", vjson.NewSchema(
+			vjson.Integer("weight").Positive().Required(),
+			vjson.Object("content", vjson.NewSchema()).Required(),
+		))).MinLength(minExperimentVariants).Required(),
+	)
+
+	return schema.ValidateBytes(data)
+}
+
+func (ce *CreateExperiment) ToModel() []models.Variant {
+	return slices.Map(ce.Variants, func(variant *Variant) models.Variant {
+		return models.Variant{
+			Weight:  variant.Weight,
+			Content: variant.Content,
+		}
+	})
+}
+
+type ConcludeExperiment struct {
+	// Идентификатор победившего варианта
+	WinnerVariantID types.ID `json:"winner_variant_id" swaggertype:"integer" format:"uint64"`
+}
+
+func ValidateConcludeExperiment(data []byte) error {
+	schema := evjson.NewSchema(
+		vjson.Integer("winner_variant_id").Positive().Required(),
+	)
+
+	return schema.ValidateBytes(data)
+}
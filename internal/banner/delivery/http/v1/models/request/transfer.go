@@ -0,0 +1,101 @@
+package request
+
+import (
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/evjson"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/slices"
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/miladibra10/vjson"
+)
+
+type ImportVersion struct {
+	// Содержимое баннера
+	Content json.RawMessage `json:"content" swaggertype:"object" additionalProperties:"true"`
+	// Версия содержимого баннера
+	Version uint32 `json:"version" swaggertype:"integer" format:"uint32"`
+	// Дата создания версии, без неё версия создаётся с текущей датой
+	CreatedAt *time.Time `json:"created_at,omitempty" swaggertype:"string" format:"date-time"`
+}
+
+// ImportTrailer последняя строка выгрузки. Импорт выгрузки с такой строкой применяется,
+// только если выгрузка полная и число баннеров в ней совпадает с Count.
+type ImportTrailer struct {
+	Done  *bool  `json:"done"`
+	Count uint64 `json:"count"`
+}
+
+// ImportBanner строка импорта в формате выгрузки баннеров, даты создания и обновления баннера не учитываются
+type ImportBanner struct {
+	// Идентификатор баннера, учитывается только при импорте с заменой
+	ID *types.ID `json:"banner_id,omitempty" swaggertype:"integer" format:"uint64"`
+	// Версии баннера, последняя из них становится текущей
+	Versions []ImportVersion `json:"versions"`
+	// Идентификатор фичи
+	FeatureID types.ID `json:"feature_id" swaggertype:"integer" format:"uint64"`
+	// Идентификаторы тэгов
+	TagIDs []types.ID `json:"tag_ids"`
+	// Флаг активности баннера
+	IsActive bool `json:"is_active" swaggertype:"boolean"`
+	// Начало окна показа баннера
+	StartAt *time.Time `json:"start_at,omitempty" swaggertype:"string" format:"date-time"`
+	// Конец окна показа баннера
+	EndAt *time.Time `json:"end_at,omitempty" swaggertype:"string" format:"date-time"`
+	// Число хранимых версий баннера
+	Retention *uint32 `json:"retention,omitempty" swaggertype:"integer" format:"uint32"`
+	// Время жизни баннера в кэше в миллисекундах
+	CacheTTL *uint32 `json:"cache_ttl,omitempty" swaggertype:"integer" format:"uint32"`
+	// Приоритет баннера при выборе среди баннеров нескольких тэгов пользователя
+	Priority int32 `json:"priority,omitempty" swaggertype:"integer" format:"int32"`
+}
+
+func ValidateImportBanner(data []byte) error {
+	schema := evjson.NewSchema(
+		vjson.Integer("banner_id").Positive(),
+		vjson.Array("versions", vjson.Object("version", vjson.NewSchema(
+			vjson.Object("content", vjson.NewSchema()).Required(),
+			vjson.Integer("version").Positive().Required(),
+			vjson.String("created_at"),
+		))).MinLength(1).Required(),
+		vjson.Integer("feature_id").Positive().Required(),
+		vjson.Array("tag_ids", vjson.Integer("id").Positive()).MinLength(1).Required(),
+		vjson.Boolean("is_active").Required(),
+		vjson.String("start_at"),
+		vjson.String("end_at"),
+		vjson.Integer("retention").Positive(),
+		vjson.Integer("cache_ttl").Positive(),
+		vjson.Integer("priority").Range(math.MinInt32, math.MaxInt32),
+	)
+
+	return schema.ValidateBytes(data)
+}
+
+func (ib *ImportBanner) ToModel(line uint64) *models.ImportBanner {
+	return &models.ImportBanner{
+		Line:      line,
+		ID:        ib.ID,
+		FeatureID: ib.FeatureID,
+		TagIDs:    ib.TagIDs,
+		IsActive:  ib.IsActive,
+		StartAt:   ib.StartAt,
+		EndAt:     ib.EndAt,
+		Retention: ib.Retention,
+		CacheTTL:  ib.CacheTTL,
+		Priority:  ib.Priority,
+		Versions: slices.Map(ib.Versions, func(version *ImportVersion) models.Content {
+			content := models.Content{
+				Version: version.Version,
+				Content: version.Content,
+			}
+
+			if version.CreatedAt != nil {
+				content.CreatedAt = *version.CreatedAt
+			}
+
+			return content
+		}),
+	}
+}
@@ -0,0 +1,34 @@
+package request
+
+import (
+	"bannersrv/internal/pkg/evjson"
+	"bannersrv/internal/pkg/types"
+
+	"github.com/miladibra10/vjson"
+)
+
+// MaxUserBannersFeatures наибольшее число фич в одном запросе баннеров пользователем
+const MaxUserBannersFeatures = 100
+
+type GetUserBanners struct {
+	// Идентификатор тэга группы пользователей
+	TagID types.ID `json:"tag_id" swaggertype:"integer" format:"uint64"`
+	// Идентификаторы фич
+	FeatureIDs []types.ID `json:"feature_ids"`
+	// Идентификатор пользователя для выбора вариантов экспериментов
+	UserID *string `json:"user_id,omitempty"`
+	// Получать актуальную информацию
+	UseLastRevision bool `json:"use_last_revision,omitempty" swaggertype:"boolean"`
+}
+
+func ValidateGetUserBanners(data []byte) error {
+	schema := evjson.NewSchema(
+		vjson.Integer("tag_id").Positive().Required(),
+		vjson.Array("feature_ids", vjson.Integer("id").Positive()).
+			MinLength(1).MaxLength(MaxUserBannersFeatures).Required(),
+		vjson.String("user_id"),
+		vjson.Boolean("use_last_revision"),
+	)
+
+	return schema.ValidateBytes(data)
+}
@@ -0,0 +1,34 @@
+package request
+
+import (
+	"bannersrv/internal/pkg/evjson"
+	"math"
+
+	"github.com/miladibra10/vjson"
+)
+
+type RollbackBanner struct {
+	// Версия баннера, содержимое которой станет текущим
+	Version uint32 `json:"version" swaggertype:"integer" format:"uint32"`
+}
+
+func ValidateRollbackBanner(data []byte) error {
+	schema := evjson.NewSchema(
+		vjson.Integer("version").Positive().Required(),
+	)
+
+	return schema.ValidateBytes(data)
+}
+
+type SetRetention struct {
+	// Число хранимых версий баннеров, для которых не задано собственное значение
+	Retention uint32 `json:"retention" swaggertype:"integer" format:"uint32"`
+}
+
+func ValidateSetRetention(data []byte) error {
+	schema := evjson.NewSchema(
+		vjson.Integer("retention").Range(1, math.MaxUint32).Required(),
+	)
+
+	return schema.ValidateBytes(data)
+}
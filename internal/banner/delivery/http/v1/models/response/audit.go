@@ -0,0 +1,64 @@
+package response
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/types"
+	"time"
+)
+
+type BannerSnapshot struct {
+	// Идентификатор фичи
+	FeatureID types.ID `json:"feature_id" swaggertype:"integer" format:"uint64"`
+	// Идентификаторы тэгов
+	TagIDs []types.ID `json:"tag_ids" swaggertype:"array,integer" format:"uint64"`
+	// Флаг активности баннера
+	IsActive bool `json:"is_active" swaggertype:"boolean"`
+	// Текущая версия содержимого баннера
+	Version uint32 `json:"version" swaggertype:"integer" format:"uint32"`
+}
+
+type AuditEntry struct {
+	// Идентификатор записи журнала
+	ID types.ID `json:"id" swaggertype:"integer" format:"uint64"`
+	// Админ, выполнивший действие
+	Actor *string `json:"actor,omitempty"`
+	// Идентификатор запроса, в котором было выполнено действие
+	RequestID *string `json:"request_id,omitempty"`
+	// Действие над баннером
+	Action string `json:"action" enums:"create,update,delete,rollback,publish,conclude,draft,approve,reject"`
+	// Идентификатор баннера
+	BannerID types.ID `json:"banner_id" swaggertype:"integer" format:"uint64"`
+	// Состояние баннера до изменения
+	Before *BannerSnapshot `json:"before,omitempty"`
+	// Состояние баннера после изменения
+	After *BannerSnapshot `json:"after,omitempty"`
+	// Дата действия
+	CreatedAt time.Time `json:"created_at" swaggertype:"string" format:"date-time"`
+}
+
+func FromEntityBannerSnapshot(snapshot *entity.BannerSnapshot) *BannerSnapshot {
+	if snapshot == nil {
+		return nil
+	}
+
+	return &BannerSnapshot{
+		FeatureID: snapshot.FeatureID,
+		TagIDs:    snapshot.TagIDs,
+		IsActive:  snapshot.IsActive,
+		Version:   snapshot.Version,
+	}
+}
+
+func FromModelAuditEntry(entry *models.AuditEntry) *AuditEntry {
+	return &AuditEntry{
+		ID:        entry.ID,
+		Actor:     entry.Actor,
+		RequestID: entry.RequestID,
+		Action:    string(entry.Action),
+		BannerID:  entry.BannerID,
+		Before:    FromEntityBannerSnapshot(entry.Before),
+		After:     FromEntityBannerSnapshot(entry.After),
+		CreatedAt: entry.CreatedAt,
+	}
+}
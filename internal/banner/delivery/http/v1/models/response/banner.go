@@ -25,7 +25,7 @@ type Content struct {
 type Banner struct {
 	// Идентификатор баннера
 	ID types.ID `json:"banner_id" swaggertype:"integer" format:"uint64"`
-	// Последние три версии баннера
+	// Хранимые версии баннера, без перенесённых в архив
 	Versions []Content `json:"versions"`
 	// Идентификатор фичи
 	FeatureID types.ID `json:"feature_id" swaggertype:"integer"`
@@ -33,6 +33,16 @@ type Banner struct {
 	TagIDs []types.ID `json:"tag_ids"`
 	// Флаг активности баннера
 	IsActive bool `json:"is_active" swaggertype:"boolean" format:"uint64"`
+	// Начало окна показа баннера
+	StartAt *time.Time `json:"start_at,omitempty" swaggertype:"string" format:"date-time"`
+	// Конец окна показа баннера
+	EndAt *time.Time `json:"end_at,omitempty" swaggertype:"string" format:"date-time"`
+	// Число хранимых версий баннера, если оно отличается от глобального
+	Retention *uint32 `json:"retention,omitempty" swaggertype:"integer" format:"uint32"`
+	// Время жизни баннера в кэше в миллисекундах, если оно отличается от времени жизни фичи
+	CacheTTL *uint32 `json:"cache_ttl,omitempty" swaggertype:"integer" format:"uint32"`
+	// Приоритет баннера при выборе среди баннеров нескольких тэгов пользователя
+	Priority int32 `json:"priority" swaggertype:"integer" format:"int32"`
 	// Дата создания баннера
 	CreatedAt time.Time `json:"created_at" swaggertype:"string" format:"date-time"`
 	// Дата обновления баннера
@@ -56,6 +66,11 @@ func FromModelBanner(banner *models.Banner) *Banner {
 		FeatureID: banner.FeatureID,
 		TagIDs:    banner.TagIDs,
 		IsActive:  banner.IsActive,
+		StartAt:   banner.StartAt,
+		EndAt:     banner.EndAt,
+		Retention: banner.Retention,
+		CacheTTL:  banner.CacheTTL,
+		Priority:  banner.Priority,
 		CreatedAt: banner.CreatedAt,
 		UpdatedAt: banner.UpdatedAt,
 	}
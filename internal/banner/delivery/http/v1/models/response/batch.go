@@ -0,0 +1,30 @@
+package response
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/pkg/types"
+)
+
+type BatchOperationResult struct {
+	// Действие над баннером
+	Action entity.BatchAction `json:"action" swaggertype:"string" enums:"create,update,delete"`
+	// Статус операции, такой же, как у отдельного запроса с этим действием
+	Status int `json:"status"`
+	// Идентификатор созданного, изменённого или удалённого баннера
+	BannerID *types.ID `json:"banner_id,omitempty" swaggertype:"integer" format:"uint64"`
+	// Идентификатор черновика с новым содержимым изменённого баннера
+	DraftID *types.ID `json:"draft_id,omitempty" swaggertype:"integer" format:"uint64"`
+	// Ошибка операции
+	Error string `json:"error,omitempty"`
+	// Тэги, по которым баннер конфликтует с другим баннером той же фичи
+	ConflictTagIDs []types.ID `json:"conflict_tag_ids,omitempty"`
+}
+
+type BatchResult struct {
+	// Режим применения операций
+	Mode string `json:"mode" enums:"atomic,best_effort"`
+	// Флаг применения успешных операций
+	Applied bool `json:"applied"`
+	// Итоги операций в порядке операций запроса
+	Results []BatchOperationResult `json:"results"`
+}
@@ -0,0 +1,35 @@
+package response
+
+import (
+	"bannersrv/internal/banner/models"
+	"encoding/json"
+	"time"
+)
+
+type CacheEntry struct {
+	// Ключ записи в кэше
+	Key string `json:"key"`
+	// Содержимое баннера
+	Content json.RawMessage `json:"content" swaggertype:"object" additionalProperties:"true"`
+	// Флаг устаревшего баннера, который отдаётся с обновлением в фоне
+	Stale bool `json:"stale"`
+	// Время, до которого баннер считается свежим
+	FreshUntil time.Time `json:"fresh_until" swaggertype:"string" format:"date-time"`
+	// Оставшееся время жизни записи в миллисекундах
+	TTL int64 `json:"ttl" swaggertype:"integer" format:"int64"`
+}
+
+type PurgeResult struct {
+	// Число пар фича-тэг, кэш которых был сброшен
+	Purged uint64 `json:"purged" swaggertype:"integer" format:"uint64"`
+}
+
+func FromModelCacheEntry(entry *models.CacheEntry) *CacheEntry {
+	return &CacheEntry{
+		Key:        entry.Key,
+		Content:    entry.Content,
+		Stale:      entry.Stale,
+		FreshUntil: entry.FreshUntil,
+		TTL:        entry.TTL.Milliseconds(),
+	}
+}
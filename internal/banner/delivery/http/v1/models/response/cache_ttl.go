@@ -0,0 +1,24 @@
+package response
+
+import (
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/types"
+	"time"
+)
+
+type FeatureCacheTTL struct {
+	// Идентификатор фичи
+	FeatureID types.ID `json:"feature_id" swaggertype:"integer" format:"uint64"`
+	// Время жизни баннеров фичи в кэше в миллисекундах
+	CacheTTL uint32 `json:"cache_ttl" swaggertype:"integer" format:"uint32"`
+	// Дата изменения времени жизни
+	UpdatedAt time.Time `json:"updated_at" swaggertype:"string" format:"date-time"`
+}
+
+func FromModelFeatureCacheTTL(ttl *models.FeatureCacheTTL) *FeatureCacheTTL {
+	return &FeatureCacheTTL{
+		FeatureID: ttl.FeatureID,
+		CacheTTL:  ttl.CacheTTL,
+		UpdatedAt: ttl.UpdatedAt,
+	}
+}
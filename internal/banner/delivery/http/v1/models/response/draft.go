@@ -0,0 +1,48 @@
+package response
+
+import (
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/types"
+	"encoding/json"
+	"time"
+)
+
+type DraftID struct {
+	// Идентификатор созданного черновика содержимого
+	DraftID types.ID `json:"draft_id" swaggertype:"integer" format:"uint64"`
+}
+
+type Draft struct {
+	// Идентификатор черновика
+	ID types.ID `json:"draft_id" swaggertype:"integer" format:"uint64"`
+	// Идентификатор баннера
+	BannerID types.ID `json:"banner_id" swaggertype:"integer" format:"uint64"`
+	// Содержимое баннера в черновике
+	Content json.RawMessage `json:"content" swaggertype:"object" additionalProperties:"true"`
+	// Состояние черновика
+	Status string `json:"status" enums:"pending,approved,rejected,published"`
+	// Автор черновика
+	Author string `json:"author"`
+	// Админ, рассмотревший черновик
+	Reviewer *string `json:"reviewer,omitempty"`
+	// Версия баннера, созданная при публикации черновика
+	PublishedVersion *uint32 `json:"published_version,omitempty" swaggertype:"integer" format:"uint32"`
+	// Дата создания черновика
+	CreatedAt time.Time `json:"created_at" swaggertype:"string" format:"date-time"`
+	// Дата обновления черновика
+	UpdatedAt time.Time `json:"updated_at" swaggertype:"string" format:"date-time"`
+}
+
+func FromModelDraft(draft *models.Draft) *Draft {
+	return &Draft{
+		ID:               draft.ID,
+		BannerID:         draft.BannerID,
+		Content:          draft.Content,
+		Status:           string(draft.Status),
+		Author:           draft.Author,
+		Reviewer:         draft.Reviewer,
+		PublishedVersion: draft.PublishedVersion,
+		CreatedAt:        draft.CreatedAt,
+		UpdatedAt:        draft.UpdatedAt,
+	}
+}
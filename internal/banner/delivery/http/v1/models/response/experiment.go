@@ -0,0 +1,70 @@
+package response
+
+import (
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/slices"
+	"encoding/json"
+	"time"
+)
+
+type ExperimentID struct {
+	// Идентификатор созданного эксперимента
+	ExperimentID types.ID `json:"experiment_id" swaggertype:"integer" format:"uint64"`
+}
+
+type Variant struct {
+	// Идентификатор варианта
+	ID types.ID `json:"variant_id" swaggertype:"integer" format:"uint64"`
+	// Вес варианта
+	Weight uint32 `json:"weight" swaggertype:"integer" format:"uint32"`
+	// Содержимое баннера в варианте
+	Content json.RawMessage `json:"content" swaggertype:"object" additionalProperties:"true"`
+}
+
+type Experiment struct {
+	// Идентификатор эксперимента
+	ID types.ID `json:"experiment_id" swaggertype:"integer" format:"uint64"`
+	// Идентификатор баннера
+	BannerID types.ID `json:"banner_id" swaggertype:"integer" format:"uint64"`
+	// Состояние эксперимента
+	Status string `json:"status" enums:"running,paused,concluded"`
+	// Идентификатор победившего варианта
+	WinnerVariantID *types.ID `json:"winner_variant_id,omitempty" swaggertype:"integer" format:"uint64"`
+	// Варианты содержимого баннера
+	Variants []Variant `json:"variants"`
+	// Дата создания эксперимента
+	CreatedAt time.Time `json:"created_at" swaggertype:"string" format:"date-time"`
+	// Дата обновления эксперимента
+	UpdatedAt time.Time `json:"updated_at" swaggertype:"string" format:"date-time"`
+	// Дата завершения эксперимента
+	ConcludedAt *time.Time `json:"concluded_at,omitempty" swaggertype:"string" format:"date-time"`
+}
+
+type ConcludedExperiment struct {
+	// Версия баннера, созданная из победившего варианта
+	Version uint32 `json:"version" swaggertype:"integer" format:"uint32"`
+}
+
+func FromModelVariant(variant *models.Variant) *Variant {
+	return &Variant{
+		ID:      variant.ID,
+		Weight:  variant.Weight,
+		Content: variant.Content,
+	}
+}
+
+func FromModelExperiment(experiment *models.Experiment) *Experiment {
+	return &Experiment{
+		ID:              experiment.ID,
+		BannerID:        experiment.BannerID,
+		Status:          string(experiment.Status),
+		WinnerVariantID: experiment.WinnerVariantID,
+		Variants: slices.Map(experiment.Variants, func(variant *models.Variant) Variant {
+			return *FromModelVariant(variant)
+		}),
+		CreatedAt:   experiment.CreatedAt,
+		UpdatedAt:   experiment.UpdatedAt,
+		ConcludedAt: experiment.ConcludedAt,
+	}
+}
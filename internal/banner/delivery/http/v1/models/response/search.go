@@ -0,0 +1,27 @@
+package response
+
+import (
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/types"
+	"time"
+)
+
+type ContentMatch struct {
+	// Идентификатор баннера
+	BannerID types.ID `json:"banner_id" swaggertype:"integer" format:"uint64"`
+	// Версия, содержимое которой подошло под поиск
+	Version uint32 `json:"version" swaggertype:"integer" format:"uint32"`
+	// Флаг текущей версии баннера
+	Current bool `json:"current" swaggertype:"boolean"`
+	// Дата создания версии
+	CreatedAt time.Time `json:"created_at" swaggertype:"string" format:"date-time"`
+}
+
+func FromModelContentMatch(match *models.ContentMatch) *ContentMatch {
+	return &ContentMatch{
+		BannerID:  match.BannerID,
+		Version:   match.Version,
+		Current:   match.Current,
+		CreatedAt: match.CreatedAt,
+	}
+}
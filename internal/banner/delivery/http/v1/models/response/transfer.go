@@ -0,0 +1,55 @@
+package response
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/pkg/slices"
+)
+
+// ExportTrailer последняя строка выгрузки, по которой полную выгрузку можно отличить от оборванной
+type ExportTrailer struct {
+	// Флаг полной выгрузки
+	Done bool `json:"done"`
+	// Число выгруженных баннеров
+	Count uint64 `json:"count" swaggertype:"integer" format:"uint64"`
+	// Ошибка, из-за которой выгрузка оборвалась
+	Error string `json:"error,omitempty"`
+}
+
+type ImportLineError struct {
+	// Номер строки импорта, начиная с 1
+	Line uint64 `json:"line" swaggertype:"integer" format:"uint64"`
+	// Ошибка импорта строки
+	Error string `json:"error"`
+}
+
+type ImportResult struct {
+	// Режим импорта
+	Mode entity.ImportMode `json:"mode" swaggertype:"string" enums:"create,upsert,replace"`
+	// Флаг проверочного импорта без применения изменений
+	DryRun bool `json:"dry_run"`
+	// Флаг применения изменений, изменения не применяются при ошибке хотя бы в одной строке
+	Applied bool `json:"applied"`
+	// Число созданных баннеров
+	Created uint64 `json:"created" swaggertype:"integer" format:"uint64"`
+	// Число заменённых баннеров
+	Updated uint64 `json:"updated" swaggertype:"integer" format:"uint64"`
+	// Число удалённых перед импортом баннеров
+	Deleted uint64 `json:"deleted" swaggertype:"integer" format:"uint64"`
+	// Ошибки импорта по строкам
+	Errors []ImportLineError `json:"errors"`
+}
+
+func FromModelImportResult(result *models.ImportResult, mode entity.ImportMode, dryRun bool) *ImportResult {
+	return &ImportResult{
+		Mode:    mode,
+		DryRun:  dryRun,
+		Applied: result.Applied,
+		Created: result.Created,
+		Updated: result.Updated,
+		Deleted: result.Deleted,
+		Errors: slices.Map(result.Errors, func(lineError *models.ImportLineError) ImportLineError {
+			return ImportLineError{Line: lineError.Line, Error: lineError.Err.Error()}
+		}),
+	}
+}
@@ -0,0 +1,50 @@
+package response
+
+import (
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/types"
+	"encoding/json"
+	"net/http"
+)
+
+type UserBanner struct {
+	// Статус получения баннера: 200 -- баннер найден, 404 -- баннер с тэгом и фичёй не найден
+	Status int `json:"status" swaggertype:"integer"`
+	// Содержимое баннера
+	Content json.RawMessage `json:"content,omitempty" swaggertype:"object" additionalProperties:"true"`
+	// Идентификатор эксперимента, если был выдан его вариант
+	ExperimentID *types.ID `json:"experiment_id,omitempty" swaggertype:"integer" format:"uint64"`
+	// Идентификатор выданного варианта эксперимента
+	VariantID *types.ID `json:"variant_id,omitempty" swaggertype:"integer" format:"uint64"`
+	// Флаг баннера, отданного из кэша после истечения времени свежести
+	Stale bool `json:"stale,omitempty" swaggertype:"boolean"`
+}
+
+// FromModelUserBanners сопоставляет каждой запрошенной фиче её баннер или отметку, что баннер не найден
+func FromModelUserBanners(featureIDs []types.ID, banners map[types.ID]*models.UserBanner) map[types.ID]*UserBanner {
+	result := make(map[types.ID]*UserBanner, len(featureIDs))
+
+	for _, featureID := range featureIDs {
+		bnr, ok := banners[featureID]
+		if !ok {
+			result[featureID] = &UserBanner{Status: http.StatusNotFound}
+
+			continue
+		}
+
+		userBanner := &UserBanner{
+			Status:  http.StatusOK,
+			Content: bnr.Content,
+			Stale:   bnr.Stale,
+		}
+
+		if bnr.VariantID != nil {
+			userBanner.ExperimentID = bnr.ExperimentID
+			userBanner.VariantID = bnr.VariantID
+		}
+
+		result[featureID] = userBanner
+	}
+
+	return result
+}
@@ -0,0 +1,40 @@
+package response
+
+import (
+	"bannersrv/internal/banner/models"
+	"encoding/json"
+	"time"
+)
+
+type Version struct {
+	// Версия содержимого баннера
+	Version uint32 `json:"version" swaggertype:"integer" format:"uint32"`
+	// Содержимое баннера
+	Content json.RawMessage `json:"content" swaggertype:"object" additionalProperties:"true"`
+	// Дата создания версии
+	CreatedAt time.Time `json:"created_at" swaggertype:"string" format:"date-time"`
+	// Флаг переноса версии в архив
+	Archived bool `json:"archived" swaggertype:"boolean"`
+	// Дата переноса версии в архив
+	ArchivedAt *time.Time `json:"archived_at,omitempty" swaggertype:"string" format:"date-time"`
+}
+
+type Retention struct {
+	// Число хранимых версий баннеров, для которых не задано собственное значение
+	Retention uint32 `json:"retention" swaggertype:"integer" format:"uint32"`
+}
+
+type CurrentVersion struct {
+	// Текущая версия баннера после отката
+	Version uint32 `json:"version" swaggertype:"integer" format:"uint32"`
+}
+
+func FromModelVersion(version *models.Version) *Version {
+	return &Version{
+		Version:    version.Version,
+		Content:    version.Content,
+		CreatedAt:  version.CreatedAt,
+		Archived:   version.ArchivedAt != nil,
+		ArchivedAt: version.ArchivedAt,
+	}
+}
@@ -0,0 +1,20 @@
+package response
+
+import "bannersrv/internal/banner/models"
+
+type WarmResult struct {
+	// Число пар фича-тэг, баннеры которых сохранены в кэш
+	Warmed uint64 `json:"warmed" swaggertype:"integer" format:"uint64"`
+	// Число пар фича-тэг, баннеры которых уже были в кэше
+	Cached uint64 `json:"cached" swaggertype:"integer" format:"uint64"`
+	// Число пар фича-тэг без активного баннера или с идущим экспериментом
+	Skipped uint64 `json:"skipped" swaggertype:"integer" format:"uint64"`
+}
+
+func FromModelWarmResult(result *models.WarmResult) *WarmResult {
+	return &WarmResult{
+		Warmed:  result.Warmed,
+		Cached:  result.Cached,
+		Skipped: result.Skipped,
+	}
+}
@@ -5,31 +5,338 @@ import (
 	"time"
 )
 
+// ScheduleState состояние баннера относительно его окна показа
+type ScheduleState string
+
+const (
+	// ScheduleScheduled окно показа баннера ещё не началось
+	ScheduleScheduled ScheduleState = "scheduled"
+	// ScheduleLive баннер находится в окне показа
+	ScheduleLive ScheduleState = "live"
+	// ScheduleExpired окно показа баннера уже закончилось
+	ScheduleExpired ScheduleState = "expired"
+)
+
 type Content struct {
 	Version   uint32
 	Content   types.Content
 	CreatedAt time.Time
 }
 
+// Version версия содержимого баннера из истории, ArchivedAt задано для версий, перенесённых в архив
+type Version struct {
+	Version    uint32
+	Content    types.Content
+	CreatedAt  time.Time
+	ArchivedAt *time.Time
+}
+
+// CurrentVersion новая текущая версия баннера после отката или публикации черновика
+// и пары фича-тэг, по которым баннер мог быть закэширован
+type CurrentVersion struct {
+	Version   uint32
+	FeatureID types.ID
+	TagIDs    []types.ID
+}
+
+// BannerKeys фича и тэги баннера, по парам которых баннер мог быть закэширован
+type BannerKeys struct {
+	FeatureID types.ID
+	TagIDs    []types.ID
+}
+
+// Schedule окно показа баннера, отсутствующая граница означает отсутствие ограничения
+type Schedule struct {
+	StartAt *time.Time
+	EndAt   *time.Time
+}
+
 type Banner struct {
 	ID        types.ID
 	FeatureID types.ID
 	TagIDs    []types.ID
 	IsActive  bool
+	StartAt   *time.Time
+	EndAt     *time.Time
+	Retention *uint32
+	CacheTTL  *uint32
+	Priority  int32
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Versions  []Content
 }
 
+// UserBanner содержимое баннера, отдаваемое пользователю, вместе с окончанием окна его показа,
+// идентификатором запущенного для баннера эксперимента и временем жизни в кэше в миллисекундах,
+// заданным для баннера или его фичи
+type UserBanner struct {
+	Content      types.Content
+	EndAt        *time.Time
+	ExperimentID *types.ID
+	CacheTTL     *uint32
+}
+
 type BannerUpdate struct {
 	ID        types.ID
 	Content   *types.NullableObject[types.Content]
 	FeatureID *types.NullableID
 	TagIDs    *types.NullableObject[[]types.ID]
 	IsActive  *types.NullableObject[bool]
+	// StartAt и EndAt изменяют окно показа баннера, значение nil снимает ограничение
+	StartAt *types.NullableObject[*time.Time]
+	EndAt   *types.NullableObject[*time.Time]
+	// Retention число хранимых версий, нулевое значение сбрасывает его к глобальному
+	Retention *types.NullableObject[uint32]
+	// CacheTTL время жизни в кэше в миллисекундах, нулевое значение сбрасывает его к значению фичи
+	CacheTTL *types.NullableObject[uint32]
+	// Priority приоритет баннера при выборе среди баннеров нескольких тэгов пользователя
+	Priority *types.NullableObject[int32]
+}
+
+// FeatureCacheTTL время жизни баннеров фичи в кэше в миллисекундах
+type FeatureCacheTTL struct {
+	FeatureID types.ID
+	CacheTTL  uint32
+	UpdatedAt time.Time
 }
 
+// BannerInfo фильтр баннеров. FeatureIDs ограничивает фичи доступными админу, nil снимает ограничение.
+// Features и Tags оставляют баннеры хотя бы одной из фич и хотя бы с одним из тэгов,
+// границы From включаются в диапазон времени, а границы To нет.
 type BannerInfo struct {
-	FeatureID *types.NullableID
-	TagID     *types.NullableID
+	FeatureID   *types.NullableID
+	TagID       *types.NullableID
+	State       *types.NullableObject[ScheduleState]
+	FeatureIDs  []types.ID
+	Features    []types.ID
+	Tags        []types.ID
+	IsActive    *bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+}
+
+// BannerSort поле сортировки списка баннеров
+type BannerSort string
+
+const (
+	BannerSortID        BannerSort = "id"
+	BannerSortCreatedAt BannerSort = "created_at"
+	BannerSortUpdatedAt BannerSort = "updated_at"
+)
+
+// BannerCursor последний баннер предыдущей страницы списка: его идентификатор
+// и время из поля сортировки, если список отсортирован не по идентификатору
+type BannerCursor struct {
+	ID   types.ID
+	Time *time.Time
+}
+
+// BannerOrder порядок списка баннеров. Баннеры с одинаковым значением поля сортировки
+// упорядочиваются по идентификатору. Если задан Cursor, список начинается после него.
+type BannerOrder struct {
+	Sort   BannerSort
+	Desc   bool
+	Cursor *BannerCursor
+}
+
+// ExperimentStatus состояние A/B эксперимента над содержимым баннера
+type ExperimentStatus string
+
+const (
+	ExperimentRunning   ExperimentStatus = "running"
+	ExperimentPaused    ExperimentStatus = "paused"
+	ExperimentConcluded ExperimentStatus = "concluded"
+)
+
+type Variant struct {
+	ID      types.ID
+	Weight  uint32
+	Content types.Content
+}
+
+type Experiment struct {
+	ID              types.ID
+	BannerID        types.ID
+	Status          ExperimentStatus
+	WinnerVariantID *types.ID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	ConcludedAt     *time.Time
+	Variants        []Variant
+}
+
+// DraftStatus состояние черновика содержимого баннера
+type DraftStatus string
+
+const (
+	DraftPending   DraftStatus = "pending"
+	DraftApproved  DraftStatus = "approved"
+	DraftRejected  DraftStatus = "rejected"
+	DraftPublished DraftStatus = "published"
+)
+
+type Draft struct {
+	ID               types.ID
+	BannerID         types.ID
+	Content          types.Content
+	Status           DraftStatus
+	Author           string
+	Reviewer         *string
+	PublishedVersion *uint32
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// AuditAction действие админа над баннером, сохраняемое в журнал изменений
+type AuditAction string
+
+const (
+	AuditCreate   AuditAction = "create"
+	AuditUpdate   AuditAction = "update"
+	AuditDelete   AuditAction = "delete"
+	AuditRollback AuditAction = "rollback"
+	AuditPublish  AuditAction = "publish"
+	AuditConclude AuditAction = "conclude"
+	AuditDraft    AuditAction = "draft"
+	AuditApprove  AuditAction = "approve"
+	AuditReject   AuditAction = "reject"
+)
+
+// BannerSnapshot состояние баннера в журнале изменений, хранится в базе в виде json
+type BannerSnapshot struct {
+	FeatureID types.ID   `json:"feature_id"`
+	TagIDs    []types.ID `json:"tag_ids"`
+	IsActive  bool       `json:"is_active"`
+	Version   uint32     `json:"version"`
+}
+
+type AuditEntry struct {
+	ID        types.ID
+	Actor     *string
+	RequestID *string
+	Action    AuditAction
+	BannerID  types.ID
+	Before    *BannerSnapshot
+	After     *BannerSnapshot
+	CreatedAt time.Time
+}
+
+type AuditFilter struct {
+	BannerID *types.ID
+	Actor    *string
+	From     *time.Time
+	To       *time.Time
+	// FeatureIDs фичи, доступные админу, nil если доступны все.
+	// Записи отбираются по фиче баннера в снимках до и после изменения
+	FeatureIDs []types.ID
+}
+
+// ImportMode режим импорта баннеров
+type ImportMode string
+
+const (
+	// ImportCreate все баннеры импорта создаются заново с новыми идентификаторами
+	ImportCreate ImportMode = "create"
+	// ImportUpsert баннеры с существующими идентификаторами заменяются, остальные создаются
+	ImportUpsert ImportMode = "upsert"
+	// ImportReplace все баннеры удаляются и создаются заново из импорта с сохранением идентификаторов
+	ImportReplace ImportMode = "replace"
+)
+
+// ImportBanner баннер из строки Line импорта. ID учитывается только в режимах upsert и replace.
+type ImportBanner struct {
+	Line      uint64
+	ID        *types.ID
+	FeatureID types.ID
+	TagIDs    []types.ID
+	IsActive  bool
+	StartAt   *time.Time
+	EndAt     *time.Time
+	Retention *uint32
+	CacheTTL  *uint32
+	Priority  int32
+	Versions  []Content
+}
+
+// ImportLineError ошибка импорта баннера из строки Line
+type ImportLineError struct {
+	Line uint64
+	Err  error
+}
+
+// ImportResult итог импорта и пары фича-тэг затронутых баннеров, кэш которых нужно сбросить
+type ImportResult struct {
+	Created uint64
+	Updated uint64
+	Deleted uint64
+	Errors  []ImportLineError
+	Keys    []BannerKeys
+}
+
+// BatchAction действие над баннером в пакетном изменении
+type BatchAction string
+
+const (
+	BatchCreate BatchAction = "create"
+	BatchUpdate BatchAction = "update"
+	BatchDelete BatchAction = "delete"
+)
+
+// BannerCreate новый баннер
+type BannerCreate struct {
+	FeatureID types.ID
+	TagIDs    []types.ID
+	Content   types.Content
+	IsActive  bool
+	Schedule  *Schedule
+	Priority  int32
+}
+
+// BatchOperation операция пакетного изменения баннеров. Для создания задаётся Create, для изменения -- Update
+// и Draft, если новое содержимое нужно сохранить как черновик, а для удаления только ID.
+type BatchOperation struct {
+	Action BatchAction
+	ID     types.ID
+	Create *BannerCreate
+	Update *BannerUpdate
+	Draft  *types.Content
+}
+
+// BatchOperationResult итог операции пакетного изменения: идентификатор созданного баннера или черновика,
+// ошибка операции и тэги, по которым баннер конфликтует с другими баннерами фичи
+type BatchOperationResult struct {
+	ID             *types.ID
+	DraftID        *types.ID
+	Err            error
+	ConflictTagIDs []types.ID
+}
+
+// BatchResult итоги операций пакетного изменения в порядке операций и пары фича-тэг баннеров,
+// изменённых применёнными операциями, кэш которых нужно сбросить
+type BatchResult struct {
+	Applied    bool
+	Operations []BatchOperationResult
+	Keys       []BannerKeys
+}
+
+// ContentSearch поиск баннеров по содержимому. JSONPath задаёт предикат над содержимым версии, а Text --
+// запрос полнотекстового поиска по строковым значениям содержимого; заданные условия должны выполняться оба.
+// Без AllVersions ищется только текущая версия баннера, иначе все хранимые версии.
+// FeatureIDs ограничивает фичи доступными админу, nil снимает ограничение.
+type ContentSearch struct {
+	JSONPath    *string
+	Text        *string
+	AllVersions bool
+	Features    []types.ID
+	FeatureIDs  []types.ID
+}
+
+// ContentMatch версия баннера, содержимое которой подошло под поиск
+type ContentMatch struct {
+	BannerID  types.ID
+	Version   uint32
+	Current   bool
+	CreatedAt time.Time
 }
@@ -14,21 +14,202 @@ type Content struct {
 	CreatedAt time.Time
 }
 
+type Version struct {
+	Version    uint32
+	Content    json.RawMessage
+	CreatedAt  time.Time
+	ArchivedAt *time.Time
+}
+
+type CurrentVersion struct {
+	Version   uint32
+	FeatureID types.ID
+	TagIDs    []types.ID
+}
+
+type Schedule struct {
+	StartAt *time.Time
+	EndAt   *time.Time
+}
+
 type Banner struct {
 	ID        types.ID
 	FeatureID types.ID
 	TagIDs    []types.ID
 	IsActive  bool
+	StartAt   *time.Time
+	EndAt     *time.Time
+	Retention *uint32
+	CacheTTL  *uint32
+	Priority  int32
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Versions  []Content
 }
 
+// BannerList страница списка баннеров. Next указывает на последний баннер страницы, если за ним есть ещё баннеры,
+// а Total содержит число всех баннеров фильтра, если его запросили.
+type BannerList struct {
+	Banners []Banner
+	Next    *entity.BannerCursor
+	Total   *uint64
+}
+
+// UserBanner содержимое баннера для пользователя. ExperimentID указывает на запущенный эксперимент,
+// а VariantID на вариант эксперимента, содержимое которого было выбрано для пользователя.
+// Stale отмечает баннер, отданный из кэша после истечения времени его свежести.
+type UserBanner struct {
+	Content      json.RawMessage
+	EndAt        *time.Time
+	ExperimentID *types.ID
+	VariantID    *types.ID
+	Stale        bool
+}
+
+type Variant struct {
+	ID      types.ID
+	Weight  uint32
+	Content json.RawMessage
+}
+
+type Experiment struct {
+	ID              types.ID
+	BannerID        types.ID
+	Status          entity.ExperimentStatus
+	WinnerVariantID *types.ID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	ConcludedAt     *time.Time
+	Variants        []Variant
+}
+
+type Draft struct {
+	ID               types.ID
+	BannerID         types.ID
+	Content          json.RawMessage
+	Status           entity.DraftStatus
+	Author           string
+	Reviewer         *string
+	PublishedVersion *uint32
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+type AuditEntry struct {
+	ID        types.ID
+	Actor     *string
+	RequestID *string
+	Action    entity.AuditAction
+	BannerID  types.ID
+	Before    *entity.BannerSnapshot
+	After     *entity.BannerSnapshot
+	CreatedAt time.Time
+}
+
+type ContentMatch struct {
+	BannerID  types.ID
+	Version   uint32
+	Current   bool
+	CreatedAt time.Time
+}
+
+type BannerCreate struct {
+	FeatureID types.ID
+	TagIDs    []types.ID
+	Content   json.RawMessage
+	IsActive  bool
+	Schedule  *Schedule
+	Priority  int32
+}
+
+// BatchOperation операция пакетного изменения баннеров. Для создания задаётся Create, для изменения -- ID
+// и Update, а для удаления только ID.
+type BatchOperation struct {
+	Action entity.BatchAction
+	ID     types.ID
+	Create *BannerCreate
+	Update *BannerUpdate
+}
+
+type BatchOperationResult struct {
+	ID             *types.ID
+	DraftID        *types.ID
+	Err            error
+	ConflictTagIDs []types.ID
+}
+
+// BatchResult итоги операций пакетного изменения в порядке операций. Applied показывает,
+// были ли применены успешные операции.
+type BatchResult struct {
+	Applied    bool
+	Operations []BatchOperationResult
+}
+
+// ImportBanner баннер из строки Line импорта. ID учитывается только в режимах upsert и replace.
+type ImportBanner struct {
+	Line      uint64
+	ID        *types.ID
+	FeatureID types.ID
+	TagIDs    []types.ID
+	IsActive  bool
+	StartAt   *time.Time
+	EndAt     *time.Time
+	Retention *uint32
+	CacheTTL  *uint32
+	Priority  int32
+	Versions  []Content
+}
+
+type ImportLineError struct {
+	Line uint64
+	Err  error
+}
+
+// ImportResult итог импорта. При ошибках в строках или проверочном импорте изменения не применяются,
+// а счётчики показывают, сколько баннеров было бы создано, заменено и удалено.
+type ImportResult struct {
+	Applied bool
+	Created uint64
+	Updated uint64
+	Deleted uint64
+	Errors  []ImportLineError
+}
+
+// WarmResult итог прогрева кэша: сколько пар сохранено в кэш, сколько уже было в кэше
+// и сколько пропущено, так как у них нет активного баннера или по ним идёт эксперимент
+type WarmResult struct {
+	Warmed  uint64
+	Cached  uint64
+	Skipped uint64
+}
+
+// CacheEntry запись кэша баннера: содержимое, время, до которого баннер считается свежим,
+// и оставшееся время жизни записи
+type CacheEntry struct {
+	Key        string
+	Content    json.RawMessage
+	Stale      bool
+	FreshUntil time.Time
+	TTL        time.Duration
+}
+
+// FeatureCacheTTL время жизни баннеров фичи в кэше в миллисекундах
+type FeatureCacheTTL struct {
+	FeatureID types.ID
+	CacheTTL  uint32
+	UpdatedAt time.Time
+}
+
 type BannerUpdate struct {
 	Content   *types.NullableObject[json.RawMessage]
 	FeatureID *types.NullableID
 	TagIDs    *types.NullableObject[[]types.ID]
 	IsActive  *types.NullableObject[bool]
+	StartAt   *types.NullableObject[*time.Time]
+	EndAt     *types.NullableObject[*time.Time]
+	Retention *types.NullableObject[uint32]
+	CacheTTL  *types.NullableObject[uint32]
+	Priority  *types.NullableObject[int32]
 }
 
 func FromContentEntity(banner *entity.Content) *Content {
@@ -39,6 +220,23 @@ func FromContentEntity(banner *entity.Content) *Content {
 	}
 }
 
+func FromVersionEntity(version *entity.Version) *Version {
+	return &Version{
+		Version:    version.Version,
+		Content:    json.RawMessage(version.Content),
+		CreatedAt:  version.CreatedAt,
+		ArchivedAt: version.ArchivedAt,
+	}
+}
+
+func FromCurrentVersionEntity(rollback *entity.CurrentVersion) *CurrentVersion {
+	return &CurrentVersion{
+		Version:   rollback.Version,
+		FeatureID: rollback.FeatureID,
+		TagIDs:    rollback.TagIDs,
+	}
+}
+
 func FromBannerEntity(banner *entity.Banner) *Banner {
 	return &Banner{
 		ID: banner.ID,
@@ -48,11 +246,94 @@ func FromBannerEntity(banner *entity.Banner) *Banner {
 		FeatureID: banner.FeatureID,
 		TagIDs:    banner.TagIDs,
 		IsActive:  banner.IsActive,
+		StartAt:   banner.StartAt,
+		EndAt:     banner.EndAt,
+		Retention: banner.Retention,
+		CacheTTL:  banner.CacheTTL,
+		Priority:  banner.Priority,
 		CreatedAt: banner.CreatedAt,
 		UpdatedAt: banner.UpdatedAt,
 	}
 }
 
+func (bc *BannerCreate) ToBannerCreateEntity() *entity.BannerCreate {
+	return &entity.BannerCreate{
+		FeatureID: bc.FeatureID,
+		TagIDs:    bc.TagIDs,
+		Content:   types.Content(bc.Content),
+		IsActive:  bc.IsActive,
+		Schedule:  bc.Schedule.ToScheduleEntity(),
+		Priority:  bc.Priority,
+	}
+}
+
+func FromBatchResultEntity(result *entity.BatchResult) *BatchResult {
+	return &BatchResult{
+		Applied: result.Applied,
+		Operations: slices.Map(result.Operations, func(operation *entity.BatchOperationResult) BatchOperationResult {
+			return BatchOperationResult{
+				ID:             operation.ID,
+				DraftID:        operation.DraftID,
+				Err:            operation.Err,
+				ConflictTagIDs: operation.ConflictTagIDs,
+			}
+		}),
+	}
+}
+
+func (ib *ImportBanner) ToImportBannerEntity() *entity.ImportBanner {
+	return &entity.ImportBanner{
+		Line:      ib.Line,
+		ID:        ib.ID,
+		FeatureID: ib.FeatureID,
+		TagIDs:    ib.TagIDs,
+		IsActive:  ib.IsActive,
+		StartAt:   ib.StartAt,
+		EndAt:     ib.EndAt,
+		Retention: ib.Retention,
+		CacheTTL:  ib.CacheTTL,
+		Priority:  ib.Priority,
+		Versions: slices.Map(ib.Versions, func(content *Content) entity.Content {
+			return entity.Content{
+				Version:   content.Version,
+				Content:   types.Content(content.Content),
+				CreatedAt: content.CreatedAt,
+			}
+		}),
+	}
+}
+
+func FromImportResultEntity(result *entity.ImportResult, applied bool) *ImportResult {
+	return &ImportResult{
+		Applied: applied,
+		Created: result.Created,
+		Updated: result.Updated,
+		Deleted: result.Deleted,
+		Errors: slices.Map(result.Errors, func(lineError *entity.ImportLineError) ImportLineError {
+			return ImportLineError{Line: lineError.Line, Err: lineError.Err}
+		}),
+	}
+}
+
+func FromUserBannerEntity(banner *entity.UserBanner) *UserBanner {
+	return &UserBanner{
+		Content:      json.RawMessage(banner.Content),
+		EndAt:        banner.EndAt,
+		ExperimentID: banner.ExperimentID,
+	}
+}
+
+func (s *Schedule) ToScheduleEntity() *entity.Schedule {
+	if s == nil {
+		return &entity.Schedule{}
+	}
+
+	return &entity.Schedule{
+		StartAt: s.StartAt,
+		EndAt:   s.EndAt,
+	}
+}
+
 func (bu *BannerUpdate) ToBannerUpdateEntity(id types.ID) *entity.BannerUpdate {
 	return &entity.BannerUpdate{
 		ID: id,
@@ -63,5 +344,85 @@ func (bu *BannerUpdate) ToBannerUpdateEntity(id types.ID) *entity.BannerUpdate {
 		FeatureID: bu.FeatureID,
 		TagIDs:    bu.TagIDs,
 		IsActive:  bu.IsActive,
+		StartAt:   bu.StartAt,
+		EndAt:     bu.EndAt,
+		Retention: bu.Retention,
+		CacheTTL:  bu.CacheTTL,
+		Priority:  bu.Priority,
+	}
+}
+
+func FromFeatureCacheTTLEntity(ttl *entity.FeatureCacheTTL) *FeatureCacheTTL {
+	return &FeatureCacheTTL{
+		FeatureID: ttl.FeatureID,
+		CacheTTL:  ttl.CacheTTL,
+		UpdatedAt: ttl.UpdatedAt,
+	}
+}
+
+func FromVariantEntity(variant *entity.Variant) *Variant {
+	return &Variant{
+		ID:      variant.ID,
+		Weight:  variant.Weight,
+		Content: json.RawMessage(variant.Content),
+	}
+}
+
+func (v *Variant) ToVariantEntity() *entity.Variant {
+	return &entity.Variant{
+		ID:      v.ID,
+		Weight:  v.Weight,
+		Content: types.Content(v.Content),
+	}
+}
+
+func FromExperimentEntity(experiment *entity.Experiment) *Experiment {
+	return &Experiment{
+		ID:              experiment.ID,
+		BannerID:        experiment.BannerID,
+		Status:          experiment.Status,
+		WinnerVariantID: experiment.WinnerVariantID,
+		CreatedAt:       experiment.CreatedAt,
+		UpdatedAt:       experiment.UpdatedAt,
+		ConcludedAt:     experiment.ConcludedAt,
+		Variants: slices.Map(experiment.Variants, func(variant *entity.Variant) Variant {
+			return *FromVariantEntity(variant)
+		}),
+	}
+}
+
+func FromDraftEntity(draft *entity.Draft) *Draft {
+	return &Draft{
+		ID:               draft.ID,
+		BannerID:         draft.BannerID,
+		Content:          json.RawMessage(draft.Content),
+		Status:           draft.Status,
+		Author:           draft.Author,
+		Reviewer:         draft.Reviewer,
+		PublishedVersion: draft.PublishedVersion,
+		CreatedAt:        draft.CreatedAt,
+		UpdatedAt:        draft.UpdatedAt,
+	}
+}
+
+func FromContentMatchEntity(match *entity.ContentMatch) *ContentMatch {
+	return &ContentMatch{
+		BannerID:  match.BannerID,
+		Version:   match.Version,
+		Current:   match.Current,
+		CreatedAt: match.CreatedAt,
+	}
+}
+
+func FromAuditEntryEntity(entry *entity.AuditEntry) *AuditEntry {
+	return &AuditEntry{
+		ID:        entry.ID,
+		Actor:     entry.Actor,
+		RequestID: entry.RequestID,
+		Action:    entry.Action,
+		BannerID:  entry.BannerID,
+		Before:    entry.Before,
+		After:     entry.After,
+		CreatedAt: entry.CreatedAt,
 	}
 }
@@ -3,14 +3,60 @@ package banner
 import (
 	"bannersrv/internal/banner/entity"
 	"bannersrv/internal/pkg/types"
+	"context"
 )
 
 type Repository interface {
-	CreateBanner(featureID types.ID, tagIDs []types.ID, content types.Content, isActive bool) (types.ID, error)
-	DeleteBanner(id types.ID) (types.ID, error)
-	UpdateBanner(banner *entity.BannerUpdate) (types.ID, error)
-	GetBanners(banner *entity.BannerInfo, offset, limit uint64) ([]entity.Banner, error)
-	GetBanner(featureID, tagID types.ID, version types.NullableObject[uint32]) (types.Content, error)
-	DeleteFilteredBanner(banner *entity.BannerInfo) error
-	CleanDeletedBanner() error
+	CreateBanner(ctx context.Context, featureID types.ID, tagIDs []types.ID,
+		content types.Content, isActive bool, schedule *entity.Schedule, priority int32) (types.ID, error)
+	// DeleteBanner и UpdateBanner возвращают фичу и тэги, по которым баннер был доступен до и после изменения
+	DeleteBanner(ctx context.Context, id types.ID) (*entity.BannerKeys, error)
+	UpdateBanner(ctx context.Context, banner *entity.BannerUpdate) ([]entity.BannerKeys, error)
+	GetBanners(ctx context.Context, banner *entity.BannerInfo, order *entity.BannerOrder,
+		offset, limit uint64) ([]entity.Banner, error)
+	CountBanners(ctx context.Context, banner *entity.BannerInfo) (uint64, error)
+	GetBanner(ctx context.Context, featureID types.ID, tagIDs []types.ID,
+		version types.NullableObject[uint32]) (*entity.UserBanner, error)
+	GetUserBanners(ctx context.Context, tagID types.ID, featureIDs []types.ID) (map[types.ID]entity.UserBanner, error)
+	DeleteFilteredBanner(ctx context.Context, banner *entity.BannerInfo) ([]entity.BannerKeys, error)
+	GetBannerFeature(ctx context.Context, id types.ID) (types.ID, error)
+	CleanDeletedBanner(ctx context.Context) error
+	BatchBanners(ctx context.Context, operations []entity.BatchOperation, author string,
+		atomic bool) (*entity.BatchResult, error)
+	ExportBanners(ctx context.Context, featureIDs []types.ID, export func(banner *entity.Banner) error) error
+	ImportBanners(ctx context.Context, banners []entity.ImportBanner, mode entity.ImportMode,
+		dryRun bool) (*entity.ImportResult, error)
+
+	GetBannerVersions(ctx context.Context, bannerID types.ID, offset, limit uint64) ([]entity.Version, error)
+	GetBannerVersion(ctx context.Context, bannerID types.ID, version uint32) (*entity.Version, error)
+	GetDefaultRetention(ctx context.Context) (uint32, error)
+	SetDefaultRetention(ctx context.Context, retention uint32) error
+	RollbackBanner(ctx context.Context, id types.ID, version uint32) (*entity.CurrentVersion, error)
+
+	GetFeatureCacheTTL(ctx context.Context, featureID types.ID) (*entity.FeatureCacheTTL, error)
+	// SetFeatureCacheTTL и DeleteFeatureCacheTTL возвращают тэги баннеров фичи, кэш которых нужно сбросить
+	SetFeatureCacheTTL(ctx context.Context, featureID types.ID, ttl uint32) (*entity.BannerKeys, error)
+	DeleteFeatureCacheTTL(ctx context.Context, featureID types.ID) (*entity.BannerKeys, error)
+
+	// UpdateBannerWithDraft возвращает фичу и тэги баннера, кэш которых нужно сбросить, и созданный черновик
+	UpdateBannerWithDraft(ctx context.Context, bnr *entity.BannerUpdate, draft *types.Content,
+		author string) ([]entity.BannerKeys, *types.ID, error)
+	GetDrafts(ctx context.Context, bannerID types.ID, status *entity.DraftStatus,
+		offset, limit uint64) ([]entity.Draft, error)
+	GetDraft(ctx context.Context, id types.ID) (*entity.Draft, error)
+	ReviewDraft(ctx context.Context, id types.ID, reviewer string, to entity.DraftStatus) error
+	PublishDraft(ctx context.Context, id types.ID) (*entity.CurrentVersion, error)
+
+	// CreateExperiment и UpdateExperimentStatus возвращают фичу и тэги баннера эксперимента
+	CreateExperiment(ctx context.Context, bannerID types.ID,
+		variants []entity.Variant) (types.ID, *entity.BannerKeys, error)
+	GetExperiment(ctx context.Context, id types.ID) (*entity.Experiment, error)
+	GetExperimentVariants(ctx context.Context, experimentID types.ID) ([]entity.Variant, error)
+	UpdateExperimentStatus(ctx context.Context, id types.ID,
+		from, to entity.ExperimentStatus) (*entity.BannerKeys, error)
+	ConcludeExperiment(ctx context.Context, id, winnerVariantID types.ID) (*entity.CurrentVersion, error)
+
+	GetAudit(ctx context.Context, filter *entity.AuditFilter, offset, limit uint64) ([]entity.AuditEntry, error)
+
+	SearchBanners(ctx context.Context, search *entity.ContentSearch, offset, limit uint64) ([]entity.ContentMatch, error)
 }
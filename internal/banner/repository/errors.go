@@ -3,6 +3,25 @@ package repository
 import "github.com/pkg/errors"
 
 var (
-	ErrorBannerNotFound       = errors.New("banner not found")
-	ErrorBannerConflictExists = errors.New("banner with presented pair featured id and tag id is already exists")
+	ErrorBannerNotFound          = errors.New("banner not found")
+	ErrorBannerConflictExists    = errors.New("banner with presented pair featured id and tag id is already exists")
+	ErrorBannerScheduleIncorrect = errors.New("banner start time must be earlier than end time")
+	ErrorVersionNotFound         = errors.New("banner version not found")
+	ErrorVersionArchived         = errors.New("banner version is archived and can't be restored")
+	ErrorVersionAlreadyCurrent   = errors.New("banner version is already current")
+
+	ErrorExperimentNotFound       = errors.New("experiment not found")
+	ErrorExperimentConflictExists = errors.New("banner already has not concluded experiment")
+	ErrorExperimentStatusConflict = errors.New("experiment status doesn't allow this action")
+	ErrorVariantNotFound          = errors.New("variant not found in experiment")
+
+	ErrorDraftNotFound       = errors.New("draft not found")
+	ErrorDraftStatusConflict = errors.New("draft status doesn't allow this action")
+	ErrorDraftSelfApproval   = errors.New("author can't approve own draft")
+
+	ErrorCacheTTLNotFound = errors.New("cache ttl is not set for feature")
+
+	ErrorBatchRolledBack = errors.New("operation is rolled back because of errors in other operations")
+
+	ErrorSearchQueryIncorrect = errors.New("jsonpath or text search query is incorrect")
 )
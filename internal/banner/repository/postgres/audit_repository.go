@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/pkg/audit"
+	"bannersrv/internal/pkg/types"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+const (
+	snapshotQuery = `
+		SELECT banner_snapshot($1)
+	`
+
+	addAuditQuery = `
+		INSERT INTO audit_log (actor, request_id, action, banner_id, before, after)
+		VALUES (NULLIF($1, ''), NULLIF($2, ''), $3, $4, $5, banner_snapshot($4))
+	`
+
+	getAuditQuery = `
+		SELECT id, actor, request_id, action, banner_id, before, after, created_at FROM audit_log
+			WHERE (CASE WHEN $1::bigint IS NOT NULL THEN banner_id = $1 ELSE true END)
+			and (CASE WHEN $2::text IS NOT NULL THEN actor = $2 ELSE true END)
+			and (CASE WHEN $3::timestamptz IS NOT NULL THEN created_at >= $3 ELSE true END)
+			and (CASE WHEN $4::timestamptz IS NOT NULL THEN created_at < $4 ELSE true END)
+			and (CASE WHEN $7::bigint[] IS NOT NULL THEN
+				(before->>'feature_id')::bigint = ANY ($7) or (after->>'feature_id')::bigint = ANY ($7)
+				ELSE true END)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $5 OFFSET $6
+	`
+)
+
+// snapshotBanner возвращает состояние баннера в виде json для журнала изменений или nil, если баннера нет
+func (*BannerRepository) snapshotBanner(ctx context.Context, tx pgx.Tx, id types.ID) ([]byte, error) {
+	var snapshot []byte
+	if err := tx.QueryRow(ctx, snapshotQuery, id).Scan(&snapshot); err != nil {
+		return nil, errors.Wrap(err, "can't get banner snapshot")
+	}
+
+	return snapshot, nil
+}
+
+// addAudit сохраняет в журнал изменений действие над баннером. Состояние после изменения вычисляется
+// в той же транзакции, а исполнитель и идентификатор запроса берутся из контекста.
+func (*BannerRepository) addAudit(ctx context.Context, tx pgx.Tx, action entity.AuditAction,
+	id types.ID, before []byte,
+) error {
+	if _, err := tx.Exec(ctx, addAuditQuery,
+		audit.Actor(ctx), audit.RequestID(ctx), action, id, before); err != nil {
+		return errors.Wrapf(err, "can't add %s action to audit log", action)
+	}
+
+	return nil
+}
+
+func (br *BannerRepository) GetAudit(ctx context.Context, filter *entity.AuditFilter,
+	offset, limit uint64,
+) ([]entity.AuditEntry, error) {
+	actor := &pgtype.Text{}
+	if filter.Actor != nil {
+		actor = &pgtype.Text{Valid: true, String: *filter.Actor}
+	}
+
+	rows, err := br.db.Query(ctx, getAuditQuery,
+		(*types.NullableID)(types.ObjectFromPointer(filter.BannerID)).ToNullableSQL(), actor,
+		filter.From, filter.To, limit, offset, filter.FeatureIDs)
+	//nolint: staticcheck
+	defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
+
+	if err != nil {
+		return nil, errors.Wrap(err, "can't execute get audit query")
+	}
+
+	entries := make([]entity.AuditEntry, 0)
+
+	for rows.Next() {
+		var entry entity.AuditEntry
+
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.Actor,
+			&entry.RequestID,
+			&entry.Action,
+			&entry.BannerID,
+			&entry.Before,
+			&entry.After,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, "can't scan get audit query result")
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "can't end scan get audit query result")
+	}
+
+	return entries, nil
+}
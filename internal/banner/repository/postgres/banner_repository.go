@@ -3,9 +3,13 @@ package postgres
 import (
 	"bannersrv/internal/banner/entity"
 	"bannersrv/internal/banner/repository"
+	"bannersrv/internal/pkg/audit"
 	"bannersrv/internal/pkg/pg"
 	"bannersrv/internal/pkg/types"
 	"context"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -16,8 +20,8 @@ import (
 
 const (
 	createQuery = `
-		INSERT INTO banner (is_active)
-		VALUES ($1)
+		INSERT INTO banner (is_active, start_at, end_at, priority)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id
 	`
 
@@ -47,6 +51,28 @@ const (
 			RETURNING id
 	`
 
+	updateScheduleQuery = `
+		UPDATE banner SET start_at = CASE WHEN $2 THEN $3::timestamptz ELSE start_at END,
+			end_at = CASE WHEN $4 THEN $5::timestamptz ELSE end_at END
+			WHERE id = $1
+	`
+
+	updateRetentionQuery = `
+		UPDATE banner SET retention = NULLIF($2::bigint, 0) WHERE id = $1
+	`
+
+	updateCacheTTLQuery = `
+		UPDATE banner SET cache_ttl = NULLIF($2::bigint, 0) WHERE id = $1
+	`
+
+	updatePriorityQuery = `
+		UPDATE banner SET priority = $2 WHERE id = $1
+	`
+
+	archiveVersionsQuery = `
+		SELECT archive_banner_versions(id, last_version) FROM banner WHERE id = $1
+	`
+
 	deleteFeaturesTagsQuery = `
 		WITH deleted_features AS (
 			DELETE FROM features_tags_banner WHERE banner_id = $1 RETURNING feature_id
@@ -59,24 +85,68 @@ const (
 	`
 
 	getQuery = `
-		SELECT vb.content FROM banner
+		SELECT vb.content, banner.end_at, e.id, COALESCE(banner.cache_ttl, fs.cache_ttl) FROM banner
 		   INNER JOIN features_tags_banner on (features_tags_banner.banner_id = banner.id and not deleted)
 		   LEFT JOIN version_banner as vb on (vb.banner_id = banner.id)
+		   LEFT JOIN experiment as e on (e.banner_id = banner.id and e.status = 'running')
+		   LEFT JOIN feature_settings as fs on (fs.feature_id = features_tags_banner.feature_id)
 		WHERE is_active and vb.version = COALESCE($3::bigint, banner.last_version) 
-		  		and feature_id = $1 and tag_id = $2 LIMIT 1
+		  		and (start_at IS NULL or start_at <= now()) and (end_at IS NULL or end_at > now())
+		  		and feature_id = $1 and tag_id = ANY ($2::bigint[])
+		ORDER BY banner.priority DESC, banner.id LIMIT 1
+	`
+
+	// Для каждой фичи выбирается не больше одного баннера, так же как при запросе баннера по паре фича-тэг
+	getFeaturesQuery = `
+		SELECT DISTINCT ON (ftb.feature_id) ftb.feature_id, vb.content, banner.end_at, e.id,
+			COALESCE(banner.cache_ttl, fs.cache_ttl) FROM banner
+		   INNER JOIN features_tags_banner as ftb on (ftb.banner_id = banner.id and not deleted)
+		   LEFT JOIN version_banner as vb on (vb.banner_id = banner.id)
+		   LEFT JOIN experiment as e on (e.banner_id = banner.id and e.status = 'running')
+		   LEFT JOIN feature_settings as fs on (fs.feature_id = ftb.feature_id)
+		WHERE is_active and vb.version = banner.last_version
+		  		and (start_at IS NULL or start_at <= now()) and (end_at IS NULL or end_at > now())
+		  		and ftb.feature_id = ANY ($1::bigint[]) and tag_id = $2
+		ORDER BY ftb.feature_id, banner.priority DESC, banner.id
 	`
 
-	filterNullQuery = `
-		SELECT banner.id, is_active, created_at, updated_at FROM banner LIMIT $1 OFFSET $2
+	// filterConditions условия фильтра баннеров. Баннеры, ожидающие отложенного удаления,
+	// отбрасываются только при фильтре по фичам или тэгам.
+	filterConditions = `
+			(CASE WHEN $1::bigint[] IS NOT NULL or $2::bigint[] IS NOT NULL or $3::bigint[] IS NOT NULL THEN
+				EXISTS (SELECT 1 FROM features_tags_banner as ftb WHERE ftb.banner_id = banner.id and not deleted
+					and (CASE WHEN $1::bigint[] IS NOT NULL THEN feature_id = ANY ($1) ELSE true END)
+					and (CASE WHEN $2::bigint[] IS NOT NULL THEN tag_id = ANY ($2) ELSE true END)
+					and (CASE WHEN $3::bigint[] IS NOT NULL THEN feature_id = ANY ($3) ELSE true END))
+				ELSE true END)
+			and (CASE $4::text
+				WHEN 'scheduled' THEN start_at > now()
+				WHEN 'live' THEN (start_at IS NULL or start_at <= now()) and (end_at IS NULL or end_at > now())
+				WHEN 'expired' THEN end_at <= now()
+				ELSE true END)
+			and (CASE WHEN $5::boolean IS NOT NULL THEN is_active = $5 ELSE true END)
+			and (CASE WHEN $6::timestamptz IS NOT NULL THEN created_at >= $6 ELSE true END)
+			and (CASE WHEN $7::timestamptz IS NOT NULL THEN created_at < $7 ELSE true END)
+			and (CASE WHEN $8::timestamptz IS NOT NULL THEN updated_at >= $8 ELSE true END)
+			and (CASE WHEN $9::timestamptz IS NOT NULL THEN updated_at < $9 ELSE true END)
 	`
 
-	filterNotNullQuery = `
-		SELECT DISTINCT banner.id, is_active,
-                        created_at, updated_at FROM banner
-			INNER JOIN features_tags_banner as ftb ON (ftb.banner_id = banner.id  and not deleted)
-			WHERE (CASE WHEN $1::bigint IS NOT NULL THEN feature_id = $1 ELSE true END)
-			and (CASE WHEN $2::bigint IS NOT NULL THEN tag_id = $2 ELSE true END)
-			LIMIT $3 OFFSET $4
+	// filterQuery выбирает страницу баннеров, в запрос подставляются условие начала страницы после курсора
+	// и порядок баннеров из bannerOrderQuery
+	filterQuery = `
+		SELECT banner.id, is_active, start_at, end_at, retention, cache_ttl, priority,
+		       created_at, updated_at FROM banner
+			WHERE` + filterConditions + `
+			and (CASE WHEN $12::bigint IS NOT NULL THEN %s ELSE true END)
+			ORDER BY %s
+			LIMIT $10 OFFSET $11
+	`
+
+	countFilterQuery = `
+		SELECT count(*) FROM banner WHERE` + filterConditions
+
+	getFeatureQuery = `
+		SELECT feature_id FROM features_tags_banner WHERE banner_id = $1 LIMIT 1
 	`
 
 	getTagQuery = `
@@ -89,13 +159,24 @@ const (
 		SELECT banner_id, content, version, created_at FROM version_banner WHERE banner_id = ANY ($1::bigint[])
 	`
 
+	// Удалённые баннеры сразу записываются в журнал изменений, чтобы не замедлять удаление отдельными запросами.
+	// Запрос возвращает фичи и тэги удалённых баннеров для сброса их кэша.
 	delayedDeletionQuery = `
-		UPDATE features_tags_banner SET deleted = true 
-			 WHERE banner_id in (
-			     SELECT banner_id FROM features_tags_banner
-				 WHERE (CASE WHEN $1::bigint IS NOT NULL THEN feature_id = $1 ELSE true END)
-					and (CASE WHEN $2::bigint IS NOT NULL THEN tag_id = $2 ELSE true END) 
-			 ) 
+		WITH deleted AS (
+			UPDATE features_tags_banner SET deleted = true 
+				 WHERE banner_id in (
+					 SELECT banner_id FROM features_tags_banner
+					 WHERE (CASE WHEN $1::bigint IS NOT NULL THEN feature_id = $1 ELSE true END)
+						and (CASE WHEN $2::bigint IS NOT NULL THEN tag_id = $2 ELSE true END) 
+						and (CASE WHEN $5::bigint[] IS NOT NULL THEN feature_id = ANY ($5) ELSE true END)
+				 )
+				 RETURNING banner_id, feature_id, tag_id
+		), audited AS (
+			INSERT INTO audit_log (actor, request_id, action, banner_id, before)
+			SELECT NULLIF($3, ''), NULLIF($4, ''), 'delete', banner_id, banner_snapshot(banner_id)
+			FROM (SELECT DISTINCT banner_id FROM deleted) as deleted_banners
+		)
+		SELECT feature_id, array_agg(DISTINCT tag_id) FROM deleted GROUP BY feature_id
 	`
 
 	cronDeleteQuery = `
@@ -113,39 +194,26 @@ func NewBannerRepository(db *pgxpool.Pool) *BannerRepository {
 	}
 }
 
-func (*BannerRepository) addContent(tx pgx.Tx, id types.ID, content types.Content) error {
-	if _, err := tx.Exec(context.Background(), addContentQuery, id, content); err != nil {
+func (*BannerRepository) addContent(ctx context.Context, tx pgx.Tx, id types.ID, content types.Content) error {
+	if _, err := tx.Exec(ctx, addContentQuery, id, content); err != nil {
 		return errors.Wrap(err, "can't add content to banner")
 	}
 
 	return nil
 }
 
-func (br *BannerRepository) CreateBanner(featureID types.ID, tagIDs []types.ID,
-	content types.Content, isActive bool,
+func (br *BannerRepository) CreateBanner(ctx context.Context, featureID types.ID, tagIDs []types.ID,
+	content types.Content, isActive bool, schedule *entity.Schedule, priority int32,
 ) (types.ID, error) {
 	var createdID types.ID
 
-	if err := pg.WithTransaction(br.db,
+	if err := pg.WithTransaction(ctx, br.db,
 		func(tx pgx.Tx) error {
-			if err := tx.QueryRow(context.Background(), createQuery, isActive).
-				Scan(
-					&createdID,
-				); err != nil {
-				return errors.Wrap(err, "can't create banner")
-			}
-
-			if err := br.addContent(tx, createdID, content); err != nil {
-				return err
-			}
+			var err error
 
-			if _, err := tx.Exec(context.Background(), addFeaturesAndTagsQuery, createdID, featureID,
-				pgtype.FlatArray[types.ID](tagIDs)); err != nil {
-				return errors.Wrapf(checkPgConflictError(err),
-					"can't add feature id %d and tag ids %v to banner", featureID, tagIDs)
-			}
+			createdID, err = br.createBanner(ctx, tx, featureID, tagIDs, content, isActive, schedule, priority)
 
-			return nil
+			return err
 		},
 	); err != nil {
 		return 0, errors.Wrap(err, "when creating banner")
@@ -154,34 +222,93 @@ func (br *BannerRepository) CreateBanner(featureID types.ID, tagIDs []types.ID,
 	return createdID, nil
 }
 
-func (br *BannerRepository) DeleteBanner(id types.ID) (types.ID, error) {
+func (br *BannerRepository) createBanner(ctx context.Context, tx pgx.Tx, featureID types.ID, tagIDs []types.ID,
+	content types.Content, isActive bool, schedule *entity.Schedule, priority int32,
+) (types.ID, error) {
+	var createdID types.ID
+
+	if schedule == nil {
+		schedule = &entity.Schedule{}
+	}
+
+	if err := tx.QueryRow(ctx, createQuery, isActive, schedule.StartAt, schedule.EndAt, priority).
+		Scan(
+			&createdID,
+		); err != nil {
+		return 0, errors.Wrap(checkPgConflictError(err), "can't create banner")
+	}
+
+	if err := br.addContent(ctx, tx, createdID, content); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, addFeaturesAndTagsQuery, createdID, featureID,
+		pgtype.FlatArray[types.ID](tagIDs)); err != nil {
+		return 0, errors.Wrapf(checkPgConflictError(err),
+			"can't add feature id %d and tag ids %v to banner", featureID, tagIDs)
+	}
+
+	return createdID, br.addAudit(ctx, tx, entity.AuditCreate, createdID, nil)
+}
+
+func (br *BannerRepository) DeleteBanner(ctx context.Context, id types.ID) (*entity.BannerKeys, error) {
+	var keys *entity.BannerKeys
+
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			var err error
+
+			keys, err = br.deleteBanner(ctx, tx, id)
+
+			return err
+		},
+	); err != nil {
+		return nil, errors.Wrap(err, "when deleting banner")
+	}
+
+	return keys, nil
+}
+
+func (br *BannerRepository) deleteBanner(ctx context.Context, tx pgx.Tx, id types.ID) (*entity.BannerKeys, error) {
 	var deletedID types.ID
-	if err := br.db.QueryRow(context.Background(), deleteQuery, id).
+
+	before, err := br.snapshotBanner(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Фича и тэги удаляются вместе с баннером, поэтому запрашиваются до удаления
+	keys, err := br.getBannerKeys(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.QueryRow(ctx, deleteQuery, id).
 		Scan(
 			&deletedID,
 		); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return deletedID, errors.Wrapf(repository.ErrorBannerNotFound, "with id %d", id)
+			return nil, errors.Wrapf(repository.ErrorBannerNotFound, "with id %d", id)
 		}
 
-		return deletedID, errors.Wrapf(err, "can't delete banner with id %d", id)
+		return nil, errors.Wrapf(err, "can't delete banner with id %d", id)
 	}
 
-	return deletedID, nil
+	return keys, br.addAudit(ctx, tx, entity.AuditDelete, id, before)
 }
 
-func (*BannerRepository) updateBannerInfo(tx pgx.Tx, bnr *entity.BannerUpdate) error {
+func (*BannerRepository) updateBannerInfo(ctx context.Context, tx pgx.Tx, bnr *entity.BannerUpdate) error {
 	switch {
 	// Если у нас изменился только айди фичи, её можно обновить по id баннера
 	case bnr.TagIDs.IsNull && !bnr.FeatureID.IsNull:
-		if _, err := tx.Exec(context.Background(), updateFeaturesQuery, bnr.ID, bnr.FeatureID.Value); err != nil {
+		if _, err := tx.Exec(ctx, updateFeaturesQuery, bnr.ID, bnr.FeatureID.Value); err != nil {
 			return errors.Wrapf(checkPgConflictError(err),
 				"can't update feature id %d to banner", bnr.FeatureID.Value)
 		}
 	// Если у нас изменился список тэгов, то нужно сначала удалить все записи с тэгами, а потом их снова создать
 	case !bnr.TagIDs.IsNull:
 		var featureID types.ID
-		if err := tx.QueryRow(context.Background(), deleteFeaturesTagsQuery, bnr.ID).Scan(&featureID); err != nil {
+		if err := tx.QueryRow(ctx, deleteFeaturesTagsQuery, bnr.ID).Scan(&featureID); err != nil {
 			return errors.Wrap(err, "can't delete feature id and tag ids of banner")
 		}
 
@@ -189,7 +316,7 @@ func (*BannerRepository) updateBannerInfo(tx pgx.Tx, bnr *entity.BannerUpdate) e
 			featureID = bnr.FeatureID.Value
 		}
 
-		if _, err := tx.Exec(context.Background(), addFeaturesAndTagsQuery, bnr.ID, featureID,
+		if _, err := tx.Exec(ctx, addFeaturesAndTagsQuery, bnr.ID, featureID,
 			pgtype.FlatArray[types.ID](bnr.TagIDs.Value)); err != nil {
 			return errors.Wrapf(checkPgConflictError(err),
 				"can't add feature id %d and tag ids %v to banner", featureID, bnr.TagIDs.Value)
@@ -199,58 +326,248 @@ func (*BannerRepository) updateBannerInfo(tx pgx.Tx, bnr *entity.BannerUpdate) e
 	return nil
 }
 
-func (br *BannerRepository) UpdateBanner(bnr *entity.BannerUpdate) (types.ID, error) {
-	var updatedID types.ID
+func (*BannerRepository) updateSchedule(ctx context.Context, tx pgx.Tx, bnr *entity.BannerUpdate) error {
+	setStart, setEnd := isSet(bnr.StartAt), isSet(bnr.EndAt)
+	if !setStart && !setEnd {
+		return nil
+	}
 
-	if err := pg.WithTransaction(br.db,
-		func(tx pgx.Tx) error {
-			if err := tx.QueryRow(context.Background(), checkDeleted, bnr.ID).Scan(&updatedID); err != nil {
-				if errors.Is(err, pgx.ErrNoRows) {
-					return repository.ErrorBannerNotFound
-				}
+	var startAt, endAt *time.Time
+	if setStart {
+		startAt = bnr.StartAt.Value
+	}
 
-				return errors.Wrapf(err, "can't check banner on deleted")
-			}
+	if setEnd {
+		endAt = bnr.EndAt.Value
+	}
 
-			if !bnr.IsActive.IsNull {
-				if err := tx.QueryRow(context.Background(), updateActiveQuery,
-					bnr.ID, bnr.IsActive.Value).
-					Scan(&updatedID); err != nil {
-					if errors.Is(err, pgx.ErrNoRows) {
-						return repository.ErrorBannerNotFound
-					}
+	if _, err := tx.Exec(ctx, updateScheduleQuery, bnr.ID, setStart, startAt, setEnd, endAt); err != nil {
+		return errors.Wrap(checkPgConflictError(err), "can't update banner schedule")
+	}
 
-					return errors.Wrapf(err, "can't update banner")
-				}
-			}
+	return nil
+}
 
-			if !bnr.Content.IsNull {
-				if err := br.addContent(tx, bnr.ID, bnr.Content.Value); err != nil {
-					return err
-				}
-			}
+// isSet возвращает true, если изменение задаёт новое значение поля
+func isSet[T any](object *types.NullableObject[T]) bool {
+	return object != nil && !object.IsNull
+}
+
+// updateRetention изменяет число хранимых версий баннера и сразу переносит в архив лишние версии
+func (*BannerRepository) updateRetention(ctx context.Context, tx pgx.Tx, bnr *entity.BannerUpdate) error {
+	if bnr.Retention == nil || bnr.Retention.IsNull {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, updateRetentionQuery, bnr.ID, bnr.Retention.Value); err != nil {
+		return errors.Wrapf(err, "can't update banner retention to %d", bnr.Retention.Value)
+	}
+
+	if _, err := tx.Exec(ctx, archiveVersionsQuery, bnr.ID); err != nil {
+		return errors.Wrap(err, "can't archive banner versions")
+	}
+
+	return nil
+}
 
-			return br.updateBannerInfo(tx, bnr)
+// updateCacheTTL изменяет время жизни баннера в кэше
+func (*BannerRepository) updateCacheTTL(ctx context.Context, tx pgx.Tx, bnr *entity.BannerUpdate) error {
+	if bnr.CacheTTL == nil || bnr.CacheTTL.IsNull {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, updateCacheTTLQuery, bnr.ID, bnr.CacheTTL.Value); err != nil {
+		return errors.Wrapf(err, "can't update banner cache ttl to %d", bnr.CacheTTL.Value)
+	}
+
+	return nil
+}
+
+// updatePriority изменяет приоритет баннера
+func (*BannerRepository) updatePriority(ctx context.Context, tx pgx.Tx, bnr *entity.BannerUpdate) error {
+	if bnr.Priority == nil || bnr.Priority.IsNull {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, updatePriorityQuery, bnr.ID, bnr.Priority.Value); err != nil {
+		return errors.Wrapf(err, "can't update banner priority to %d", bnr.Priority.Value)
+	}
+
+	return nil
+}
+
+func (br *BannerRepository) UpdateBanner(ctx context.Context, bnr *entity.BannerUpdate) ([]entity.BannerKeys, error) {
+	var keys []entity.BannerKeys
+
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			var err error
+
+			keys, err = br.updateBanner(ctx, tx, bnr)
+
+			return err
 		},
 	); err != nil {
-		return 0, errors.Wrapf(err, "when updating banner with id %d", bnr.ID)
+		return nil, errors.Wrapf(err, "when updating banner with id %d", bnr.ID)
 	}
 
-	return updatedID, nil
+	return keys, nil
 }
 
-func (*BannerRepository) filterBanners(tx pgx.Tx, bnr *entity.BannerInfo,
-	offset, limit uint64,
+func (br *BannerRepository) updateBanner(ctx context.Context, tx pgx.Tx,
+	bnr *entity.BannerUpdate,
+) ([]entity.BannerKeys, error) {
+	var updatedID types.ID
+
+	keys := make([]entity.BannerKeys, 0, 2)
+
+	if err := tx.QueryRow(ctx, checkDeleted, bnr.ID).Scan(&updatedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrorBannerNotFound
+		}
+
+		return nil, errors.Wrapf(err, "can't check banner on deleted")
+	}
+
+	before, err := br.snapshotBanner(ctx, tx, bnr.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeKeys, err := br.getBannerKeys(ctx, tx, bnr.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys = append(keys, *beforeKeys)
+
+	if !bnr.IsActive.IsNull {
+		if err := tx.QueryRow(ctx, updateActiveQuery,
+			bnr.ID, bnr.IsActive.Value).
+			Scan(&updatedID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, repository.ErrorBannerNotFound
+			}
+
+			return nil, errors.Wrapf(err, "can't update banner")
+		}
+	}
+
+	if err := br.updateSchedule(ctx, tx, bnr); err != nil {
+		return nil, err
+	}
+
+	if err := br.updateRetention(ctx, tx, bnr); err != nil {
+		return nil, err
+	}
+
+	if err := br.updateCacheTTL(ctx, tx, bnr); err != nil {
+		return nil, err
+	}
+
+	if err := br.updatePriority(ctx, tx, bnr); err != nil {
+		return nil, err
+	}
+
+	if !bnr.Content.IsNull {
+		if err := br.addContent(ctx, tx, bnr.ID, bnr.Content.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := br.updateBannerInfo(ctx, tx, bnr); err != nil {
+		return nil, err
+	}
+
+	// После смены фичи или тэгов баннер становится доступен по новым парам
+	if !bnr.FeatureID.IsNull || !bnr.TagIDs.IsNull {
+		afterKeys, err := br.getBannerKeys(ctx, tx, bnr.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, *afterKeys)
+	}
+
+	return keys, br.addAudit(ctx, tx, entity.AuditUpdate, bnr.ID, before)
+}
+
+// bannerTimeColumns столбцы времени, по которым сортируется список баннеров,
+// остальные значения сортировки упорядочивают баннеры по идентификатору
+var bannerTimeColumns = map[entity.BannerSort]string{
+	entity.BannerSortCreatedAt: "created_at",
+	entity.BannerSortUpdatedAt: "updated_at",
+}
+
+// bannerOrderQuery возвращает запрос страницы баннеров в порядке order. При сортировке по времени
+// курсор сравнивается по паре из времени и идентификатора, поэтому запросу нужен дополнительный параметр.
+func bannerOrderQuery(order *entity.BannerOrder) string {
+	direction, compare := "ASC", ">"
+	if order.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	after := "banner.id " + compare + " $12"
+	orderBy := "banner.id " + direction
+
+	if column, ok := bannerTimeColumns[order.Sort]; ok {
+		after = fmt.Sprintf("(%s, banner.id) %s ($13::timestamptz, $12)", column, compare)
+		orderBy = fmt.Sprintf("%s %s, %s", column, direction, orderBy)
+	}
+
+	return fmt.Sprintf(filterQuery, after, orderBy)
+}
+
+// filterArgs возвращает параметры условий фильтра. Фильтры по одной фиче и одному тэгу
+// добавляются к спискам фич и тэгов.
+func filterArgs(bnr *entity.BannerInfo) []any {
+	features, tags := bnr.Features, bnr.Tags
+
+	if bnr.FeatureID != nil && !bnr.FeatureID.IsNull {
+		features = append(slices.Clip(features), bnr.FeatureID.Value)
+	}
+
+	if bnr.TagID != nil && !bnr.TagID.IsNull {
+		tags = append(slices.Clip(tags), bnr.TagID.Value)
+	}
+
+	state := &pgtype.Text{}
+	if bnr.State != nil && !bnr.State.IsNull {
+		state = &pgtype.Text{Valid: true, String: string(bnr.State.Value)}
+	}
+
+	return []any{
+		features, tags, bnr.FeatureIDs, state, bnr.IsActive,
+		bnr.CreatedFrom, bnr.CreatedTo, bnr.UpdatedFrom, bnr.UpdatedTo,
+	}
+}
+
+func (*BannerRepository) filterBanners(ctx context.Context, tx pgx.Tx, bnr *entity.BannerInfo,
+	order *entity.BannerOrder, offset, limit uint64,
 ) ([]entity.Banner, error) {
-	args := []any{bnr.FeatureID.ToNullableSQL(), bnr.TagID.ToNullableSQL(), limit, offset}
-	query := filterNotNullQuery
+	if order == nil {
+		order = &entity.BannerOrder{Sort: entity.BannerSortID}
+	}
+
+	var (
+		cursorID   *types.ID
+		cursorTime *time.Time
+	)
 
-	if bnr.FeatureID.IsNull && bnr.TagID.IsNull {
-		args = []any{limit, offset}
-		query = filterNullQuery
+	if order.Cursor != nil {
+		cursorID, cursorTime = &order.Cursor.ID, order.Cursor.Time
 	}
 
-	rows, err := tx.Query(context.Background(), query, args...)
+	args := append(filterArgs(bnr), limit, offset,
+		(*types.NullableID)(types.ObjectFromPointer(cursorID)).ToNullableSQL())
+
+	if _, ok := bannerTimeColumns[order.Sort]; ok {
+		args = append(args, cursorTime)
+	}
+
+	query := bannerOrderQuery(order)
+
+	rows, err := tx.Query(ctx, query, args...)
 	//nolint: staticcheck
 	defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
 
@@ -266,6 +583,11 @@ func (*BannerRepository) filterBanners(tx pgx.Tx, bnr *entity.BannerInfo,
 		err := rows.Scan(
 			&filteredBanner.ID,
 			&filteredBanner.IsActive,
+			&filteredBanner.StartAt,
+			&filteredBanner.EndAt,
+			&filteredBanner.Retention,
+			&filteredBanner.CacheTTL,
+			&filteredBanner.Priority,
 			&filteredBanner.CreatedAt,
 			&filteredBanner.UpdatedAt,
 		)
@@ -286,7 +608,7 @@ func (*BannerRepository) filterBanners(tx pgx.Tx, bnr *entity.BannerInfo,
 	return banners, nil
 }
 
-func (*BannerRepository) selectTagFeatureForBanners(tx pgx.Tx, banners []entity.Banner) ([]entity.Banner, error) {
+func (*BannerRepository) selectTagFeatureForBanners(ctx context.Context, tx pgx.Tx, banners []entity.Banner) ([]entity.Banner, error) {
 	bannerIDs := make([]types.ID, len(banners))
 	bannerIndexes := make(map[types.ID]int64)
 
@@ -295,7 +617,7 @@ func (*BannerRepository) selectTagFeatureForBanners(tx pgx.Tx, banners []entity.
 		bannerIndexes[bnr.ID] = int64(index)
 	}
 
-	rows, err := tx.Query(context.Background(), getTagQuery, bannerIDs)
+	rows, err := tx.Query(ctx, getTagQuery, bannerIDs)
 	//nolint: staticcheck
 	defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
 
@@ -332,7 +654,7 @@ func (*BannerRepository) selectTagFeatureForBanners(tx pgx.Tx, banners []entity.
 	return banners, nil
 }
 
-func (*BannerRepository) selectContentForBanners(tx pgx.Tx, banners []entity.Banner) ([]entity.Banner, error) {
+func (*BannerRepository) selectContentForBanners(ctx context.Context, tx pgx.Tx, banners []entity.Banner) ([]entity.Banner, error) {
 	bannerIDs := make([]types.ID, len(banners))
 	bannerIndexes := make(map[types.ID]int64)
 
@@ -341,7 +663,7 @@ func (*BannerRepository) selectContentForBanners(tx pgx.Tx, banners []entity.Ban
 		bannerIndexes[bnr.ID] = int64(index)
 	}
 
-	rows, err := tx.Query(context.Background(), getVersionQuery, bannerIDs)
+	rows, err := tx.Query(ctx, getVersionQuery, bannerIDs)
 	//nolint: staticcheck
 	defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
 
@@ -374,16 +696,17 @@ func (*BannerRepository) selectContentForBanners(tx pgx.Tx, banners []entity.Ban
 	return banners, nil
 }
 
-func (br *BannerRepository) GetBanners(bnr *entity.BannerInfo,
+// GetBanners возвращает страницу баннеров по фильтру в порядке order, по умолчанию по возрастанию идентификатора
+func (br *BannerRepository) GetBanners(ctx context.Context, bnr *entity.BannerInfo, order *entity.BannerOrder,
 	offset, limit uint64,
 ) ([]entity.Banner, error) {
 	var banners []entity.Banner
 
-	if err := pg.WithTransaction(br.db,
+	if err := pg.WithTransaction(ctx, br.db,
 		func(tx pgx.Tx) error {
 			var err error
 
-			banners, err = br.filterBanners(tx, bnr, offset, limit)
+			banners, err = br.filterBanners(ctx, tx, bnr, order, offset, limit)
 			if err != nil {
 				return err
 			}
@@ -392,12 +715,12 @@ func (br *BannerRepository) GetBanners(bnr *entity.BannerInfo,
 				return nil
 			}
 
-			banners, err = br.selectTagFeatureForBanners(tx, banners)
+			banners, err = br.selectTagFeatureForBanners(ctx, tx, banners)
 			if err != nil {
 				return err
 			}
 
-			banners, err = br.selectContentForBanners(tx, banners)
+			banners, err = br.selectContentForBanners(ctx, tx, banners)
 			if err != nil {
 				return err
 			}
@@ -405,71 +728,161 @@ func (br *BannerRepository) GetBanners(bnr *entity.BannerInfo,
 			return nil
 		},
 	); err != nil {
-		return nil, errors.Wrapf(err,
-			"when selecting banners for admin with feature id %d, tag id %d, limit %d and offset %d",
-			bnr.FeatureID.Value, bnr.TagID.Value, limit, offset)
+		return nil, errors.Wrapf(err, "when selecting banners for admin with limit %d and offset %d", limit, offset)
 	}
 
 	return banners, nil
 }
 
-func (br *BannerRepository) GetBanner(featureID, tagID types.ID,
+// CountBanners возвращает число баннеров, подходящих под фильтр
+func (br *BannerRepository) CountBanners(ctx context.Context, bnr *entity.BannerInfo) (uint64, error) {
+	var count uint64
+	if err := br.db.QueryRow(ctx, countFilterQuery, filterArgs(bnr)...).Scan(&count); err != nil {
+		return 0, errors.Wrap(err, "can't count filtered banners")
+	}
+
+	return count, nil
+}
+
+// GetBannerFeature возвращает фичу баннера, в том числе ожидающего отложенного удаления
+func (br *BannerRepository) GetBannerFeature(ctx context.Context, id types.ID) (types.ID, error) {
+	var featureID types.ID
+	if err := br.db.QueryRow(ctx, getFeatureQuery, id).Scan(&featureID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, errors.Wrapf(repository.ErrorBannerNotFound, "with id %d", id)
+		}
+
+		return 0, errors.Wrapf(err, "can't get feature of banner with id %d", id)
+	}
+
+	return featureID, nil
+}
+
+// GetBanner возвращает баннер фичи по одному из тэгов tagIDs. Если тэгам соответствуют несколько баннеров,
+// выбирается баннер с наибольшим приоритетом, а из баннеров с равным приоритетом -- созданный раньше.
+func (br *BannerRepository) GetBanner(ctx context.Context, featureID types.ID, tagIDs []types.ID,
 	version types.NullableObject[uint32],
-) (types.Content, error) {
-	var content types.Content
-	if err := br.db.QueryRow(context.Background(), getQuery, featureID, tagID,
+) (*entity.UserBanner, error) {
+	var bnr entity.UserBanner
+	if err := br.db.QueryRow(ctx, getQuery, featureID, tagIDs,
 		&pgtype.Uint32{
 			Valid:  !version.IsNull,
 			Uint32: version.Value,
 		}).
 		Scan(
-			&content,
+			&bnr.Content,
+			&bnr.EndAt,
+			&bnr.ExperimentID,
+			&bnr.CacheTTL,
 		); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return content, errors.Wrapf(repository.ErrorBannerNotFound,
-				"with feature id %d and tag id %d and version %v", featureID, tagID, version)
+			return nil, errors.Wrapf(repository.ErrorBannerNotFound,
+				"with feature id %d and tag ids %v and version %v", featureID, tagIDs, version)
+		}
+
+		return nil, errors.Wrapf(err,
+			"can't get banner with feature id %d and tag ids %v and version %v", featureID, tagIDs, version)
+	}
+
+	return &bnr, nil
+}
+
+// GetUserBanners возвращает последние версии баннеров фич featureIDs по тэгу tagID.
+// Фич, для которых баннер не найден, в результате нет.
+func (br *BannerRepository) GetUserBanners(ctx context.Context, tagID types.ID,
+	featureIDs []types.ID,
+) (map[types.ID]entity.UserBanner, error) {
+	rows, err := br.db.Query(ctx, getFeaturesQuery, featureIDs, tagID)
+	//nolint: staticcheck
+	defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get banners with tag id %d and feature ids %v", tagID, featureIDs)
+	}
+
+	banners := make(map[types.ID]entity.UserBanner, len(featureIDs))
+
+	for rows.Next() {
+		var featureID types.ID
+
+		var bnr entity.UserBanner
+
+		if err := rows.Scan(
+			&featureID,
+			&bnr.Content,
+			&bnr.EndAt,
+			&bnr.ExperimentID,
+			&bnr.CacheTTL,
+		); err != nil {
+			return nil, errors.Wrap(err, "can't scan get banners query result")
 		}
 
-		return content, errors.Wrapf(err,
-			"can't get banner with feature id %d and tag id %d and version %v", featureID, tagID, version)
+		banners[featureID] = bnr
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "can't end scan get banners query result")
 	}
 
-	return content, nil
+	return banners, nil
 }
 
-func (br *BannerRepository) DeleteFilteredBanner(bnr *entity.BannerInfo) error {
-	if err := pg.WithTransaction(br.db,
+func (br *BannerRepository) DeleteFilteredBanner(ctx context.Context,
+	bnr *entity.BannerInfo,
+) ([]entity.BannerKeys, error) {
+	keys := make([]entity.BannerKeys, 0)
+
+	if err := pg.WithTransaction(ctx, br.db,
 		func(tx pgx.Tx) error {
-			res, err := tx.Exec(context.Background(), delayedDeletionQuery,
+			rows, err := tx.Query(ctx, delayedDeletionQuery,
 				&pgtype.Uint32{
 					Valid:  !bnr.FeatureID.IsNull,
 					Uint32: uint32(bnr.FeatureID.Value),
 				}, &pgtype.Uint32{
 					Valid:  !bnr.TagID.IsNull,
 					Uint32: uint32(bnr.TagID.Value),
-				})
+				}, audit.Actor(ctx), audit.RequestID(ctx), bnr.FeatureIDs)
+			//nolint: staticcheck
+			defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
+
 			if err != nil {
 				return errors.Wrap(err, "can't delete banner")
 			}
 
-			effected := res.RowsAffected()
+			for rows.Next() {
+				var deleted entity.BannerKeys
 
-			if effected == 0 {
+				var tags pgtype.Array[types.ID]
+
+				if err := rows.Scan(&deleted.FeatureID, &tags); err != nil {
+					return errors.Wrap(err, "can't scan delete banner query result")
+				}
+
+				deleted.TagIDs = tags.Elements
+
+				keys = append(keys, deleted)
+			}
+
+			if err := rows.Err(); err != nil {
+				return errors.Wrap(err, "can't end scan delete banner query result")
+			}
+
+			if len(keys) == 0 {
 				return repository.ErrorBannerNotFound
 			}
 
 			return nil
 		},
 	); err != nil {
-		return errors.Wrapf(err,
+		return nil, errors.Wrapf(err,
 			"when deleting banner with feature id %d or tag id %d", bnr.FeatureID.Value, bnr.TagID.Value)
 	}
 
-	return nil
+	return keys, nil
 }
 
-func (br *BannerRepository) CleanDeletedBanner() error {
-	_, err := br.db.Exec(context.Background(), cronDeleteQuery)
+func (br *BannerRepository) CleanDeletedBanner(ctx context.Context) error {
+	_, err := br.db.Exec(ctx, cronDeleteQuery)
 	if err != nil {
 		return errors.Wrap(err, "can't delete deleted banner")
 	}
@@ -480,6 +893,9 @@ func (br *BannerRepository) CleanDeletedBanner() error {
 const (
 	uniqueConflictCode   = "23505"
 	uniqueConstraintName = "banner_identifier"
+
+	checkViolationCode     = "23514"
+	scheduleConstraintName = "banner_schedule"
 )
 
 func checkPgConflictError(err error) error {
@@ -493,5 +909,9 @@ func checkPgConflictError(err error) error {
 		return repository.ErrorBannerConflictExists
 	}
 
+	if e.Code == checkViolationCode && e.ConstraintName == scheduleConstraintName {
+		return repository.ErrorBannerScheduleIncorrect
+	}
+
 	return err
 }
@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/repository"
+	"bannersrv/internal/pkg/pg"
+	"bannersrv/internal/pkg/types"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+const (
+	conflictTagsQuery = `
+		SELECT DISTINCT tag_id FROM features_tags_banner
+			WHERE not deleted and feature_id = $1 and tag_id = ANY ($2::bigint[]) and banner_id != $3
+			ORDER BY tag_id
+	`
+)
+
+// errBatchRollback откатывает транзакцию пакетного изменения без применения операций
+var errBatchRollback = errors.New("batch is rolled back")
+
+// BatchBanners выполняет операции над баннерами в одной транзакции, каждую в отдельной точке сохранения.
+// Если atomic задан, операции применяются, только если ни одна из них не завершилась ошибкой, а успешные
+// операции откатываются с ошибкой ErrorBatchRolledBack. Иначе применяются все успешные операции.
+// Новое содержимое изменяемого баннера сохраняется как черновик author.
+func (br *BannerRepository) BatchBanners(ctx context.Context, operations []entity.BatchOperation,
+	author string, atomic bool,
+) (*entity.BatchResult, error) {
+	result := &entity.BatchResult{
+		Operations: make([]entity.BatchOperationResult, len(operations)),
+		Keys:       make([]entity.BannerKeys, 0, len(operations)),
+	}
+
+	failed := false
+
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			for i := range operations {
+				keys, err := br.batchOperation(ctx, tx, &operations[i], author, &result.Operations[i])
+				if err != nil {
+					return err
+				}
+
+				failed = failed || result.Operations[i].Err != nil
+				result.Keys = append(result.Keys, keys...)
+			}
+
+			if atomic && failed {
+				return errBatchRollback
+			}
+
+			return nil
+		},
+	); err != nil {
+		if !errors.Is(err, errBatchRollback) {
+			return nil, errors.Wrapf(err, "when executing batch of %d banner operations", len(operations))
+		}
+
+		for i := range result.Operations {
+			if result.Operations[i].Err == nil {
+				result.Operations[i] = entity.BatchOperationResult{Err: repository.ErrorBatchRolledBack}
+			}
+		}
+
+		result.Keys = nil
+
+		return result, nil
+	}
+
+	result.Applied = true
+
+	return result, nil
+}
+
+// batchOperation выполняет операцию в точке сохранения. Ошибка операции откатывает только её изменения
+// и сохраняется в result, а возвращаемая ошибка означает, что продолжать пакетное изменение нельзя.
+func (br *BannerRepository) batchOperation(ctx context.Context, tx pgx.Tx, operation *entity.BatchOperation,
+	author string, result *entity.BatchOperationResult,
+) ([]entity.BannerKeys, error) {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create savepoint")
+	}
+
+	keys, err := br.applyBatchOperation(ctx, savepoint, operation, author, result)
+	if err != nil {
+		if errRollback := savepoint.Rollback(ctx); errRollback != nil {
+			return nil, errors.Wrapf(err, "can't rollback %s operation with error %s", operation.Action, errRollback)
+		}
+
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		*result = entity.BatchOperationResult{Err: err}
+
+		if errors.Is(err, repository.ErrorBannerConflictExists) {
+			if result.ConflictTagIDs, err = br.conflictTags(ctx, tx, operation); err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, nil
+	}
+
+	if err := savepoint.Commit(ctx); err != nil {
+		return nil, errors.Wrapf(err, "can't release savepoint of %s operation", operation.Action)
+	}
+
+	return keys, nil
+}
+
+func (br *BannerRepository) applyBatchOperation(ctx context.Context, tx pgx.Tx, operation *entity.BatchOperation,
+	author string, result *entity.BatchOperationResult,
+) ([]entity.BannerKeys, error) {
+	switch operation.Action {
+	case entity.BatchCreate:
+		bnr := operation.Create
+
+		createdID, err := br.createBanner(ctx, tx, bnr.FeatureID, bnr.TagIDs, bnr.Content, bnr.IsActive, bnr.Schedule,
+			bnr.Priority)
+		if err != nil {
+			return nil, err
+		}
+
+		result.ID = &createdID
+
+		return []entity.BannerKeys{{FeatureID: bnr.FeatureID, TagIDs: bnr.TagIDs}}, nil
+	case entity.BatchUpdate:
+		keys, draftID, err := br.updateBannerWithDraft(ctx, tx, operation.Update, operation.Draft, author)
+		if err != nil {
+			return nil, err
+		}
+
+		result.ID, result.DraftID = &operation.ID, draftID
+
+		return keys, nil
+	case entity.BatchDelete:
+		keys, err := br.deleteBanner(ctx, tx, operation.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		result.ID = &operation.ID
+
+		return []entity.BannerKeys{*keys}, nil
+	default:
+		return nil, errors.Errorf("unknown batch action %s", operation.Action)
+	}
+}
+
+// conflictTags возвращает тэги, по которым баннер операции конфликтует с другими баннерами фичи.
+// Фича и тэги, не изменяемые операцией, берутся у текущего баннера.
+func (br *BannerRepository) conflictTags(ctx context.Context, tx pgx.Tx,
+	operation *entity.BatchOperation,
+) ([]types.ID, error) {
+	var bannerID types.ID
+
+	var keys entity.BannerKeys
+
+	switch operation.Action {
+	case entity.BatchCreate:
+		keys = entity.BannerKeys{FeatureID: operation.Create.FeatureID, TagIDs: operation.Create.TagIDs}
+	case entity.BatchUpdate:
+		current, err := br.getBannerKeys(ctx, tx, operation.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		bannerID, keys = operation.ID, *current
+
+		if !operation.Update.FeatureID.IsNull {
+			keys.FeatureID = operation.Update.FeatureID.Value
+		}
+
+		if !operation.Update.TagIDs.IsNull {
+			keys.TagIDs = operation.Update.TagIDs.Value
+		}
+	default:
+		return nil, nil
+	}
+
+	rows, err := tx.Query(ctx, conflictTagsQuery, keys.FeatureID, pgtype.FlatArray[types.ID](keys.TagIDs), bannerID)
+	//nolint: staticcheck
+	defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
+
+	if err != nil {
+		return nil, errors.Wrap(err, "can't execute conflict tags query")
+	}
+
+	tagIDs := make([]types.ID, 0)
+
+	for rows.Next() {
+		var tagID types.ID
+		if err := rows.Scan(&tagID); err != nil {
+			return nil, errors.Wrap(err, "can't scan conflict tags query result")
+		}
+
+		tagIDs = append(tagIDs, tagID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "can't end scan conflict tags query result")
+	}
+
+	return tagIDs, nil
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/repository"
+	"bannersrv/internal/pkg/pg"
+	"bannersrv/internal/pkg/types"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+const (
+	getFeatureCacheTTLQuery = `
+		SELECT feature_id, cache_ttl, updated_at FROM feature_settings WHERE feature_id = $1
+	`
+
+	setFeatureCacheTTLQuery = `
+		INSERT INTO feature_settings (feature_id, cache_ttl) VALUES ($1, $2)
+		ON CONFLICT (feature_id) DO UPDATE SET cache_ttl = excluded.cache_ttl, updated_at = now()
+	`
+
+	deleteFeatureCacheTTLQuery = `
+		DELETE FROM feature_settings WHERE feature_id = $1 RETURNING feature_id
+	`
+
+	getFeatureBannerTagsQuery = `
+		SELECT array_agg(DISTINCT tag_id) FROM features_tags_banner WHERE feature_id = $1 and not deleted
+	`
+)
+
+func (br *BannerRepository) GetFeatureCacheTTL(ctx context.Context,
+	featureID types.ID,
+) (*entity.FeatureCacheTTL, error) {
+	var ttl entity.FeatureCacheTTL
+	if err := br.db.QueryRow(ctx, getFeatureCacheTTLQuery, featureID).
+		Scan(
+			&ttl.FeatureID,
+			&ttl.CacheTTL,
+			&ttl.UpdatedAt,
+		); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(repository.ErrorCacheTTLNotFound, "with feature id %d", featureID)
+		}
+
+		return nil, errors.Wrapf(err, "can't get cache ttl of feature %d", featureID)
+	}
+
+	return &ttl, nil
+}
+
+// SetFeatureCacheTTL задаёт время жизни баннеров фичи в кэше и возвращает тэги её баннеров для сброса их кэша
+func (br *BannerRepository) SetFeatureCacheTTL(ctx context.Context, featureID types.ID,
+	ttl uint32,
+) (*entity.BannerKeys, error) {
+	var keys *entity.BannerKeys
+
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, setFeatureCacheTTLQuery, featureID, ttl); err != nil {
+				return errors.Wrapf(err, "can't set cache ttl to %d", ttl)
+			}
+
+			var err error
+
+			keys, err = br.getFeatureKeys(ctx, tx, featureID)
+
+			return err
+		},
+	); err != nil {
+		return nil, errors.Wrapf(err, "when setting cache ttl of feature %d", featureID)
+	}
+
+	return keys, nil
+}
+
+// DeleteFeatureCacheTTL сбрасывает время жизни баннеров фичи в кэше к значению по умолчанию
+// и возвращает тэги её баннеров для сброса их кэша
+func (br *BannerRepository) DeleteFeatureCacheTTL(ctx context.Context, featureID types.ID) (*entity.BannerKeys, error) {
+	var keys *entity.BannerKeys
+
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			var deletedID types.ID
+			if err := tx.QueryRow(ctx, deleteFeatureCacheTTLQuery, featureID).Scan(&deletedID); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return repository.ErrorCacheTTLNotFound
+				}
+
+				return errors.Wrap(err, "can't delete cache ttl")
+			}
+
+			var err error
+
+			keys, err = br.getFeatureKeys(ctx, tx, featureID)
+
+			return err
+		},
+	); err != nil {
+		return nil, errors.Wrapf(err, "when deleting cache ttl of feature %d", featureID)
+	}
+
+	return keys, nil
+}
+
+// getFeatureKeys возвращает тэги всех баннеров фичи
+func (*BannerRepository) getFeatureKeys(ctx context.Context, tx pgx.Tx,
+	featureID types.ID,
+) (*entity.BannerKeys, error) {
+	keys := entity.BannerKeys{FeatureID: featureID}
+
+	var tags pgtype.Array[types.ID]
+	if err := tx.QueryRow(ctx, getFeatureBannerTagsQuery, featureID).Scan(&tags); err != nil {
+		return nil, errors.Wrap(err, "can't get tag ids of feature banners")
+	}
+
+	keys.TagIDs = tags.Elements
+
+	return &keys, nil
+}
@@ -0,0 +1,329 @@
+package postgres
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/repository"
+	"bannersrv/internal/pkg/pg"
+	"bannersrv/internal/pkg/types"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+const (
+	createDraftQuery = `
+		INSERT INTO draft_banner (banner_id, content, author) VALUES ($1, $2, $3) RETURNING id
+	`
+
+	getDraftsQuery = `
+		SELECT id, banner_id, content, status, author, reviewer, published_version, created_at, updated_at
+			FROM draft_banner
+			WHERE banner_id = $1 and (CASE WHEN $2::text IS NOT NULL THEN status = $2 ELSE true END)
+			ORDER BY id DESC
+			LIMIT $3 OFFSET $4
+	`
+
+	getDraftQuery = `
+		SELECT id, banner_id, content, status, author, reviewer, published_version, created_at, updated_at
+			FROM draft_banner WHERE id = $1
+	`
+
+	lockDraftQuery = `
+		SELECT banner_id, content, status, author FROM draft_banner WHERE id = $1 FOR UPDATE
+	`
+
+	reviewDraftQuery = `
+		UPDATE draft_banner SET status = $2, reviewer = $3, updated_at = now() WHERE id = $1
+	`
+
+	publishDraftQuery = `
+		UPDATE draft_banner SET status = 'published', published_version = $2, updated_at = now() WHERE id = $1
+	`
+)
+
+// UpdateBannerWithDraft применяет изменения полей баннера и сохраняет содержимое draft как черновик author
+// в одной транзакции, поэтому при ошибке создания черновика поля баннера не изменяются.
+// Возвращает фичу и тэги баннера, кэш которых нужно сбросить, и идентификатор черновика, если он создан.
+func (br *BannerRepository) UpdateBannerWithDraft(ctx context.Context, bnr *entity.BannerUpdate,
+	draft *types.Content, author string,
+) ([]entity.BannerKeys, *types.ID, error) {
+	var keys []entity.BannerKeys
+
+	var draftID *types.ID
+
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			var err error
+
+			keys, draftID, err = br.updateBannerWithDraft(ctx, tx, bnr, draft, author)
+
+			return err
+		},
+	); err != nil {
+		return nil, nil, errors.Wrapf(err, "when updating banner with id %d", bnr.ID)
+	}
+
+	return keys, draftID, nil
+}
+
+// updateBannerWithDraft применяет изменения полей баннера и создаёт черновик, если draft задан.
+// Если кроме черновика ничего не изменяется, баннер не обновляется, чтобы не записывать в журнал
+// изменений пустое изменение и не сбрасывать кэш.
+func (br *BannerRepository) updateBannerWithDraft(ctx context.Context, tx pgx.Tx, bnr *entity.BannerUpdate,
+	draft *types.Content, author string,
+) ([]entity.BannerKeys, *types.ID, error) {
+	var keys []entity.BannerKeys
+
+	if draft == nil || hasFieldChanges(bnr) {
+		var err error
+
+		if keys, err = br.updateBanner(ctx, tx, bnr); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if draft == nil {
+		return keys, nil, nil
+	}
+
+	draftID, err := br.createDraft(ctx, tx, bnr.ID, *draft, author)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return keys, &draftID, nil
+}
+
+// hasFieldChanges возвращает true, если изменение задаёт хотя бы одно поле баннера
+func hasFieldChanges(bnr *entity.BannerUpdate) bool {
+	return !bnr.Content.IsNull || !bnr.FeatureID.IsNull || !bnr.TagIDs.IsNull || !bnr.IsActive.IsNull ||
+		isSet(bnr.StartAt) || isSet(bnr.EndAt) || isSet(bnr.Retention) || isSet(bnr.CacheTTL) || isSet(bnr.Priority)
+}
+
+// createDraft создаёт черновик содержимого баннера и записывает его создание в журнал изменений
+func (br *BannerRepository) createDraft(ctx context.Context, tx pgx.Tx, bannerID types.ID,
+	content types.Content, author string,
+) (types.ID, error) {
+	var checkedID types.ID
+	if err := tx.QueryRow(ctx, checkDeleted, bannerID).Scan(&checkedID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repository.ErrorBannerNotFound
+		}
+
+		return 0, errors.Wrapf(err, "can't check banner on deleted")
+	}
+
+	before, err := br.snapshotBanner(ctx, tx, bannerID)
+	if err != nil {
+		return 0, err
+	}
+
+	var createdID types.ID
+	if err := tx.QueryRow(ctx, createDraftQuery, bannerID, content, author).Scan(&createdID); err != nil {
+		return 0, errors.Wrap(err, "can't create draft")
+	}
+
+	return createdID, br.addAudit(ctx, tx, entity.AuditDraft, bannerID, before)
+}
+
+func (br *BannerRepository) GetDrafts(ctx context.Context, bannerID types.ID, status *entity.DraftStatus,
+	offset, limit uint64,
+) ([]entity.Draft, error) {
+	drafts := make([]entity.Draft, 0)
+
+	sqlStatus := &pgtype.Text{}
+	if status != nil {
+		sqlStatus = &pgtype.Text{Valid: true, String: string(*status)}
+	}
+
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			var checkedID types.ID
+			if err := tx.QueryRow(ctx, checkBannerExistsQuery, bannerID).Scan(&checkedID); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return repository.ErrorBannerNotFound
+				}
+
+				return errors.Wrap(err, "can't check banner on existence")
+			}
+
+			rows, err := tx.Query(ctx, getDraftsQuery, bannerID, sqlStatus, limit, offset)
+			//nolint: staticcheck
+			defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
+
+			if err != nil {
+				return errors.Wrap(err, "can't execute get drafts query")
+			}
+
+			for rows.Next() {
+				var draft entity.Draft
+
+				if err := scanDraft(rows, &draft); err != nil {
+					return errors.Wrap(err, "can't scan get drafts query result")
+				}
+
+				drafts = append(drafts, draft)
+			}
+
+			if err := rows.Err(); err != nil {
+				return errors.Wrap(err, "can't end scan get drafts query result")
+			}
+
+			return nil
+		},
+	); err != nil {
+		return nil, errors.Wrapf(err,
+			"when selecting drafts of banner with id %d, limit %d and offset %d", bannerID, limit, offset)
+	}
+
+	return drafts, nil
+}
+
+func (br *BannerRepository) GetDraft(ctx context.Context, id types.ID) (*entity.Draft, error) {
+	var draft entity.Draft
+
+	if err := scanDraft(br.db.QueryRow(ctx, getDraftQuery, id), &draft); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(repository.ErrorDraftNotFound, "with id %d", id)
+		}
+
+		return nil, errors.Wrapf(err, "can't get draft with id %d", id)
+	}
+
+	return &draft, nil
+}
+
+// ReviewDraft переводит черновик на рассмотрении в состояние to, запоминая рассмотревшего его админа.
+// Одобрить черновик может только админ, не являющийся его автором.
+func (br *BannerRepository) ReviewDraft(ctx context.Context, id types.ID, reviewer string,
+	to entity.DraftStatus,
+) error {
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			var bannerID types.ID
+
+			var content types.Content
+
+			var status entity.DraftStatus
+
+			var author string
+
+			if err := tx.QueryRow(ctx, lockDraftQuery, id).Scan(&bannerID, &content, &status, &author); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return repository.ErrorDraftNotFound
+				}
+
+				return errors.Wrap(err, "can't lock draft")
+			}
+
+			if status != entity.DraftPending {
+				return errors.Wrapf(repository.ErrorDraftStatusConflict, "draft is %s", status)
+			}
+
+			if to == entity.DraftApproved && author == reviewer {
+				return repository.ErrorDraftSelfApproval
+			}
+
+			before, err := br.snapshotBanner(ctx, tx, bannerID)
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.Exec(ctx, reviewDraftQuery, id, to, reviewer); err != nil {
+				return errors.Wrap(err, "can't update draft status")
+			}
+
+			action := entity.AuditApprove
+			if to == entity.DraftRejected {
+				action = entity.AuditReject
+			}
+
+			return br.addAudit(ctx, tx, action, bannerID, before)
+		},
+	); err != nil {
+		return errors.Wrapf(err, "when moving draft with id %d to %s", id, to)
+	}
+
+	return nil
+}
+
+// PublishDraft добавляет содержимое одобренного черновика как новую версию баннера
+func (br *BannerRepository) PublishDraft(ctx context.Context, id types.ID) (*entity.CurrentVersion, error) {
+	published := &entity.CurrentVersion{}
+
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			var bannerID types.ID
+
+			var content types.Content
+
+			var status entity.DraftStatus
+
+			var author string
+
+			if err := tx.QueryRow(ctx, lockDraftQuery, id).Scan(&bannerID, &content, &status, &author); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return repository.ErrorDraftNotFound
+				}
+
+				return errors.Wrap(err, "can't lock draft")
+			}
+
+			if status != entity.DraftApproved {
+				return errors.Wrapf(repository.ErrorDraftStatusConflict, "draft is %s", status)
+			}
+
+			var lastVersion uint32
+			if err := tx.QueryRow(ctx, lockBannerQuery, bannerID).Scan(&lastVersion); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return repository.ErrorBannerNotFound
+				}
+
+				return errors.Wrap(err, "can't lock banner")
+			}
+
+			before, err := br.snapshotBanner(ctx, tx, bannerID)
+			if err != nil {
+				return err
+			}
+
+			if err := tx.QueryRow(ctx, addContentWithVersionQuery, bannerID, content).
+				Scan(&published.Version); err != nil {
+				return errors.Wrap(err, "can't add content of draft to banner")
+			}
+
+			if _, err := tx.Exec(ctx, publishDraftQuery, id, published.Version); err != nil {
+				return errors.Wrap(err, "can't mark draft as published")
+			}
+
+			keys, err := br.getBannerKeys(ctx, tx, bannerID)
+			if err != nil {
+				return err
+			}
+
+			published.FeatureID, published.TagIDs = keys.FeatureID, keys.TagIDs
+
+			return br.addAudit(ctx, tx, entity.AuditPublish, bannerID, before)
+		},
+	); err != nil {
+		return nil, errors.Wrapf(err, "when publishing draft with id %d", id)
+	}
+
+	return published, nil
+}
+
+func scanDraft(row pgx.Row, draft *entity.Draft) error {
+	return row.Scan(
+		&draft.ID,
+		&draft.BannerID,
+		&draft.Content,
+		&draft.Status,
+		&draft.Author,
+		&draft.Reviewer,
+		&draft.PublishedVersion,
+		&draft.CreatedAt,
+		&draft.UpdatedAt,
+	)
+}
@@ -0,0 +1,281 @@
+package postgres
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/repository"
+	"bannersrv/internal/pkg/pg"
+	"bannersrv/internal/pkg/types"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+)
+
+const (
+	createExperimentQuery = `
+		INSERT INTO experiment (banner_id) VALUES ($1) RETURNING id
+	`
+
+	addVariantQuery = `
+		INSERT INTO experiment_variant (experiment_id, weight, content) VALUES ($1, $2, $3)
+	`
+
+	getExperimentQuery = `
+		SELECT id, banner_id, status, winner_variant_id, created_at, updated_at, concluded_at 
+			FROM experiment WHERE id = $1
+	`
+
+	getVariantsQuery = `
+		SELECT id, weight, content FROM experiment_variant WHERE experiment_id = $1 ORDER BY id
+	`
+
+	updateExperimentStatusQuery = `
+		UPDATE experiment SET status = $3, updated_at = now()
+			WHERE id = $1 and status = $2
+			RETURNING banner_id
+	`
+
+	lockExperimentQuery = `
+		SELECT banner_id, status FROM experiment WHERE id = $1 FOR UPDATE
+	`
+
+	getVariantContentQuery = `
+		SELECT content FROM experiment_variant WHERE id = $2 and experiment_id = $1
+	`
+
+	addContentWithVersionQuery = `
+		INSERT INTO version_banner (banner_id, content) VALUES ($1, $2) RETURNING version
+	`
+
+	concludeExperimentQuery = `
+		UPDATE experiment SET status = 'concluded', winner_variant_id = $2, 
+		                      concluded_at = now(), updated_at = now()
+			WHERE id = $1
+	`
+)
+
+const experimentConstraintName = "banner_experiment"
+
+// CreateExperiment создаёт эксперимент над баннером и возвращает его идентификатор
+// вместе с фичей и тэгами баннера, по которым нужно сбросить кэш
+func (br *BannerRepository) CreateExperiment(ctx context.Context, bannerID types.ID,
+	variants []entity.Variant,
+) (types.ID, *entity.BannerKeys, error) {
+	var createdID types.ID
+
+	var keys *entity.BannerKeys
+
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			var checkedID types.ID
+			if err := tx.QueryRow(ctx, checkDeleted, bannerID).Scan(&checkedID); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return repository.ErrorBannerNotFound
+				}
+
+				return errors.Wrapf(err, "can't check banner on deleted")
+			}
+
+			if err := tx.QueryRow(ctx, createExperimentQuery, bannerID).Scan(&createdID); err != nil {
+				return errors.Wrap(checkExperimentConflictError(err), "can't create experiment")
+			}
+
+			for _, variant := range variants {
+				if _, err := tx.Exec(ctx, addVariantQuery, createdID, variant.Weight, variant.Content); err != nil {
+					return errors.Wrap(err, "can't add variant to experiment")
+				}
+			}
+
+			var err error
+
+			keys, err = br.getBannerKeys(ctx, tx, bannerID)
+
+			return err
+		},
+	); err != nil {
+		return 0, nil, errors.Wrapf(err, "when creating experiment for banner with id %d", bannerID)
+	}
+
+	return createdID, keys, nil
+}
+
+func (br *BannerRepository) GetExperiment(ctx context.Context, id types.ID) (*entity.Experiment, error) {
+	var experiment entity.Experiment
+
+	if err := br.db.QueryRow(ctx, getExperimentQuery, id).
+		Scan(
+			&experiment.ID,
+			&experiment.BannerID,
+			&experiment.Status,
+			&experiment.WinnerVariantID,
+			&experiment.CreatedAt,
+			&experiment.UpdatedAt,
+			&experiment.ConcludedAt,
+		); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(repository.ErrorExperimentNotFound, "with id %d", id)
+		}
+
+		return nil, errors.Wrapf(err, "can't get experiment with id %d", id)
+	}
+
+	variants, err := br.GetExperimentVariants(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	experiment.Variants = variants
+
+	return &experiment, nil
+}
+
+func (br *BannerRepository) GetExperimentVariants(ctx context.Context,
+	experimentID types.ID,
+) ([]entity.Variant, error) {
+	rows, err := br.db.Query(ctx, getVariantsQuery, experimentID)
+	//nolint: staticcheck
+	defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't execute get variants query for experiment with id %d", experimentID)
+	}
+
+	variants := make([]entity.Variant, 0)
+
+	for rows.Next() {
+		var variant entity.Variant
+
+		if err := rows.Scan(
+			&variant.ID,
+			&variant.Weight,
+			&variant.Content,
+		); err != nil {
+			return nil, errors.Wrap(err, "can't scan get variants query result")
+		}
+
+		variants = append(variants, variant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "can't end scan get variants query result")
+	}
+
+	return variants, nil
+}
+
+// UpdateExperimentStatus переводит эксперимент из состояния from в состояние to
+// и возвращает фичу и тэги баннера эксперимента
+func (br *BannerRepository) UpdateExperimentStatus(ctx context.Context, id types.ID,
+	from, to entity.ExperimentStatus,
+) (*entity.BannerKeys, error) {
+	var keys *entity.BannerKeys
+
+	err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			var bannerID types.ID
+			if err := tx.QueryRow(ctx, updateExperimentStatusQuery, id, from, to).Scan(&bannerID); err != nil {
+				return err
+			}
+
+			var err error
+
+			keys, err = br.getBannerKeys(ctx, tx, bannerID)
+
+			return err
+		},
+	)
+	if err == nil {
+		return keys, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrapf(err, "can't update status of experiment with id %d", id)
+	}
+
+	// Эксперимент либо отсутствует, либо находится в другом состоянии
+	if _, err := br.GetExperiment(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return nil, errors.Wrapf(repository.ErrorExperimentStatusConflict,
+		"experiment with id %d is not %s", id, from)
+}
+
+// ConcludeExperiment завершает эксперимент и добавляет содержимое победившего варианта
+// как новую версию баннера. Возвращает номер созданной версии.
+func (br *BannerRepository) ConcludeExperiment(ctx context.Context, id,
+	winnerVariantID types.ID,
+) (*entity.CurrentVersion, error) {
+	concluded := &entity.CurrentVersion{}
+
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			var bannerID types.ID
+
+			var status entity.ExperimentStatus
+
+			if err := tx.QueryRow(ctx, lockExperimentQuery, id).Scan(&bannerID, &status); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return repository.ErrorExperimentNotFound
+				}
+
+				return errors.Wrap(err, "can't lock experiment")
+			}
+
+			if status == entity.ExperimentConcluded {
+				return errors.Wrap(repository.ErrorExperimentStatusConflict, "experiment is already concluded")
+			}
+
+			var content types.Content
+			if err := tx.QueryRow(ctx, getVariantContentQuery, id, winnerVariantID).Scan(&content); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return errors.Wrapf(repository.ErrorVariantNotFound, "with id %d", winnerVariantID)
+				}
+
+				return errors.Wrap(err, "can't get content of winner variant")
+			}
+
+			before, err := br.snapshotBanner(ctx, tx, bannerID)
+			if err != nil {
+				return err
+			}
+
+			if err := tx.QueryRow(ctx, addContentWithVersionQuery, bannerID, content).
+				Scan(&concluded.Version); err != nil {
+				return errors.Wrap(err, "can't add content of winner variant to banner")
+			}
+
+			if _, err := tx.Exec(ctx, concludeExperimentQuery, id, winnerVariantID); err != nil {
+				return errors.Wrap(err, "can't conclude experiment")
+			}
+
+			keys, err := br.getBannerKeys(ctx, tx, bannerID)
+			if err != nil {
+				return err
+			}
+
+			concluded.FeatureID, concluded.TagIDs = keys.FeatureID, keys.TagIDs
+
+			return br.addAudit(ctx, tx, entity.AuditConclude, bannerID, before)
+		},
+	); err != nil {
+		return nil, errors.Wrapf(err, "when concluding experiment with id %d", id)
+	}
+
+	return concluded, nil
+}
+
+func checkExperimentConflictError(err error) error {
+	var e *pgconn.PgError
+
+	if !errors.As(err, &e) {
+		return err
+	}
+
+	if e.Code == uniqueConflictCode && e.ConstraintName == experimentConstraintName {
+		return repository.ErrorExperimentConflictExists
+	}
+
+	return err
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/repository"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+)
+
+const (
+	// searchContentQuery ищет версии баннеров, в запрос подставляются заданные условия поиска по содержимому
+	searchContentQuery = `
+		SELECT vb.banner_id, vb.version, vb.version = banner.last_version, vb.created_at FROM version_banner as vb
+			INNER JOIN banner ON (banner.id = vb.banner_id)
+			WHERE ($1::boolean or vb.version = banner.last_version)
+			and EXISTS (SELECT 1 FROM features_tags_banner as ftb WHERE ftb.banner_id = vb.banner_id and not deleted
+				and (CASE WHEN $2::bigint[] IS NOT NULL THEN feature_id = ANY ($2) ELSE true END)
+				and (CASE WHEN $3::bigint[] IS NOT NULL THEN feature_id = ANY ($3) ELSE true END))
+			%s
+			ORDER BY vb.banner_id, vb.version DESC
+			LIMIT $4 OFFSET $5
+	`
+
+	// Выражения условий совпадают с выражениями индексов version_banner_content_path и version_banner_content_text
+	searchPathCondition = `
+			and vb.content @@ $%d::jsonpath`
+	searchTextCondition = `
+			and jsonb_to_tsvector('simple', vb.content, '["string"]') @@ websearch_to_tsquery('simple', $%d)`
+)
+
+const (
+	syntaxErrorCode    = "42601"
+	dataExceptionClass = "22"
+)
+
+// SearchBanners возвращает версии баннеров, содержимое которых подходит под поиск, по возрастанию
+// идентификатора баннера и от новых версий к старым. Условия поиска добавляются в запрос, только если заданы,
+// иначе планировщик не сможет использовать для них индексы.
+func (br *BannerRepository) SearchBanners(ctx context.Context, search *entity.ContentSearch,
+	offset, limit uint64,
+) ([]entity.ContentMatch, error) {
+	args := []any{search.AllVersions, search.Features, search.FeatureIDs, limit, offset}
+
+	var conditions strings.Builder
+
+	if search.JSONPath != nil {
+		args = append(args, *search.JSONPath)
+		conditions.WriteString(fmt.Sprintf(searchPathCondition, len(args)))
+	}
+
+	if search.Text != nil {
+		args = append(args, *search.Text)
+		conditions.WriteString(fmt.Sprintf(searchTextCondition, len(args)))
+	}
+
+	rows, err := br.db.Query(ctx, fmt.Sprintf(searchContentQuery, conditions.String()), args...)
+	//nolint: staticcheck
+	defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
+
+	if err != nil {
+		return nil, checkPgSearchError(errors.Wrap(err, "can't execute search content query"))
+	}
+
+	matches := make([]entity.ContentMatch, 0)
+
+	for rows.Next() {
+		var match entity.ContentMatch
+
+		if err := rows.Scan(&match.BannerID, &match.Version, &match.Current, &match.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, "can't scan search content query result")
+		}
+
+		matches = append(matches, match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, checkPgSearchError(errors.Wrap(err, "can't end scan search content query result"))
+	}
+
+	return matches, nil
+}
+
+// checkPgSearchError заменяет ошибки разбора jsonpath и регулярных выражений в нём
+// на ErrorSearchQueryIncorrect с сообщением базы, которое отдаётся админу вместо контекста ошибки
+func checkPgSearchError(err error) error {
+	var e *pgconn.PgError
+
+	if !errors.As(err, &e) {
+		return err
+	}
+
+	if e.Code == syntaxErrorCode || strings.HasPrefix(e.Code, dataExceptionClass) {
+		return errors.Wrap(repository.ErrorSearchQueryIncorrect, e.Message)
+	}
+
+	return err
+}
@@ -0,0 +1,453 @@
+package postgres
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/pkg/audit"
+	"bannersrv/internal/pkg/pg"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Версии собираются в json одним запросом, чтобы баннер выгружался целиком из одной строки результата
+	exportQuery = `
+		SELECT banner.id, ftb.feature_id, array_agg(ftb.tag_id ORDER BY ftb.tag_id), is_active, start_at, end_at,
+		       retention, cache_ttl, priority, banner.created_at, banner.updated_at,
+		       (SELECT jsonb_agg(jsonb_build_object('version', vb.version, 'content', vb.content,
+		                                            'created_at', vb.created_at) ORDER BY vb.version)
+		        FROM version_banner as vb WHERE vb.banner_id = banner.id)
+		FROM banner
+			INNER JOIN features_tags_banner as ftb ON (ftb.banner_id = banner.id and not deleted)
+		WHERE (CASE WHEN $1::bigint[] IS NOT NULL THEN ftb.feature_id = ANY ($1) ELSE true END)
+		GROUP BY banner.id, ftb.feature_id
+		ORDER BY banner.id
+	`
+
+	// Без идентификатора баннер получает следующий идентификатор из последовательности, как при обычном создании
+	importCreateQuery = `
+		INSERT INTO banner (id, is_active, start_at, end_at, retention, cache_ttl, priority)
+		VALUES (COALESCE($1::bigint, nextval(pg_get_serial_sequence('banner', 'id'))), $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	importUpdateQuery = `
+		UPDATE banner SET is_active = $2, start_at = $3, end_at = $4, retention = $5, cache_ttl = $6, priority = $7
+			WHERE id = $1
+	`
+
+	// Баннер с тем же идентификатором может ожидать отложенного удаления, он удаляется сразу
+	importDeleteQuery = `
+		DELETE FROM banner WHERE id = $1
+	`
+
+	importDeleteFeaturesTagsQuery = `
+		DELETE FROM features_tags_banner WHERE banner_id = $1
+	`
+
+	importDeleteVersionsQuery = `
+		DELETE FROM version_banner WHERE banner_id = $1
+	`
+
+	// Архив удаляется вместе с версиями, иначе архивирование импортированных версий пропустит
+	// совпадающие по номеру версии старого архива
+	importDeleteArchiveQuery = `
+		DELETE FROM archive_version_banner WHERE banner_id = $1
+	`
+
+	importAddVersionQuery = `
+		INSERT INTO version_banner (banner_id, version, content, created_at)
+		VALUES ($1, $2, $3, COALESCE($4, now()))
+	`
+
+	// Триггер не изменяет последнюю версию при добавлении первой версии баннера
+	importLastVersionQuery = `
+		UPDATE banner SET last_version = (SELECT max(version) FROM version_banner WHERE banner_id = $1)
+			WHERE id = $1
+	`
+
+	importKeysQuery = `
+		SELECT feature_id, array_agg(DISTINCT tag_id) FROM features_tags_banner WHERE not deleted GROUP BY feature_id
+	`
+
+	importAuditDeleteQuery = `
+		INSERT INTO audit_log (actor, request_id, action, banner_id, before)
+		SELECT NULLIF($1, ''), NULLIF($2, ''), 'delete', banner_id, banner_snapshot(banner_id)
+		FROM (SELECT DISTINCT banner_id FROM features_tags_banner WHERE not deleted) as live_banners
+	`
+
+	importDeleteAllQuery = `
+		DELETE FROM banner
+	`
+
+	// Последовательность сдвигается за импортированные идентификаторы, чтобы новые баннеры их не занимали
+	importSequenceQuery = `
+		SELECT setval(pg_get_serial_sequence('banner', 'id'),
+		              GREATEST(nextval(pg_get_serial_sequence('banner', 'id')), (SELECT max(id) FROM banner)))
+	`
+)
+
+// errImportRollback откатывает транзакцию импорта без применения изменений
+var errImportRollback = errors.New("import is rolled back")
+
+type exportVersion struct {
+	Version   uint32          `json:"version"`
+	Content   json.RawMessage `json:"content"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ExportBanners передаёт в export баннеры фич featureIDs по одному в порядке идентификаторов,
+// не загружая их в память целиком. Если featureIDs равен nil, выгружаются баннеры всех фич.
+func (br *BannerRepository) ExportBanners(ctx context.Context, featureIDs []types.ID,
+	export func(bnr *entity.Banner) error,
+) error {
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			rows, err := tx.Query(ctx, exportQuery, featureIDs)
+			//nolint: staticcheck
+			defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
+
+			if err != nil {
+				return errors.Wrap(err, "can't execute export banners query")
+			}
+
+			for rows.Next() {
+				var bnr entity.Banner
+
+				var tags pgtype.Array[types.ID]
+
+				var versions []byte
+
+				if err := rows.Scan(
+					&bnr.ID,
+					&bnr.FeatureID,
+					&tags,
+					&bnr.IsActive,
+					&bnr.StartAt,
+					&bnr.EndAt,
+					&bnr.Retention,
+					&bnr.CacheTTL,
+					&bnr.Priority,
+					&bnr.CreatedAt,
+					&bnr.UpdatedAt,
+					&versions,
+				); err != nil {
+					return errors.Wrap(err, "can't scan export banners query result")
+				}
+
+				bnr.TagIDs = tags.Elements
+
+				if bnr.Versions, err = exportVersions(versions); err != nil {
+					return errors.Wrapf(err, "of banner with id %d", bnr.ID)
+				}
+
+				if err := export(&bnr); err != nil {
+					return err
+				}
+			}
+
+			if err := rows.Err(); err != nil {
+				return errors.Wrap(err, "can't end scan export banners query result")
+			}
+
+			return nil
+		},
+	); err != nil {
+		return errors.Wrap(err, "when exporting banners")
+	}
+
+	return nil
+}
+
+func exportVersions(data []byte) ([]entity.Content, error) {
+	var versions []exportVersion
+
+	if data != nil {
+		if err := json.Unmarshal(data, &versions); err != nil {
+			return nil, errors.Wrap(err, "can't parse versions")
+		}
+	}
+
+	contents := make([]entity.Content, 0, len(versions))
+	for _, version := range versions {
+		contents = append(contents, entity.Content{
+			Version:   version.Version,
+			Content:   types.Content(version.Content),
+			CreatedAt: version.CreatedAt,
+		})
+	}
+
+	return contents, nil
+}
+
+// ImportBanners импортирует баннеры в одной транзакции. Каждая строка импортируется в отдельной точке сохранения,
+// поэтому ошибки, в том числе конфликты по паре фича-тэг, собираются по всем строкам. Изменения применяются,
+// только если ни в одной строке нет ошибок и dryRun не задан, иначе транзакция откатывается.
+func (br *BannerRepository) ImportBanners(ctx context.Context, banners []entity.ImportBanner,
+	mode entity.ImportMode, dryRun bool,
+) (*entity.ImportResult, error) {
+	result := &entity.ImportResult{
+		Errors: make([]entity.ImportLineError, 0),
+		Keys:   make([]entity.BannerKeys, 0),
+	}
+
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			if mode == entity.ImportReplace {
+				if err := br.deleteAllBanners(ctx, tx, result); err != nil {
+					return err
+				}
+			}
+
+			for i := range banners {
+				if err := br.importLine(ctx, tx, &banners[i], mode, result); err != nil {
+					return err
+				}
+			}
+
+			if dryRun || len(result.Errors) != 0 {
+				return errImportRollback
+			}
+
+			if _, err := tx.Exec(ctx, importSequenceQuery); err != nil {
+				return errors.Wrap(err, "can't move banner id sequence")
+			}
+
+			return nil
+		},
+	); err != nil && !errors.Is(err, errImportRollback) {
+		return nil, errors.Wrapf(err, "when importing %d banners in %s mode", len(banners), mode)
+	}
+
+	return result, nil
+}
+
+// deleteAllBanners удаляет все баннеры перед импортом с заменой, записывая удаление в журнал изменений
+func (*BannerRepository) deleteAllBanners(ctx context.Context, tx pgx.Tx, result *entity.ImportResult) error {
+	rows, err := tx.Query(ctx, importKeysQuery)
+	//nolint: staticcheck
+	defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
+
+	if err != nil {
+		return errors.Wrap(err, "can't get keys of all banners")
+	}
+
+	for rows.Next() {
+		var deleted entity.BannerKeys
+
+		var tags pgtype.Array[types.ID]
+
+		if err := rows.Scan(&deleted.FeatureID, &tags); err != nil {
+			return errors.Wrap(err, "can't scan keys of all banners")
+		}
+
+		deleted.TagIDs = tags.Elements
+
+		result.Keys = append(result.Keys, deleted)
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "can't end scan keys of all banners")
+	}
+
+	tag, err := tx.Exec(ctx, importAuditDeleteQuery, audit.Actor(ctx), audit.RequestID(ctx))
+	if err != nil {
+		return errors.Wrap(err, "can't add delete actions to audit log")
+	}
+
+	result.Deleted = uint64(tag.RowsAffected())
+
+	if _, err := tx.Exec(ctx, importDeleteAllQuery); err != nil {
+		return errors.Wrap(err, "can't delete all banners")
+	}
+
+	return nil
+}
+
+// importLine импортирует баннер строки в точке сохранения. Ошибка строки откатывает только её изменения
+// и добавляется в результат, а возвращаемая ошибка означает, что продолжать импорт нельзя.
+func (br *BannerRepository) importLine(ctx context.Context, tx pgx.Tx, bnr *entity.ImportBanner,
+	mode entity.ImportMode, result *entity.ImportResult,
+) error {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "can't create savepoint for line %d", bnr.Line)
+	}
+
+	created, keys, err := br.importBanner(ctx, savepoint, bnr, mode)
+	if err != nil {
+		if errRollback := savepoint.Rollback(ctx); errRollback != nil {
+			return errors.Wrapf(err, "can't rollback line %d with error %s", bnr.Line, errRollback)
+		}
+
+		if ctx.Err() != nil {
+			return err
+		}
+
+		result.Errors = append(result.Errors, entity.ImportLineError{Line: bnr.Line, Err: err})
+
+		return nil
+	}
+
+	if err := savepoint.Commit(ctx); err != nil {
+		return errors.Wrapf(err, "can't release savepoint for line %d", bnr.Line)
+	}
+
+	if created {
+		result.Created++
+	} else {
+		result.Updated++
+	}
+
+	result.Keys = append(result.Keys, keys...)
+
+	return nil
+}
+
+// importBanner создаёт баннер или заменяет существующий баннер с тем же идентификатором в режиме upsert.
+// Возвращает, был ли баннер создан, и пары фича-тэг, по которым баннер был доступен до и после импорта.
+func (br *BannerRepository) importBanner(ctx context.Context, tx pgx.Tx, bnr *entity.ImportBanner,
+	mode entity.ImportMode,
+) (bool, []entity.BannerKeys, error) {
+	var id *types.ID
+	if mode != entity.ImportCreate {
+		id = bnr.ID
+	}
+
+	keys := make([]entity.BannerKeys, 0, 2)
+
+	exists, err := br.importedBannerExists(ctx, tx, id)
+	if err != nil {
+		return false, nil, err
+	}
+
+	var before []byte
+
+	if exists {
+		if before, err = br.snapshotBanner(ctx, tx, *id); err != nil {
+			return false, nil, err
+		}
+
+		beforeKeys, err := br.getBannerKeys(ctx, tx, *id)
+		if err != nil {
+			return false, nil, err
+		}
+
+		keys = append(keys, *beforeKeys)
+
+		if err := br.clearImportedBanner(ctx, tx, bnr, *id); err != nil {
+			return false, nil, err
+		}
+	} else {
+		if id != nil {
+			if _, err := tx.Exec(ctx, importDeleteQuery, *id); err != nil {
+				return false, nil, errors.Wrapf(err, "can't delete banner with id %d", *id)
+			}
+		}
+
+		var createdID types.ID
+		if err := tx.QueryRow(ctx, importCreateQuery, id, bnr.IsActive, bnr.StartAt, bnr.EndAt,
+			bnr.Retention, bnr.CacheTTL, bnr.Priority).Scan(&createdID); err != nil {
+			return false, nil, errors.Wrap(checkPgConflictError(err), "can't create banner")
+		}
+
+		id = &createdID
+	}
+
+	if _, err := tx.Exec(ctx, addFeaturesAndTagsQuery, *id, bnr.FeatureID,
+		pgtype.FlatArray[types.ID](bnr.TagIDs)); err != nil {
+		return false, nil, errors.Wrapf(checkPgConflictError(err),
+			"can't add feature id %d and tag ids %v to banner", bnr.FeatureID, bnr.TagIDs)
+	}
+
+	if err := br.addImportedVersions(ctx, tx, *id, bnr.Versions); err != nil {
+		return false, nil, err
+	}
+
+	keys = append(keys, entity.BannerKeys{FeatureID: bnr.FeatureID, TagIDs: bnr.TagIDs})
+
+	action := entity.AuditCreate
+	if exists {
+		action = entity.AuditUpdate
+	}
+
+	return !exists, keys, br.addAudit(ctx, tx, action, *id, before)
+}
+
+// importedBannerExists проверяет, что баннер с идентификатором id существует и не ожидает удаления
+func (*BannerRepository) importedBannerExists(ctx context.Context, tx pgx.Tx, id *types.ID) (bool, error) {
+	if id == nil {
+		return false, nil
+	}
+
+	var existingID types.ID
+	if err := tx.QueryRow(ctx, checkDeleted, *id).Scan(&existingID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, errors.Wrapf(err, "can't check banner with id %d on deleted", *id)
+	}
+
+	return true, nil
+}
+
+// clearImportedBanner заменяет поля существующего баннера и удаляет его тэги, хранимые и архивные версии,
+// чтобы они были созданы заново из импорта
+func (*BannerRepository) clearImportedBanner(ctx context.Context, tx pgx.Tx, bnr *entity.ImportBanner,
+	id types.ID,
+) error {
+	if _, err := tx.Exec(ctx, importUpdateQuery, id, bnr.IsActive, bnr.StartAt, bnr.EndAt,
+		bnr.Retention, bnr.CacheTTL, bnr.Priority); err != nil {
+		return errors.Wrapf(checkPgConflictError(err), "can't update banner with id %d", id)
+	}
+
+	if _, err := tx.Exec(ctx, importDeleteFeaturesTagsQuery, id); err != nil {
+		return errors.Wrapf(err, "can't delete feature id and tag ids of banner with id %d", id)
+	}
+
+	if _, err := tx.Exec(ctx, importDeleteVersionsQuery, id); err != nil {
+		return errors.Wrapf(err, "can't delete versions of banner with id %d", id)
+	}
+
+	if _, err := tx.Exec(ctx, importDeleteArchiveQuery, id); err != nil {
+		return errors.Wrapf(err, "can't delete archived versions of banner with id %d", id)
+	}
+
+	return nil
+}
+
+// addImportedVersions добавляет версии баннера по возрастанию номера. Номера версий сохраняются,
+// если они идут подряд, а версии сверх числа хранимых сразу переносятся в архив.
+func (*BannerRepository) addImportedVersions(ctx context.Context, tx pgx.Tx, id types.ID,
+	versions []entity.Content,
+) error {
+	sorted := append([]entity.Content(nil), versions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for _, version := range sorted {
+		createdAt := &version.CreatedAt
+		if version.CreatedAt.IsZero() {
+			createdAt = nil
+		}
+
+		if _, err := tx.Exec(ctx, importAddVersionQuery, id, version.Version, version.Content,
+			createdAt); err != nil {
+			return errors.Wrapf(err, "can't add version %d to banner", version.Version)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, importLastVersionQuery, id); err != nil {
+		return errors.Wrap(err, "can't update last version of banner")
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package postgres
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/repository"
+	"bannersrv/internal/pkg/pg"
+	"bannersrv/internal/pkg/types"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pkg/errors"
+)
+
+const (
+	checkBannerExistsQuery = `
+		SELECT id FROM banner WHERE id = $1
+	`
+
+	getVersionsQuery = `
+		SELECT version, content, created_at, NULL::timestamptz FROM version_banner WHERE banner_id = $1
+		UNION ALL
+		SELECT version, content, created_at, archived_at FROM archive_version_banner WHERE banner_id = $1
+		ORDER BY version DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	getOneVersionQuery = `
+		SELECT version, content, created_at, NULL::timestamptz FROM version_banner
+			WHERE banner_id = $1 and version = $2
+		UNION ALL
+		SELECT version, content, created_at, archived_at FROM archive_version_banner
+			WHERE banner_id = $1 and version = $2
+		LIMIT 1
+	`
+
+	getDefaultRetentionQuery = `
+		SELECT retention FROM settings
+	`
+
+	updateDefaultRetentionQuery = `
+		UPDATE settings SET retention = $1
+	`
+
+	// archiveVersionsBatchQuery переносит в архив лишние версии пачки баннеров без собственного числа
+	// хранимых версий и возвращает наибольший идентификатор из пачки, null -- баннеров больше нет
+	archiveVersionsBatchQuery = `
+		SELECT max(id) FROM (
+			SELECT id, archive_banner_versions(id, last_version) FROM banner
+				WHERE retention IS NULL and id > $1
+				ORDER BY id
+				LIMIT $2
+		) as archived
+	`
+
+	lockBannerQuery = `
+		SELECT last_version FROM banner
+			WHERE id = $1 and id IN (SELECT banner_id FROM features_tags_banner WHERE not deleted)
+			FOR UPDATE
+	`
+
+	getVersionContentQuery = `
+		SELECT content FROM version_banner WHERE banner_id = $1 and version = $2
+	`
+
+	checkArchivedVersionQuery = `
+		SELECT version FROM archive_version_banner WHERE banner_id = $1 and version = $2
+	`
+
+	getFeatureTagsQuery = `
+		SELECT feature_id, array_agg(tag_id) FROM features_tags_banner
+			WHERE banner_id = $1
+			GROUP BY feature_id
+	`
+)
+
+// archiveBatchSize число баннеров, лишние версии которых переносятся в архив в одной транзакции
+const archiveBatchSize = 1000
+
+// GetBannerVersions возвращает историю версий баннера, включая архивные, от новых к старым.
+// История доступна и для баннеров, ожидающих отложенного удаления.
+func (br *BannerRepository) GetBannerVersions(ctx context.Context, bannerID types.ID,
+	offset, limit uint64,
+) ([]entity.Version, error) {
+	versions := make([]entity.Version, 0)
+
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			var checkedID types.ID
+			if err := tx.QueryRow(ctx, checkBannerExistsQuery, bannerID).Scan(&checkedID); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return repository.ErrorBannerNotFound
+				}
+
+				return errors.Wrap(err, "can't check banner on existence")
+			}
+
+			rows, err := tx.Query(ctx, getVersionsQuery, bannerID, limit, offset)
+			//nolint: staticcheck
+			defer rows.Close() //lint:ignore SA5001 Close() doesn't return error
+
+			if err != nil {
+				return errors.Wrap(err, "can't execute get versions query")
+			}
+
+			for rows.Next() {
+				var version entity.Version
+
+				if err := rows.Scan(
+					&version.Version,
+					&version.Content,
+					&version.CreatedAt,
+					&version.ArchivedAt,
+				); err != nil {
+					return errors.Wrap(err, "can't scan get versions query result")
+				}
+
+				versions = append(versions, version)
+			}
+
+			if err := rows.Err(); err != nil {
+				return errors.Wrap(err, "can't end scan get versions query result")
+			}
+
+			return nil
+		},
+	); err != nil {
+		return nil, errors.Wrapf(err,
+			"when selecting versions of banner with id %d, limit %d and offset %d", bannerID, limit, offset)
+	}
+
+	return versions, nil
+}
+
+func (br *BannerRepository) GetBannerVersion(ctx context.Context, bannerID types.ID,
+	version uint32,
+) (*entity.Version, error) {
+	var bannerVersion entity.Version
+
+	if err := br.db.QueryRow(ctx, getOneVersionQuery, bannerID, version).
+		Scan(
+			&bannerVersion.Version,
+			&bannerVersion.Content,
+			&bannerVersion.CreatedAt,
+			&bannerVersion.ArchivedAt,
+		); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(repository.ErrorVersionNotFound,
+				"with banner id %d and version %d", bannerID, version)
+		}
+
+		return nil, errors.Wrapf(err, "can't get version %d of banner with id %d", version, bannerID)
+	}
+
+	return &bannerVersion, nil
+}
+
+func (br *BannerRepository) GetDefaultRetention(ctx context.Context) (uint32, error) {
+	var retention uint32
+	if err := br.db.QueryRow(ctx, getDefaultRetentionQuery).Scan(&retention); err != nil {
+		return 0, errors.Wrap(err, "can't get default retention")
+	}
+
+	return retention, nil
+}
+
+// SetDefaultRetention задаёт глобальное число хранимых версий и переносит в архив лишние версии
+// баннеров, для которых собственное значение не задано. Версии переносятся пачками по archiveBatchSize
+// баннеров в отдельных транзакциях, чтобы не блокировать все баннеры сразу. Если перенос прерван,
+// лишние версии оставшихся баннеров переносятся при добавлении их следующей версии.
+func (br *BannerRepository) SetDefaultRetention(ctx context.Context, retention uint32) error {
+	if _, err := br.db.Exec(ctx, updateDefaultRetentionQuery, retention); err != nil {
+		return errors.Wrapf(err, "can't update default retention to %d", retention)
+	}
+
+	var lastID types.ID
+
+	for {
+		var archivedID pgtype.Int8
+		if err := br.db.QueryRow(ctx, archiveVersionsBatchQuery, lastID, archiveBatchSize).
+			Scan(&archivedID); err != nil {
+			return errors.Wrapf(err, "can't archive versions of banners after id %d", lastID)
+		}
+
+		if !archivedID.Valid {
+			return nil
+		}
+
+		lastID = types.ID(archivedID.Int64)
+	}
+}
+
+// RollbackBanner делает содержимое версии version текущим, добавляя его как новую версию баннера,
+// чтобы история изменений сохранялась. Версии, перенесённые в архив, для отката недоступны.
+func (br *BannerRepository) RollbackBanner(ctx context.Context, id types.ID,
+	version uint32,
+) (*entity.CurrentVersion, error) {
+	rollback := &entity.CurrentVersion{}
+
+	if err := pg.WithTransaction(ctx, br.db,
+		func(tx pgx.Tx) error {
+			var lastVersion uint32
+			if err := tx.QueryRow(ctx, lockBannerQuery, id).Scan(&lastVersion); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return repository.ErrorBannerNotFound
+				}
+
+				return errors.Wrap(err, "can't lock banner")
+			}
+
+			if version == lastVersion {
+				return errors.Wrapf(repository.ErrorVersionAlreadyCurrent, "version %d", version)
+			}
+
+			var content types.Content
+			if err := tx.QueryRow(ctx, getVersionContentQuery, id, version).Scan(&content); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return br.checkArchivedVersion(ctx, tx, id, version)
+				}
+
+				return errors.Wrap(err, "can't get content of version")
+			}
+
+			before, err := br.snapshotBanner(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+
+			if err := tx.QueryRow(ctx, addContentWithVersionQuery, id, content).Scan(&rollback.Version); err != nil {
+				return errors.Wrap(err, "can't add content of version to banner")
+			}
+
+			keys, err := br.getBannerKeys(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+
+			rollback.FeatureID, rollback.TagIDs = keys.FeatureID, keys.TagIDs
+
+			return br.addAudit(ctx, tx, entity.AuditRollback, id, before)
+		},
+	); err != nil {
+		return nil, errors.Wrapf(err, "when rolling back banner with id %d to version %d", id, version)
+	}
+
+	return rollback, nil
+}
+
+// checkArchivedVersion возвращает ошибку, объясняющую отсутствие версии среди хранимых
+func (*BannerRepository) checkArchivedVersion(ctx context.Context, tx pgx.Tx, id types.ID, version uint32) error {
+	var archived uint32
+	if err := tx.QueryRow(ctx, checkArchivedVersionQuery, id, version).Scan(&archived); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.Wrapf(repository.ErrorVersionNotFound, "version %d", version)
+		}
+
+		return errors.Wrap(err, "can't check version in archive")
+	}
+
+	return errors.Wrapf(repository.ErrorVersionArchived, "version %d", version)
+}
+
+// getBannerKeys возвращает фичу и тэги баннера, в том числе ожидающего отложенного удаления
+func (*BannerRepository) getBannerKeys(ctx context.Context, tx pgx.Tx, id types.ID) (*entity.BannerKeys, error) {
+	var keys entity.BannerKeys
+
+	var tags pgtype.Array[types.ID]
+	if err := tx.QueryRow(ctx, getFeatureTagsQuery, id).Scan(&keys.FeatureID, &tags); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.Wrapf(repository.ErrorBannerNotFound, "with id %d", id)
+		}
+
+		return nil, errors.Wrap(err, "can't get feature id and tag ids of banner")
+	}
+
+	keys.TagIDs = tags.Elements
+
+	return &keys, nil
+}
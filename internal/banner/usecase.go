@@ -1,16 +1,60 @@
 package banner
 
 import (
+	"bannersrv/internal/banner/entity"
 	"bannersrv/internal/banner/models"
 	"bannersrv/internal/pkg/types"
+	"context"
 	"encoding/json"
 )
 
 type Usecase interface {
-	CreateBanner(tagIDs []types.ID, featureID types.ID, content json.RawMessage, isActive bool) (types.ID, error)
-	DeleteBanner(id types.ID) error
-	UpdateBanner(id types.ID, banner *models.BannerUpdate) error
-	GetAdminBanners(featureID, tagID *types.ID, offset, limit *uint64) ([]models.Banner, error)
-	GetUserBanner(featureID, tagID types.ID, version *uint32) (json.RawMessage, error)
-	DeleteFilteredBanner(featureID, tagID *types.ID) error
+	CreateBanner(ctx context.Context, tagIDs []types.ID, featureID types.ID,
+		content json.RawMessage, isActive bool, schedule *models.Schedule, priority int32) (types.ID, error)
+	DeleteBanner(ctx context.Context, id types.ID) error
+	UpdateBanner(ctx context.Context, id types.ID, banner *models.BannerUpdate, author string) (*types.ID, error)
+	GetAdminBanners(ctx context.Context, filter *entity.BannerInfo, order *entity.BannerOrder,
+		offset, limit *uint64, withTotal bool) (*models.BannerList, error)
+	GetUserBanner(ctx context.Context, featureID types.ID, tagIDs []types.ID, version *uint32,
+		userID *string) (*models.UserBanner, error)
+	GetUserBanners(ctx context.Context, tagID types.ID, featureIDs []types.ID,
+		userID *string, useLastRevision bool) (map[types.ID]*models.UserBanner, error)
+	DeleteFilteredBanner(ctx context.Context, featureID, tagID *types.ID) error
+	BatchBanners(ctx context.Context, operations []models.BatchOperation, author string,
+		atomic bool) (*models.BatchResult, error)
+	ExportBanners(ctx context.Context, export func(banner *models.Banner) error) error
+	ImportBanners(ctx context.Context, banners []models.ImportBanner, mode entity.ImportMode,
+		dryRun bool) (*models.ImportResult, error)
+
+	GetBannerVersions(ctx context.Context, bannerID types.ID, offset, limit *uint64) ([]models.Version, error)
+	GetBannerVersion(ctx context.Context, bannerID types.ID, version uint32) (*models.Version, error)
+	RollbackBanner(ctx context.Context, id types.ID, version uint32) (*models.CurrentVersion, error)
+	GetDefaultRetention(ctx context.Context) (uint32, error)
+	SetDefaultRetention(ctx context.Context, retention uint32) error
+
+	GetFeatureCacheTTL(ctx context.Context, featureID types.ID) (*models.FeatureCacheTTL, error)
+	SetFeatureCacheTTL(ctx context.Context, featureID types.ID, ttl uint32) error
+	DeleteFeatureCacheTTL(ctx context.Context, featureID types.ID) error
+
+	GetDrafts(ctx context.Context, bannerID types.ID, status *entity.DraftStatus,
+		offset, limit *uint64) ([]models.Draft, error)
+	GetDraft(ctx context.Context, id types.ID) (*models.Draft, error)
+	ApproveDraft(ctx context.Context, id types.ID, reviewer string) error
+	RejectDraft(ctx context.Context, id types.ID, reviewer string) error
+	PublishDraft(ctx context.Context, id types.ID) (*models.CurrentVersion, error)
+
+	CreateExperiment(ctx context.Context, bannerID types.ID, variants []models.Variant) (types.ID, error)
+	GetExperiment(ctx context.Context, id types.ID) (*models.Experiment, error)
+	PauseExperiment(ctx context.Context, id types.ID) error
+	ResumeExperiment(ctx context.Context, id types.ID) error
+	ConcludeExperiment(ctx context.Context, id, winnerVariantID types.ID) (uint32, error)
+
+	GetAudit(ctx context.Context, filter *entity.AuditFilter, offset, limit *uint64) ([]models.AuditEntry, error)
+
+	SearchBanners(ctx context.Context, search *entity.ContentSearch, offset, limit *uint64) ([]models.ContentMatch, error)
+
+	WarmCache(ctx context.Context, limit *uint64) (*models.WarmResult, error)
+	InspectCache(ctx context.Context, featureID types.ID, tagIDs []types.ID,
+		version *uint32) (*models.CacheEntry, error)
+	PurgeCache(ctx context.Context, featureID, tagID *types.ID) (uint64, error)
 }
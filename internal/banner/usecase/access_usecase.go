@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"context"
+)
+
+// checkBannerRead проверяет, что админ из контекста имеет доступ к фиче баннера
+func (bu *BannerUsecase) checkBannerRead(ctx context.Context, id types.ID) error {
+	scope := access.FromContext(ctx)
+	if !scope.Restricted() {
+		return nil
+	}
+
+	featureID, err := bu.rep.GetBannerFeature(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return scope.CheckRead(featureID)
+}
+
+// checkBannerWrite проверяет, что админ из контекста может изменять баннер
+func (bu *BannerUsecase) checkBannerWrite(ctx context.Context, id types.ID) error {
+	if err := access.FromContext(ctx).CheckWrite(); err != nil {
+		return err
+	}
+
+	return bu.checkBannerRead(ctx, id)
+}
+
+// checkDraftWrite проверяет, что админ из контекста может рассматривать и публиковать черновик
+func (bu *BannerUsecase) checkDraftWrite(ctx context.Context, id types.ID) error {
+	scope := access.FromContext(ctx)
+	if err := scope.CheckWrite(); err != nil {
+		return err
+	}
+
+	if !scope.Restricted() {
+		return nil
+	}
+
+	draft, err := bu.rep.GetDraft(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return bu.checkBannerRead(ctx, draft.BannerID)
+}
+
+// checkExperimentWrite проверяет, что админ из контекста может управлять экспериментом
+func (bu *BannerUsecase) checkExperimentWrite(ctx context.Context, id types.ID) error {
+	scope := access.FromContext(ctx)
+	if err := scope.CheckWrite(); err != nil {
+		return err
+	}
+
+	if !scope.Restricted() {
+		return nil
+	}
+
+	experiment, err := bu.rep.GetExperiment(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return bu.checkBannerRead(ctx, experiment.BannerID)
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/pkg/slices"
+	"context"
+)
+
+// GetAudit возвращает записи журнала изменений баннеров фич, доступных админу
+func (bu *BannerUsecase) GetAudit(ctx context.Context, filter *entity.AuditFilter,
+	offset, limit *uint64,
+) ([]models.AuditEntry, error) {
+	var entityOffset uint64 = defaultOffset
+
+	var entityLimit uint64 = defaultLimit
+
+	if offset != nil {
+		entityOffset = *offset
+	}
+
+	if limit != nil {
+		entityLimit = *limit
+	}
+
+	filter.FeatureIDs = allowedFeatures(ctx)
+
+	entries, err := bu.rep.GetAudit(ctx, filter, entityOffset, entityLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Map(entries, func(e *entity.AuditEntry) models.AuditEntry {
+		return *models.FromAuditEntryEntity(e)
+	}), nil
+}
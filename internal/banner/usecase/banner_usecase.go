@@ -4,9 +4,18 @@ import (
 	"bannersrv/internal/banner"
 	"bannersrv/internal/banner/entity"
 	"bannersrv/internal/banner/models"
+	"bannersrv/internal/banner/repository"
+	"bannersrv/internal/caches"
+	"bannersrv/internal/pkg/access"
 	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/lru"
 	"bannersrv/pkg/slices"
+	"context"
 	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
@@ -15,36 +24,99 @@ const (
 )
 
 type BannerUsecase struct {
-	rep banner.Repository
+	rep        banner.Repository
+	cache      caches.Manager
+	popularity caches.PopularityTracker
+
+	group    singleflight.Group
+	locker   Locker
+	uncached *lru.Cache[struct{}]
+	timeout  time.Duration
+	lockTTL  time.Duration
 }
 
-func NewBannerUsecase(bnr banner.Repository) *BannerUsecase {
+// NewBannerUsecase создаёт юзкейс баннеров. Если locker не nil, то запросы баннера пользователем
+// при промахе кэша объединяются не только внутри реплики, но и между репликами.
+// Кэш прогревается по самым запрашиваемым парам фича-тэг из popularity.
+func NewBannerUsecase(bnr banner.Repository, cache caches.Manager, popularity caches.PopularityTracker,
+	locker Locker, cfg *banner.CoalescingConfig,
+) *BannerUsecase {
 	return &BannerUsecase{
-		rep: bnr,
+		rep:        bnr,
+		cache:      cache,
+		popularity: popularity,
+		locker:     locker,
+		uncached:   lru.New[struct{}](uncachedKeysSize, 0, nil),
+		timeout:    time.Duration(cfg.Timeout) * time.Millisecond,
+		lockTTL:    time.Duration(cfg.LockTTL) * time.Millisecond,
 	}
 }
 
-func (bu *BannerUsecase) CreateBanner(tagIDs []types.ID, featureID types.ID,
-	content json.RawMessage, isActive bool,
+func (bu *BannerUsecase) CreateBanner(ctx context.Context, tagIDs []types.ID, featureID types.ID,
+	content json.RawMessage, isActive bool, schedule *models.Schedule, priority int32,
 ) (types.ID, error) {
-	return bu.rep.CreateBanner(featureID, tagIDs, types.Content(content), isActive)
+	if err := access.FromContext(ctx).CheckWrite(featureID); err != nil {
+		return 0, err
+	}
+
+	return bu.rep.CreateBanner(ctx, featureID, tagIDs, types.Content(content), isActive, schedule.ToScheduleEntity(),
+		priority)
 }
 
-func (bu *BannerUsecase) DeleteBanner(id types.ID) error {
-	_, err := bu.rep.DeleteBanner(id)
+func (bu *BannerUsecase) DeleteBanner(ctx context.Context, id types.ID) error {
+	if err := bu.checkBannerWrite(ctx, id); err != nil {
+		return err
+	}
+
+	keys, err := bu.rep.DeleteBanner(ctx, id)
+	if err != nil {
+		return err
+	}
 
-	return err
+	bu.invalidateCache(ctx, *keys)
+
+	return nil
 }
 
-func (bu *BannerUsecase) UpdateBanner(id types.ID, bnr *models.BannerUpdate) error {
-	_, err := bu.rep.UpdateBanner(bnr.ToBannerUpdateEntity(id))
+// UpdateBanner сразу применяет изменения всех полей баннера, кроме содержимого.
+// Новое содержимое сохраняется как черновик author, идентификатор которого возвращается.
+// Админ должен иметь доступ как к текущей фиче баннера, так и к фиче, в которую баннер переносится.
+func (bu *BannerUsecase) UpdateBanner(ctx context.Context, id types.ID, bnr *models.BannerUpdate,
+	author string,
+) (*types.ID, error) {
+	if err := bu.checkBannerWrite(ctx, id); err != nil {
+		return nil, err
+	}
 
-	return err
+	update := bnr.ToBannerUpdateEntity(id)
+	if !update.FeatureID.IsNull {
+		if err := access.FromContext(ctx).CheckWrite(update.FeatureID.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	var draft *types.Content
+	if !update.Content.IsNull {
+		draft = &update.Content.Value
+		update.Content = types.NewNullObject[types.Content]()
+	}
+
+	keys, draftID, err := bu.rep.UpdateBannerWithDraft(ctx, update, draft, author)
+	if err != nil {
+		return nil, err
+	}
+
+	bu.invalidateCache(ctx, keys...)
+
+	return draftID, nil
 }
 
-func (bu *BannerUsecase) GetAdminBanners(featureID, tagID *types.ID,
-	offset, limit *uint64,
-) ([]models.Banner, error) {
+// GetAdminBanners возвращает страницу баннеров фич, доступных админу. Страница запрашивается на один баннер
+// больше limit, чтобы узнать, есть ли баннеры после неё. Число всех баннеров фильтра считается,
+// только если withTotal.
+func (bu *BannerUsecase) GetAdminBanners(ctx context.Context, filter *entity.BannerInfo, order *entity.BannerOrder,
+	offset, limit *uint64, withTotal bool,
+) (*models.BannerList, error) {
 	var entityOffset uint64 = defaultOffset
 
 	var entityLimit uint64 = defaultLimit
@@ -57,31 +129,159 @@ func (bu *BannerUsecase) GetAdminBanners(featureID, tagID *types.ID,
 		entityLimit = *limit
 	}
 
-	banners, err := bu.rep.GetBanners(&entity.BannerInfo{
-		FeatureID: (*types.NullableID)(types.ObjectFromPointer(featureID)),
-		TagID:     (*types.NullableID)(types.ObjectFromPointer(tagID)),
-	}, entityOffset, entityLimit)
+	filter.FeatureIDs = allowedFeatures(ctx)
+
+	banners, err := bu.rep.GetBanners(ctx, filter, order, entityOffset, entityLimit+1)
 	if err != nil {
 		return nil, err
 	}
 
-	return slices.Map(banners, func(b *entity.Banner) models.Banner {
+	list := &models.BannerList{}
+
+	if uint64(len(banners)) > entityLimit {
+		banners = banners[:entityLimit]
+
+		if entityLimit != 0 {
+			list.Next = bannerCursor(order, &banners[entityLimit-1])
+		}
+	}
+
+	if withTotal {
+		total, err := bu.rep.CountBanners(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		list.Total = &total
+	}
+
+	list.Banners = slices.Map(banners, func(b *entity.Banner) models.Banner {
 		return *models.FromBannerEntity(b)
-	}), nil
+	})
+
+	return list, nil
 }
 
-func (bu *BannerUsecase) GetUserBanner(featureID, tagID types.ID, version *uint32) (json.RawMessage, error) {
-	content, err := bu.rep.GetBanner(featureID, tagID, *types.ObjectFromPointer(version))
+// bannerCursor возвращает курсор, указывающий на баннер в списке с порядком order
+func bannerCursor(order *entity.BannerOrder, bnr *entity.Banner) *entity.BannerCursor {
+	cursor := &entity.BannerCursor{ID: bnr.ID}
+
+	switch order.Sort {
+	case entity.BannerSortCreatedAt:
+		cursor.Time = &bnr.CreatedAt
+	case entity.BannerSortUpdatedAt:
+		cursor.Time = &bnr.UpdatedAt
+	case entity.BannerSortID:
+	}
+
+	return cursor
+}
+
+// GetUserBanner возвращает баннер фичи по тэгам пользователя. Если тэгам соответствуют несколько баннеров,
+// выдаётся баннер с наибольшим приоритетом.
+func (bu *BannerUsecase) GetUserBanner(ctx context.Context, featureID types.ID, tagIDs []types.ID,
+	version *uint32, userID *string,
+) (*models.UserBanner, error) {
+	bnr, err := bu.loadUserBanner(ctx, featureID, caches.TagSet(tagIDs), version)
 	if err != nil {
 		return nil, err
 	}
 
-	return json.RawMessage(content), nil
+	userBanner := models.FromUserBannerEntity(bnr)
+
+	// Варианты эксперимента выдаются только для последней версии
+	if version != nil {
+		return userBanner, nil
+	}
+
+	if err := bu.chooseUserVariant(ctx, userBanner, userID); err != nil {
+		return nil, err
+	}
+
+	return userBanner, nil
 }
 
-func (bu *BannerUsecase) DeleteFilteredBanner(featureID, tagID *types.ID) error {
-	return bu.rep.DeleteFilteredBanner(&entity.BannerInfo{
-		FeatureID: (*types.NullableID)(types.ObjectFromPointer(featureID)),
-		TagID:     (*types.NullableID)(types.ObjectFromPointer(tagID)),
+// chooseUserVariant подменяет содержимое баннера вариантом запущенного эксперимента,
+// закреплённым за пользователем userID. Без эксперимента или пользователя баннер не меняется.
+func (bu *BannerUsecase) chooseUserVariant(ctx context.Context, userBanner *models.UserBanner,
+	userID *string,
+) error {
+	if userBanner.ExperimentID == nil || userID == nil {
+		return nil
+	}
+
+	variants, err := bu.rep.GetExperimentVariants(ctx, *userBanner.ExperimentID)
+	if err != nil {
+		return err
+	}
+
+	if variant := chooseVariant(*userBanner.ExperimentID, *userID, variants); variant != nil {
+		userBanner.Content = json.RawMessage(variant.Content)
+		userBanner.VariantID = &variant.ID
+	}
+
+	return nil
+}
+
+// RefreshUserBanner заново загружает устаревший баннер из базы в кэш, если его уже не обновляет другая реплика.
+// Если баннер больше нельзя отдавать из кэша, например по паре начался эксперимент, все записи тэгов фичи
+// удаляются из кэша, чтобы устаревший баннер не отдавался до истечения жёсткого времени жизни.
+func (bu *BannerUsecase) RefreshUserBanner(ctx context.Context, featureID types.ID, tagIDs []types.ID,
+	version *uint32,
+) error {
+	bnr, err := bu.reloadUserBanner(ctx, featureID, caches.TagSet(tagIDs), version)
+	if err == nil && bnr == nil {
+		return nil
+	}
+
+	if errors.Is(err, repository.ErrorBannerNotFound) || (err == nil && bnr.ExperimentID != nil && version == nil) {
+		bu.cache.InvalidateCache(ctx, featureID, tagIDs)
+
+		return nil
+	}
+
+	return err
+}
+
+// DeleteFilteredBanner удаляет баннеры по фиче или тегу только среди фич, доступных админу
+func (bu *BannerUsecase) DeleteFilteredBanner(ctx context.Context, featureID, tagID *types.ID) error {
+	scope := access.FromContext(ctx)
+	if err := scope.CheckWrite(); err != nil {
+		return err
+	}
+
+	if featureID != nil {
+		if err := scope.CheckWrite(*featureID); err != nil {
+			return err
+		}
+	}
+
+	keys, err := bu.rep.DeleteFilteredBanner(ctx, &entity.BannerInfo{
+		FeatureID:  (*types.NullableID)(types.ObjectFromPointer(featureID)),
+		TagID:      (*types.NullableID)(types.ObjectFromPointer(tagID)),
+		FeatureIDs: allowedFeatures(ctx),
 	})
+	if err != nil {
+		return err
+	}
+
+	bu.invalidateCache(ctx, keys...)
+
+	return nil
+}
+
+// invalidateCache сбрасывает кэш баннеров по всем парам фича-тэг, затронутым изменением
+func (bu *BannerUsecase) invalidateCache(ctx context.Context, keys ...entity.BannerKeys) {
+	for _, key := range keys {
+		bu.cache.InvalidateCache(ctx, key.FeatureID, key.TagIDs)
+	}
+}
+
+// allowedFeatures возвращает фичи, доступные админу из контекста, или nil, если доступ не ограничен
+func allowedFeatures(ctx context.Context) []types.ID {
+	if scope := access.FromContext(ctx); scope.Restricted() {
+		return scope.FeatureIDs
+	}
+
+	return nil
 }
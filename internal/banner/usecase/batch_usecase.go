@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/banner/repository"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// BatchBanners выполняет операции над баннерами в одной транзакции. Операции над баннерами фич, недоступных
+// админу, завершаются ошибкой без обращения к базе. С atomic операции применяются, только если все они успешны,
+// иначе применяются все успешные операции. Новое содержимое изменяемых баннеров, как и при обычном изменении,
+// сохраняется как черновик author. После применения сбрасывается кэш всех изменённых баннеров.
+func (bu *BannerUsecase) BatchBanners(ctx context.Context, operations []models.BatchOperation,
+	author string, atomic bool,
+) (*models.BatchResult, error) {
+	if err := access.FromContext(ctx).CheckWrite(); err != nil {
+		return nil, err
+	}
+
+	results := make([]entity.BatchOperationResult, len(operations))
+	checked := make([]entity.BatchOperation, 0, len(operations))
+	indexes := make([]int, 0, len(operations))
+
+	for i := range operations {
+		operation, err := bu.checkBatchOperation(ctx, &operations[i])
+		if err != nil {
+			if !errors.Is(err, access.ErrorFeatureForbidden) && !errors.Is(err, repository.ErrorBannerNotFound) {
+				return nil, err
+			}
+
+			results[i].Err = err
+
+			continue
+		}
+
+		checked = append(checked, *operation)
+		indexes = append(indexes, i)
+	}
+
+	if atomic && len(checked) != len(operations) {
+		for _, i := range indexes {
+			results[i].Err = repository.ErrorBatchRolledBack
+		}
+
+		return models.FromBatchResultEntity(&entity.BatchResult{Operations: results}), nil
+	}
+
+	result, err := bu.rep.BatchBanners(ctx, checked, author, atomic)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range indexes {
+		results[i] = result.Operations[j]
+	}
+
+	if result.Applied {
+		bu.invalidateCache(ctx, result.Keys...)
+	}
+
+	return models.FromBatchResultEntity(&entity.BatchResult{Applied: result.Applied, Operations: results}), nil
+}
+
+// checkBatchOperation проверяет доступ админа к баннеру операции и фиче, в которую баннер переносится
+func (bu *BannerUsecase) checkBatchOperation(ctx context.Context,
+	operation *models.BatchOperation,
+) (*entity.BatchOperation, error) {
+	checked := &entity.BatchOperation{Action: operation.Action, ID: operation.ID}
+
+	switch operation.Action {
+	case entity.BatchCreate:
+		if err := access.FromContext(ctx).CheckWrite(operation.Create.FeatureID); err != nil {
+			return nil, err
+		}
+
+		checked.Create = operation.Create.ToBannerCreateEntity()
+	case entity.BatchUpdate:
+		if err := bu.checkBannerWrite(ctx, operation.ID); err != nil {
+			return nil, err
+		}
+
+		update := operation.Update.ToBannerUpdateEntity(operation.ID)
+		if !update.FeatureID.IsNull {
+			if err := access.FromContext(ctx).CheckWrite(update.FeatureID.Value); err != nil {
+				return nil, err
+			}
+		}
+
+		if !update.Content.IsNull {
+			checked.Draft = &update.Content.Value
+			update.Content = types.NewNullObject[types.Content]()
+		}
+
+		checked.Update = update
+	case entity.BatchDelete:
+		if err := bu.checkBannerWrite(ctx, operation.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return checked, nil
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"time"
+)
+
+func (bu *BannerUsecase) GetFeatureCacheTTL(ctx context.Context, featureID types.ID) (*models.FeatureCacheTTL, error) {
+	if err := access.FromContext(ctx).CheckRead(featureID); err != nil {
+		return nil, err
+	}
+
+	ttl, err := bu.rep.GetFeatureCacheTTL(ctx, featureID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.FromFeatureCacheTTLEntity(ttl), nil
+}
+
+// SetFeatureCacheTTL задаёт время жизни баннеров фичи в кэше и сбрасывает их кэш,
+// чтобы новое время жизни применялось сразу, а не после истечения прежнего
+func (bu *BannerUsecase) SetFeatureCacheTTL(ctx context.Context, featureID types.ID, ttl uint32) error {
+	if err := access.FromContext(ctx).CheckWrite(featureID); err != nil {
+		return err
+	}
+
+	keys, err := bu.rep.SetFeatureCacheTTL(ctx, featureID, ttl)
+	if err != nil {
+		return err
+	}
+
+	bu.invalidateCache(ctx, *keys)
+
+	return nil
+}
+
+func (bu *BannerUsecase) DeleteFeatureCacheTTL(ctx context.Context, featureID types.ID) error {
+	if err := access.FromContext(ctx).CheckWrite(featureID); err != nil {
+		return err
+	}
+
+	keys, err := bu.rep.DeleteFeatureCacheTTL(ctx, featureID)
+	if err != nil {
+		return err
+	}
+
+	bu.invalidateCache(ctx, *keys)
+
+	return nil
+}
+
+// cacheTTL время жизни баннера в кэше, заданное для баннера или его фичи, nil -- время жизни по умолчанию
+func cacheTTL(ttl *uint32) *time.Duration {
+	if ttl == nil {
+		return nil
+	}
+
+	duration := time.Duration(*ttl) * time.Millisecond
+
+	return &duration
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// InspectCache возвращает запись кэша баннера по фиче, тэгам пользователя и версии, если она указана
+func (bu *BannerUsecase) InspectCache(ctx context.Context, featureID types.ID, tagIDs []types.ID,
+	version *uint32,
+) (*models.CacheEntry, error) {
+	if err := access.FromContext(ctx).CheckRead(featureID); err != nil {
+		return nil, err
+	}
+
+	entry, err := bu.cache.InspectCache(ctx, featureID, tagIDs, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CacheEntry{
+		Key:        entry.Key,
+		Content:    json.RawMessage(entry.Content),
+		Stale:      !time.Now().Before(entry.FreshUntil),
+		FreshUntil: entry.FreshUntil,
+		TTL:        entry.TTL,
+	}, nil
+}
+
+// PurgeCache удаляет из кэша баннеры фичи, тэга, пары фича-тэг или все баннеры и возвращает
+// число затронутых пар. Сброс кэша тэга или всего кэша затрагивает все фичи, поэтому доступен
+// только админу без ограничений.
+func (bu *BannerUsecase) PurgeCache(ctx context.Context, featureID, tagID *types.ID) (uint64, error) {
+	scope := access.FromContext(ctx)
+
+	if featureID == nil {
+		if err := scope.CheckFull(); err != nil {
+			return 0, err
+		}
+	} else if err := scope.CheckWrite(*featureID); err != nil {
+		return 0, err
+	}
+
+	return bu.cache.PurgeCache(ctx, featureID, tagID)
+}
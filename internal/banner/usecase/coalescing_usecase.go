@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/caches"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// lockPollInterval период проверки кэша репликой, ожидающей баннер от владельца блокировки
+	lockPollInterval = 10 * time.Millisecond
+	// uncachedKeysSize число запоминаемых репликой запросов, результат которых не кэшируется
+	uncachedKeysSize = 10000
+	// uncachedKeyTTL время, в течение которого реплика не захватывает блокировку для таких запросов
+	uncachedKeyTTL = time.Minute
+)
+
+// Locker блокировки, общие для всех реплик сервиса
+type Locker interface {
+	// TryLock захватывает свободную блокировку key на ttl и возвращает токен для её снятия
+	TryLock(ctx context.Context, key string, ttl time.Duration) (string, bool, error)
+	Unlock(ctx context.Context, key, token string) error
+}
+
+// loadUserBanner возвращает баннер пользователю из базы и сохраняет его в кэш. Одинаковые одновременные
+// запросы объединяются в один общий запрос к базе, результат которого, в том числе ошибка, возвращается
+// всем ожидающим его запросам. Общий запрос не прерывается отменой запроса, который его начал.
+func (bu *BannerUsecase) loadUserBanner(ctx context.Context, featureID types.ID, tagIDs []types.ID,
+	version *uint32,
+) (*entity.UserBanner, error) {
+	key := userBannerKey(featureID, tagIDs, version)
+
+	result := bu.group.DoChan(key, func() (any, error) {
+		sharedCtx := context.WithoutCancel(ctx)
+
+		if bu.timeout != 0 {
+			var cancel context.CancelFunc
+
+			sharedCtx, cancel = context.WithTimeout(sharedCtx, bu.timeout)
+			defer cancel()
+		}
+
+		return bu.loadUserBannerLocked(sharedCtx, key, featureID, tagIDs, version)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		return res.Val.(*entity.UserBanner), nil //nolint: forcetypeassert // общий запрос возвращает только баннер
+	}
+}
+
+// reloadUserBanner заново загружает устаревший баннер из базы в кэш не дольше lockTTL, чтобы блокировка
+// не истекла раньше обновления. Одинаковые обновления объединяются внутри реплики, а между репликами
+// баннер обновляет только реплика, захватившая блокировку без ожидания. Если блокировка занята другой репликой,
+// которая уже загружает этот баннер, возвращается nil без ошибки.
+func (bu *BannerUsecase) reloadUserBanner(ctx context.Context, featureID types.ID, tagIDs []types.ID,
+	version *uint32,
+) (*entity.UserBanner, error) {
+	key := userBannerKey(featureID, tagIDs, version)
+
+	bnr, err, _ := bu.group.Do("refresh-"+key, func() (any, error) {
+		refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), bu.lockTTL)
+		defer cancel()
+
+		if bu.locker != nil {
+			lockKey := "lock-" + key
+
+			token, locked, err := bu.locker.TryLock(refreshCtx, lockKey, bu.lockTTL)
+			if err == nil && !locked {
+				return (*entity.UserBanner)(nil), nil
+			}
+
+			if locked {
+				defer func() {
+					// Если снять блокировку не удалось, она истечёт сама через lockTTL
+					_ = bu.locker.Unlock(context.WithoutCancel(refreshCtx), lockKey, token) // nolint: errcheck
+				}()
+			}
+		}
+
+		return bu.fetchUserBanner(refreshCtx, key, featureID, tagIDs, version)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bnr.(*entity.UserBanner), nil //nolint: forcetypeassert // общий запрос возвращает только баннер
+}
+
+// loadUserBannerLocked запрашивает баннер из базы только на реплике, захватившей блокировку,
+// остальные реплики ожидают появления свежего баннера в кэше, пока блокировка не будет снята или не истечёт.
+// Если блокировку не удалось проверить, баннер запрашивается из базы, чтобы недоступность Redis
+// не останавливала сервис. Результат запроса с запущенным экспериментом не попадает в кэш и ждать его
+// бесполезно, поэтому такие запросы, пока реплика их помнит, объединяются только внутри реплики.
+func (bu *BannerUsecase) loadUserBannerLocked(ctx context.Context, key string, featureID types.ID,
+	tagIDs []types.ID, version *uint32,
+) (*entity.UserBanner, error) {
+	if _, uncached := bu.uncached.Get(key); bu.locker == nil || uncached {
+		return bu.fetchUserBanner(ctx, key, featureID, tagIDs, version)
+	}
+
+	lockKey := "lock-" + key
+	deadline := time.Now().Add(bu.lockTTL)
+
+	for {
+		token, locked, err := bu.locker.TryLock(ctx, lockKey, bu.lockTTL)
+		if err != nil {
+			return bu.fetchUserBanner(ctx, key, featureID, tagIDs, version)
+		}
+
+		if locked {
+			defer func() {
+				// Если снять блокировку не удалось, она истечёт сама через lockTTL
+				_ = bu.locker.Unlock(context.WithoutCancel(ctx), lockKey, token) // nolint: errcheck
+			}()
+
+			return bu.fetchUserBanner(ctx, key, featureID, tagIDs, version)
+		}
+
+		if entry, err := bu.cache.HaveCache(ctx, featureID, tagIDs, version); err == nil && !entry.Stale {
+			return &entity.UserBanner{Content: entry.Content}, nil
+		}
+
+		// Владелец блокировки мог завершиться, так и не сохранив баннер в кэш
+		if time.Now().After(deadline) {
+			return bu.fetchUserBanner(ctx, key, featureID, tagIDs, version)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// fetchUserBanner запрашивает баннер из базы и сохраняет его в кэш, а если сохранить нельзя,
+// запоминает запрос key как некэшируемый
+func (bu *BannerUsecase) fetchUserBanner(ctx context.Context, key string, featureID types.ID, tagIDs []types.ID,
+	version *uint32,
+) (*entity.UserBanner, error) {
+	bnr, err := bu.rep.GetBanner(ctx, featureID, tagIDs, *types.ObjectFromPointer(version))
+	if err != nil {
+		return nil, err
+	}
+
+	// Пока идёт эксперимент, содержимое зависит от пользователя и не может быть закэшировано по паре фича-тэг
+	if bnr.ExperimentID == nil || version != nil {
+		bu.uncached.Remove(key)
+		bu.cache.SetCache(ctx, featureID, tagIDs, version, bnr.Content, bnr.EndAt, cacheTTL(bnr.CacheTTL))
+	} else {
+		bu.uncached.Set(key, "", struct{}{}, 0, uncachedKeyTTL)
+	}
+
+	return bnr, nil
+}
+
+// userBannerKey ключ объединения запросов баннера пользователем и блокировки между репликами
+func userBannerKey(featureID types.ID, tagIDs []types.ID, version *uint32) string {
+	key := fmt.Sprintf("%d-%s", featureID, caches.TagSetKey(tagIDs))
+	if version != nil {
+		key = fmt.Sprintf("%s-%d", key, *version)
+	}
+
+	return key
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/slices"
+	"context"
+)
+
+func (bu *BannerUsecase) GetDrafts(ctx context.Context, bannerID types.ID, status *entity.DraftStatus,
+	offset, limit *uint64,
+) ([]models.Draft, error) {
+	if err := bu.checkBannerRead(ctx, bannerID); err != nil {
+		return nil, err
+	}
+
+	var entityOffset uint64 = defaultOffset
+
+	var entityLimit uint64 = defaultLimit
+
+	if offset != nil {
+		entityOffset = *offset
+	}
+
+	if limit != nil {
+		entityLimit = *limit
+	}
+
+	drafts, err := bu.rep.GetDrafts(ctx, bannerID, status, entityOffset, entityLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Map(drafts, func(d *entity.Draft) models.Draft {
+		return *models.FromDraftEntity(d)
+	}), nil
+}
+
+func (bu *BannerUsecase) GetDraft(ctx context.Context, id types.ID) (*models.Draft, error) {
+	draft, err := bu.rep.GetDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bu.checkBannerRead(ctx, draft.BannerID); err != nil {
+		return nil, err
+	}
+
+	return models.FromDraftEntity(draft), nil
+}
+
+func (bu *BannerUsecase) ApproveDraft(ctx context.Context, id types.ID, reviewer string) error {
+	if err := bu.checkDraftWrite(ctx, id); err != nil {
+		return err
+	}
+
+	return bu.rep.ReviewDraft(ctx, id, reviewer, entity.DraftApproved)
+}
+
+func (bu *BannerUsecase) RejectDraft(ctx context.Context, id types.ID, reviewer string) error {
+	if err := bu.checkDraftWrite(ctx, id); err != nil {
+		return err
+	}
+
+	return bu.rep.ReviewDraft(ctx, id, reviewer, entity.DraftRejected)
+}
+
+func (bu *BannerUsecase) PublishDraft(ctx context.Context, id types.ID) (*models.CurrentVersion, error) {
+	if err := bu.checkDraftWrite(ctx, id); err != nil {
+		return nil, err
+	}
+
+	published, err := bu.rep.PublishDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	bu.cache.InvalidateCache(ctx, published.FeatureID, published.TagIDs)
+
+	return models.FromCurrentVersionEntity(published), nil
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/slices"
+	"context"
+	"hash/fnv"
+	"strconv"
+)
+
+// CreateExperiment создаёт эксперимент над баннером. Кэш баннера сбрасывается, иначе пользователям
+// до истечения времени свежести кэша отдавалось бы прежнее содержимое без выбора варианта.
+func (bu *BannerUsecase) CreateExperiment(ctx context.Context, bannerID types.ID,
+	variants []models.Variant,
+) (types.ID, error) {
+	if err := bu.checkBannerWrite(ctx, bannerID); err != nil {
+		return 0, err
+	}
+
+	createdID, keys, err := bu.rep.CreateExperiment(ctx, bannerID,
+		slices.Map(variants, func(variant *models.Variant) entity.Variant {
+			return *variant.ToVariantEntity()
+		}))
+	if err != nil {
+		return 0, err
+	}
+
+	bu.invalidateCache(ctx, *keys)
+
+	return createdID, nil
+}
+
+func (bu *BannerUsecase) GetExperiment(ctx context.Context, id types.ID) (*models.Experiment, error) {
+	experiment, err := bu.rep.GetExperiment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bu.checkBannerRead(ctx, experiment.BannerID); err != nil {
+		return nil, err
+	}
+
+	return models.FromExperimentEntity(experiment), nil
+}
+
+func (bu *BannerUsecase) PauseExperiment(ctx context.Context, id types.ID) error {
+	if err := bu.checkExperimentWrite(ctx, id); err != nil {
+		return err
+	}
+
+	_, err := bu.rep.UpdateExperimentStatus(ctx, id, entity.ExperimentRunning, entity.ExperimentPaused)
+
+	return err
+}
+
+// ResumeExperiment возобновляет эксперимент и, как при создании эксперимента, сбрасывает кэш его баннера,
+// закэшированный, пока эксперимент был приостановлен
+func (bu *BannerUsecase) ResumeExperiment(ctx context.Context, id types.ID) error {
+	if err := bu.checkExperimentWrite(ctx, id); err != nil {
+		return err
+	}
+
+	keys, err := bu.rep.UpdateExperimentStatus(ctx, id, entity.ExperimentPaused, entity.ExperimentRunning)
+	if err != nil {
+		return err
+	}
+
+	bu.invalidateCache(ctx, *keys)
+
+	return nil
+}
+
+func (bu *BannerUsecase) ConcludeExperiment(ctx context.Context, id, winnerVariantID types.ID) (uint32, error) {
+	if err := bu.checkExperimentWrite(ctx, id); err != nil {
+		return 0, err
+	}
+
+	concluded, err := bu.rep.ConcludeExperiment(ctx, id, winnerVariantID)
+	if err != nil {
+		return 0, err
+	}
+
+	bu.cache.InvalidateCache(ctx, concluded.FeatureID, concluded.TagIDs)
+
+	return concluded.Version, nil
+}
+
+// chooseVariant детерминированно выбирает вариант эксперимента для пользователя с учётом весов вариантов.
+// Один и тот же пользователь в рамках эксперимента всегда получает один и тот же вариант.
+func chooseVariant(experimentID types.ID, userID string, variants []entity.Variant) *entity.Variant {
+	var totalWeight uint64
+
+	for _, variant := range variants {
+		totalWeight += uint64(variant.Weight)
+	}
+
+	if totalWeight == 0 {
+		return nil
+	}
+
+	hash := fnv.New64a()
+	key := strconv.FormatUint(uint64(experimentID), 10) + ":" + userID
+	_, _ = hash.Write([]byte(key)) // nolint: errcheck // hash.Write never returns error
+
+	point := hash.Sum64() % totalWeight
+
+	for i := range variants {
+		if point < uint64(variants[i].Weight) {
+			return &variants[i]
+		}
+
+		point -= uint64(variants[i].Weight)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/pkg/slices"
+	"context"
+)
+
+// SearchBanners ищет версии баннеров по содержимому среди баннеров фич, доступных админу
+func (bu *BannerUsecase) SearchBanners(ctx context.Context, search *entity.ContentSearch,
+	offset, limit *uint64,
+) ([]models.ContentMatch, error) {
+	var entityOffset uint64 = defaultOffset
+
+	var entityLimit uint64 = defaultLimit
+
+	if offset != nil {
+		entityOffset = *offset
+	}
+
+	if limit != nil {
+		entityLimit = *limit
+	}
+
+	search.FeatureIDs = allowedFeatures(ctx)
+
+	matches, err := bu.rep.SearchBanners(ctx, search, entityOffset, entityLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Map(matches, func(m *entity.ContentMatch) models.ContentMatch {
+		return *models.FromContentMatchEntity(m)
+	}), nil
+}
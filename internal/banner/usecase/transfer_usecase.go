@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/access"
+	"context"
+	"sort"
+)
+
+// ExportBanners передаёт в export баннеры фич, доступных админу, по одному в порядке идентификаторов
+func (bu *BannerUsecase) ExportBanners(ctx context.Context, export func(bnr *models.Banner) error) error {
+	return bu.rep.ExportBanners(ctx, allowedFeatures(ctx), func(bnr *entity.Banner) error {
+		return export(models.FromBannerEntity(bnr))
+	})
+}
+
+// ImportBanners импортирует баннеры в режиме mode. Импорт с созданием баннеров доступен для фич админа,
+// а замена баннеров по идентификатору и всех баннеров -- только админу с доступом ко всем фичам.
+// Строки с фичами, недоступными админу, попадают в ошибки импорта. Изменения применяются, только если
+// ошибок нет и dryRun не задан, после чего сбрасывается кэш всех затронутых баннеров.
+func (bu *BannerUsecase) ImportBanners(ctx context.Context, banners []models.ImportBanner,
+	mode entity.ImportMode, dryRun bool,
+) (*models.ImportResult, error) {
+	scope := access.FromContext(ctx)
+	if err := scope.CheckWrite(); err != nil {
+		return nil, err
+	}
+
+	if mode != entity.ImportCreate {
+		if err := scope.CheckFull(); err != nil {
+			return nil, err
+		}
+	}
+
+	lineErrors := make([]entity.ImportLineError, 0)
+	imported := make([]entity.ImportBanner, 0, len(banners))
+
+	for i := range banners {
+		if err := scope.CheckWrite(banners[i].FeatureID); err != nil {
+			lineErrors = append(lineErrors, entity.ImportLineError{Line: banners[i].Line, Err: err})
+
+			continue
+		}
+
+		imported = append(imported, *banners[i].ToImportBannerEntity())
+	}
+
+	// Строки без доступа не импортируются, но остальные строки всё равно проверяются на конфликты
+	result, err := bu.rep.ImportBanners(ctx, imported, mode, dryRun || len(lineErrors) != 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Errors = append(lineErrors, result.Errors...)
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Line < result.Errors[j].Line
+	})
+
+	applied := !dryRun && len(result.Errors) == 0
+	if applied {
+		bu.invalidateCache(ctx, result.Keys...)
+	}
+
+	return models.FromImportResultEntity(result, applied), nil
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/types"
+	"context"
+	"encoding/json"
+)
+
+// GetUserBanners возвращает пользователю баннеры нескольких фич по одному тэгу. Без useLastRevision баннеры
+// отдаются из кэша, а устаревшие обновляются в фоне. Баннеры, которых нет в кэше, и все баннеры
+// с useLastRevision запрашиваются из базы одним запросом и сохраняются в кэш.
+// Фич, для которых баннер не найден, в результате нет.
+func (bu *BannerUsecase) GetUserBanners(ctx context.Context, tagID types.ID, featureIDs []types.ID,
+	userID *string, useLastRevision bool,
+) (map[types.ID]*models.UserBanner, error) {
+	banners := make(map[types.ID]*models.UserBanner, len(featureIDs))
+	misses := make([]types.ID, 0, len(featureIDs))
+	seen := make(map[types.ID]struct{}, len(featureIDs))
+	tagIDs := []types.ID{tagID}
+
+	for _, featureID := range featureIDs {
+		if _, ok := seen[featureID]; ok {
+			continue
+		}
+
+		seen[featureID] = struct{}{}
+
+		if !useLastRevision {
+			if entry, err := bu.cache.HaveCache(ctx, featureID, tagIDs, nil); err == nil {
+				banners[featureID] = &models.UserBanner{Content: json.RawMessage(entry.Content), Stale: entry.Stale}
+
+				if entry.Stale {
+					bu.refreshUserBanner(ctx, featureID, tagIDs)
+				}
+
+				continue
+			}
+		}
+
+		misses = append(misses, featureID)
+	}
+
+	if len(misses) != 0 {
+		loaded, err := bu.rep.GetUserBanners(ctx, tagID, misses)
+		if err != nil {
+			return nil, err
+		}
+
+		for featureID, bnr := range loaded {
+			// Пока идёт эксперимент, содержимое зависит от пользователя и не может быть закэшировано по паре фича-тэг
+			if bnr.ExperimentID == nil {
+				bu.cache.SetCache(ctx, featureID, tagIDs, nil, bnr.Content, bnr.EndAt, cacheTTL(bnr.CacheTTL))
+			}
+
+			userBanner := models.FromUserBannerEntity(&bnr)
+			if err := bu.chooseUserVariant(ctx, userBanner, userID); err != nil {
+				return nil, err
+			}
+
+			banners[featureID] = userBanner
+		}
+	}
+
+	if bu.popularity != nil {
+		for featureID := range banners {
+			bu.popularity.Track(featureID, tagID)
+		}
+	}
+
+	return banners, nil
+}
+
+// refreshUserBanner обновляет устаревший баннер последней версии в фоне, не задерживая ответ пользователю
+func (bu *BannerUsecase) refreshUserBanner(ctx context.Context, featureID types.ID, tagIDs []types.ID) {
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		// Если обновить баннер не удалось, он будет обновлён при следующем запросе
+		_ = bu.RefreshUserBanner(ctx, featureID, tagIDs, nil) // nolint: errcheck
+	}()
+}
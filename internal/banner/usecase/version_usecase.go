@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"bannersrv/internal/banner/entity"
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/slices"
+	"context"
+)
+
+func (bu *BannerUsecase) GetBannerVersions(ctx context.Context, bannerID types.ID,
+	offset, limit *uint64,
+) ([]models.Version, error) {
+	if err := bu.checkBannerRead(ctx, bannerID); err != nil {
+		return nil, err
+	}
+
+	var entityOffset uint64 = defaultOffset
+
+	var entityLimit uint64 = defaultLimit
+
+	if offset != nil {
+		entityOffset = *offset
+	}
+
+	if limit != nil {
+		entityLimit = *limit
+	}
+
+	versions, err := bu.rep.GetBannerVersions(ctx, bannerID, entityOffset, entityLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Map(versions, func(v *entity.Version) models.Version {
+		return *models.FromVersionEntity(v)
+	}), nil
+}
+
+func (bu *BannerUsecase) GetBannerVersion(ctx context.Context, bannerID types.ID,
+	version uint32,
+) (*models.Version, error) {
+	if err := bu.checkBannerRead(ctx, bannerID); err != nil {
+		return nil, err
+	}
+
+	bannerVersion, err := bu.rep.GetBannerVersion(ctx, bannerID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.FromVersionEntity(bannerVersion), nil
+}
+
+func (bu *BannerUsecase) RollbackBanner(ctx context.Context, id types.ID,
+	version uint32,
+) (*models.CurrentVersion, error) {
+	if err := bu.checkBannerWrite(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rollback, err := bu.rep.RollbackBanner(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	bu.cache.InvalidateCache(ctx, rollback.FeatureID, rollback.TagIDs)
+
+	return models.FromCurrentVersionEntity(rollback), nil
+}
+
+func (bu *BannerUsecase) GetDefaultRetention(ctx context.Context) (uint32, error) {
+	return bu.rep.GetDefaultRetention(ctx)
+}
+
+// SetDefaultRetention задаёт глобальное число хранимых версий. Значение затрагивает баннеры всех фич,
+// поэтому доступно только админу без ограничений.
+func (bu *BannerUsecase) SetDefaultRetention(ctx context.Context, retention uint32) error {
+	if err := access.FromContext(ctx).CheckFull(); err != nil {
+		return err
+	}
+
+	return bu.rep.SetDefaultRetention(ctx, retention)
+}
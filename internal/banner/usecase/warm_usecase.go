@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"bannersrv/internal/banner/models"
+	"bannersrv/internal/banner/repository"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// WarmCache сохраняет в кэш баннеры limit самых запрашиваемых пар фича-тэг, которых ещё нет в кэше
+// или которые в нём устарели.
+// Баннеры запрашиваются так же, как пользователем, поэтому неактивные баннеры и баннеры вне окна показа
+// в кэш не попадают. Прогрев затрагивает баннеры всех фич, поэтому доступен только админу без ограничений.
+func (bu *BannerUsecase) WarmCache(ctx context.Context, limit *uint64) (*models.WarmResult, error) {
+	if err := access.FromContext(ctx).CheckFull(); err != nil {
+		return nil, err
+	}
+
+	var entityLimit uint64 = defaultLimit
+
+	if limit != nil {
+		entityLimit = *limit
+	}
+
+	pairs, err := bu.popularity.GetPopular(ctx, entityLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.WarmResult{}
+
+	for _, pair := range pairs {
+		tagIDs := []types.ID{pair.TagID}
+
+		if entry, err := bu.cache.HaveCache(ctx, pair.FeatureID, tagIDs, nil); err == nil && !entry.Stale {
+			result.Cached++
+
+			continue
+		}
+
+		bnr, err := bu.rep.GetBanner(ctx, pair.FeatureID, tagIDs, *types.NewNullObject[uint32]())
+		if err != nil {
+			if errors.Is(err, repository.ErrorBannerNotFound) {
+				result.Skipped++
+
+				continue
+			}
+
+			return nil, err
+		}
+
+		// Пока идёт эксперимент, содержимое зависит от пользователя и не может быть закэшировано по паре фича-тэг
+		if bnr.ExperimentID != nil {
+			result.Skipped++
+
+			continue
+		}
+
+		bu.cache.SetCache(ctx, pair.FeatureID, tagIDs, nil, bnr.Content, bnr.EndAt, cacheTTL(bnr.CacheTTL))
+		result.Warmed++
+	}
+
+	return result, nil
+}
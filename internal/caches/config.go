@@ -0,0 +1,77 @@
+package caches
+
+// Backend хранилище кэша баннеров
+type Backend string
+
+const (
+	// RedisBackend кэш в Redis, при недоступности которого кэш временно хранится в памяти процесса
+	RedisBackend Backend = "redis"
+	// MemoryBackend кэш только в памяти процесса, Redis для кэша не используется
+	MemoryBackend Backend = "memory"
+)
+
+// Config параметры кэширования баннеров
+type Config struct {
+	Backend    Backend          `yaml:"backend" default:"redis"`
+	TTL        TTLConfig        `yaml:"ttl"`
+	Local      LocalConfig      `yaml:"local"`
+	Memory     MemoryConfig     `yaml:"memory"`
+	Failover   FailoverConfig   `yaml:"failover"`
+	Popularity PopularityConfig `yaml:"popularity"`
+	Warm       WarmConfig       `yaml:"warm"`
+}
+
+// TTLConfig время жизни баннеров в кэше в миллисекундах. Баннер свежий в течение Soft и отдаётся как есть,
+// после чего до истечения Hard отдаётся как устаревший с обновлением в фоне. Равные значения отключают
+// отдачу устаревших баннеров. Soft используется для баннеров, время жизни которых не задано для фичи
+// или самого баннера. Время жизни каждой записи сокращается на случайные от 0 до Jitter процентов,
+// чтобы записи, сохранённые одновременно, не истекали разом.
+type TTLConfig struct {
+	Soft   uint64 `yaml:"soft" default:"300000"`
+	Hard   uint64 `yaml:"hard" default:"3600000"`
+	Jitter uint64 `yaml:"jitter" default:"10"`
+}
+
+// LocalConfig параметры кэша в памяти процесса, стоящего перед кэшем в Redis.
+// Записи хранятся не дольше TTL миллисекунд и вытесняются при превышении MaxEntries записей или MaxBytes байт
+// содержимого. Изменения кэша рассылаются остальным репликам через канал Channel.
+// Нулевое значение MaxEntries отключает кэш в памяти.
+type LocalConfig struct {
+	MaxEntries uint64 `yaml:"max_entries" default:"0"`
+	MaxBytes   uint64 `yaml:"max_bytes" default:"67108864"`
+	TTL        uint64 `yaml:"ttl" default:"10000"`
+	Channel    string `yaml:"channel" default:"banner-cache-invalidation"`
+}
+
+// MemoryConfig параметры кэша в памяти процесса, используемого вместо Redis. Записи вытесняются
+// при превышении MaxEntries записей или MaxBytes байт содержимого.
+type MemoryConfig struct {
+	MaxEntries uint64 `yaml:"max_entries" default:"100000"`
+	MaxBytes   uint64 `yaml:"max_bytes" default:"268435456"`
+}
+
+// FailoverConfig параметры проверки доступности Redis раз в CheckPeriod миллисекунд.
+// Проверка, не завершившаяся за CheckTimeout миллисекунд, считается неудачной.
+type FailoverConfig struct {
+	CheckPeriod  uint64 `yaml:"check_period" default:"1000"`
+	CheckTimeout uint64 `yaml:"check_timeout" default:"200"`
+}
+
+// PopularityConfig параметры учёта запросов баннеров пользователями. Запросы считаются в памяти реплики
+// и раз в FlushPeriod миллисекунд добавляются в Redis в счётчики периодов длиной Bucket миллисекунд.
+// Популярность пары фича-тэг считается по запросам за последние Window миллисекунд.
+// В каждом периоде хранится не больше MaxPairs самых запрашиваемых пар.
+type PopularityConfig struct {
+	Window      uint64 `yaml:"window" default:"86400000"`
+	Bucket      uint64 `yaml:"bucket" default:"3600000"`
+	FlushPeriod uint64 `yaml:"flush_period" default:"1000"`
+	MaxPairs    uint64 `yaml:"max_pairs" default:"10000"`
+}
+
+// WarmConfig параметры прогрева кэша Limit самыми популярными парами фича-тэг.
+// Сервис прогревает кэш при запуске, если задан OnStartup, а cron сервис раз в Period миллисекунд.
+type WarmConfig struct {
+	Limit     uint64 `yaml:"limit" default:"100"`
+	OnStartup bool   `yaml:"on_startup" default:"true"`
+	Period    uint64 `yaml:"period" default:"300000"`
+}
@@ -5,7 +5,10 @@ import (
 	"bannersrv/internal/app/delivery/http/tools"
 	"bannersrv/internal/banner/delivery/http/v1/handlers"
 	"bannersrv/internal/caches"
+	"bannersrv/internal/pkg/metrics"
+	"bannersrv/internal/pkg/types"
 	"bannersrv/pkg/logger"
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
@@ -18,9 +21,18 @@ import (
 
 const (
 	UseLastRevisionParam = "use_last_revision"
+
+	// StaleHeader помечает баннер, отданный из кэша после истечения времени его свежести
+	StaleHeader = "X-Cache-Stale"
 )
 
-func CacheBanner(cacheManager caches.Manager) gin.HandlerFunc {
+// CacheBanner отдаёт баннер из кэша. Устаревший баннер отдаётся с заголовком StaleHeader и обновляется в фоне,
+// поэтому при недоступности базы пользователи продолжают получать последний закэшированный баннер.
+// Запросы с use_last_revision кэш не используют и при недоступности базы завершаются ошибкой.
+// Исход обращения к кэшу каждого запроса учитывается в metricsManager, если он задан.
+func CacheBanner(cacheManager caches.Manager, refresher caches.Refresher,
+	metricsManager metrics.Manager,
+) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		l := middleware.GetLogger(c)
 
@@ -39,15 +51,20 @@ func CacheBanner(cacheManager caches.Manager) gin.HandlerFunc {
 			skipCache = tmp
 		}
 
-		if !skipCache {
-			err := loadCache(c, cacheManager, l)
-			if err == nil {
+		if skipCache {
+			_, versioned := c.GetQuery(handlers.VersionParam)
+			countLookup(metricsManager, caches.OutcomeBypass, versioned)
+		} else {
+			outcome, versioned, err := loadCache(c, cacheManager, refresher, l)
+			if err != nil && !errors.Is(err, cr.ErrorCacheMiss) {
+				tools.SendError(c, err, http.StatusBadRequest, l)
+
 				return
 			}
 
-			if !errors.Is(err, cr.ErrorCacheMiss) {
-				tools.SendError(c, err, http.StatusBadRequest, l)
+			countLookup(metricsManager, outcome, versioned)
 
+			if err == nil {
 				return
 			}
 		}
@@ -56,37 +73,73 @@ func CacheBanner(cacheManager caches.Manager) gin.HandlerFunc {
 	}
 }
 
-func loadCache(c *gin.Context, cacheManager caches.Manager, l logger.Interface) error {
-	tagID, err := tools.ParseQueryParamToTypesID(c, handlers.TagIDParam,
+// loadCache отдаёт баннер из кэша и возвращает исход обращения к кэшу и признак запроса с версией.
+// Если баннер не был отдан, то возвращается ошибка разбора параметров или промах кэша.
+func loadCache(c *gin.Context, cacheManager caches.Manager, refresher caches.Refresher,
+	l logger.Interface,
+) (string, bool, error) {
+	tagIDs, err := tools.ParseQueryParamToTypesIDs(c, handlers.TagIDParam,
 		handlers.ErrorFeatureIDNotPresented, handlers.ErrorTagIDIncorrectType, l)
 	if err != nil {
-		return err
+		return "", false, err
 	}
 
 	featureID, err := tools.ParseQueryParamToTypesID(c, handlers.FeatureIDParam,
 		handlers.ErrorFeatureIDNotPresented, handlers.ErrorFeatureIDIncorrectType, l)
 	if err != nil {
-		return err
+		return "", false, err
 	}
 
 	version, err := tools.ParseQueryParamToUint32(c, handlers.VersionParam,
 		nil, handlers.ErrorVersionIncorrectType, l)
 	if err != nil {
-		return err
+		return "", false, err
 	}
 
-	content, err := cacheManager.HaveCache(*featureID, *tagID, version)
+	entry, err := cacheManager.HaveCache(c.Request.Context(), *featureID, tagIDs, version)
 	if err != nil {
 		if !errors.Is(err, cr.ErrorCacheMiss) {
 			l.Error(errors.Wrapf(err,
-				"failed to check cached banner with feature id %d, tag id %d, version %d", featureID, tagID, version))
+				"failed to check cached banner with feature id %d, tag ids %v, version %d", featureID, tagIDs, version))
+
+			return caches.OutcomeError, version != nil, cr.ErrorCacheMiss
 		}
 
-		return cr.ErrorCacheMiss
+		return caches.OutcomeMiss, version != nil, cr.ErrorCacheMiss
 	}
 
-	tools.SendStatus(c, http.StatusOK, json.RawMessage(content), l)
-	l.Info("banner wad loaded from cache with feature id %d and tag id %d, version %d", featureID, tagID, version)
+	outcome := caches.OutcomeHit
 
-	return nil
+	if entry.Stale {
+		outcome = caches.OutcomeStale
+
+		c.Header(StaleHeader, "true")
+		refreshCache(c.Request.Context(), refresher, *featureID, tagIDs, version, l)
+	}
+
+	tools.SendStatus(c, http.StatusOK, json.RawMessage(entry.Content), l)
+	l.Info("banner wad loaded from cache with feature id %d and tag ids %v, version %d", featureID, tagIDs, version)
+
+	return outcome, version != nil, nil
+}
+
+func countLookup(metricsManager metrics.Manager, outcome string, versioned bool) {
+	if metricsManager != nil {
+		metricsManager.GetCacheLookups().WithLabelValues(outcome, caches.Lookup(versioned)).Inc()
+	}
+}
+
+// refreshCache обновляет устаревший баннер в фоне, не задерживая ответ пользователю.
+// Время обновления ограничивает сам refresher.
+func refreshCache(ctx context.Context, refresher caches.Refresher, featureID types.ID, tagIDs []types.ID,
+	version *uint32, l logger.Interface,
+) {
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		if err := refresher.RefreshUserBanner(ctx, featureID, tagIDs, version); err != nil {
+			l.Warn(errors.Wrapf(err, "can't refresh stale banner with feature id %d, tag ids %v, version %v",
+				featureID, tagIDs, version))
+		}
+	}()
 }
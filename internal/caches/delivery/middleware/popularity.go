@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"bannersrv/internal/app/delivery/http/middleware"
+	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/banner/delivery/http/v1/handlers"
+	"bannersrv/internal/caches"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrackPopularity учитывает успешные запросы баннера пользователем, в том числе отданные из кэша,
+// поэтому должен стоять перед CacheBanner. Прогрев кэша работает по парам фича-тэг, поэтому запросы
+// с несколькими тэгами не учитываются.
+func TrackPopularity(tracker caches.PopularityTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Status() != http.StatusOK {
+			return
+		}
+
+		l := middleware.GetLogger(c)
+
+		featureID, err := tools.ParseQueryParamToTypesID(c, handlers.FeatureIDParam, nil, nil, l)
+		if err != nil || featureID == nil {
+			return
+		}
+
+		tagIDs, err := tools.ParseQueryParamToTypesIDs(c, handlers.TagIDParam, nil, nil, l)
+		if err != nil {
+			return
+		}
+
+		if tagSet := caches.TagSet(tagIDs); len(tagSet) == 1 {
+			tracker.Track(*featureID, tagSet[0])
+		}
+	}
+}
@@ -1,8 +1,62 @@
 package caches
 
-import "bannersrv/internal/pkg/types"
+import (
+	"bannersrv/internal/pkg/types"
+	"context"
+	"time"
+)
 
+// Entry баннер из кэша. Устаревший баннер пережил время свежести, но ещё может отдаваться,
+// пока его обновление из базы не завершится или база недоступна.
+type Entry struct {
+	Content types.Content
+	Stale   bool
+}
+
+// EntryInfo запись кэша для проверки админом. FreshUntil время, до которого баннер считается свежим,
+// а TTL оставшееся время жизни записи.
+type EntryInfo struct {
+	Key        string
+	Content    types.Content
+	FreshUntil time.Time
+	TTL        time.Duration
+}
+
+// Manager кэш баннеров по фиче и тэгам пользователя. Записи с одними и теми же тэгами в любом порядке
+// совпадают, а запись с одним тэгом -- это запись пары фича-тэг.
 type Manager interface {
-	HaveCache(featureID, tagID types.ID, version *uint32) (types.Content, error)
-	SetCache(featureID, tagID types.ID, version *uint32, content types.Content) error
+	HaveCache(ctx context.Context, featureID types.ID, tagIDs []types.ID, version *uint32) (*Entry, error)
+	// SetCache сохраняет баннер в кэш. Если ttl не задан, баннер свежий в течение времени жизни по умолчанию.
+	// Ошибки сохранения не возвращаются, а логируются самим менеджером.
+	SetCache(ctx context.Context, featureID types.ID, tagIDs []types.ID, version *uint32,
+		content types.Content, expiresAt *time.Time, ttl *time.Duration)
+	// InvalidateCache удаляет из кэша все версии баннеров по фиче и каждому из тэгов,
+	// а также все записи фичи с несколькими тэгами.
+	// Ошибки сброса кэша не возвращаются, а повторяются и логируются самим менеджером.
+	InvalidateCache(ctx context.Context, featureID types.ID, tagIDs []types.ID)
+	// InspectCache возвращает запись кэша баннера вместе с её временем жизни
+	InspectCache(ctx context.Context, featureID types.ID, tagIDs []types.ID, version *uint32) (*EntryInfo, error)
+	// PurgeCache удаляет из кэша все версии баннеров по фиче и тэгу, если они заданы, и возвращает
+	// число сброшенных индексов пар фича-тэг и фич с несколькими тэгами.
+	// В отличие от InvalidateCache ошибки сброса возвращаются.
+	PurgeCache(ctx context.Context, featureID, tagID *types.ID) (uint64, error)
+}
+
+type Refresher interface {
+	// RefreshUserBanner заново загружает устаревший баннер из базы в кэш. Обновление ограничено по времени
+	// и одновременно выполняется только одной репликой.
+	RefreshUserBanner(ctx context.Context, featureID types.ID, tagIDs []types.ID, version *uint32) error
+}
+
+// Pair пара фича-тэг, по которой пользователи запрашивают баннер
+type Pair struct {
+	FeatureID types.ID
+	TagID     types.ID
+}
+
+type PopularityTracker interface {
+	// Track учитывает запрос баннера пользователем. Запросы сохраняются в фоне, поэтому вызов не блокируется.
+	Track(featureID, tagID types.ID)
+	// GetPopular возвращает не больше limit самых запрашиваемых пар от популярных к менее популярным
+	GetPopular(ctx context.Context, limit uint64) ([]Pair, error)
 }
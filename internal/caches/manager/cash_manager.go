@@ -2,39 +2,305 @@ package manager
 
 import (
 	"bannersrv/internal/caches"
+	"bannersrv/internal/caches/repository"
+	"bannersrv/internal/pkg/metrics"
 	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/logger"
+	"context"
+	"encoding/json"
 	"fmt"
+	"math/rand/v2"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 const (
-	cacheExpiredTime = 5 * time.Minute
+	// Параметры повторных попыток сброса кэша, задержка между попытками удваивается
+	invalidateAttempts = 3
+	invalidateBackoff  = 50 * time.Millisecond
+	invalidateTimeout  = 2 * time.Second
+
+	// purgeBatch число индексов, сбрасываемых одним запросом при очистке кэша админом
+	purgeBatch = 100
+
+	getOperation = "get"
+	setOperation = "set"
+
+	// tagSetIndex суффикс индекса записей фичи с несколькими тэгами
+	tagSetIndex = "tags"
 )
 
+// envelope запись кэша вместе с временем, до которого баннер считается свежим
+type envelope struct {
+	FreshUntil int64           `json:"fresh_until"`
+	Content    json.RawMessage `json:"content"`
+}
+
 type CacheManager struct {
-	rep caches.Repository
+	rep     caches.Repository
+	softTTL time.Duration
+	hardTTL time.Duration
+	// jitter наибольшая доля, на которую сокращается время жизни записи
+	jitter  float64
+	metrics metrics.Manager
+	l       logger.Interface
 }
 
-func NewCacheManager(cache caches.Repository) *CacheManager {
+func NewCacheManager(cache caches.Repository, cfg caches.TTLConfig, metricsManager metrics.Manager,
+	l logger.Interface,
+) *CacheManager {
 	return &CacheManager{
-		rep: cache,
+		rep:     cache,
+		softTTL: time.Duration(cfg.Soft) * time.Millisecond,
+		hardTTL: time.Duration(max(cfg.Soft, cfg.Hard)) * time.Millisecond,
+		jitter:  float64(min(cfg.Jitter, 100)) / 100,
+		metrics: metricsManager,
+		l:       l,
 	}
 }
 
-func (cm *CacheManager) HaveCache(featureID, tagID types.ID, version *uint32) (types.Content, error) {
-	key := fmt.Sprintf("%d-%d", featureID, tagID)
+// cacheKey ключ записи баннера по фиче, тэгам и версии, если она указана
+func cacheKey(featureID types.ID, tagIDs []types.ID, version *uint32) string {
+	key := fmt.Sprintf("%d-%s", featureID, caches.TagSetKey(tagIDs))
 	if version != nil {
 		key = fmt.Sprintf("%s-%d", key, *version)
 	}
 
-	return cm.rep.HaveCache(key)
+	return key
 }
 
-func (cm *CacheManager) SetCache(featureID, tagID types.ID, version *uint32, content types.Content) error {
-	key := fmt.Sprintf("%d-%d", featureID, tagID)
-	if version != nil {
-		key = fmt.Sprintf("%s-%d", key, *version)
+// indexKey ключ индекса всех закэшированных версий баннера по паре фича-тэг
+func indexKey(featureID, tagID types.ID) string {
+	return fmt.Sprintf("index-%d-%d", featureID, tagID)
+}
+
+// entryIndexKey ключ индекса записи по фиче и тэгам. Записи с несколькими тэгами попадают в общий индекс фичи,
+// поэтому сбрасываются при любом изменении её баннеров: изменение одного баннера или его приоритета
+// может поменять выбор баннера для любого набора тэгов, в который входит хотя бы один его тэг.
+func entryIndexKey(featureID types.ID, tagIDs []types.ID) string {
+	if set := caches.TagSet(tagIDs); len(set) == 1 {
+		return indexKey(featureID, set[0])
 	}
 
-	return cm.rep.SetCache(key, content, cacheExpiredTime)
+	return fmt.Sprintf("index-%d-%s", featureID, tagSetIndex)
+}
+
+// HaveCache возвращает баннер из кэша
+func (cm *CacheManager) HaveCache(ctx context.Context, featureID types.ID, tagIDs []types.ID,
+	version *uint32,
+) (*caches.Entry, error) {
+	start := time.Now()
+	key := cacheKey(featureID, tagIDs, version)
+
+	raw, err := cm.rep.HaveCache(ctx, key)
+	if err != nil {
+		cm.observe(getOperation, errorOutcome(err), version, start)
+
+		return nil, err
+	}
+
+	entry, err := parseEnvelope(key, raw)
+	if err != nil {
+		cm.observe(getOperation, caches.OutcomeMiss, version, start)
+
+		return nil, err
+	}
+
+	stale := entry.stale()
+
+	outcome := caches.OutcomeHit
+	if stale {
+		outcome = caches.OutcomeStale
+	}
+
+	cm.observe(getOperation, outcome, version, start)
+
+	return &caches.Entry{
+		Content: types.Content(entry.Content),
+		Stale:   stale,
+	}, nil
+}
+
+// InspectCache возвращает запись кэша вместе с оставшимся временем её жизни
+func (cm *CacheManager) InspectCache(ctx context.Context, featureID types.ID, tagIDs []types.ID,
+	version *uint32,
+) (*caches.EntryInfo, error) {
+	key := cacheKey(featureID, tagIDs, version)
+
+	raw, ttl, err := cm.rep.HaveCacheWithTTL(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := parseEnvelope(key, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &caches.EntryInfo{
+		Key:        key,
+		Content:    types.Content(entry.Content),
+		FreshUntil: time.UnixMilli(entry.FreshUntil),
+		TTL:        ttl,
+	}, nil
+}
+
+// parseEnvelope разбирает запись кэша. Записи, которые не удалось разобрать, например сохранённые
+// в прежнем формате, считаются промахом кэша.
+func parseEnvelope(key string, raw types.Content) (*envelope, error) {
+	var entry envelope
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil || entry.Content == nil {
+		return nil, errors.Wrapf(repository.ErrorCacheMiss, "cache with key %s has unknown format", key)
+	}
+
+	return &entry, nil
+}
+
+func (e *envelope) stale() bool {
+	return time.Now().UnixMilli() >= e.FreshUntil
+}
+
+// lifetime возвращает мягкое и жёсткое время жизни записи, сокращённые на случайную долю.
+// Собственное время жизни баннера заменяет мягкое, а жёсткое при необходимости увеличивается до него.
+func (cm *CacheManager) lifetime(ttl *time.Duration) (time.Duration, time.Duration) {
+	soft, hard := cm.softTTL, cm.hardTTL
+	if ttl != nil {
+		soft, hard = *ttl, max(cm.hardTTL, *ttl)
+	}
+
+	factor := 1 - rand.Float64()*cm.jitter // nolint: gosec // разброс истечения не требует криптостойкости
+
+	return time.Duration(float64(soft) * factor), time.Duration(float64(hard) * factor)
+}
+
+// SetCache сохраняет баннер в кэш на жёсткое время жизни, из которых свежим он считается мягкое время жизни.
+// Если задан expiresAt, то время жизни записи не превысит его, чтобы кэш не отдавал баннер после окончания
+// окна показа.
+func (cm *CacheManager) SetCache(ctx context.Context, featureID types.ID, tagIDs []types.ID,
+	version *uint32, content types.Content, expiresAt *time.Time, ttl *time.Duration,
+) {
+	start := time.Now()
+	key := cacheKey(featureID, tagIDs, version)
+
+	softTTL, hardTTL := cm.lifetime(ttl)
+
+	if expiresAt != nil {
+		hardTTL = min(hardTTL, time.Until(*expiresAt))
+		if hardTTL <= 0 {
+			return
+		}
+	}
+
+	// Содержимое встраивается без повторного кодирования, чтобы из кэша отдавался баннер байт в байт
+	raw := fmt.Sprintf(`{"fresh_until":%d,"content":%s}`,
+		time.Now().Add(min(softTTL, hardTTL)).UnixMilli(), content)
+
+	if err := cm.rep.SetCache(ctx, key, types.Content(raw), hardTTL, entryIndexKey(featureID, tagIDs)); err != nil {
+		cm.observe(setOperation, caches.OutcomeError, version, start)
+		cm.l.Error(errors.Wrapf(err, "can't cache banner with key %s", key))
+
+		return
+	}
+
+	cm.observe(setOperation, caches.OutcomeOK, version, start)
+
+	cm.l.Info("banner with key %s was cached", key)
+}
+
+// InvalidateCache удаляет из кэша все версии баннеров по фиче и каждому из тэгов, включая записи с явно
+// указанной версией, так как изменение активности или окна показа влияет и на них, а также все записи фичи
+// с несколькими тэгами. Изменение к этому моменту уже сохранено, поэтому сброс не прерывается отменой запроса,
+// а неудачные попытки повторяются.
+func (cm *CacheManager) InvalidateCache(ctx context.Context, featureID types.ID, tagIDs []types.ID) {
+	if len(tagIDs) == 0 {
+		return
+	}
+
+	indexes := make([]string, 0, len(tagIDs)+1)
+	for _, tagID := range tagIDs {
+		indexes = append(indexes, indexKey(featureID, tagID))
+	}
+
+	indexes = append(indexes, entryIndexKey(featureID, nil))
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), invalidateTimeout)
+	defer cancel()
+
+	backoff := invalidateBackoff
+	attempts := 1
+
+	err := cm.rep.DeleteIndexedCache(ctx, indexes...)
+	for ; err != nil && attempts < invalidateAttempts && ctx.Err() == nil; attempts++ {
+		time.Sleep(backoff)
+		backoff *= 2
+
+		err = cm.rep.DeleteIndexedCache(ctx, indexes...)
+	}
+
+	if err == nil {
+		return
+	}
+
+	cm.l.Error(errors.Wrapf(err,
+		"can't invalidate cache of banners with feature id %d and tag ids %v after %d attempts",
+		featureID, tagIDs, attempts))
+}
+
+// PurgeCache удаляет из кэша все записи по фиче и тэгу, если они заданы, а иначе все записи кэша.
+// При сбросе по тэгу сбрасываются и все записи с несколькими тэгами, так как тэги этих записей не индексируются.
+// Индексы сбрасываются частями, поэтому при ошибке часть из них может быть уже сброшена.
+func (cm *CacheManager) PurgeCache(ctx context.Context, featureID, tagID *types.ID) (uint64, error) {
+	feature, tag := "*", "*"
+	if featureID != nil {
+		feature = fmt.Sprint(*featureID)
+	}
+
+	if tagID != nil {
+		tag = fmt.Sprint(*tagID)
+	}
+
+	indexes, err := cm.rep.GetIndexes(ctx, fmt.Sprintf("index-%s-%s", feature, tag))
+	if err != nil {
+		return 0, err
+	}
+
+	if tagID != nil {
+		tagSetIndexes, err := cm.rep.GetIndexes(ctx, fmt.Sprintf("index-%s-%s", feature, tagSetIndex))
+		if err != nil {
+			return 0, err
+		}
+
+		indexes = append(indexes, tagSetIndexes...)
+	}
+
+	var purged uint64
+
+	for start := 0; start < len(indexes); start += purgeBatch {
+		batch := indexes[start:min(start+purgeBatch, len(indexes))]
+
+		if err := cm.rep.DeleteIndexedCache(ctx, batch...); err != nil {
+			return purged, errors.Wrapf(err, "purged %d of %d cache indexes", purged, len(indexes))
+		}
+
+		purged += uint64(len(batch))
+	}
+
+	return purged, nil
+}
+
+// errorOutcome исход неудачного обращения к кэшу: промах или ошибка хранилища
+func errorOutcome(err error) string {
+	if errors.Is(err, repository.ErrorCacheMiss) {
+		return caches.OutcomeMiss
+	}
+
+	return caches.OutcomeError
+}
+
+func (cm *CacheManager) observe(operation, outcome string, version *uint32, start time.Time) {
+	if cm.metrics != nil {
+		cm.metrics.GetCacheDurations().WithLabelValues(operation, outcome, caches.Lookup(version != nil)).
+			Observe(time.Since(start).Seconds())
+	}
 }
@@ -0,0 +1,111 @@
+package manager
+
+import (
+	"bannersrv/internal/caches"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/logger"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// flushTimeout ограничение времени сохранения запросов, накопленных к остановке сервиса
+const flushTimeout = 2 * time.Second
+
+// PopularityManager считает запросы баннеров в памяти и периодически сохраняет их в репозиторий,
+// чтобы не обращаться к нему на каждый запрос пользователя. Число различных пар в памяти ограничено,
+// запросы новых пар сверх ограничения до следующего сохранения не учитываются.
+type PopularityManager struct {
+	rep      caches.PopularityRepository
+	period   time.Duration
+	maxPairs int
+
+	mu     sync.Mutex
+	counts map[string]uint64
+
+	l logger.Interface
+}
+
+func NewPopularityManager(rep caches.PopularityRepository, cfg caches.PopularityConfig,
+	l logger.Interface,
+) *PopularityManager {
+	return &PopularityManager{
+		rep:      rep,
+		period:   time.Duration(cfg.FlushPeriod) * time.Millisecond,
+		maxPairs: int(cfg.MaxPairs),
+		counts:   make(map[string]uint64),
+		l:        l,
+	}
+}
+
+func popularityKey(featureID, tagID types.ID) string {
+	return fmt.Sprintf("%d-%d", featureID, tagID)
+}
+
+func (pm *PopularityManager) Track(featureID, tagID types.ID) {
+	key := popularityKey(featureID, tagID)
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if _, ok := pm.counts[key]; ok || len(pm.counts) < pm.maxPairs {
+		pm.counts[key]++
+	}
+}
+
+// Run сохраняет накопленные запросы раз в период до отмены ctx, после чего сохраняет оставшиеся
+func (pm *PopularityManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(pm.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), flushTimeout)
+			pm.flush(flushCtx)
+			cancel()
+
+			return
+		case <-ticker.C:
+			pm.flush(ctx)
+		}
+	}
+}
+
+// flush сохраняет накопленные запросы. Если сохранить их не удалось, они теряются,
+// так как популярность нужна только для выбора пар для прогрева кэша.
+func (pm *PopularityManager) flush(ctx context.Context) {
+	pm.mu.Lock()
+	counts := pm.counts
+	pm.counts = make(map[string]uint64, len(counts))
+	pm.mu.Unlock()
+
+	if err := pm.rep.IncrPopularity(ctx, counts); err != nil {
+		pm.l.Error(errors.Wrapf(err, "can't save popularity of %d banner pairs", len(counts)))
+	}
+}
+
+func (pm *PopularityManager) GetPopular(ctx context.Context, limit uint64) ([]caches.Pair, error) {
+	keys, err := pm.rep.GetPopular(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make([]caches.Pair, 0, len(keys))
+
+	for _, key := range keys {
+		var pair caches.Pair
+		if _, err := fmt.Sscanf(key, "%d-%d", &pair.FeatureID, &pair.TagID); err != nil {
+			pm.l.Warn(errors.Wrapf(err, "can't parse popular banner pair %s", key))
+
+			continue
+		}
+
+		pairs = append(pairs, pair)
+	}
+
+	return pairs, nil
+}
@@ -0,0 +1,22 @@
+package caches
+
+// Исходы обращений к кэшу в метриках
+const (
+	OutcomeHit   = "hit"
+	OutcomeStale = "stale"
+	OutcomeMiss  = "miss"
+	OutcomeError = "error"
+	// OutcomeBypass запрос с use_last_revision, не обращавшийся к кэшу
+	OutcomeBypass = "bypass"
+	// OutcomeOK успешное сохранение в кэш
+	OutcomeOK = "ok"
+)
+
+// Lookup вид обращения к кэшу в метриках: с явно указанной версией баннера или без неё
+func Lookup(versioned bool) string {
+	if versioned {
+		return "versioned"
+	}
+
+	return "unversioned"
+}
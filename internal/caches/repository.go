@@ -2,10 +2,25 @@ package caches
 
 import (
 	"bannersrv/internal/pkg/types"
+	"context"
 	"time"
 )
 
 type Repository interface {
-	HaveCache(key string) (types.Content, error)
-	SetCache(key string, content types.Content, ttl time.Duration) error
+	HaveCache(ctx context.Context, key string) (types.Content, error)
+	// HaveCacheWithTTL возвращает запись вместе с оставшимся временем её жизни
+	HaveCacheWithTTL(ctx context.Context, key string) (types.Content, time.Duration, error)
+	// SetCache сохраняет запись и добавляет её в индекс index, по которому она может быть удалена
+	SetCache(ctx context.Context, key string, content types.Content, ttl time.Duration, index string) error
+	// DeleteIndexedCache удаляет все записи, добавленные в индексы indexes, вместе с самими индексами
+	DeleteIndexedCache(ctx context.Context, indexes ...string) error
+	// GetIndexes возвращает индексы, имена которых подходят под glob шаблон pattern
+	GetIndexes(ctx context.Context, pattern string) ([]string, error)
+}
+
+type PopularityRepository interface {
+	// IncrPopularity увеличивает счётчики запросов по ключам на переданные значения
+	IncrPopularity(ctx context.Context, counts map[string]uint64) error
+	// GetPopular возвращает не больше limit ключей с наибольшим числом запросов
+	GetPopular(ctx context.Context, limit uint64) ([]string, error)
 }
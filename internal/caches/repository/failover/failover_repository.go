@@ -0,0 +1,252 @@
+package failover
+
+import (
+	"bannersrv/internal/caches"
+	"bannersrv/internal/caches/repository"
+	"bannersrv/internal/pkg/metrics"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/logger"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// replayBatch число индексов, сбрасываемых в Redis одним запросом после его восстановления
+const replayBatch = 100
+
+// Pinger проверяет доступность Redis
+type Pinger interface {
+	Ping(ctx context.Context) *redis.StatusCmd
+}
+
+// Fallback кэш, используемый, пока Redis недоступен
+type Fallback interface {
+	caches.Repository
+	// Purge удаляет все записи
+	Purge()
+}
+
+// FailoverCache кэш в Redis, который при его недоступности временно переключается на кэш в памяти процесса.
+// Кэш переключается на память при первой ошибке Redis, а обратно -- после успешной проверки доступности.
+// Индексы, сброшенные пока Redis был недоступен, сбрасываются в нём перед возвратом, чтобы Redis не отдавал
+// баннеры, изменённые во время его недоступности. Кэш в памяти очищается при каждом переключении,
+// так как сбросы кэша, выполненные в Redis, в него не попадают.
+type FailoverCache struct {
+	primary  caches.Repository
+	fallback Fallback
+	pinger   Pinger
+	period   time.Duration
+	timeout  time.Duration
+
+	down atomic.Bool
+	// mu защищает pending и возврат на Redis от одновременного сброса кэша в памяти
+	mu      sync.Mutex
+	pending map[string]struct{}
+
+	metrics metrics.Manager
+	l       logger.Interface
+}
+
+func NewFailoverCache(primary caches.Repository, fallback Fallback, pinger Pinger,
+	cfg caches.FailoverConfig, metricsManager metrics.Manager, l logger.Interface,
+) *FailoverCache {
+	fc := &FailoverCache{
+		primary:  primary,
+		fallback: fallback,
+		pinger:   pinger,
+		period:   time.Duration(cfg.CheckPeriod) * time.Millisecond,
+		timeout:  time.Duration(cfg.CheckTimeout) * time.Millisecond,
+		pending:  make(map[string]struct{}),
+		metrics:  metricsManager,
+		l:        l,
+	}
+
+	fc.reportBackend(caches.RedisBackend)
+
+	return fc
+}
+
+func (fc *FailoverCache) HaveCache(ctx context.Context, key string) (types.Content, error) {
+	if !fc.down.Load() {
+		content, err := fc.primary.HaveCache(ctx, key)
+		if !fc.failed(ctx, err) {
+			return content, err
+		}
+	}
+
+	return fc.fallback.HaveCache(ctx, key)
+}
+
+func (fc *FailoverCache) HaveCacheWithTTL(ctx context.Context, key string) (types.Content, time.Duration, error) {
+	if !fc.down.Load() {
+		content, ttl, err := fc.primary.HaveCacheWithTTL(ctx, key)
+		if !fc.failed(ctx, err) {
+			return content, ttl, err
+		}
+	}
+
+	return fc.fallback.HaveCacheWithTTL(ctx, key)
+}
+
+func (fc *FailoverCache) SetCache(ctx context.Context, key string, content types.Content,
+	ttl time.Duration, index string,
+) error {
+	if !fc.down.Load() {
+		err := fc.primary.SetCache(ctx, key, content, ttl, index)
+		if !fc.failed(ctx, err) {
+			return err
+		}
+	}
+
+	return fc.fallback.SetCache(ctx, key, content, ttl, index)
+}
+
+func (fc *FailoverCache) DeleteIndexedCache(ctx context.Context, indexes ...string) error {
+	if !fc.down.Load() {
+		err := fc.primary.DeleteIndexedCache(ctx, indexes...)
+		if !fc.failed(ctx, err) {
+			return err
+		}
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	// Redis мог стать доступным, пока ожидалась блокировка
+	if !fc.down.Load() {
+		return fc.primary.DeleteIndexedCache(ctx, indexes...)
+	}
+
+	for _, index := range indexes {
+		fc.pending[index] = struct{}{}
+	}
+
+	return fc.fallback.DeleteIndexedCache(ctx, indexes...)
+}
+
+// GetIndexes возвращает индексы кэша, используемого в данный момент. Пока Redis недоступен,
+// индексы в нём не видны, поэтому не могут быть сброшены в нём после его восстановления.
+func (fc *FailoverCache) GetIndexes(ctx context.Context, pattern string) ([]string, error) {
+	if !fc.down.Load() {
+		indexes, err := fc.primary.GetIndexes(ctx, pattern)
+		if !fc.failed(ctx, err) {
+			return indexes, err
+		}
+	}
+
+	return fc.fallback.GetIndexes(ctx, pattern)
+}
+
+// Run проверяет доступность Redis раз в период до отмены ctx и переключает кэш между Redis и памятью
+func (fc *FailoverCache) Run(ctx context.Context) {
+	ticker := time.NewTicker(fc.period)
+	defer ticker.Stop()
+
+	for {
+		fc.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (fc *FailoverCache) check(ctx context.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx, fc.timeout)
+	defer cancel()
+
+	if err := fc.pinger.Ping(pingCtx).Err(); err != nil {
+		if ctx.Err() == nil {
+			fc.switchToFallback(err)
+		}
+
+		return
+	}
+
+	if fc.down.Load() {
+		fc.switchToPrimary(ctx)
+	}
+}
+
+// failed проверяет, что запрос к Redis завершился его ошибкой, и в этом случае переключает кэш на память.
+// Промах кэша и отмена запроса ошибкой Redis не считаются.
+func (fc *FailoverCache) failed(ctx context.Context, err error) bool {
+	if err == nil || errors.Is(err, repository.ErrorCacheMiss) || ctx.Err() != nil {
+		return false
+	}
+
+	fc.switchToFallback(err)
+
+	return true
+}
+
+func (fc *FailoverCache) switchToFallback(err error) {
+	if !fc.down.CompareAndSwap(false, true) {
+		return
+	}
+
+	fc.fallback.Purge()
+	fc.reportBackend(caches.MemoryBackend)
+	fc.countSwitch(caches.MemoryBackend)
+	fc.l.Warn(errors.Wrap(err, "cache switched from redis to memory"))
+}
+
+// switchToPrimary сбрасывает в Redis индексы, сброшенные за время его недоступности, и возвращает кэш на Redis.
+// Если сбросить индексы не удалось, кэш остаётся в памяти до следующей проверки.
+func (fc *FailoverCache) switchToPrimary(ctx context.Context) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	indexes := make([]string, 0, len(fc.pending))
+	for index := range fc.pending {
+		indexes = append(indexes, index)
+	}
+
+	for start := 0; start < len(indexes); start += replayBatch {
+		batch := indexes[start:min(start+replayBatch, len(indexes))]
+
+		if err := fc.primary.DeleteIndexedCache(ctx, batch...); err != nil {
+			fc.l.Error(errors.Wrapf(err, "can't invalidate %d cache indexes in redis after its recovery",
+				len(indexes)))
+
+			return
+		}
+
+		for _, index := range batch {
+			delete(fc.pending, index)
+		}
+	}
+
+	fc.down.Store(false)
+	fc.fallback.Purge()
+	fc.reportBackend(caches.RedisBackend)
+	fc.countSwitch(caches.RedisBackend)
+	fc.l.Info("cache switched from memory back to redis")
+}
+
+func (fc *FailoverCache) reportBackend(backend caches.Backend) {
+	if fc.metrics == nil {
+		return
+	}
+
+	for _, b := range []caches.Backend{caches.RedisBackend, caches.MemoryBackend} {
+		value := 0.0
+		if b == backend {
+			value = 1
+		}
+
+		fc.metrics.GetCacheBackend().WithLabelValues(string(b)).Set(value)
+	}
+}
+
+func (fc *FailoverCache) countSwitch(backend caches.Backend) {
+	if fc.metrics != nil {
+		fc.metrics.GetCacheBackendSwitches().WithLabelValues(string(backend)).Inc()
+	}
+}
@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"bannersrv/internal/caches"
+	"bannersrv/internal/caches/repository"
+	"bannersrv/internal/pkg/metrics"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/lru"
+	"context"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const memoryTier = "memory"
+
+// MemoryCache кэш в памяти процесса, заменяющий Redis. Записи индекса хранятся в одной группе LRU кэша,
+// поэтому удаляются вместе с ним. Кэш не разделяется между репликами: сброс кэша на одной реплике
+// не затрагивает остальные, на них записи доживают до своего времени свежести.
+type MemoryCache struct {
+	local   *lru.Cache[types.Content]
+	metrics metrics.Manager
+}
+
+func NewMemoryCache(cfg caches.MemoryConfig, metricsManager metrics.Manager) *MemoryCache {
+	mc := &MemoryCache{metrics: metricsManager}
+
+	mc.local = lru.New[types.Content](cfg.MaxEntries, cfg.MaxBytes, func(reason lru.EvictReason) {
+		if mc.metrics != nil {
+			mc.metrics.GetCacheEvictions().WithLabelValues(memoryTier, string(reason)).Inc()
+		}
+	})
+
+	return mc
+}
+
+func (mc *MemoryCache) HaveCache(_ context.Context, key string) (types.Content, error) {
+	content, ok := mc.local.Get(key)
+	if !ok {
+		mc.countHit("miss")
+
+		return "", errors.Wrapf(repository.ErrorCacheMiss,
+			"error when try get cache with key: %s", key)
+	}
+
+	mc.countHit("hit")
+
+	return content, nil
+}
+
+// HaveCacheWithTTL возвращает запись вместе с оставшимся временем её жизни. Запрос используется только
+// для проверки кэша админом, поэтому не учитывается в метриках попаданий.
+func (mc *MemoryCache) HaveCacheWithTTL(_ context.Context, key string) (types.Content, time.Duration, error) {
+	content, ttl, ok := mc.local.GetWithTTL(key)
+	if !ok {
+		return "", 0, errors.Wrapf(repository.ErrorCacheMiss,
+			"error when try get cache with key: %s", key)
+	}
+
+	return content, ttl, nil
+}
+
+func (mc *MemoryCache) SetCache(_ context.Context, key string, content types.Content,
+	ttl time.Duration, index string,
+) error {
+	mc.local.Set(key, index, content, uint64(len(content)), ttl)
+	mc.reportSize()
+
+	return nil
+}
+
+func (mc *MemoryCache) DeleteIndexedCache(_ context.Context, indexes ...string) error {
+	mc.local.RemoveGroups(indexes...)
+	mc.reportSize()
+
+	return nil
+}
+
+func (mc *MemoryCache) GetIndexes(_ context.Context, pattern string) ([]string, error) {
+	indexes := make([]string, 0)
+
+	for _, group := range mc.local.Groups() {
+		matched, err := path.Match(pattern, group)
+		if err != nil {
+			return nil, errors.Wrapf(err, "incorrect index pattern %s", pattern)
+		}
+
+		if matched {
+			indexes = append(indexes, group)
+		}
+	}
+
+	return indexes, nil
+}
+
+// Purge удаляет все записи
+func (mc *MemoryCache) Purge() {
+	mc.local.Purge()
+	mc.reportSize()
+}
+
+func (mc *MemoryCache) countHit(result string) {
+	if mc.metrics != nil {
+		mc.metrics.GetCacheHits().WithLabelValues(memoryTier, result).Inc()
+	}
+}
+
+func (mc *MemoryCache) reportSize() {
+	if mc.metrics == nil {
+		return
+	}
+
+	entries, bytes := mc.local.Len()
+	mc.metrics.GetCacheSize().WithLabelValues(memoryTier, "entries").Set(float64(entries))
+	mc.metrics.GetCacheSize().WithLabelValues(memoryTier, "bytes").Set(float64(bytes))
+}
@@ -10,17 +10,46 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// scanCount число ключей, просматриваемых Redis за один вызов SCAN
+const scanCount = 1000
+
+// deleteIndexed удаляет записи из индексов KEYS и сами индексы и возвращает ключи удалённых записей.
+// Записи удаляются частями, чтобы не превысить ограничение Lua на число аргументов unpack.
+var deleteIndexed = redis.NewScript(`
+local deleted = {}
+for _, index in ipairs(KEYS) do
+	local keys = redis.call('SMEMBERS', index)
+	for i = 1, #keys, 1000 do
+		redis.call('DEL', unpack(keys, i, math.min(i + 999, #keys)))
+	end
+	for _, key in ipairs(keys) do
+		table.insert(deleted, key)
+	end
+	redis.call('DEL', index)
+end
+return deleted
+`)
+
 type CashRedis struct {
 	client *redis.Client
-	ctx    context.Context
 }
 
 func NewCashRedis(client *redis.Client) *CashRedis {
-	return &CashRedis{client: client, ctx: context.Background()}
+	return &CashRedis{client: client}
 }
 
-func (cr *CashRedis) SetCache(key string, content types.Content, ttl time.Duration) error {
-	if err := cr.client.Set(cr.ctx, key, string(content), ttl).Err(); err != nil {
+// SetCache сохраняет запись и добавляет её в индекс. Индекс живёт не меньше самой долгой из его записей.
+func (cr *CashRedis) SetCache(ctx context.Context, key string, content types.Content,
+	ttl time.Duration, index string,
+) error {
+	if _, err := cr.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, string(content), ttl)
+		pipe.SAdd(ctx, index, key)
+		pipe.ExpireNX(ctx, index, ttl)
+		pipe.ExpireGT(ctx, index, ttl)
+
+		return nil
+	}); err != nil {
 		return errors.Wrapf(err,
 			"error when try save in cache with key: %s", key)
 	}
@@ -28,9 +57,9 @@ func (cr *CashRedis) SetCache(key string, content types.Content, ttl time.Durati
 	return nil
 }
 
-func (cr *CashRedis) HaveCache(key string) (types.Content, error) {
+func (cr *CashRedis) HaveCache(ctx context.Context, key string) (types.Content, error) {
 	var content string
-	if err := cr.client.Get(cr.ctx, key).Scan(&content); err != nil {
+	if err := cr.client.Get(ctx, key).Scan(&content); err != nil {
 		if errors.Is(err, redis.Nil) {
 			err = repository.ErrorCacheMiss
 		}
@@ -41,3 +70,71 @@ func (cr *CashRedis) HaveCache(key string) (types.Content, error) {
 
 	return types.Content(content), nil
 }
+
+// HaveCacheWithTTL возвращает запись вместе с оставшимся временем её жизни
+func (cr *CashRedis) HaveCacheWithTTL(ctx context.Context, key string) (types.Content, time.Duration, error) {
+	var get *redis.StringCmd
+
+	var ttl *redis.DurationCmd
+
+	if _, err := cr.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		ttl = pipe.PTTL(ctx, key)
+
+		return nil
+	}); err != nil && !errors.Is(err, redis.Nil) {
+		return "", 0, errors.Wrapf(err,
+			"error when try get cache with key: %s", key)
+	}
+
+	content, err := get.Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			err = repository.ErrorCacheMiss
+		}
+
+		return "", 0, errors.Wrapf(err,
+			"error when try get cache with key: %s", key)
+	}
+
+	return types.Content(content), ttl.Val(), nil
+}
+
+func (cr *CashRedis) DeleteIndexedCache(ctx context.Context, indexes ...string) error {
+	_, err := cr.DeleteIndexedCacheKeys(ctx, indexes...)
+
+	return err
+}
+
+// DeleteIndexedCacheKeys удаляет записи индексов вместе с индексами и возвращает ключи удалённых записей
+func (cr *CashRedis) DeleteIndexedCacheKeys(ctx context.Context, indexes ...string) ([]string, error) {
+	keys, err := deleteIndexed.Run(ctx, cr.client, indexes).StringSlice()
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"error when try delete cache with indexes: %v", indexes)
+	}
+
+	return keys, nil
+}
+
+// GetIndexes возвращает индексы по шаблону, просматривая ключи с помощью SCAN, чтобы не блокировать Redis.
+// SCAN может вернуть один ключ несколько раз, поэтому повторы отбрасываются.
+func (cr *CashRedis) GetIndexes(ctx context.Context, pattern string) ([]string, error) {
+	indexes := make([]string, 0)
+	seen := make(map[string]struct{})
+
+	iter := cr.client.Scan(ctx, 0, pattern, scanCount).Iterator()
+	for iter.Next(ctx) {
+		if _, ok := seen[iter.Val()]; !ok {
+			seen[iter.Val()] = struct{}{}
+			indexes = append(indexes, iter.Val())
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, errors.Wrapf(err,
+			"error when try get cache indexes with pattern: %s", pattern)
+	}
+
+	return indexes, nil
+}
@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"bannersrv/internal/caches"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// unionTTL время жизни временного объединения периодов на случай, если его не удалось удалить
+const unionTTL = time.Minute
+
+// PopularityRedis хранит число запросов по ключам в отсортированных множествах, по одному на каждый период.
+// Устаревшие периоды удаляются самим Redis по истечении окна учёта.
+type PopularityRedis struct {
+	client   *redis.Client
+	window   time.Duration
+	bucket   time.Duration
+	maxPairs int64
+}
+
+func NewPopularityRedis(client *redis.Client, cfg caches.PopularityConfig) *PopularityRedis {
+	return &PopularityRedis{
+		client:   client,
+		window:   time.Duration(cfg.Window) * time.Millisecond,
+		bucket:   time.Duration(cfg.Bucket) * time.Millisecond,
+		maxPairs: int64(cfg.MaxPairs),
+	}
+}
+
+func (pr *PopularityRedis) bucketKey(t time.Time) string {
+	return fmt.Sprintf("popularity-%d", t.UnixMilli()/pr.bucket.Milliseconds())
+}
+
+// IncrPopularity добавляет запросы в счётчики текущего периода и оставляет в нём только maxPairs самых
+// запрашиваемых ключей
+func (pr *PopularityRedis) IncrPopularity(ctx context.Context, counts map[string]uint64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	key := pr.bucketKey(time.Now())
+
+	if _, err := pr.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for member, count := range counts {
+			pipe.ZIncrBy(ctx, key, float64(count), member)
+		}
+
+		pipe.Expire(ctx, key, pr.window+pr.bucket)
+		pipe.ZRemRangeByRank(ctx, key, 0, -pr.maxPairs-1)
+
+		return nil
+	}); err != nil {
+		return errors.Wrapf(err,
+			"error when try increment popularity with key: %s", key)
+	}
+
+	return nil
+}
+
+// GetPopular объединяет счётчики всех периодов окна учёта и возвращает ключи с наибольшим числом запросов
+func (pr *PopularityRedis) GetPopular(ctx context.Context, limit uint64) ([]string, error) {
+	if limit == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+
+	keys := make([]string, 0, pr.window/pr.bucket+1)
+	for t := now.Add(-pr.window); !t.After(now); t = t.Add(pr.bucket) {
+		keys = append(keys, pr.bucketKey(t))
+	}
+
+	union := "popularity-union-" + uuid.New().String()
+
+	var popular *redis.StringSliceCmd
+
+	if _, err := pr.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZUnionStore(ctx, union, &redis.ZStore{Keys: keys})
+		pipe.Expire(ctx, union, unionTTL)
+		popular = pipe.ZRevRange(ctx, union, 0, int64(limit)-1)
+		pipe.Del(ctx, union)
+
+		return nil
+	}); err != nil {
+		return nil, errors.Wrapf(err,
+			"error when try get popularity with keys: %v", keys)
+	}
+
+	return popular.Val(), nil
+}
@@ -0,0 +1,268 @@
+package tiered
+
+import (
+	"bannersrv/internal/caches"
+	"bannersrv/internal/caches/repository"
+	"bannersrv/internal/pkg/metrics"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/pkg/logger"
+	"bannersrv/pkg/lru"
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	localTier  = "local"
+	remoteTier = "redis"
+
+	hitResult  = "hit"
+	missResult = "miss"
+
+	// listenRetryDelay задержка перед повторным получением сообщений после ошибки подписки
+	listenRetryDelay = time.Second
+)
+
+// Remote кэш второго уровня, общий для всех реплик
+type Remote interface {
+	caches.Repository
+	// DeleteIndexedCacheKeys удаляет записи индексов вместе с индексами и возвращает ключи удалённых записей
+	DeleteIndexedCacheKeys(ctx context.Context, indexes ...string) ([]string, error)
+}
+
+// invalidation сообщение об изменении кэша репликой Origin, по которому остальные реплики
+// удаляют из памяти записи Keys и все записи индексов Indexes
+type invalidation struct {
+	Origin  string   `json:"origin"`
+	Keys    []string `json:"keys,omitempty"`
+	Indexes []string `json:"indexes,omitempty"`
+}
+
+// TieredCache кэш в памяти процесса перед кэшем в Redis. Изменения кэша рассылаются остальным
+// репликам через Redis pub/sub. Пока реплика не подписана на канал, кэш в памяти не используется,
+// так как пропущенные сообщения могли бы оставить в нём устаревшие записи.
+type TieredCache struct {
+	remote  Remote
+	client  *redis.Client
+	local   *lru.Cache[types.Content]
+	ttl     time.Duration
+	channel string
+	origin  string
+
+	subscribed atomic.Bool
+	// generation увеличивается при каждом удалении записей из памяти, чтобы не сохранять в память
+	// записи, прочитанные из Redis до удаления
+	generation atomic.Uint64
+
+	metrics metrics.Manager
+	l       logger.Interface
+}
+
+func NewTieredCache(remote Remote, client *redis.Client, cfg caches.LocalConfig,
+	metricsManager metrics.Manager, l logger.Interface,
+) *TieredCache {
+	tc := &TieredCache{
+		remote:  remote,
+		client:  client,
+		ttl:     time.Duration(cfg.TTL) * time.Millisecond,
+		channel: cfg.Channel,
+		origin:  uuid.New().String(),
+		metrics: metricsManager,
+		l:       l,
+	}
+
+	tc.local = lru.New[types.Content](cfg.MaxEntries, cfg.MaxBytes, func(reason lru.EvictReason) {
+		if tc.metrics != nil {
+			tc.metrics.GetCacheEvictions().WithLabelValues(localTier, string(reason)).Inc()
+		}
+	})
+
+	return tc
+}
+
+func (tc *TieredCache) HaveCache(ctx context.Context, key string) (types.Content, error) {
+	subscribed := tc.subscribed.Load()
+
+	if subscribed {
+		if content, ok := tc.local.Get(key); ok {
+			tc.countHit(localTier, hitResult)
+
+			return content, nil
+		}
+
+		tc.countHit(localTier, missResult)
+	}
+
+	generation := tc.generation.Load()
+
+	content, ttl, err := tc.remote.HaveCacheWithTTL(ctx, key)
+	if err != nil {
+		if errors.Is(err, repository.ErrorCacheMiss) {
+			tc.countHit(remoteTier, missResult)
+		}
+
+		return "", err
+	}
+
+	tc.countHit(remoteTier, hitResult)
+
+	if subscribed && tc.generation.Load() == generation {
+		tc.setLocal(key, "", content, ttl)
+	}
+
+	return content, nil
+}
+
+// HaveCacheWithTTL возвращает запись из Redis, минуя память, так как время жизни записи определяется Redis
+func (tc *TieredCache) HaveCacheWithTTL(ctx context.Context, key string) (types.Content, time.Duration, error) {
+	return tc.remote.HaveCacheWithTTL(ctx, key)
+}
+
+// SetCache сохраняет запись в Redis и в память, остальные реплики удаляют прежнюю запись из памяти
+func (tc *TieredCache) SetCache(ctx context.Context, key string, content types.Content,
+	ttl time.Duration, index string,
+) error {
+	if err := tc.remote.SetCache(ctx, key, content, ttl, index); err != nil {
+		return err
+	}
+
+	if tc.subscribed.Load() {
+		tc.setLocal(key, index, content, ttl)
+	}
+
+	return tc.publish(ctx, &invalidation{Keys: []string{key}})
+}
+
+// DeleteIndexedCache удаляет записи индексов из Redis и из памяти всех реплик. Записи, прочитанные из Redis,
+// не привязаны к индексу в памяти, поэтому удаляются по ключам, которые были в индексе в Redis.
+func (tc *TieredCache) DeleteIndexedCache(ctx context.Context, indexes ...string) error {
+	tc.removeLocal(func() { tc.local.RemoveGroups(indexes...) })
+
+	keys, err := tc.remote.DeleteIndexedCacheKeys(ctx, indexes...)
+	if err != nil {
+		return err
+	}
+
+	tc.removeLocal(func() { tc.local.Remove(keys...) })
+
+	return tc.publish(ctx, &invalidation{Keys: keys, Indexes: indexes})
+}
+
+// GetIndexes возвращает индексы из Redis. Записи в памяти всегда сохраняются и в Redis, поэтому их индексы
+// есть и в Redis.
+func (tc *TieredCache) GetIndexes(ctx context.Context, pattern string) ([]string, error) {
+	return tc.remote.GetIndexes(ctx, pattern)
+}
+
+// Listen применяет изменения кэша, полученные от других реплик, до отмены ctx.
+// При каждой подписке и ошибке получения сообщений кэш в памяти очищается, так как сообщения могли быть потеряны.
+func (tc *TieredCache) Listen(ctx context.Context) {
+	pubsub := tc.client.Subscribe(ctx, tc.channel)
+
+	defer func() {
+		tc.subscribed.Store(false)
+		_ = pubsub.Close() // nolint: errcheck // подписка больше не нужна, ошибка закрытия ни на что не влияет
+	}()
+
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			tc.subscribed.Store(false)
+			tc.removeLocal(func() { tc.local.Purge() })
+			tc.l.Error(errors.Wrapf(err, "can't receive cache invalidation from channel %s", tc.channel))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(listenRetryDelay):
+			}
+
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				tc.removeLocal(func() { tc.local.Purge() })
+				tc.subscribed.Store(true)
+				tc.l.Info("subscribed to cache invalidation channel %s", tc.channel)
+			}
+		case *redis.Message:
+			tc.apply(m.Payload)
+		}
+	}
+}
+
+func (tc *TieredCache) apply(payload string) {
+	var msg invalidation
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		tc.l.Error(errors.Wrapf(err, "can't parse cache invalidation %s", payload))
+
+		return
+	}
+
+	if msg.Origin == tc.origin {
+		return
+	}
+
+	tc.removeLocal(func() {
+		tc.local.Remove(msg.Keys...)
+		tc.local.RemoveGroups(msg.Indexes...)
+	})
+}
+
+func (tc *TieredCache) publish(ctx context.Context, msg *invalidation) error {
+	msg.Origin = tc.origin
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Wrap(err, "can't marshal cache invalidation")
+	}
+
+	if err := tc.client.Publish(ctx, tc.channel, payload).Err(); err != nil {
+		return errors.Wrapf(err, "can't publish cache invalidation to channel %s", tc.channel)
+	}
+
+	return nil
+}
+
+// setLocal сохраняет запись в память не дольше ttl записи в Redis
+func (tc *TieredCache) setLocal(key, index string, content types.Content, ttl time.Duration) {
+	if ttl <= 0 || ttl > tc.ttl {
+		ttl = tc.ttl
+	}
+
+	tc.local.Set(key, index, content, uint64(len(content)), ttl)
+	tc.reportSize()
+}
+
+func (tc *TieredCache) removeLocal(remove func()) {
+	tc.generation.Add(1)
+	remove()
+	tc.reportSize()
+}
+
+func (tc *TieredCache) countHit(tier, result string) {
+	if tc.metrics != nil {
+		tc.metrics.GetCacheHits().WithLabelValues(tier, result).Inc()
+	}
+}
+
+func (tc *TieredCache) reportSize() {
+	if tc.metrics == nil {
+		return
+	}
+
+	entries, bytes := tc.local.Len()
+	tc.metrics.GetCacheSize().WithLabelValues(localTier, "entries").Set(float64(entries))
+	tc.metrics.GetCacheSize().WithLabelValues(localTier, "bytes").Set(float64(bytes))
+}
@@ -0,0 +1,30 @@
+package caches
+
+import (
+	"bannersrv/internal/pkg/types"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TagSet возвращает тэги пользователя по возрастанию без повторов, чтобы запросы с одними и теми же тэгами
+// в любом порядке использовали одну запись кэша
+func TagSet(tagIDs []types.ID) []types.ID {
+	set := slices.Clone(tagIDs)
+	slices.Sort(set)
+
+	return slices.Compact(set)
+}
+
+// TagSetKey часть ключа кэша с тэгами пользователя: тэги по возрастанию через запятую.
+// Ключ с одним тэгом совпадает с ключом пары фича-тэг.
+func TagSetKey(tagIDs []types.ID) string {
+	set := TagSet(tagIDs)
+
+	parts := make([]string, len(set))
+	for i, tagID := range set {
+		parts[i] = strconv.FormatUint(uint64(tagID), 10)
+	}
+
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,80 @@
+package access
+
+import (
+	"bannersrv/internal/pkg/types"
+	"context"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrorFeatureForbidden = errors.New("admin has no access to feature")
+	ErrorReadOnly         = errors.New("admin has read-only access")
+	ErrorFullAccess       = errors.New("action requires admin access to all features")
+)
+
+type contextKey string
+
+const scopeKey contextKey = "access_scope"
+
+// Scope права админа на баннеры. FeatureIDs равный nil даёт доступ ко всем фичам,
+// а ReadOnly запрещает любые изменения. Отсутствие Scope в контексте не ограничивает доступ.
+type Scope struct {
+	ReadOnly   bool
+	FeatureIDs []types.ID
+}
+
+// Restricted возвращает true, если доступ ограничен списком фич
+func (s *Scope) Restricted() bool {
+	return s != nil && s.FeatureIDs != nil
+}
+
+// CheckRead проверяет доступ на просмотр баннеров фич featureIDs
+func (s *Scope) CheckRead(featureIDs ...types.ID) error {
+	if !s.Restricted() {
+		return nil
+	}
+
+	for _, featureID := range featureIDs {
+		if !slices.Contains(s.FeatureIDs, featureID) {
+			return errors.Wrapf(ErrorFeatureForbidden, "feature id %d", featureID)
+		}
+	}
+
+	return nil
+}
+
+// CheckWrite проверяет доступ на изменение баннеров фич featureIDs
+func (s *Scope) CheckWrite(featureIDs ...types.ID) error {
+	if s != nil && s.ReadOnly {
+		return ErrorReadOnly
+	}
+
+	return s.CheckRead(featureIDs...)
+}
+
+// CheckFull проверяет, что админ может изменять баннеры всех фич
+func (s *Scope) CheckFull() error {
+	if err := s.CheckWrite(); err != nil {
+		return err
+	}
+
+	if s.Restricted() {
+		return ErrorFullAccess
+	}
+
+	return nil
+}
+
+// WithScope сохраняет в контекст права админа, выполняющего запрос
+func WithScope(ctx context.Context, scope *Scope) context.Context {
+	return context.WithValue(ctx, scopeKey, scope)
+}
+
+// FromContext возвращает права админа из контекста или nil, если они не заданы
+func FromContext(ctx context.Context) *Scope {
+	scope, _ := ctx.Value(scopeKey).(*Scope)
+
+	return scope
+}
@@ -0,0 +1,34 @@
+package audit
+
+import "context"
+
+type contextKey string
+
+const (
+	actorKey     contextKey = "audit_actor"
+	requestIDKey contextKey = "audit_request_id"
+)
+
+// WithActor сохраняет в контекст идентификатор субъекта, выполняющего запрос
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// WithRequestID сохраняет в контекст идентификатор запроса
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// Actor возвращает идентификатор субъекта из контекста или пустую строку, если он не задан
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey).(string)
+
+	return actor
+}
+
+// RequestID возвращает идентификатор запроса из контекста или пустую строку, если он не задан
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+
+	return requestID
+}
@@ -0,0 +1,48 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// unlock снимает блокировку KEYS[1], только если она всё ещё принадлежит владельцу токена ARGV[1],
+// чтобы не снять блокировку, захваченную другой репликой после истечения её срока
+var unlock = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisLocker короткие блокировки, общие для всех реплик сервиса
+type RedisLocker struct {
+	client *redis.Client
+}
+
+func NewRedisLocker(client *redis.Client) *RedisLocker {
+	return &RedisLocker{client: client}
+}
+
+// TryLock захватывает свободную блокировку key на ttl и возвращает токен для её снятия
+func (rl *RedisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	token := uuid.New().String()
+
+	locked, err := rl.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return "", false, errors.Wrapf(err, "can't lock %s", key)
+	}
+
+	return token, locked, nil
+}
+
+func (rl *RedisLocker) Unlock(ctx context.Context, key, token string) error {
+	if err := unlock.Run(ctx, rl.client, []string{key}, token).Err(); err != nil {
+		return errors.Wrapf(err, "can't unlock %s", key)
+	}
+
+	return nil
+}
@@ -8,4 +8,12 @@ type Manager interface {
 	GetErrorHits() *prometheus.CounterVec
 	GetRequestCounter() prometheus.Counter
 	GetExecution() *prometheus.HistogramVec
+	GetRateLimitHits() *prometheus.CounterVec
+	GetCacheHits() *prometheus.CounterVec
+	GetCacheEvictions() *prometheus.CounterVec
+	GetCacheSize() *prometheus.GaugeVec
+	GetCacheBackend() *prometheus.GaugeVec
+	GetCacheBackendSwitches() *prometheus.CounterVec
+	GetCacheLookups() *prometheus.CounterVec
+	GetCacheDurations() *prometheus.HistogramVec
 }
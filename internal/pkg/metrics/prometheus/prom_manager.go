@@ -11,6 +11,14 @@ type MetricsManager struct {
 	HitsErrors    *prometheus.CounterVec
 	ExecutionTime *prometheus.HistogramVec
 	TotalHits     prometheus.Counter
+	RateLimitHits *prometheus.CounterVec
+	CacheHits     *prometheus.CounterVec
+	CacheEvicts   *prometheus.CounterVec
+	CacheSize     *prometheus.GaugeVec
+	CacheBackend  *prometheus.GaugeVec
+	CacheSwitches *prometheus.CounterVec
+	CacheLookups  *prometheus.CounterVec
+	CacheDuration *prometheus.HistogramVec
 }
 
 func NewPrometheusMetrics(serviceName string) *MetricsManager {
@@ -34,6 +42,39 @@ func NewPrometheusMetrics(serviceName string) *MetricsManager {
 		TotalHits: prometheus.NewCounter(prometheus.CounterOpts{
 			Name: serviceName + "_total_hits",
 		}),
+		RateLimitHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: serviceName + "_rate_limit_hits",
+			Help: "Count requests rejected by rate limiter",
+		}, []string{"group", "limit", "path"}),
+		CacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: serviceName + "_cache_hits",
+			Help: "Count cache lookups by tier and result",
+		}, []string{"tier", "result"}),
+		CacheEvicts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: serviceName + "_cache_evictions",
+			Help: "Count entries evicted from cache by tier and reason",
+		}, []string{"tier", "reason"}),
+		CacheSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: serviceName + "_cache_size",
+			Help: "Size of cache by tier in entries and bytes",
+		}, []string{"tier", "unit"}),
+		CacheBackend: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: serviceName + "_cache_backend",
+			Help: "Active cache backend, 1 for the backend in use and 0 for the others",
+		}, []string{"backend"}),
+		CacheSwitches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: serviceName + "_cache_backend_switches",
+			Help: "Count switches of cache backend by target backend",
+		}, []string{"backend"}),
+		CacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: serviceName + "_cache_lookups",
+			Help: "Count user banner requests by cache outcome and by versioned or unversioned lookup",
+		}, []string{"outcome", "lookup"}),
+		CacheDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    serviceName + "_cache_durations",
+			Help:    "Duration of cache operations by operation, outcome and versioned or unversioned lookup",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"operation", "outcome", "lookup"}),
 	}
 
 	return metrics
@@ -52,6 +93,38 @@ func (mm *MetricsManager) SetupMonitoring() error {
 		return err
 	}
 
+	if err := prometheus.Register(mm.RateLimitHits); err != nil {
+		return err
+	}
+
+	if err := prometheus.Register(mm.CacheHits); err != nil {
+		return err
+	}
+
+	if err := prometheus.Register(mm.CacheEvicts); err != nil {
+		return err
+	}
+
+	if err := prometheus.Register(mm.CacheSize); err != nil {
+		return err
+	}
+
+	if err := prometheus.Register(mm.CacheBackend); err != nil {
+		return err
+	}
+
+	if err := prometheus.Register(mm.CacheSwitches); err != nil {
+		return err
+	}
+
+	if err := prometheus.Register(mm.CacheLookups); err != nil {
+		return err
+	}
+
+	if err := prometheus.Register(mm.CacheDuration); err != nil {
+		return err
+	}
+
 	return prometheus.Register(mm.TotalHits)
 }
 
@@ -70,3 +143,35 @@ func (mm *MetricsManager) GetRequestCounter() prometheus.Counter {
 func (mm *MetricsManager) GetExecution() *prometheus.HistogramVec {
 	return mm.ExecutionTime
 }
+
+func (mm *MetricsManager) GetRateLimitHits() *prometheus.CounterVec {
+	return mm.RateLimitHits
+}
+
+func (mm *MetricsManager) GetCacheHits() *prometheus.CounterVec {
+	return mm.CacheHits
+}
+
+func (mm *MetricsManager) GetCacheEvictions() *prometheus.CounterVec {
+	return mm.CacheEvicts
+}
+
+func (mm *MetricsManager) GetCacheSize() *prometheus.GaugeVec {
+	return mm.CacheSize
+}
+
+func (mm *MetricsManager) GetCacheBackend() *prometheus.GaugeVec {
+	return mm.CacheBackend
+}
+
+func (mm *MetricsManager) GetCacheBackendSwitches() *prometheus.CounterVec {
+	return mm.CacheSwitches
+}
+
+func (mm *MetricsManager) GetCacheLookups() *prometheus.CounterVec {
+	return mm.CacheLookups
+}
+
+func (mm *MetricsManager) GetCacheDurations() *prometheus.HistogramVec {
+	return mm.CacheDuration
+}
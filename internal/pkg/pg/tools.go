@@ -8,14 +8,15 @@ import (
 	"github.com/pkg/errors"
 )
 
-func WithTransaction(db *pgxpool.Pool, transaction func(tx pgx.Tx) error) error {
-	tx, err := db.Begin(context.Background())
+func WithTransaction(ctx context.Context, db *pgxpool.Pool, transaction func(tx pgx.Tx) error) error {
+	tx, err := db.Begin(ctx)
 	if err != nil {
 		return errors.Wrap(err, "can't begin transaction")
 	}
 
 	if err := transaction(tx); err != nil {
-		errRollback := tx.Rollback(context.Background())
+		// Откат выполняется без учёта отмены контекста, иначе соединение будет закрыто вместо возврата в пул
+		errRollback := tx.Rollback(context.WithoutCancel(ctx))
 		if errRollback != nil {
 			return errors.Wrapf(err, "can't rollback with error %s", errRollback)
 		}
@@ -23,7 +24,7 @@ func WithTransaction(db *pgxpool.Pool, transaction func(tx pgx.Tx) error) error
 		return err
 	}
 
-	if err := tx.Commit(context.Background()); err != nil {
+	if err := tx.Commit(ctx); err != nil {
 		return errors.Wrapf(err, "can't commit transaction")
 	}
 
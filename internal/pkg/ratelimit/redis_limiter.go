@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucket атомарно списывает токен из корзины key ёмкостью ARGV[1], которая полностью
+// наполняется за ARGV[2] миллисекунд. Время берётся из Redis, чтобы расхождение часов реплик
+// не влияло на лимит. Возвращает 1 и 0, если запрос разрешён, иначе 0 и время в миллисекундах,
+// через которое в корзине появится токен.
+var tokenBucket = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local rate = capacity / period
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or capacity
+local ts = tonumber(bucket[2]) or now
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], period)
+
+return {allowed, retry}
+`)
+
+// RedisLimiter ограничивает частоту запросов алгоритмом token bucket,
+// состояние корзин хранится в Redis и общее для всех реплик сервиса
+type RedisLimiter struct {
+	client *redis.Client
+}
+
+func NewRedisLimiter(client *redis.Client) *RedisLimiter {
+	return &RedisLimiter{client: client}
+}
+
+// Allow списывает запрос из корзины key, разрешающей limit запросов за period.
+// Если запрос не разрешён, то возвращает время, через которое стоит повторить запрос.
+func (rl *RedisLimiter) Allow(ctx context.Context, key string, limit uint64,
+	period time.Duration,
+) (bool, time.Duration, error) {
+	res, err := tokenBucket.Run(ctx, rl.client, []string{key}, limit, period.Milliseconds()).Int64Slice()
+	if err != nil {
+		return false, 0, errors.Wrapf(err, "can't check rate limit with key: %s", key)
+	}
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
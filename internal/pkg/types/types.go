@@ -2,6 +2,7 @@ package types
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"math"
 	"strconv"
 
@@ -47,6 +48,45 @@ func ObjectFromPointer[T any](object *T) *NullableObject[T] {
 	return NewObject(*object)
 }
 
+// Object поле запроса, отличающее отсутствующее поле от поля, явно переданного как null.
+// Present true, если поле передано, а Value nil, если передан null.
+type Object[T any] struct {
+	Present bool
+	Value   *T
+}
+
+func (object *Object[T]) UnmarshalJSON(data []byte) error {
+	object.Present = true
+
+	if string(data) == "null" {
+		object.Value = nil
+
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	object.Value = &value
+
+	return nil
+}
+
+func (object Object[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(object.Value)
+}
+
+// ToNullableObject возвращает изменение поля: пустое, если поле не передано, иначе переданное значение или nil
+func (object Object[T]) ToNullableObject() *NullableObject[*T] {
+	if !object.Present {
+		return NewNullObject[*T]()
+	}
+
+	return NewObject(object.Value)
+}
+
 type NullableID NullableObject[ID]
 
 func (object *NullableID) ToNullableSQL() *pgtype.Uint32 {
@@ -0,0 +1,30 @@
+package token
+
+// Config параметры подписи и проверки JWT токенов.
+// Для проверки подписи используется ключ из JWKSFile, если он задан, иначе Secret для HS256
+// и PublicKeyFile (или открытая часть PrivateKeyFile) для RS256.
+// Админ с ролью ReadOnlyRole может только просматривать баннеры, а клейм FeaturesClaim ограничивает
+// доступные админу фичи, при его отсутствии доступны все фичи.
+// Проверенные ключи доступа кэшируются на APIKeyCacheTTL, что ограничивает задержку их отзыва,
+// найденные ключи хранятся в кэше на APIKeyCacheSize записей, а ненайденные -- на APIKeyMissCacheSize.
+type Config struct {
+	Algorithm      string `yaml:"algorithm" default:"HS256"`
+	Secret         string `yaml:"secret" env:"TOKEN_SECRET"`
+	PublicKeyFile  string `yaml:"public_key_file"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+	JWKSFile       string `yaml:"jwks_file"`
+	KeyID          string `yaml:"key_id"`
+	Issuer         string `yaml:"issuer"`
+	Audience       string `yaml:"audience"`
+	RoleClaim      string `yaml:"role_claim" default:"role"`
+	AdminRole      string `yaml:"admin_role" default:"admin"`
+	ReadOnlyRole   string `yaml:"read_only_role" default:"admin_read_only"`
+	UserRole       string `yaml:"user_role" default:"user"`
+	FeaturesClaim  string `yaml:"features_claim" default:"features"`
+	TTL            uint64 `yaml:"ttl" default:"3600000"`
+
+	APIKeyCacheTTL      uint64 `yaml:"api_key_cache_ttl" default:"5000"`
+	APIKeyCacheSize     int    `yaml:"api_key_cache_size" default:"10000"`
+	APIKeyMissCacheSize int    `yaml:"api_key_miss_cache_size" default:"1000"`
+	APIKeyLookupTimeout uint64 `yaml:"api_key_lookup_timeout" default:"1000"`
+}
@@ -4,7 +4,10 @@ import (
 	"bannersrv/external/auth"
 	"bannersrv/internal/app/delivery/http/middleware"
 	"bannersrv/internal/app/delivery/http/tools"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/audit"
 	"bannersrv/internal/token"
+	"bannersrv/pkg/logger"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -16,23 +19,35 @@ func WithAdminToken(tokenService token.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		l := middleware.GetLogger(c)
 
-		tok := middleware.GetToken(c)
-
-		ok, err := tokenService.IsAdminToken(auth.Token(tok))
+		claims, err := tokenService.Parse(auth.Token(middleware.GetToken(c)))
 		if err != nil {
-			tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
-			l.Error(errors.Wrapf(err, "try check admin token %s", tok))
+			sendTokenError(c, err, l, "try check admin token")
 
 			return
 		}
 
-		if !ok {
+		if !claims.Admin {
 			tools.SendStatus(c, http.StatusForbidden, nil, l)
 
 			return
 		}
 
-		l.Info("handle request with admin permissions")
+		l = setSubject(c, claims.Subject, l)
+		scope := claims.Scope
+
+		// Админ с доступом только на чтение может выполнять только GET запросы,
+		// ограничения по фичам проверяются при обработке запроса
+		if scope.ReadOnly && c.Request.Method != http.MethodGet {
+			tools.SendErrorStatus(c, access.ErrorReadOnly, http.StatusForbidden, l)
+
+			return
+		}
+
+		ctx := audit.WithActor(c.Request.Context(), claims.Subject)
+		c.Request = c.Request.WithContext(access.WithScope(ctx, scope))
+
+		l.Info("handle request with admin permissions, read only %t, features %v",
+			scope.ReadOnly, scope.FeatureIDs)
 		c.Next()
 	}
 }
@@ -42,23 +57,42 @@ func WithUserToken(tokenService token.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		l := middleware.GetLogger(c)
 
-		tok := middleware.GetToken(c)
-
-		ok, err := tokenService.IsUserToken(auth.Token(tok))
+		claims, err := tokenService.Parse(auth.Token(middleware.GetToken(c)))
 		if err != nil {
-			tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
-			l.Error(errors.Wrapf(err, "try check user token %s", tok))
+			sendTokenError(c, err, l, "try check user token")
 
 			return
 		}
 
-		if !ok {
+		if !claims.User {
 			tools.SendStatus(c, http.StatusForbidden, nil, l)
 
 			return
 		}
 
-		l.Info("handle request with user permissions")
+		setSubject(c, claims.Subject, l).Info("handle request with user permissions")
 		c.Next()
 	}
 }
+
+// setSubject сохраняет владельца токена в контекст запроса и возвращает логгер запроса с ним
+func setSubject(c *gin.Context, subject string, l logger.Interface) logger.Interface {
+	l = l.With(middleware.Subject, subject)
+
+	c.Set(string(middleware.SubjectField), subject)
+	c.Set(string(middleware.LoggerField), l)
+
+	return l
+}
+
+func sendTokenError(c *gin.Context, err error, l logger.Interface, message string) {
+	if errors.Is(err, token.ErrorInvalidToken) {
+		tools.SendStatus(c, http.StatusUnauthorized, nil, l)
+		l.Warn(errors.Wrap(err, message))
+
+		return
+	}
+
+	tools.SendError(c, tools.ErrorServerError, http.StatusInternalServerError, l)
+	l.Error(errors.Wrap(err, message))
+}
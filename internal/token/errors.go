@@ -0,0 +1,11 @@
+package token
+
+import "github.com/pkg/errors"
+
+var (
+	ErrorInvalidToken       = errors.New("token is invalid")
+	ErrorUnsupportedMethod  = errors.New("signing method is not supported")
+	ErrorKeyNotConfigured   = errors.New("signing key is not configured")
+	ErrorUnknownKeyID       = errors.New("key with such id not found")
+	ErrorUnsupportedKeyType = errors.New("key type is not supported")
+)
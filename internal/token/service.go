@@ -2,9 +2,21 @@ package token
 
 import (
 	"bannersrv/external/auth"
+	"bannersrv/internal/pkg/access"
 )
 
+// Claims права проверенного токена
+type Claims struct {
+	// Admin true для админского токена, в том числе с доступом только на чтение
+	Admin bool
+	User  bool
+	// Subject идентификатор владельца токена
+	Subject string
+	// Scope права админского токена: список доступных фич и запрет на изменения, nil для остальных токенов
+	Scope *access.Scope
+}
+
 type Service interface {
-	IsAdminToken(token auth.Token) (bool, error)
-	IsUserToken(token auth.Token) (bool, error)
+	// Parse проверяет токен и возвращает его права, подпись токена проверяется один раз
+	Parse(token auth.Token) (*Claims, error)
 }
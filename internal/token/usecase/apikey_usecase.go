@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"bannersrv/external/auth"
+	"bannersrv/internal/apikey"
+	"bannersrv/internal/apikey/entity"
+	"bannersrv/internal/apikey/repository"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/token"
+	"bannersrv/pkg/lru"
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// APIKeyUsecase проверяет ключи доступа, выданные через /api_key.
+// Найденные и ненайденные ключи кэшируются на cacheTTL, чтобы проверка токена на каждом запросе
+// не обращалась к базе, поэтому отзыв ключа вступает в силу не позднее чем через cacheTTL.
+// Ненайденные ключи хранятся в отдельном кэше, чтобы перебор случайных ключей не вытеснял найденные,
+// а одновременные проверки одного ключа обращаются к базе один раз.
+// Истечение срока действия проверяется на каждом запросе.
+type APIKeyUsecase struct {
+	rep           apikey.Repository
+	cacheTTL      time.Duration
+	lookupTimeout time.Duration
+
+	keys   *lru.Cache[*entity.APIKey]
+	misses *lru.Cache[struct{}]
+	group  singleflight.Group
+}
+
+func NewAPIKeyUsecase(rep apikey.Repository, cfg *token.Config) *APIKeyUsecase {
+	return &APIKeyUsecase{
+		rep:           rep,
+		cacheTTL:      time.Duration(cfg.APIKeyCacheTTL) * time.Millisecond,
+		lookupTimeout: time.Duration(cfg.APIKeyLookupTimeout) * time.Millisecond,
+		keys:          lru.New[*entity.APIKey](uint64(cfg.APIKeyCacheSize), 0, nil),
+		misses:        lru.New[struct{}](uint64(cfg.APIKeyMissCacheSize), 0, nil),
+	}
+}
+
+// Parse проверяет ключ и возвращает права его владельца
+func (au *APIKeyUsecase) Parse(tok auth.Token) (*token.Claims, error) {
+	key, err := au.lookup(tok)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &token.Claims{
+		Admin:   key.Role == entity.RoleAdmin || key.Role == entity.RoleAdminReadOnly,
+		User:    key.Role == entity.RoleUser,
+		Subject: key.Owner,
+	}
+
+	if claims.Admin {
+		claims.Scope = &access.Scope{
+			ReadOnly:   key.Role != entity.RoleAdmin,
+			FeatureIDs: key.FeatureIDs,
+		}
+	}
+
+	return claims, nil
+}
+
+// lookup возвращает действующий ключ, при необходимости обновляя его из базы
+func (au *APIKeyUsecase) lookup(tok auth.Token) (*entity.APIKey, error) {
+	hash := string(apikey.HashKey(string(tok)))
+
+	key, ok := au.keys.Get(hash)
+	if !ok {
+		if _, missed := au.misses.Get(hash); missed {
+			return nil, errors.Wrap(token.ErrorInvalidToken, "api key not found")
+		}
+
+		loaded, err, _ := au.group.Do(hash, func() (any, error) {
+			return au.load(hash)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		key, _ = loaded.(*entity.APIKey)
+	}
+
+	if key == nil || !key.IsActive(time.Now()) {
+		return nil, errors.Wrap(token.ErrorInvalidToken, "api key not found, revoked or expired")
+	}
+
+	return key, nil
+}
+
+// load ищет ключ в базе и сохраняет результат в кэш найденных или ненайденных ключей
+func (au *APIKeyUsecase) load(hash string) (*entity.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), au.lookupTimeout)
+	defer cancel()
+
+	key, err := au.rep.GetAPIKeyByHash(ctx, []byte(hash))
+	if err != nil {
+		if errors.Is(err, repository.ErrorAPIKeyNotFound) {
+			au.misses.Set(hash, "", struct{}{}, 0, au.cacheTTL)
+
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "can't load api key")
+	}
+
+	au.keys.Set(hash, "", key, 0, au.cacheTTL)
+
+	return key, nil
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"bannersrv/external/auth"
+	"bannersrv/internal/apikey"
+	"bannersrv/internal/token"
+)
+
+// CompositeUsecase проверяет ключи доступа через apiKeys, а остальные токены как JWT
+type CompositeUsecase struct {
+	jwt     token.Service
+	apiKeys token.Service
+}
+
+func NewCompositeUsecase(jwt, apiKeys token.Service) *CompositeUsecase {
+	return &CompositeUsecase{
+		jwt:     jwt,
+		apiKeys: apiKeys,
+	}
+}
+
+func (cu *CompositeUsecase) Parse(tok auth.Token) (*token.Claims, error) {
+	return cu.service(tok).Parse(tok)
+}
+
+func (cu *CompositeUsecase) service(tok auth.Token) token.Service {
+	if apikey.IsKey(string(tok)) {
+		return cu.apiKeys
+	}
+
+	return cu.jwt
+}
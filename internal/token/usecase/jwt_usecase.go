@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"bannersrv/external/auth"
+	"bannersrv/internal/pkg/access"
+	"bannersrv/internal/pkg/types"
+	"bannersrv/internal/token"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+// JWTUsecase проверяет подписанные JWT токены: подпись, срок действия, издателя и аудиторию.
+// Права токена определяются по роли из клейма RoleClaim, который может быть строкой или массивом строк,
+// а доступные админу фичи по массиву идентификаторов из клейма FeaturesClaim.
+type JWTUsecase struct {
+	parser        *jwt.Parser
+	keys          keySet
+	roleClaim     string
+	adminRole     string
+	readOnlyRole  string
+	userRole      string
+	featuresClaim string
+}
+
+func NewJWTUsecase(cfg *token.Config) (*JWTUsecase, error) {
+	method, err := SigningMethod(cfg.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := loadVerificationKeys(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't load token verification keys")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{method.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &JWTUsecase{
+		parser:        jwt.NewParser(options...),
+		keys:          keys,
+		roleClaim:     cfg.RoleClaim,
+		adminRole:     cfg.AdminRole,
+		readOnlyRole:  cfg.ReadOnlyRole,
+		userRole:      cfg.UserRole,
+		featuresClaim: cfg.FeaturesClaim,
+	}, nil
+}
+
+// Parse проверяет токен и возвращает его права. Владелец токена берётся из клейма sub,
+// а админ без полной роли получает доступ только на чтение.
+func (ju *JWTUsecase) Parse(tok auth.Token) (*token.Claims, error) {
+	claims, err := ju.parse(tok)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, errors.Wrap(token.ErrorInvalidToken, "subject is empty")
+	}
+
+	roles := ju.roles(claims)
+
+	parsed := &token.Claims{
+		Admin:   slices.Contains(roles, ju.adminRole) || slices.Contains(roles, ju.readOnlyRole),
+		User:    slices.Contains(roles, ju.userRole),
+		Subject: subject,
+	}
+
+	if parsed.Admin {
+		if parsed.Scope, err = ju.scope(claims, roles); err != nil {
+			return nil, err
+		}
+	}
+
+	return parsed, nil
+}
+
+// scope возвращает права админа по его ролям и клейму FeaturesClaim
+func (ju *JWTUsecase) scope(claims jwt.MapClaims, roles []string) (*access.Scope, error) {
+	scope := &access.Scope{
+		ReadOnly: !slices.Contains(roles, ju.adminRole),
+	}
+
+	rawFeatures, ok := claims[ju.featuresClaim]
+	if !ok {
+		return scope, nil
+	}
+
+	features, ok := rawFeatures.([]any)
+	if !ok {
+		return nil, errors.Wrapf(token.ErrorInvalidToken, "claim %s is not an array", ju.featuresClaim)
+	}
+
+	scope.FeatureIDs = make([]types.ID, 0, len(features))
+
+	for _, feature := range features {
+		featureID, ok := feature.(float64)
+		if !ok || featureID < 0 || featureID != float64(types.ID(featureID)) {
+			return nil, errors.Wrapf(token.ErrorInvalidToken, "claim %s contains not an id %v", ju.featuresClaim, feature)
+		}
+
+		scope.FeatureIDs = append(scope.FeatureIDs, types.ID(featureID))
+	}
+
+	return scope, nil
+}
+
+func (ju *JWTUsecase) parse(tok auth.Token) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+
+	if _, err := ju.parser.ParseWithClaims(string(tok), claims, ju.keys.keyFunc); err != nil {
+		return nil, errors.Wrap(token.ErrorInvalidToken, err.Error())
+	}
+
+	return claims, nil
+}
+
+func (ju *JWTUsecase) roles(claims jwt.MapClaims) []string {
+	switch role := claims[ju.roleClaim].(type) {
+	case string:
+		return []string{role}
+	case []any:
+		roles := make([]string, 0, len(role))
+
+		for _, r := range role {
+			if rs, ok := r.(string); ok {
+				roles = append(roles, rs)
+			}
+		}
+
+		return roles
+	default:
+		return nil
+	}
+}
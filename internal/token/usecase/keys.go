@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"bannersrv/internal/token"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+const (
+	keyTypeRSA       = "RSA"
+	keyTypeSymmetric = "oct"
+	keyUseSignature  = "sig"
+)
+
+// jsonWebKey ключ из JWKS файла, поддерживаются RSA ключи для RS256 и симметричные ключи для HS256
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	K         string `json:"k"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// keySet ключи проверки подписи по их идентификаторам. Ключ с пустым идентификатором используется
+// для токенов без заголовка kid, а также если ключ в наборе единственный.
+type keySet map[string]any
+
+// SigningMethod возвращает метод подписи для алгоритма из конфигурации
+func SigningMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		return jwt.SigningMethodHS256, nil
+	case jwt.SigningMethodRS256.Alg():
+		return jwt.SigningMethodRS256, nil
+	default:
+		return nil, errors.Wrapf(token.ErrorUnsupportedMethod, "algorithm %s", algorithm)
+	}
+}
+
+// LoadSigningKey возвращает ключ для подписи токенов: секрет для HS256 или закрытый ключ для RS256
+func LoadSigningKey(cfg *token.Config) (any, error) {
+	switch cfg.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if cfg.Secret == "" {
+			return nil, errors.Wrap(token.ErrorKeyNotConfigured, "secret is empty")
+		}
+
+		return []byte(cfg.Secret), nil
+	case jwt.SigningMethodRS256.Alg():
+		if cfg.PrivateKeyFile == "" {
+			return nil, errors.Wrap(token.ErrorKeyNotConfigured, "private key file is empty")
+		}
+
+		return loadPrivateKey(cfg.PrivateKeyFile)
+	default:
+		return nil, errors.Wrapf(token.ErrorUnsupportedMethod, "algorithm %s", cfg.Algorithm)
+	}
+}
+
+func loadVerificationKeys(cfg *token.Config) (keySet, error) {
+	if cfg.JWKSFile != "" {
+		return loadJWKS(cfg.JWKSFile, cfg.Algorithm)
+	}
+
+	switch cfg.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if cfg.Secret == "" {
+			return nil, errors.Wrap(token.ErrorKeyNotConfigured, "secret is empty")
+		}
+
+		return keySet{cfg.KeyID: []byte(cfg.Secret)}, nil
+	case jwt.SigningMethodRS256.Alg():
+		if cfg.PublicKeyFile != "" {
+			key, err := loadPublicKey(cfg.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+
+			return keySet{cfg.KeyID: key}, nil
+		}
+
+		if cfg.PrivateKeyFile != "" {
+			key, err := loadPrivateKey(cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+
+			return keySet{cfg.KeyID: &key.PublicKey}, nil
+		}
+
+		return nil, errors.Wrap(token.ErrorKeyNotConfigured, "public key file is empty")
+	default:
+		return nil, errors.Wrapf(token.ErrorUnsupportedMethod, "algorithm %s", cfg.Algorithm)
+	}
+}
+
+func loadPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read private key file %s", path)
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't parse private key file %s", path)
+	}
+
+	return key, nil
+}
+
+func loadPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read public key file %s", path)
+	}
+
+	key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't parse public key file %s", path)
+	}
+
+	return key, nil
+}
+
+// loadJWKS загружает ключи проверки подписи из JWKS файла, пропуская ключи других алгоритмов и назначений
+func loadJWKS(path string, algorithm string) (keySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read jwks file %s", path)
+	}
+
+	var jwks jsonWebKeySet
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, errors.Wrapf(err, "can't parse jwks file %s", path)
+	}
+
+	keys := make(keySet, len(jwks.Keys))
+
+	for _, jwk := range jwks.Keys {
+		if (jwk.Use != "" && jwk.Use != keyUseSignature) || (jwk.Algorithm != "" && jwk.Algorithm != algorithm) {
+			continue
+		}
+
+		key, err := jwk.verificationKey(algorithm)
+		if err != nil {
+			return nil, errors.Wrapf(err, "in key %s of jwks file %s", jwk.KeyID, path)
+		}
+
+		keys[jwk.KeyID] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.Wrapf(token.ErrorKeyNotConfigured, "no %s keys in jwks file %s", algorithm, path)
+	}
+
+	return keys, nil
+}
+
+func (jwk *jsonWebKey) verificationKey(algorithm string) (any, error) {
+	switch {
+	case jwk.KeyType == keyTypeRSA && algorithm == jwt.SigningMethodRS256.Alg():
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't decode modulus")
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't decode exponent")
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case jwk.KeyType == keyTypeSymmetric && algorithm == jwt.SigningMethodHS256.Alg():
+		k, err := base64.RawURLEncoding.DecodeString(jwk.K)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't decode symmetric key")
+		}
+
+		return k, nil
+	default:
+		return nil, errors.Wrapf(token.ErrorUnsupportedKeyType, "%s for algorithm %s", jwk.KeyType, algorithm)
+	}
+}
+
+// keyFunc выбирает ключ проверки подписи по заголовку kid токена
+func (ks keySet) keyFunc(tok *jwt.Token) (any, error) {
+	kid, _ := tok.Header["kid"].(string)
+
+	if key, ok := ks[kid]; ok {
+		return key, nil
+	}
+
+	if kid == "" && len(ks) == 1 {
+		for _, key := range ks {
+			return key, nil
+		}
+	}
+
+	return nil, errors.Wrapf(token.ErrorUnknownKeyID, "kid %s", kid)
+}
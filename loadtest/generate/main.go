@@ -91,7 +91,8 @@ func main() {
 			featureID := featureIDs[i]
 			banner := randjson.Make(jsonContentDepth, nil)
 
-			createdID, err := bannerRepository.CreateBanner(featureID, tags, types.Content(banner), true)
+			createdID, err := bannerRepository.CreateBanner(context.Background(), featureID, tags,
+				types.Content(banner), true, nil, 0)
 			if err != nil {
 				log.Fatal(err)
 			}
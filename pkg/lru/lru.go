@@ -0,0 +1,215 @@
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// EvictReason describes why an entry left the cache.
+type EvictReason string
+
+const (
+	// EvictCapacity the entry was the least recently used one when a limit was exceeded.
+	EvictCapacity EvictReason = "capacity"
+	// EvictExpired the entry outlived its ttl.
+	EvictExpired EvictReason = "expired"
+	// EvictRemoved the entry was removed explicitly by key or group.
+	EvictRemoved EvictReason = "removed"
+)
+
+type entry[V any] struct {
+	key       string
+	group     string
+	value     V
+	size      uint64
+	expiresAt time.Time
+}
+
+// Cache is a thread-safe LRU cache bounded by the number of entries and by their total size.
+// Every entry expires after its own ttl and may belong to a group, so that related entries
+// can be removed together. A zero limit disables the corresponding bound.
+type Cache[V any] struct {
+	mu sync.Mutex
+
+	maxEntries uint64
+	maxBytes   uint64
+	bytes      uint64
+
+	order  *list.List
+	items  map[string]*list.Element
+	groups map[string]map[string]struct{}
+
+	onEvict func(reason EvictReason)
+}
+
+// New creates a cache. If onEvict is not nil, it is called for every evicted entry
+// while the cache is locked, so it must not call the cache.
+func New[V any](maxEntries, maxBytes uint64, onEvict func(reason EvictReason)) *Cache[V] {
+	return &Cache[V]{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+		groups:     make(map[string]map[string]struct{}),
+		onEvict:    onEvict,
+	}
+}
+
+// Get returns the value stored by key and marks it as recently used.
+func (c *Cache[V]) Get(key string) (V, bool) {
+	value, _, ok := c.GetWithTTL(key)
+
+	return value, ok
+}
+
+// GetWithTTL returns the value stored by key together with its remaining ttl and marks it as recently used.
+func (c *Cache[V]) GetWithTTL(key string) (V, time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var empty V
+
+	elem, ok := c.items[key]
+	if !ok {
+		return empty, 0, false
+	}
+
+	e := elem.Value.(*entry[V]) //nolint: forcetypeassert // the list stores only entries
+
+	ttl := time.Until(e.expiresAt)
+	if ttl <= 0 {
+		c.remove(elem, EvictExpired)
+
+		return empty, 0, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	return e.value, ttl, true
+}
+
+// Set stores the value of the given size by key for ttl and adds it to group, if group is not empty.
+// A value larger than the size limit is not stored at all.
+func (c *Cache[V]) Set(key, group string, value V, size uint64, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem, "")
+	}
+
+	if ttl <= 0 || (c.maxBytes != 0 && size > c.maxBytes) {
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[V]{
+		key:       key,
+		group:     group,
+		value:     value,
+		size:      size,
+		expiresAt: time.Now().Add(ttl),
+	})
+	c.bytes += size
+
+	if group != "" {
+		if c.groups[group] == nil {
+			c.groups[group] = make(map[string]struct{})
+		}
+
+		c.groups[group][key] = struct{}{}
+	}
+
+	for (c.maxEntries != 0 && uint64(c.order.Len()) > c.maxEntries) || (c.maxBytes != 0 && c.bytes > c.maxBytes) {
+		c.remove(c.order.Back(), EvictCapacity)
+	}
+}
+
+// Remove removes entries by keys and returns the number of removed entries.
+func (c *Cache[V]) Remove(keys ...string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+
+	for _, key := range keys {
+		if elem, ok := c.items[key]; ok {
+			c.remove(elem, EvictRemoved)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// RemoveGroups removes all entries of the groups and returns the number of removed entries.
+func (c *Cache[V]) RemoveGroups(groups ...string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+
+	for _, group := range groups {
+		for key := range c.groups[group] {
+			c.remove(c.items[key], EvictRemoved)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// Groups returns the names of all groups that have entries.
+func (c *Cache[V]) Groups() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	groups := make([]string, 0, len(c.groups))
+	for group := range c.groups {
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// Purge removes all entries and returns the number of removed entries.
+func (c *Cache[V]) Purge() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := c.order.Len()
+
+	for c.order.Len() != 0 {
+		c.remove(c.order.Back(), EvictRemoved)
+	}
+
+	return removed
+}
+
+// Len returns the number of entries and their total size, including expired entries not yet evicted.
+func (c *Cache[V]) Len() (entries, bytes uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return uint64(c.order.Len()), c.bytes
+}
+
+// remove deletes the element from all indexes, an empty reason means the entry is being replaced.
+func (c *Cache[V]) remove(elem *list.Element, reason EvictReason) {
+	e := c.order.Remove(elem).(*entry[V]) //nolint: forcetypeassert // the list stores only entries
+
+	delete(c.items, e.key)
+	c.bytes -= e.size
+
+	if e.group != "" {
+		delete(c.groups[e.group], e.key)
+
+		if len(c.groups[e.group]) == 0 {
+			delete(c.groups, e.group)
+		}
+	}
+
+	if reason != "" && c.onEvict != nil {
+		c.onEvict(reason)
+	}
+}
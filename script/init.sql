@@ -4,7 +4,32 @@ CREATE TABLE IF NOT EXISTS banner
     is_active    boolean     not null default true,
     created_at   timestamptz not null default now(), -- время появление банера как такового
     updated_at   timestamptz not null default now(), -- время последнего изменения банера
-    last_version bigint      not null default 1
+    last_version bigint      not null default 1,
+    start_at     timestamptz,                        -- начало окна показа банера, null - без ограничения
+    end_at       timestamptz,                        -- конец окна показа банера, null - без ограничения
+    retention    bigint,                             -- число хранимых версий банера, null - значение из settings
+    cache_ttl    bigint,                             -- время жизни банера в кэше в мс, null - значение фичи
+    priority     integer     not null default 0,     -- приоритет банера при выборе по нескольким тэгам
+    constraint banner_schedule CHECK (start_at IS NULL OR end_at IS NULL OR start_at < end_at),
+    constraint banner_retention CHECK (retention IS NULL OR retention > 0),
+    constraint banner_cache_ttl CHECK (cache_ttl IS NULL OR cache_ttl > 0)
+);
+
+-- Глобальные настройки сервиса, таблица всегда содержит ровно одну строку
+CREATE TABLE IF NOT EXISTS settings
+(
+    id        boolean not null primary key default true CHECK (id),
+    retention bigint  not null default 3 CHECK (retention > 0) -- число хранимых версий банера по умолчанию
+);
+
+INSERT INTO settings DEFAULT VALUES ON CONFLICT DO NOTHING;
+
+-- Настройки фич. Фича без записи использует время жизни в кэше из конфига сервиса
+CREATE TABLE IF NOT EXISTS feature_settings
+(
+    feature_id bigint      not null primary key,
+    cache_ttl  bigint      not null CHECK (cache_ttl > 0), -- время жизни банеров фичи в кэше в мс
+    updated_at timestamptz not null default now()
 );
 
 
@@ -29,6 +54,109 @@ CREATE TABLE IF NOT EXISTS version_banner
     constraint banner_version UNIQUE (version, banner_id)
 );
 
+-- Версии банера, вышедшие за пределы хранимого числа версий
+CREATE TABLE IF NOT EXISTS archive_version_banner
+(
+    id          bigserial   not null primary key,
+    version     bigint      not null,
+    banner_id   bigint      not null references banner (id) on delete cascade,
+    content     jsonb       not null,
+    created_at  timestamptz not null,               -- время создания версии банера
+    archived_at timestamptz not null default now(), -- время переноса версии в архив
+    constraint archive_banner_version UNIQUE (version, banner_id)
+);
+
+-- Черновики содержимого банера, становящиеся версией банера только после рассмотрения и публикации
+CREATE TABLE IF NOT EXISTS draft_banner
+(
+    id                bigserial   not null primary key,
+    banner_id         bigint      not null references banner (id) on delete cascade,
+    content           jsonb       not null,
+    status            text        not null default 'pending', -- pending, approved, rejected или published
+    author            text        not null,                   -- субъект токена админа, создавшего черновик
+    reviewer          text,                                   -- субъект токена админа, рассмотревшего черновик
+    published_version bigint,                                 -- версия банера, созданная при публикации
+    created_at        timestamptz not null default now(),
+    updated_at        timestamptz not null default now(),
+    constraint draft_status CHECK (status IN ('pending', 'approved', 'rejected', 'published'))
+);
+
+CREATE INDEX draft_banner_id ON draft_banner (banner_id);
+
+CREATE TABLE IF NOT EXISTS experiment
+(
+    id                bigserial   not null primary key,
+    banner_id         bigint      not null references banner (id) on delete cascade,
+    status            text        not null default 'running', -- running, paused или concluded
+    winner_variant_id bigint,                                 -- вариант, ставший версией банера при завершении
+    created_at        timestamptz not null default now(),
+    updated_at        timestamptz not null default now(),
+    concluded_at      timestamptz,
+    constraint experiment_status CHECK (status IN ('running', 'paused', 'concluded'))
+);
+
+-- У банера может быть не более одного незавершённого эксперимента
+CREATE UNIQUE INDEX banner_experiment ON experiment (banner_id) WHERE status != 'concluded';
+
+CREATE TABLE IF NOT EXISTS experiment_variant
+(
+    id            bigserial not null primary key,
+    experiment_id bigint    not null references experiment (id) on delete cascade,
+    weight        bigint    not null CHECK (weight > 0),
+    content       jsonb     not null
+);
+
+CREATE INDEX experiment_variant_experiment ON experiment_variant (experiment_id);
+
+-- Журнал изменений банеров, выполненных админами
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id         bigserial   not null primary key,
+    actor      text,                              -- субъект токена админа, null - изменение не через API
+    request_id text,                              -- идентификатор запроса, в рамках которого выполнено изменение
+    action     text        not null,              -- create, update, delete, rollback, publish, conclude, draft, approve или reject
+    banner_id  bigint      not null,              -- без внешнего ключа, чтобы запись переживала удаление банера
+    before     jsonb,                             -- состояние банера до изменения, null - банер не существовал
+    after      jsonb,                             -- состояние банера после изменения, null - банер удалён
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX audit_log_banner ON audit_log (banner_id, created_at);
+CREATE INDEX audit_log_actor ON audit_log (actor, created_at);
+CREATE INDEX audit_log_created ON audit_log (created_at);
+
+-- Ключи доступа к API, выдаваемые админами. Сам ключ не хранится, только его хэш sha256
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id           bigserial   not null primary key,
+    key_hash     bytea       not null unique,
+    role         text        not null CHECK (role IN ('admin', 'admin_read_only', 'user')),
+    owner        text        not null,
+    feature_ids  bigint[],                          -- доступные админу фичи, null - все фичи
+    created_at   timestamptz not null default now(),
+    expires_at   timestamptz,                       -- null - ключ бессрочный
+    revoked_at   timestamptz,                       -- null - ключ не отозван
+    rotated_from bigint references api_keys (id)    -- ключ, на замену которому выдан данный
+);
+
+CREATE INDEX api_keys_owner ON api_keys (owner, id);
+
+-- Состояние банера для журнала изменений: фича, тэги, флаг активности и текущая версия
+CREATE OR REPLACE FUNCTION banner_snapshot(b_id bigint) RETURNS jsonb AS
+$$
+    SELECT jsonb_build_object(
+                   'feature_id', ftb.feature_id,
+                   'tag_ids', array_agg(ftb.tag_id ORDER BY ftb.tag_id),
+                   'is_active', b.is_active,
+                   'version', b.last_version
+           )
+    FROM banner b
+             INNER JOIN features_tags_banner ftb ON ftb.banner_id = b.id
+    WHERE b.id = b_id
+    GROUP BY b.id, ftb.feature_id
+    LIMIT 1;
+$$ LANGUAGE sql;
+
 -- Для обновления поля update_at после обновление таблицы banner
 CREATE OR REPLACE FUNCTION banner_update_trigger() RETURNS TRIGGER AS
 $$
@@ -45,7 +173,27 @@ CREATE OR REPLACE TRIGGER banner_update
     WHEN (OLD.* IS DISTINCT FROM NEW.*)
 EXECUTE FUNCTION banner_update_trigger();
 
--- Для обновления поля update_at после обновление таблицы banner
+-- Переносит в архив версии банера b_id, не входящие в число хранимых относительно версии current_version
+CREATE OR REPLACE FUNCTION archive_banner_versions(b_id bigint, current_version bigint) RETURNS VOID AS
+$$
+DECLARE
+    b_retention bigint;
+BEGIN
+    SELECT COALESCE(b.retention, s.retention) INTO b_retention
+    FROM banner b, settings s
+    WHERE b.id = b_id;
+
+    WITH archived AS (
+        DELETE FROM version_banner
+            WHERE banner_id = b_id and version <= current_version - b_retention
+            RETURNING version, banner_id, content, created_at
+    )
+    INSERT INTO archive_version_banner (version, banner_id, content, created_at)
+    SELECT version, banner_id, content, created_at FROM archived
+    ON CONFLICT DO NOTHING;
+END;
+$$ LANGUAGE plpgsql;
+
 CREATE OR REPLACE FUNCTION banner_insert_version_trigger() RETURNS TRIGGER AS
 $$
 DECLARE
@@ -56,7 +204,7 @@ BEGIN
     IF v_count != 0 THEN
         SELECT max(version) INTO last_version FROM version_banner WHERE banner_id = NEW.banner_id;
         NEW.version = last_version + 1;
-        DELETE FROM version_banner WHERE banner_id = NEW.banner_id and version < last_version - 1;
+        PERFORM archive_banner_versions(NEW.banner_id, NEW.version);
         UPDATE banner SET last_version = NEW.version WHERE id = NEW.banner_id;
     END IF;
     RETURN NEW;
@@ -74,4 +222,14 @@ CREATE INDEX banner_feature on features_tags_banner (feature_id) WHERE not delet
 CREATE INDEX banner_tag on features_tags_banner (tag_id) WHERE not deleted;
 CREATE INDEX banner_feature_ids on features_tags_banner (banner_id) WHERE not deleted;
 CREATE INDEX version_banner_id ON version_banner(banner_id);
+CREATE INDEX archive_version_banner_id ON archive_version_banner(banner_id);
 CREATE INDEX feature_banner on features_tags_banner(banner_id, feature_id);
+
+-- Для постраничной выдачи баннеров админу по курсору с сортировкой по времени
+CREATE INDEX banner_created ON banner (created_at, id);
+CREATE INDEX banner_updated ON banner (updated_at, id);
+
+-- Для поиска баннеров по содержимому: предикатов jsonpath и полнотекстового поиска по строковым значениям
+CREATE INDEX version_banner_content_path ON version_banner USING gin (content jsonb_path_ops);
+CREATE INDEX version_banner_content_text ON version_banner
+    USING gin (jsonb_to_tsvector('simple'::regconfig, content, '["string"]'::jsonb));